@@ -16,6 +16,7 @@ import (
 	"github.com/yuqie6/WorkMirror/internal/bootstrap"
 	"github.com/yuqie6/WorkMirror/internal/collector"
 	"github.com/yuqie6/WorkMirror/internal/pkg/config"
+	"github.com/yuqie6/WorkMirror/internal/pkg/lockfile"
 	"github.com/yuqie6/WorkMirror/internal/server"
 )
 
@@ -48,10 +49,24 @@ func main() {
 	}
 	defer rt.Close()
 
+	// 与 headless Agent 共用单实例锁文件：CLI 据此识别正在运行的 Agent（互斥本身由上面的 Mutex 保证）
+	if lock, err := lockfile.TryLock(bootstrap.AgentLockPath(rt.Cfg)); err == nil {
+		defer lock.Unlock()
+	}
+
 	slog.Info("Mirror Agent 启动中...", "name", rt.Cfg.App.Name, "version", rt.Cfg.App.Version)
 	slog.Info("Mirror Agent 已启动")
 
-	uiServer, err := server.Start(ctx, rt, server.Options{ListenAddr: "127.0.0.1:0"})
+	// 启用编辑器心跳时使用固定端口，插件的 api_url 才能保持不变；端口被占用则退回随机端口
+	listenAddr := "127.0.0.1:0"
+	if rt.Cfg.Editor.Enabled && rt.Cfg.Editor.ListenAddr != "" {
+		listenAddr = rt.Cfg.Editor.ListenAddr
+	}
+	uiServer, err := server.Start(ctx, rt, server.Options{ListenAddr: listenAddr})
+	if err != nil && listenAddr != "127.0.0.1:0" {
+		slog.Warn("固定端口不可用，编辑器插件将无法上报心跳", "listen_addr", listenAddr, "error", err)
+		uiServer, err = server.Start(ctx, rt, server.Options{ListenAddr: "127.0.0.1:0"})
+	}
 	if err != nil {
 		slog.Error("启动本地 UI/API 失败", "error", err)
 	}
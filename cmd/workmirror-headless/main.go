@@ -0,0 +1,87 @@
+// workmirror-headless 无托盘的 Agent：采集 + 本地 HTTP API，可在 Linux/CI 上运行。
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/bootstrap"
+	"github.com/yuqie6/WorkMirror/internal/pkg/config"
+	"github.com/yuqie6/WorkMirror/internal/pkg/lockfile"
+	"github.com/yuqie6/WorkMirror/internal/server"
+)
+
+// defaultListenAddr 固定端口，便于脚本/CI 直接访问（可用 -listen 覆盖）
+const defaultListenAddr = "127.0.0.1:7410"
+
+func main() {
+	cfgPath := flag.String("config", "", "配置文件路径（默认: <exe>/config/config.yaml）")
+	listenAddr := flag.String("listen", defaultListenAddr, "HTTP 监听地址（127.0.0.1:0 表示随机端口）")
+	flag.Parse()
+
+	if err := run(*cfgPath, *listenAddr); err != nil {
+		fmt.Fprintln(os.Stderr, "workmirror-headless:", err)
+		os.Exit(1)
+	}
+}
+
+func run(cfgPath, listenAddr string) error {
+	if cfgPath == "" {
+		p, err := config.DefaultConfigPath()
+		if err != nil {
+			return err
+		}
+		cfgPath = p
+		if _, err := os.Stat(cfgPath); errors.Is(err, os.ErrNotExist) {
+			_ = config.WriteFile(cfgPath, config.Default())
+		}
+	}
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	// 单实例：同一个数据库只允许一个 Agent 写入
+	lockPath := bootstrap.AgentLockPath(cfg)
+	lock, err := lockfile.TryLock(lockPath)
+	if err != nil {
+		if errors.Is(err, lockfile.ErrLocked) {
+			return fmt.Errorf("已有 Agent 在运行（%s）", lockPath)
+		}
+		return err
+	}
+	defer lock.Unlock()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	rt, err := bootstrap.NewAgentRuntime(ctx, cfgPath)
+	if err != nil {
+		return fmt.Errorf("启动 Agent 失败: %w", err)
+	}
+	defer rt.Close()
+
+	srv, err := server.Start(ctx, rt, server.Options{ListenAddr: listenAddr})
+	if err != nil {
+		return fmt.Errorf("启动本地 API 失败: %w", err)
+	}
+	slog.Info("Mirror Agent（headless）已启动", "version", rt.Cfg.App.Version, "base_url", srv.BaseURL())
+
+	<-ctx.Done()
+	slog.Info("正在关闭...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = srv.Shutdown(shutdownCtx)
+
+	slog.Info("Mirror Agent 已退出")
+	return nil
+}
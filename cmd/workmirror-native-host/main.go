@@ -0,0 +1,89 @@
+// workmirror-native-host 浏览器扩展的 native messaging host：
+// 由浏览器在扩展调用 connectNative 时启动，从 stdin 读取标签页切换/导航/空闲事件，
+// 还原为前台停留记录写入 Agent 的数据库。stdout 只用于协议，日志输出到 stderr。
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/yuqie6/WorkMirror/internal/pkg/config"
+	"github.com/yuqie6/WorkMirror/internal/pkg/nativemsg"
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+	"github.com/yuqie6/WorkMirror/internal/repository"
+	"github.com/yuqie6/WorkMirror/internal/service"
+)
+
+func main() {
+	// 浏览器会追加扩展来源（chrome-extension://<id>/）等参数；flag 在第一个非 flag 参数处停止解析
+	cfgPath := flag.String("config", "", "配置文件路径（默认: <exe>/config/config.yaml）")
+	flag.Parse()
+
+	if err := run(*cfgPath); err != nil {
+		fmt.Fprintln(os.Stderr, "workmirror-native-host:", err)
+		os.Exit(1)
+	}
+}
+
+func run(cfgPath string) error {
+	if cfgPath == "" {
+		p, err := config.DefaultConfigPath()
+		if err != nil {
+			return err
+		}
+		cfgPath = p
+	}
+	if _, err := os.Stat(cfgPath); err != nil {
+		return fmt.Errorf("读取配置文件失败（需与 Agent 安装在同一目录）: %w", err)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Browser.NativeHostBrowsers) == 0 {
+		return errors.New("未启用：请在配置文件中设置 browser.native_host_browsers")
+	}
+
+	logCloser, _ := config.SetupLogger(config.LoggerOptions{
+		Level:     cfg.App.LogLevel,
+		Path:      cfg.App.LogPath,
+		Component: "workmirror-native-host",
+		Console:   os.Stderr,
+	})
+	if logCloser != nil {
+		defer logCloser.Close()
+	}
+
+	// 迁移由 Agent 负责：这里只打开已初始化的数据库
+	db, err := repository.OpenExistingDatabase(cfg.Storage.DBPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	svc := service.NewBrowserFocusService(repository.NewBrowserEventRepository(db.DB), cfg.Browser.NativeHostBrowsers)
+	svc.SetSanitizer(privacy.New(cfg.Privacy.Enabled, cfg.Privacy.Patterns))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	// 收到信号时关闭 stdin，解除阻塞中的读取
+	go func() {
+		<-ctx.Done()
+		_ = os.Stdin.Close()
+	}()
+
+	slog.Info("native messaging host 已启动", "browsers", cfg.Browser.NativeHostBrowsers)
+	serveErr := nativemsg.Serve(ctx, os.Stdin, os.Stdout, svc.HandleMessage)
+	if ctx.Err() != nil {
+		serveErr = nil
+	}
+	closeErr := svc.Close(context.Background())
+	slog.Info("native messaging host 已退出", "persisted", svc.Persisted())
+	return errors.Join(serveErr, closeErr)
+}
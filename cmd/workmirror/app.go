@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/bootstrap"
+	"github.com/yuqie6/WorkMirror/internal/pkg/lockfile"
+	"github.com/yuqie6/WorkMirror/internal/server"
+)
+
+// commonFlags 所有子命令共享的参数
+type commonFlags struct {
+	configPath  string
+	jsonOut     bool
+	lockTimeout time.Duration
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *commonFlags) {
+	fs := flag.NewFlagSet("workmirror "+name, flag.ContinueOnError)
+	c := &commonFlags{}
+	fs.StringVar(&c.configPath, "config", "", "配置文件路径")
+	fs.BoolVar(&c.jsonOut, "json", false, "输出 JSON")
+	fs.DurationVar(&c.lockTimeout, "lock-timeout", 2*time.Minute, "等待写锁的最长时间")
+	return fs, c
+}
+
+// app CLI 运行时：复用 HTTP API 的处理逻辑（进程内调用，不监听端口），保证输出与 API 契约一致
+type app struct {
+	flags *commonFlags
+	core  *bootstrap.Core
+	api   http.Handler
+	out   io.Writer
+}
+
+func openApp(c *commonFlags) (*app, error) {
+	core, err := bootstrap.NewCoreWithOptions(c.configPath, bootstrap.CoreOptions{LogConsole: os.Stderr})
+	if err != nil {
+		return nil, err
+	}
+	// 不启动采集器与后台任务：只借用 Core 的仓储与服务
+	rt := &bootstrap.AgentRuntime{Core: core}
+	return &app{
+		flags: c,
+		core:  core,
+		api:   server.NewAPIHandler(rt, nil),
+		out:   os.Stdout,
+	}, nil
+}
+
+func (a *app) Close() error {
+	return a.core.Close()
+}
+
+// withWriteLock 写命令需先拿到写锁，与 Agent 的后台切分/补全任务互斥
+func (a *app) withWriteLock(fn func() error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.flags.lockTimeout)
+	defer cancel()
+
+	path := bootstrap.WriteLockPath(a.core.Cfg)
+	lock, err := lockfile.TryLock(path)
+	if errors.Is(err, lockfile.ErrLocked) {
+		fmt.Fprintln(os.Stderr, "Agent 后台任务正在写入，等待写锁...", path)
+		lock, err = lockfile.Wait(ctx, path, 500*time.Millisecond)
+	}
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	return fn()
+}
+
+// memResponse 进程内调用 API 的响应缓冲
+type memResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *memResponse) Header() http.Header { return r.header }
+
+func (r *memResponse) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *memResponse) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+// call 进程内调用 /api 路由；非 2xx 时返回 API 的错误信息
+func (a *app) call(method, path string, body any) ([]byte, error) {
+	var reader io.Reader = http.NoBody
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b // 原样转发（导入文件）
+	default:
+		raw, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(context.Background(), method, path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp := &memResponse{header: make(http.Header)}
+	a.api.ServeHTTP(resp, req)
+
+	if resp.status < 200 || resp.status >= 300 {
+		var apiErr struct {
+			Error string `json:"error"`
+			Code  string `json:"code"`
+			Hint  string `json:"hint"`
+		}
+		if json.Unmarshal(resp.body.Bytes(), &apiErr) == nil && apiErr.Error != "" {
+			msg := apiErr.Error
+			if apiErr.Hint != "" {
+				msg += "（" + apiErr.Hint + "）"
+			}
+			return nil, errors.New(msg)
+		}
+		return nil, fmt.Errorf("%s %s: HTTP %d", method, path, resp.status)
+	}
+	return resp.body.Bytes(), nil
+}
+
+// render JSON 模式原样（缩进）输出 API 响应；否则解码为 v 并交给 table 渲染
+func (a *app) render(payload []byte, v any, table func(w io.Writer)) error {
+	if a.flags.jsonOut {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, bytes.TrimSpace(payload), "", "  "); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		_, err := a.out.Write(buf.Bytes())
+		return err
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	table(a.out)
+	return nil
+}
+
+func today() string {
+	return time.Now().Format("2006-01-02")
+}
+
+// validateDate 校验 YYYY-MM-DD
+func validateDate(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if _, err := time.ParseInLocation("2006-01-02", s, time.Local); err != nil {
+		return "", fmt.Errorf("日期格式错误，请使用 YYYY-MM-DD: %q", s)
+	}
+	return s, nil
+}
@@ -0,0 +1,541 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/bootstrap"
+	"github.com/yuqie6/WorkMirror/internal/dto"
+)
+
+func runSessions(args []string) error {
+	sub, rest, err := subcommand("sessions", args, "build", "rebuild", "enrich", "list")
+	if err != nil {
+		return err
+	}
+	fs, common := newFlagSet("sessions " + sub)
+	date := fs.String("date", today(), "日期 YYYY-MM-DD")
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+	d, err := validateDate(*date)
+	if err != nil {
+		return err
+	}
+
+	a, err := openApp(common)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	if sub == "list" {
+		payload, err := a.call(http.MethodGet, "/api/sessions/by-date?date="+url.QueryEscape(d), nil)
+		if err != nil {
+			return err
+		}
+		var sessions []dto.SessionDTO
+		return a.render(payload, &sessions, func(w io.Writer) { printSessions(w, sessions) })
+	}
+
+	return a.withWriteLock(func() error {
+		payload, err := a.call(http.MethodPost, "/api/sessions/"+sub, &dto.DateRequestDTO{Date: d})
+		if err != nil {
+			return err
+		}
+		var res dto.SessionBuildResultDTO
+		return a.render(payload, &res, func(w io.Writer) {
+			switch sub {
+			case "enrich":
+				fmt.Fprintf(w, "%s 语义补全 %d 个会话\n", d, res.Enriched)
+			default:
+				fmt.Fprintf(w, "%s 新建 %d 个会话，语义补全 %d 个\n", d, res.Created, res.Enriched)
+			}
+		})
+	})
+}
+
+func runSummary(args []string) error {
+	sub, rest, err := subcommand("summary", args, "daily", "week", "month")
+	if err != nil {
+		return err
+	}
+	fs, common := newFlagSet("summary " + sub)
+	date := fs.String("date", today(), "日报日期 YYYY-MM-DD（daily）")
+	start := fs.String("start", "", "周期内任意一天 YYYY-MM-DD（week/month，默认本周/本月）")
+	force := fs.Bool("force", false, "忽略缓存重新生成")
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+
+	q := url.Values{}
+	if *force {
+		q.Set("force", "1")
+	}
+	var path string
+	if sub == "daily" {
+		d, err := validateDate(*date)
+		if err != nil {
+			return err
+		}
+		q.Set("date", d)
+		path = "/api/summary/daily?" + q.Encode()
+	} else {
+		q.Set("type", sub)
+		if strings.TrimSpace(*start) != "" {
+			d, err := validateDate(*start)
+			if err != nil {
+				return err
+			}
+			q.Set("start_date", d)
+		}
+		path = "/api/summary/period?" + q.Encode()
+	}
+
+	a, err := openApp(common)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	// 总结会写入缓存表（日报/周期汇总），按写命令处理
+	return a.withWriteLock(func() error {
+		payload, err := a.call(http.MethodGet, path, nil)
+		if err != nil {
+			return err
+		}
+		if sub == "daily" {
+			var s dto.DailySummaryDTO
+			return a.render(payload, &s, func(w io.Writer) { printDailySummary(w, &s) })
+		}
+		var s dto.PeriodSummaryDTO
+		return a.render(payload, &s, func(w io.Writer) { printPeriodSummary(w, &s) })
+	})
+}
+
+func runTrends(args []string) error {
+	fs, common := newFlagSet("trends")
+	period := fs.Int("period", 7, "统计周期（天）：7 或 30")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *period != 7 && *period != 30 {
+		return fmt.Errorf("--period 只支持 7 或 30")
+	}
+
+	a, err := openApp(common)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	payload, err := a.call(http.MethodGet, "/api/trends?days="+strconv.Itoa(*period), nil)
+	if err != nil {
+		return err
+	}
+	var report dto.TrendReportDTO
+	return a.render(payload, &report, func(w io.Writer) { printTrends(w, &report) })
+}
+
+func runSkills(args []string) error {
+	_, rest, err := subcommand("skills", args, "tree")
+	if err != nil {
+		return err
+	}
+	fs, common := newFlagSet("skills tree")
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+
+	a, err := openApp(common)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	payload, err := a.call(http.MethodGet, "/api/skills/tree", nil)
+	if err != nil {
+		return err
+	}
+	var nodes []dto.SkillNodeDTO
+	return a.render(payload, &nodes, func(w io.Writer) { printSkillTree(w, nodes) })
+}
+
+func runImport(args []string) error {
+	sub, rest, err := subcommand("import", args, "git", "activitywatch", "wakatime", "calendar")
+	if err != nil {
+		return err
+	}
+	switch sub {
+	case "activitywatch", "wakatime":
+		return runHistoryImport(sub, rest)
+	case "calendar":
+		return runCalendarImport(rest)
+	}
+	fs, common := newFlagSet("import git")
+	start := fs.String("start", "", "起始日期 YYYY-MM-DD（必填）")
+	end := fs.String("end", "", "结束日期 YYYY-MM-DD（含当天，默认至今）")
+	author := fs.String("author", "", "作者邮箱（默认各仓库的 git user.email）")
+	repos := fs.String("repos", "", "仓库目录，逗号分隔（默认 diff.watch_paths 下的仓库）")
+	analyze := fs.Bool("analyze", false, "导入后用 AI 分析 Diff（默认按语言离线归因技能）")
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+	req := dto.GitImportRequestDTO{AuthorEmail: *author, Analyze: *analyze}
+	if req.StartDate, err = validateDate(*start); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*end) != "" {
+		if req.EndDate, err = validateDate(*end); err != nil {
+			return err
+		}
+	}
+	for _, r := range strings.Split(*repos, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			req.Repos = append(req.Repos, r)
+		}
+	}
+
+	a, err := openApp(common)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	return a.withWriteLock(func() error {
+		if _, err := a.call(http.MethodPost, "/api/import/git", &req); err != nil {
+			return err
+		}
+		// 导入在进程内后台执行：轮询进度输出到 stderr，结束后按常规格式输出结果
+		for {
+			time.Sleep(time.Second)
+			payload, err := a.call(http.MethodGet, "/api/import/git/status", nil)
+			if err != nil {
+				return err
+			}
+			var st dto.GitImportStatusDTO
+			if err := json.Unmarshal(payload, &st); err != nil {
+				return fmt.Errorf("解析响应失败: %w", err)
+			}
+			if st.Running {
+				fmt.Fprintf(os.Stderr, "[%s] 仓库 %d/%d，提交 %d（已导入 %d），Diff %d\n",
+					st.Phase, st.ReposDone, st.ReposTotal, st.CommitsScanned, st.CommitsImported, st.DiffsCreated)
+				continue
+			}
+			if err := a.render(payload, &st, func(w io.Writer) { printGitImport(w, &st) }); err != nil {
+				return err
+			}
+			if st.Error != "" {
+				return fmt.Errorf("导入未完全成功: %s", st.Error)
+			}
+			return nil
+		}
+	})
+}
+
+// runHistoryImport 导入 ActivityWatch / WakaTime 的导出文件（同步执行，可重复导入）
+func runHistoryImport(source string, args []string) error {
+	fs, common := newFlagSet("import " + source)
+	file := fs.String("file", "", "导出文件路径（必填）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*file) == "" {
+		return fmt.Errorf("--file 不能为空")
+	}
+	f, err := os.Open(*file)
+	if err != nil {
+		return fmt.Errorf("打开导出文件失败: %w", err)
+	}
+	defer f.Close()
+
+	a, err := openApp(common)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	return a.withWriteLock(func() error {
+		payload, err := a.call(http.MethodPost, "/api/import/"+source, f)
+		if err != nil {
+			return err
+		}
+		var res dto.HistoryImportResultDTO
+		return a.render(payload, &res, func(w io.Writer) { printHistoryImport(w, &res) })
+	})
+}
+
+// runCalendarImport 导入 .ics 日程（同步执行；再次导入同一文件会同步修改与删除）
+func runCalendarImport(args []string) error {
+	fs, common := newFlagSet("import calendar")
+	files := fs.String("file", "", ".ics 文件路径，逗号分隔（必填）")
+	start := fs.String("start", "", "起始日期 YYYY-MM-DD（默认 90 天前）")
+	end := fs.String("end", "", "结束日期 YYYY-MM-DD（含当天，默认 30 天后）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var req dto.CalendarImportRequestDTO
+	for _, f := range strings.Split(*files, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			req.Files = append(req.Files, f)
+		}
+	}
+	if len(req.Files) == 0 {
+		return fmt.Errorf("--file 不能为空")
+	}
+	var err error
+	if strings.TrimSpace(*start) != "" {
+		if req.StartDate, err = validateDate(*start); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(*end) != "" {
+		if req.EndDate, err = validateDate(*end); err != nil {
+			return err
+		}
+	}
+
+	a, err := openApp(common)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	return a.withWriteLock(func() error {
+		payload, err := a.call(http.MethodPost, "/api/import/calendar", &req)
+		if err != nil {
+			return err
+		}
+		var res dto.CalendarImportResultDTO
+		return a.render(payload, &res, func(w io.Writer) { printCalendarImport(w, &res) })
+	})
+}
+
+// statusOutput CLI 进程内不运行采集器，collectors 段以 agent_running 为准解读
+type statusOutput struct {
+	AgentRunning bool            `json:"agent_running"`
+	Status       json.RawMessage `json:"status"`
+}
+
+func runStatus(args []string) error {
+	fs, common := newFlagSet("status")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	a, err := openApp(common)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	payload, err := a.call(http.MethodGet, "/api/status", nil)
+	if err != nil {
+		return err
+	}
+	out, err := json.Marshal(&statusOutput{
+		AgentRunning: bootstrap.AgentRunning(a.core.Cfg),
+		Status:       json.RawMessage(payload),
+	})
+	if err != nil {
+		return err
+	}
+	var st struct {
+		AgentRunning bool          `json:"agent_running"`
+		Status       dto.StatusDTO `json:"status"`
+	}
+	return a.render(out, &st, func(w io.Writer) { printStatus(w, st.AgentRunning, &st.Status) })
+}
+
+// ===== 表格输出 =====
+
+func printGitImport(w io.Writer, st *dto.GitImportStatusDTO) {
+	fmt.Fprintf(w, "仓库 %d 个，扫描提交 %d 个，导入 %d 个（已导入过 %d 个）\n",
+		st.ReposTotal, st.CommitsScanned, st.CommitsImported, st.CommitsSkipped)
+	fmt.Fprintf(w, "新建 Diff %d 条，AI 分析 %d 条，新建会话 %d 个\n", st.DiffsCreated, st.DiffsAnalyzed, st.SessionsCreated)
+}
+
+func printHistoryImport(w io.Writer, res *dto.HistoryImportResultDTO) {
+	fmt.Fprintf(w, "导入事件 %d 条，浏览记录 %d 条（已导入过 %d 条，跳过 %d 条）\n",
+		res.EventsImported, res.BrowserImported, res.Duplicates, res.Skipped)
+	if res.EventsImported+res.BrowserImported > 0 {
+		fmt.Fprintf(w, "时间范围 %s ~ %s，新建会话 %d 个\n", formatMs(res.StartTime), formatMs(res.EndTime), res.SessionsCreated)
+	}
+}
+
+func printCalendarImport(w io.Writer, res *dto.CalendarImportResultDTO) {
+	fmt.Fprintf(w, "文件 %d 个：新增日程 %d 条，更新 %d 条，删除 %d 条，未变 %d 条（跳过全天/取消/空闲 %d 条）\n",
+		res.Files, res.Imported, res.Updated, res.Removed, res.Unchanged, res.Skipped)
+	fmt.Fprintf(w, "时间范围 %s ~ %s，新建会话 %d 个\n", formatMs(res.StartTime), formatMs(res.EndTime), res.SessionsCreated)
+}
+
+func newTable(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+}
+
+func formatMs(ms int64) string {
+	if ms <= 0 {
+		return "-"
+	}
+	return time.UnixMilli(ms).Format("2006-01-02 15:04:05")
+}
+
+func oneLine(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	r := []rune(s)
+	if max > 0 && len(r) > max {
+		return string(r[:max]) + "…"
+	}
+	return s
+}
+
+func printSessions(w io.Writer, sessions []dto.SessionDTO) {
+	if len(sessions) == 0 {
+		fmt.Fprintln(w, "（无会话）")
+		return
+	}
+	tw := newTable(w)
+	fmt.Fprintln(tw, "ID\tTIME\tAPP\tCATEGORY\tDIFFS\tBROWSER\tSEMANTIC\tSUMMARY")
+	for _, s := range sessions {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			s.ID, s.TimeRange, s.PrimaryApp, s.Category, s.DiffCount, s.BrowserCount, s.SemanticSource, oneLine(s.Summary, 60))
+	}
+	_ = tw.Flush()
+}
+
+func printDailySummary(w io.Writer, s *dto.DailySummaryDTO) {
+	fmt.Fprintf(w, "日报 %s（编码 %d 分钟，变更 %d 次）\n\n", s.Date, s.TotalCoding, s.TotalDiffs)
+	fmt.Fprintf(w, "总结:\n%s\n\n", strings.TrimSpace(s.Summary))
+	fmt.Fprintf(w, "亮点:\n%s\n\n", strings.TrimSpace(s.Highlights))
+	fmt.Fprintf(w, "困难:\n%s\n\n", strings.TrimSpace(s.Struggles))
+	fmt.Fprintf(w, "技能: %s\n", strings.Join(s.SkillsGained, ", "))
+}
+
+func printPeriodSummary(w io.Writer, s *dto.PeriodSummaryDTO) {
+	fmt.Fprintf(w, "%s %s ~ %s（编码 %d 分钟，变更 %d 次）\n\n", s.Type, s.StartDate, s.EndDate, s.TotalCoding, s.TotalDiffs)
+	fmt.Fprintf(w, "概览:\n%s\n\n", strings.TrimSpace(s.Overview))
+	if len(s.Achievements) > 0 {
+		fmt.Fprintln(w, "成果:")
+		for _, it := range s.Achievements {
+			fmt.Fprintf(w, "- %s\n", it)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "模式:\n%s\n\n", strings.TrimSpace(s.Patterns))
+	fmt.Fprintf(w, "建议:\n%s\n\n", strings.TrimSpace(s.Suggestions))
+	fmt.Fprintf(w, "技能: %s\n", strings.Join(s.TopSkills, ", "))
+}
+
+func printTrends(w io.Writer, r *dto.TrendReportDTO) {
+	fmt.Fprintf(w, "趋势 %s（%s ~ %s）: 变更 %d 次，编码 %d 分钟，日均 %.1f 次\n\n",
+		r.Period, r.StartDate, r.EndDate, r.TotalDiffs, r.TotalCodingMins, r.AvgDiffsPerDay)
+
+	tw := newTable(w)
+	fmt.Fprintln(tw, "LANGUAGE\tDIFFS\tSHARE")
+	for _, l := range r.TopLanguages {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%%\n", l.Language, l.DiffCount, l.Percentage)
+	}
+	_ = tw.Flush()
+	fmt.Fprintln(w)
+
+	tw = newTable(w)
+	fmt.Fprintln(tw, "SKILL\tSTATUS\tDAYS\tCHANGES\tEXP\tGROWTH")
+	for _, s := range r.TopSkills {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%.1f\t%.0f%%\n", s.SkillName, s.Status, s.DaysActive, s.Changes, s.ExpGain, s.GrowthRate*100)
+	}
+	_ = tw.Flush()
+
+	if len(r.DailyStats) > 0 {
+		fmt.Fprintln(w)
+		tw = newTable(w)
+		fmt.Fprintln(tw, "DATE\tDIFFS\tCODING_MIN\tSESSIONS")
+		for _, d := range r.DailyStats {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", d.Date, d.TotalDiffs, d.TotalCodingMins, d.SessionCount)
+		}
+		_ = tw.Flush()
+	}
+
+	if len(r.Bottlenecks) > 0 {
+		fmt.Fprintln(w, "\n瓶颈:")
+		for _, b := range r.Bottlenecks {
+			fmt.Fprintf(w, "- %s\n", b)
+		}
+	}
+}
+
+func printSkillTree(w io.Writer, nodes []dto.SkillNodeDTO) {
+	if len(nodes) == 0 {
+		fmt.Fprintln(w, "（暂无技能）")
+		return
+	}
+	children := make(map[string][]dto.SkillNodeDTO)
+	known := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		known[n.Key] = true
+	}
+	for _, n := range nodes {
+		parent := n.ParentKey
+		if !known[parent] {
+			parent = "" // 父节点不在结果中时按根节点展示
+		}
+		children[parent] = append(children[parent], n)
+	}
+	for k := range children {
+		list := children[k]
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Experience != list[j].Experience {
+				return list[i].Experience > list[j].Experience
+			}
+			return list[i].Key < list[j].Key
+		})
+	}
+
+	tw := newTable(w)
+	fmt.Fprintln(tw, "SKILL\tCATEGORY\tLEVEL\tEXP\tPROGRESS\tSTATUS\tLAST_ACTIVE")
+	var walk func(parent string, depth int)
+	walk = func(parent string, depth int) {
+		for _, n := range children[parent] {
+			fmt.Fprintf(tw, "%s%s\t%s\t%d\t%d\t%d%%\t%s\t%s\n",
+				strings.Repeat("  ", depth), n.Name, n.Category, n.Level, n.Experience, n.Progress, n.Status, formatMs(n.LastActive))
+			if depth < 8 {
+				walk(n.Key, depth+1)
+			}
+		}
+	}
+	walk("", 0)
+	_ = tw.Flush()
+}
+
+func printStatus(w io.Writer, agentRunning bool, st *dto.StatusDTO) {
+	tw := newTable(w)
+	fmt.Fprintf(tw, "Agent\t%s\n", map[bool]string{true: "运行中", false: "未运行"}[agentRunning])
+	fmt.Fprintf(tw, "版本\t%s\n", st.App.Version)
+	fmt.Fprintf(tw, "配置\t%s\n", st.App.ConfigPath)
+	fmt.Fprintf(tw, "数据库\t%s（schema v%d）\n", st.Storage.DBPath, st.Storage.SchemaVersion)
+	if st.App.SafeMode {
+		fmt.Fprintf(tw, "安全模式\t%s\n", st.Storage.SafeModeReason)
+	}
+	fmt.Fprintf(tw, "窗口事件(24h)\t%d（最后落库 %s）\n", st.Collectors.Window.Count24h, formatMs(st.Collectors.Window.LastPersistedAt))
+	fmt.Fprintf(tw, "代码变更(24h)\t%d（最后落库 %s）\n", st.Collectors.Diff.Count24h, formatMs(st.Collectors.Diff.LastPersistedAt))
+	fmt.Fprintf(tw, "浏览记录(24h)\t%d（最后落库 %s）\n", st.Collectors.Browser.Count24h, formatMs(st.Collectors.Browser.LastPersistedAt))
+	fmt.Fprintf(tw, "会话(24h)\t%d（待语义补全 %d，最后切分 %s）\n",
+		st.Pipeline.Sessions.Sessions24h, st.Pipeline.Sessions.PendingSemantic24h, formatMs(st.Pipeline.Sessions.LastSplitAt))
+	fmt.Fprintf(tw, "AI\t%s（configured=%v）\n", st.Pipeline.AI.Mode, st.Pipeline.AI.Configured)
+	_ = tw.Flush()
+
+	if len(st.RecentErrors) > 0 {
+		fmt.Fprintln(w, "\n最近错误:")
+		for _, e := range st.RecentErrors {
+			fmt.Fprintf(w, "- %s %s\n", e.Time, oneLine(e.Message, 120))
+		}
+	}
+}
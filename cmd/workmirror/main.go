@@ -0,0 +1,80 @@
+// workmirror 命令行：不依赖托盘 Agent，直接对同一数据库执行会话切分、总结生成与查询。
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const usage = `用法: workmirror <command> [flags]
+
+命令:
+  sessions build|rebuild|enrich [--date YYYY-MM-DD]   切分/重建/语义补全指定日期的会话
+  sessions list [--date YYYY-MM-DD]                   列出指定日期的会话
+  summary daily [--date YYYY-MM-DD] [--force]         生成（或读取缓存的）日报
+  summary week|month [--start YYYY-MM-DD] [--force]   生成（或读取缓存的）周报/月报
+  trends [--period 7|30]                              趋势报告
+  skills tree                                         技能树
+  status                                              数据库/管道状态
+  import git --start YYYY-MM-DD [--end YYYY-MM-DD]     从 Git 历史导入 Diff、技能与会话（可重复执行）
+             [--author EMAIL] [--repos A,B] [--analyze]
+  import activitywatch|wakatime --file PATH          导入 ActivityWatch / WakaTime 导出的历史并切分会话（可重复执行）
+  import calendar --file A.ics[,B.ics]                导入日程，会议时段标记为 meeting 会话（可重复执行，同步修改与删除）
+             [--start YYYY-MM-DD] [--end YYYY-MM-DD]
+
+通用参数:
+  --config PATH          配置文件路径（默认: <exe>/config/config.yaml）
+  --json                 输出 JSON（默认输出表格）
+  --lock-timeout 2m      写命令等待写锁的最长时间（Agent 后台任务持锁时）
+`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, "workmirror:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Print(usage)
+		return nil
+	}
+
+	cmd, rest := args[0], args[1:]
+	switch cmd {
+	case "sessions":
+		return runSessions(rest)
+	case "summary":
+		return runSummary(rest)
+	case "trends":
+		return runTrends(rest)
+	case "skills":
+		return runSkills(rest)
+	case "status":
+		return runStatus(rest)
+	case "import":
+		return runImport(rest)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("未知命令: %s", cmd)
+	}
+}
+
+// subcommand 拆出二级子命令（如 sessions build）
+func subcommand(group string, args []string, allowed ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("%s 需要子命令: %v", group, allowed)
+	}
+	for _, a := range allowed {
+		if args[0] == a {
+			return a, args[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("%s 不支持子命令 %q（可选: %v）", group, args[0], allowed)
+}
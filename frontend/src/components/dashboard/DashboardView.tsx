@@ -28,7 +28,19 @@ interface TrendReportDTO {
     total_diffs: number;
     total_coding_mins: number;
     session_count: number;
+    browsing_categories?: CategoryTrend[];
   }>;
+  top_domains?: Array<{ domain: string; category: string; skill?: string; visits: number; minutes: number }>;
+  browsing_mins: number;
+  browsing_categories?: CategoryTrend[];
+  session_browsing?: Array<{ session_id: number; date: string; start_time: number; end_time: number; categories: CategoryTrend[] }>;
+}
+
+// 域名分类（docs/code/qa/...，也可能是用户自定义分类）
+interface CategoryTrend {
+  category: string;
+  visits: number;
+  minutes: number;
 }
 
 // 后端 AppStatsDTO - 匹配 internal/dto/httpapi.go:89
@@ -45,6 +57,12 @@ interface DashboardViewProps {
 
 export default function DashboardView({ onNavigate }: DashboardViewProps) {
   const { t } = useTranslation();
+  // 自定义分类没有翻译时原样显示
+  const categoryLabel = (category: string) => {
+    const key = `dashboard.domainCategories.${category}`;
+    const label = t(key);
+    return label === key ? category : label;
+  };
   const [trends, setTrends] = useState<TrendReportDTO | null>(null);
   const [appStats, setAppStats] = useState<AppStat[]>([]);
   const [evidence, setEvidence] = useState<EvidenceStatusDTO | null>(null);
@@ -282,6 +300,44 @@ export default function DashboardView({ onNavigate }: DashboardViewProps) {
         </Card>
       )}
 
+      {/* Top Domains by dwell time */}
+      {trends?.top_domains && trends.top_domains.some((d) => d.minutes > 0) && (
+        <Card className="bg-zinc-900 border-zinc-800">
+          <CardHeader className="pb-2">
+            <div className="flex items-center justify-between">
+              <CardTitle className="text-zinc-200 text-base font-medium">{t('dashboard.topReadingDomains')}</CardTitle>
+              <span className="text-xs text-zinc-600">
+                {t('dashboard.browsingTotal')}: {trends.browsing_mins}m
+              </span>
+            </div>
+          </CardHeader>
+          <CardContent>
+            {trends.browsing_categories && trends.browsing_categories.some((c) => c.minutes > 0) && (
+              <div className="flex flex-wrap gap-1.5 mb-3">
+                {trends.browsing_categories.filter((c) => c.minutes > 0).map((c) => (
+                  <span key={c.category} className="px-2 py-0.5 bg-zinc-950 border border-zinc-800 rounded text-xs text-zinc-400">
+                    {categoryLabel(c.category)} <span className="text-zinc-600">{c.minutes}m</span>
+                  </span>
+                ))}
+              </div>
+            )}
+            <div className="space-y-1.5">
+              {trends.top_domains.filter((d) => d.minutes > 0).slice(0, 8).map((d) => (
+                <div key={d.domain} className="flex items-center justify-between text-sm">
+                  <span className="text-zinc-300 truncate">
+                    {d.domain}
+                    <span className="ml-2 text-xs text-zinc-600">{d.skill || categoryLabel(d.category)}</span>
+                  </span>
+                  <span className="text-xs text-zinc-500 whitespace-nowrap ml-3">
+                    {d.minutes}m · {d.visits} {t('dashboard.visits')}
+                  </span>
+                </div>
+              ))}
+            </div>
+          </CardContent>
+        </Card>
+      )}
+
       {/* Activity Heatmap */}
       <Card className="bg-zinc-900 border-zinc-800">
         <CardHeader className="pb-2">
@@ -12,7 +12,7 @@ import {
   TabsList,
   TabsTrigger,
 } from '@/components/ui/tabs';
-import { Sparkles, Cog, AlertTriangle, ChevronDown, ChevronRight, FileCode, Plus, Minus, MonitorSmartphone, Globe, Clock, GripVertical, Calendar, ExternalLink, Code, Search, Coffee } from 'lucide-react';
+import { Sparkles, Cog, AlertTriangle, ChevronDown, ChevronRight, FileCode, Plus, Minus, MonitorSmartphone, Globe, Clock, GripVertical, Calendar, ExternalLink, Code, Search, Coffee, GitCommit, SquareTerminal, Keyboard, Users } from 'lucide-react';
 import { cn } from '@/lib/utils';
 import { GetSessionsByDate, GetSessionDetail, GetSessionEvents, GetDiffDetail } from '@/api/app';
 import { SessionDTO, SessionDetailDTO, SessionWindowEventDTO } from '@/types/session';
@@ -543,6 +543,30 @@ export default function SessionsView({
                                     <span>{session.browser_count}</span>
                                   </div>
                                 )}
+                                {session.commit_count > 0 && (
+                                  <div className="flex items-center gap-1 text-[10px] font-mono text-violet-500/80 bg-violet-500/5 px-1.5 py-0.5 rounded-full">
+                                    <GitCommit size={10} />
+                                    <span>{session.commit_count}</span>
+                                  </div>
+                                )}
+                                {(session.command_count ?? 0) > 0 && (
+                                  <div className="flex items-center gap-1 text-[10px] font-mono text-amber-500/80 bg-amber-500/5 px-1.5 py-0.5 rounded-full">
+                                    <SquareTerminal size={10} />
+                                    <span>{session.command_count}</span>
+                                  </div>
+                                )}
+                                {(session.editor_file_count ?? 0) > 0 && (
+                                  <div className="flex items-center gap-1 text-[10px] font-mono text-sky-500/80 bg-sky-500/5 px-1.5 py-0.5 rounded-full">
+                                    <Keyboard size={10} />
+                                    <span>{session.editor_file_count}</span>
+                                  </div>
+                                )}
+                                {(session.meeting_count ?? 0) > 0 && (
+                                  <div className="flex items-center gap-1 text-[10px] font-mono text-rose-500/80 bg-rose-500/5 px-1.5 py-0.5 rounded-full" title={t('sessions.meetings')}>
+                                    <Users size={10} />
+                                    <span>{session.meeting_count}</span>
+                                  </div>
+                                )}
                                 {evidenceStrength === 'weak' && (
                                   <AlertTriangle size={12} className="text-amber-500" />
                                 )}
@@ -636,6 +660,15 @@ export default function SessionsView({
               </div>
               <h2 className="text-xl font-bold text-white">{selectedSession.category || t('sessions.sessionDetail')}</h2>
               <p className="text-zinc-400 text-sm mt-2">{selectedSession.summary}</p>
+              {selectedSession.meetings && selectedSession.meetings.length > 0 && (
+                <div className="flex flex-wrap items-center gap-2 mt-3 text-xs text-zinc-400">
+                  <Users size={12} className="text-rose-400" />
+                  <span className="text-zinc-500">{t('sessions.meetings')}</span>
+                  {selectedSession.meetings.map((m) => (
+                    <Badge key={m.id} variant="outline">{m.title || '—'}</Badge>
+                  ))}
+                </div>
+              )}
             </div>
 
             {/* Tabs */}
@@ -650,6 +683,15 @@ export default function SessionsView({
                 <TabsTrigger value="browser" className="flex-1 text-xs">
                   <Globe size={12} className="mr-1" /> {t('sessions.browserActivity')}
                 </TabsTrigger>
+                <TabsTrigger value="commits" className="flex-1 text-xs">
+                  <GitCommit size={12} className="mr-1" /> {t('sessions.commits')}
+                </TabsTrigger>
+                <TabsTrigger value="commands" className="flex-1 text-xs">
+                  <SquareTerminal size={12} className="mr-1" /> {t('sessions.commands')}
+                </TabsTrigger>
+                <TabsTrigger value="editor" className="flex-1 text-xs">
+                  <Keyboard size={12} className="mr-1" /> {t('sessions.editorFiles')}
+                </TabsTrigger>
                 <TabsTrigger value="apps" className="flex-1 text-xs">
                   <MonitorSmartphone size={12} className="mr-1" /> {t('sessions.appUsage')}
                 </TabsTrigger>
@@ -670,6 +712,9 @@ export default function SessionsView({
                                 <FileCode size={14} className="text-indigo-400" />
                                 <span className="text-zinc-300">{diff.file_name}</span>
                                 <span className="text-zinc-600">{diff.language}</span>
+                                {diff.backfilled && (
+                                  <span className="text-amber-500/80" title={t('sessions.backfilledHint')}>{t('sessions.backfilled')}</span>
+                                )}
                               </div>
                               <div className="flex items-center gap-3">
                                 <span className="text-emerald-500 text-xs flex items-center gap-0.5"><Plus size={10} /> {diff.lines_added}</span>
@@ -860,13 +905,63 @@ export default function SessionsView({
               <TabsContent value="browser" className="mt-4">
                 {selectedSession.browser && selectedSession.browser.length > 0 ? (
                   <div className="space-y-2">
-                    {selectedSession.browser.slice(0, 100).map((evt, idx) => (
-                      <div key={idx} className="p-2 bg-zinc-900 border border-zinc-800 rounded text-sm">
+                    {selectedSession.searches && selectedSession.searches.length > 0 && (
+                      <div className="p-2 bg-zinc-900 border border-zinc-800 rounded">
+                        <div className="text-xs text-zinc-500 mb-2">{t('sessions.searchedFor')}</div>
+                        <div className="flex flex-wrap gap-1">
+                          {selectedSession.searches.map((s) => (
+                            <span
+                              key={s.id}
+                              className="px-2 py-0.5 bg-zinc-950 border border-zinc-800 rounded text-xs text-zinc-300"
+                              title={`${formatTimestamp(s.timestamp)} · ${s.engine}`}
+                            >
+                              {s.term}
+                            </span>
+                          ))}
+                        </div>
+                      </div>
+                    )}
+                    {selectedSession.manual_testing && selectedSession.manual_testing.length > 0 && (
+                      <div className="p-2 bg-zinc-900 border border-zinc-800 rounded space-y-1">
+                        {selectedSession.manual_testing.map((m) => (
+                          <div key={m.path || m.project} className="flex items-center gap-2 text-xs" title={m.path}>
+                            <span className="text-emerald-400">
+                              {t('sessions.manualTesting')} {m.project}
+                            </span>
+                            <span className="text-zinc-500 font-mono truncate">{m.hosts.join(', ')}</span>
+                            <span className="text-zinc-600">
+                              {m.visits} {t('sessions.visits')}
+                              {m.duration > 0 && ` · ${formatDuration(m.duration)}`}
+                            </span>
+                          </div>
+                        ))}
+                      </div>
+                    )}
+                    {selectedSession.browser.slice(0, 100).map((evt, idx) => {
+                      const from = evt.from_visit_id
+                        ? selectedSession.browser.find(
+                            (o) => o.visit_id === evt.from_visit_id && o.browser === evt.browser && o.profile === evt.profile,
+                          )
+                        : undefined;
+                      const isRedirect = evt.transition === 'redirect';
+                      return (
+                      <div key={idx} className={`p-2 bg-zinc-900 border border-zinc-800 rounded text-sm ${isRedirect ? 'opacity-50' : ''}`}>
                         <div className="flex items-center gap-3">
                           <span className="text-xs font-mono text-zinc-600 w-12">{formatTimestamp(evt.timestamp)}</span>
                           <Globe size={12} className="text-sky-500" />
                           <span className="text-zinc-400">{evt.domain}</span>
                           {evt.duration > 0 && <span className="text-xs text-zinc-600">{formatDuration(evt.duration)}</span>}
+                          {isRedirect && <span className="text-xs text-zinc-600">{t('sessions.redirectHop')}</span>}
+                          {evt.testing_project && (
+                            <span className="text-xs text-emerald-500/80">
+                              {t('sessions.manualTesting')} {evt.testing_project}
+                            </span>
+                          )}
+                          {from && from.domain !== evt.domain && (
+                            <span className="text-xs text-zinc-600 truncate">
+                              {t('sessions.navigatedFrom')} {from.domain}
+                            </span>
+                          )}
                         </div>
                         <div className="mt-1 text-xs text-zinc-500 pl-[60px] space-y-1">
                           {evt.title && <div className="truncate">{evt.title}</div>}
@@ -884,7 +979,8 @@ export default function SessionsView({
                           )}
                         </div>
                       </div>
-                    ))}
+                      );
+                    })}
                     {selectedSession.browser.length > 100 && (
                       <div className="text-xs text-zinc-600 text-center py-2">{t('sessions.browserEvidenceTruncated')}</div>
                     )}
@@ -894,6 +990,80 @@ export default function SessionsView({
                 )}
               </TabsContent>
 
+              {/* Git 提交 */}
+              <TabsContent value="commits" className="mt-4">
+                {selectedSession.commits && selectedSession.commits.length > 0 ? (
+                  <div className="space-y-2">
+                    {selectedSession.commits.map((c) => (
+                      <div key={c.id} className="p-2 bg-zinc-900 border border-zinc-800 rounded text-sm">
+                        <div className="flex items-center gap-3">
+                          <span className="text-xs font-mono text-zinc-600 w-12">{formatTimestamp(c.timestamp)}</span>
+                          <GitCommit size={12} className="text-violet-500" />
+                          <span className="font-mono text-xs text-zinc-500">{c.hash.slice(0, 7)}</span>
+                          <span className="text-zinc-300 truncate">{c.subject}</span>
+                        </div>
+                        <div className="mt-1 text-xs text-zinc-500 pl-[60px] flex items-center gap-3">
+                          <span>{c.repo}{c.branch ? ` · ${c.branch}` : ''}</span>
+                          <span>{c.files_changed} {t('sessions.commitFiles')}</span>
+                          <span className="text-emerald-500">+{c.lines_added}</span>
+                          <span className="text-rose-500">-{c.lines_deleted}</span>
+                        </div>
+                      </div>
+                    ))}
+                  </div>
+                ) : (
+                  <div className="text-zinc-500 text-sm italic text-center py-8">{t('sessions.noCommits')}</div>
+                )}
+              </TabsContent>
+
+              {/* 终端命令 */}
+              <TabsContent value="commands" className="mt-4">
+                {selectedSession.commands && selectedSession.commands.length > 0 ? (
+                  <div className="space-y-1">
+                    {selectedSession.commands.map((c) => (
+                      <div key={c.id} className="flex items-start gap-3 p-2 bg-zinc-900 border border-zinc-800 rounded text-sm">
+                        <span
+                          className="text-xs font-mono text-zinc-600 w-12 shrink-0"
+                          title={c.timed ? undefined : t('sessions.commandUntimed')}
+                        >
+                          {c.timed ? '' : '~'}{formatTimestamp(c.timestamp)}
+                        </span>
+                        <span className="text-[10px] font-mono text-amber-500/80 w-16 shrink-0">{c.shell}</span>
+                        <code className="text-zinc-300 font-mono text-xs whitespace-pre-wrap break-all flex-1">{c.command}</code>
+                        {c.duration ? <span className="text-xs text-zinc-600 shrink-0">{formatDuration(c.duration)}</span> : null}
+                      </div>
+                    ))}
+                  </div>
+                ) : (
+                  <div className="text-zinc-500 text-sm italic text-center py-8">{t('sessions.noCommands')}</div>
+                )}
+              </TabsContent>
+
+              {/* 编辑器插件心跳（按文件） */}
+              <TabsContent value="editor" className="mt-4">
+                {selectedSession.editor_files && selectedSession.editor_files.length > 0 ? (
+                  <div className="space-y-1">
+                    {selectedSession.editor_files.map((f) => (
+                      <div key={`${f.project}/${f.file}`} className="flex items-center gap-3 p-2 bg-zinc-900 border border-zinc-800 rounded text-sm">
+                        <span className="text-[10px] font-mono text-sky-500/80 w-24 shrink-0 truncate" title={f.branch ? `${f.project} (${f.branch})` : f.project}>
+                          {f.project}
+                        </span>
+                        <code className="text-zinc-300 font-mono text-xs break-all flex-1">{f.file}</code>
+                        {f.language && <span className="text-[10px] text-zinc-500 shrink-0">{f.language}</span>}
+                        {f.writes > 0 && (
+                          <span className="text-[10px] text-zinc-500 shrink-0" title={t('sessions.editorWrites')}>
+                            ✎{f.writes}
+                          </span>
+                        )}
+                        <span className="text-xs text-zinc-600 shrink-0">{formatDuration(f.duration)}</span>
+                      </div>
+                    ))}
+                  </div>
+                ) : (
+                  <div className="text-zinc-500 text-sm italic text-center py-8">{t('sessions.noEditorFiles')}</div>
+                )}
+              </TabsContent>
+
               {/* 应用使用 */}
               <TabsContent value="apps" className="mt-4">
                 {selectedSession.app_usage.length > 0 ? (
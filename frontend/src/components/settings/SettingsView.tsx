@@ -6,6 +6,16 @@ import { Settings as SettingsIcon, Moon, Shield, Save, RefreshCw, Plus, X, Key,
 import { GetSettings, SaveSettings } from '@/api/app';
 import { useTranslation } from '@/lib/i18n';
 
+// 匹配后端 BrowserProfileDTO
+interface BrowserProfile {
+  key: string; // <browser>/<profile>
+  browser: string;
+  profile: string;
+  name: string;
+  history_path: string;
+  enabled: boolean;
+}
+
 // 匹配后端 dto/httpapi.go SettingsDTO 完整字段
 interface SettingsData {
   config_path: string;
@@ -56,6 +66,12 @@ interface SettingsData {
   diff_watch_paths: string[];
   browser_enabled: boolean;
   browser_history_path: string;
+  browser_browsers: string[];
+  browser_profiles: BrowserProfile[];
+  browser_search_terms: boolean;
+  browser_reading_exp: boolean;
+  terminal_enabled: boolean;
+  editor_enabled: boolean;
 
   privacy_enabled: boolean;
   privacy_patterns: string[];
@@ -106,6 +122,12 @@ interface SaveSettingsRequest {
   diff_watch_paths?: string[];
   browser_enabled?: boolean;
   browser_history_path?: string;
+  browser_browsers?: string[];
+  browser_disabled_profiles?: string[];
+  browser_search_terms?: boolean;
+  browser_reading_exp?: boolean;
+  terminal_enabled?: boolean;
+  editor_enabled?: boolean;
 
   privacy_enabled?: boolean;
   privacy_patterns?: string[];
@@ -772,6 +794,98 @@ export default function SettingsView() {
               className="bg-zinc-950 border border-zinc-800 rounded px-2 py-1 text-xs text-zinc-400 font-mono w-64"
             />
           </div>
+          <div className="flex items-center justify-between">
+            <div>
+              <div className="text-sm text-zinc-300">{t('settings.browserBrowsers')}</div>
+              <div className="text-xs text-zinc-500">{t('settings.browserBrowsersHint')}</div>
+            </div>
+            <div className="flex gap-2">
+              {(['chromium-family', 'firefox'] as const).map((b) => {
+                const configured = pendingChanges.browser_browsers ?? settings.browser_browsers ?? [];
+                // 为空表示全部
+                const selected = configured.length > 0 ? configured : ['chromium-family', 'firefox'];
+                const active = selected.includes(b);
+                return (
+                  <button
+                    key={b}
+                    onClick={() => updatePending('browser_browsers', active ? selected.filter((x) => x !== b) : [...selected, b])}
+                    className={`px-2 py-1 rounded text-xs border ${active ? 'bg-zinc-700 border-zinc-600 text-zinc-200' : 'bg-zinc-950 border-zinc-800 text-zinc-500'}`}
+                  >
+                    {t(b === 'firefox' ? 'settings.browserFirefox' : 'settings.browserChromium')}
+                  </button>
+                );
+              })}
+            </div>
+          </div>
+          <div className="flex items-center justify-between">
+            <div>
+              <div className="text-sm text-zinc-300">{t('settings.browserSearchTerms')}</div>
+              <div className="text-xs text-zinc-500">{t('settings.browserSearchTermsHint')}</div>
+            </div>
+            <Switch
+              checked={pendingChanges.browser_search_terms ?? settings.browser_search_terms}
+              onCheckedChange={(checked: boolean) => updatePending('browser_search_terms', checked)}
+            />
+          </div>
+          <div className="flex items-center justify-between">
+            <div>
+              <div className="text-sm text-zinc-300">{t('settings.browserReadingExp')}</div>
+              <div className="text-xs text-zinc-500">{t('settings.browserReadingExpHint')}</div>
+            </div>
+            <Switch
+              checked={pendingChanges.browser_reading_exp ?? settings.browser_reading_exp}
+              onCheckedChange={(checked: boolean) => updatePending('browser_reading_exp', checked)}
+            />
+          </div>
+          {(settings.browser_profiles ?? []).length > 0 && (
+            <div className="space-y-2">
+              <div>
+                <div className="text-sm text-zinc-300">{t('settings.browserProfiles')}</div>
+                <div className="text-xs text-zinc-500">{t('settings.browserProfilesHint')}</div>
+              </div>
+              {settings.browser_profiles.map((p) => {
+                const disabled = pendingChanges.browser_disabled_profiles
+                  ?? settings.browser_profiles.filter((x) => !x.enabled).map((x) => x.key);
+                const enabled = !disabled.includes(p.key);
+                return (
+                  <div key={p.key} className="flex items-center justify-between bg-zinc-950 border border-zinc-800 rounded px-2 py-1">
+                    <div className="min-w-0">
+                      <div className="text-xs text-zinc-300">{p.browser} · {p.name || p.profile}</div>
+                      <div className="text-xs text-zinc-600 font-mono truncate">{p.history_path}</div>
+                    </div>
+                    <Switch
+                      checked={enabled}
+                      onCheckedChange={(checked: boolean) => updatePending(
+                        'browser_disabled_profiles',
+                        checked ? disabled.filter((k) => k !== p.key) : [...disabled, p.key],
+                      )}
+                    />
+                  </div>
+                );
+              })}
+            </div>
+          )}
+          <div className="flex items-center justify-between">
+            <div>
+              <div className="text-sm text-zinc-300">{t('settings.terminalMonitor')}</div>
+              <div className="text-xs text-zinc-500">{t('settings.terminalMonitorHint')}</div>
+            </div>
+            <Switch
+              checked={pendingChanges.terminal_enabled ?? settings.terminal_enabled ?? false}
+              onCheckedChange={(checked: boolean) => updatePending('terminal_enabled', checked)}
+            />
+          </div>
+          <div className="flex items-center justify-between">
+            <div>
+              <div className="text-sm text-zinc-300">{t('settings.editorHeartbeats')}</div>
+              <div className="text-xs text-zinc-500">{t('settings.editorHeartbeatsHint')}</div>
+              <code className="text-[10px] font-mono text-zinc-600">api_url = http://127.0.0.1:7410/api/v1</code>
+            </div>
+            <Switch
+              checked={pendingChanges.editor_enabled ?? settings.editor_enabled ?? true}
+              onCheckedChange={(checked: boolean) => updatePending('editor_enabled', checked)}
+            />
+          </div>
         </CardContent>
       </Card>
 
@@ -165,6 +165,15 @@ export default function StatusView() {
             heartbeat={formatHeartbeat(status.collectors.browser.last_collected_at)}
             heartbeatLabel={t('systemHealth.heartbeat')}
           />
+          {status.collectors.terminal?.enabled && (
+            <CollectorRow
+              name={t('status.terminalMonitor')}
+              status={status.collectors.terminal.running ? 'healthy' : 'warning'}
+              detail={`${t('status.commands24h')}: ${status.collectors.terminal.count_24h}, ${t('status.historyFiles')}: ${status.collectors.terminal.history_paths?.length || 0}`}
+              heartbeat={formatHeartbeat(status.collectors.terminal.last_collected_at)}
+              heartbeatLabel={t('systemHealth.heartbeat')}
+            />
+          )}
           <CollectorRow
             name={t('status.aiPipeline')}
             status={health.ai}
@@ -13,6 +13,10 @@ export interface SessionDTO {
   skills_involved: string[];
   diff_count: number;
   browser_count: number;
+  commit_count: number;
+  command_count?: number;
+  editor_file_count?: number;
+  meeting_count?: number;
 
   semantic_source: 'ai' | 'rule' | string;
   semantic_version?: string;
@@ -34,6 +38,7 @@ export interface SessionDiffDTO {
   lines_added: number;
   lines_deleted: number;
   timestamp: number;
+  backfilled?: boolean;
 }
 
 export interface SessionBrowserEventDTO {
@@ -43,6 +48,70 @@ export interface SessionBrowserEventDTO {
   title: string;
   url: string;
   duration: number;
+  browser?: string;
+  profile?: string;
+  visit_id?: number;
+  from_visit_id?: number;
+  transition?: string;
+  testing_project?: string;
+}
+
+export interface SessionCommitDTO {
+  id: number;
+  hash: string;
+  repo: string;
+  branch: string;
+  subject: string;
+  body?: string;
+  timestamp: number;
+  files_changed: number;
+  lines_added: number;
+  lines_deleted: number;
+  files: string[];
+}
+
+export interface SessionSearchTermDTO {
+  id: number;
+  timestamp: number;
+  term: string;
+  engine: string;
+  domain?: string;
+}
+
+export interface SessionTerminalCommandDTO {
+  id: number;
+  timestamp: number;
+  shell: string;
+  command: string;
+  duration?: number;
+  timed: boolean;
+}
+
+export interface SessionEditorFileDTO {
+  project: string;
+  file: string;
+  language?: string;
+  branch?: string;
+  editor?: string;
+  duration: number;
+  heartbeats: number;
+  writes: number;
+}
+
+export interface SessionManualTestingDTO {
+  project: string;
+  path?: string;
+  source: 'port_hint' | 'diff' | 'editor' | 'window_title';
+  hosts: string[];
+  visits: number;
+  duration: number;
+}
+
+export interface SessionMeetingDTO {
+  id: number;
+  title: string;
+  start_time: number;
+  end_time: number;
 }
 
 export interface SessionWindowEventDTO {
@@ -56,4 +125,10 @@ export interface SessionDetailDTO extends SessionDTO {
   app_usage: SessionAppUsageDTO[];
   diffs: SessionDiffDTO[];
   browser: SessionBrowserEventDTO[];
+  commits: SessionCommitDTO[];
+  searches?: SessionSearchTermDTO[];
+  commands?: SessionTerminalCommandDTO[];
+  editor_files?: SessionEditorFileDTO[];
+  manual_testing?: SessionManualTestingDTO[];
+  meetings?: SessionMeetingDTO[];
 }
@@ -32,6 +32,9 @@ export interface CollectorStatusDTO {
     watch_paths?: string[];
     effective_paths?: number;
     history_path?: string;
+    history_paths?: string[];
+    backend?: string;
+    watch_modes?: Record<string, 'notify' | 'poll'>;
     sanitized_enabled?: boolean;
 }
 
@@ -39,6 +42,8 @@ export interface CollectorsStatusDTO {
     window: CollectorStatusDTO;
     diff: CollectorStatusDTO;
     browser: CollectorStatusDTO;
+    commits?: CollectorStatusDTO;
+    terminal?: CollectorStatusDTO;
 }
 
 export interface SessionPipelineStatusDTO {
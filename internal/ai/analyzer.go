@@ -236,6 +236,39 @@ func (a *DiffAnalyzer) GenerateDailySummary(ctx context.Context, req *DailySumma
 		diffSummary.WriteString(fmt.Sprintf("- %s (%s): %s\n", d.FileName, d.Language, description))
 	}
 
+	// 构建提交摘要：提交信息是用户自己写的工作描述，优先级高于逐文件 diff
+	var commitSummary strings.Builder
+	if len(req.Commits) > 0 {
+		commits := req.Commits
+		if len(commits) > 30 {
+			commits = commits[:30]
+		}
+		if a.lang == "en" {
+			commitSummary.WriteString(fmt.Sprintf("\nCommits (%d; written by the user, quote them directly):\n", len(req.Commits)))
+		} else {
+			commitSummary.WriteString(fmt.Sprintf("\n提交记录（%d 次；提交信息由用户本人撰写，可直接引用）:\n", len(req.Commits)))
+		}
+		for _, c := range commits {
+			commitSummary.WriteString(fmt.Sprintf("- [%s] %s (%d files, %d lines)\n", c.Repo, c.Subject, c.FilesChanged, c.LinesChanged))
+			if body := firstLines(c.Body, 3, 300); body != "" {
+				commitSummary.WriteString("  " + strings.ReplaceAll(body, "\n", "\n  ") + "\n")
+			}
+		}
+	}
+
+	// 构建会议摘要：会议时间不会体现在代码与浏览证据里
+	var meetingSummary strings.Builder
+	if len(req.Meetings) > 0 {
+		if a.lang == "en" {
+			meetingSummary.WriteString(fmt.Sprintf("\nMeetings (%d, from the user's calendar):\n", len(req.Meetings)))
+		} else {
+			meetingSummary.WriteString(fmt.Sprintf("\n会议（%d 场，来自用户日程）:\n", len(req.Meetings)))
+		}
+		for _, m := range req.Meetings {
+			meetingSummary.WriteString(fmt.Sprintf("- %s %s\n", m.TimeRange, m.Title))
+		}
+	}
+
 	// 构建历史记忆摘要
 	var historySummary strings.Builder
 	if len(req.HistoryMemories) > 0 {
@@ -254,6 +287,8 @@ func (a *DiffAnalyzer) GenerateDailySummary(ctx context.Context, req *DailySumma
 		len(diffs),
 		windowSummary.String(),
 		diffSummary.String(),
+		commitSummary.String(),
+		meetingSummary.String(),
 		historySummary.String(),
 		a.lang,
 	)
@@ -372,6 +407,10 @@ func (a *DiffAnalyzer) GenerateSessionSummary(ctx context.Context, req *SessionS
 		if desc == "" {
 			desc = fmt.Sprintf("%d行变更", d.LinesChanged)
 		}
+		// 累计改动节选（相对 HEAD）：补充逐次保存的解读看不到的整体改动
+		if c := firstLines(d.DiffContent, 12, 400); c != "" {
+			desc += "\n  累计改动（节选）:\n    " + strings.ReplaceAll(c, "\n", "\n    ")
+		}
 		diffLines = append(diffLines, fmt.Sprintf("%s (%s): %s", d.FileName, d.Language, desc))
 	}
 
@@ -381,7 +420,45 @@ func (a *DiffAnalyzer) GenerateSessionSummary(ctx context.Context, req *SessionS
 		if title == "" {
 			title = it.Domain
 		}
-		browserLines = append(browserLines, fmt.Sprintf("%s: %s", it.Domain, title))
+		line := fmt.Sprintf("%s: %s", it.Domain, title)
+		if d := formatWindowTitleDuration(it.DurationSec); d != "" {
+			line += "（停留 " + d + "）"
+		}
+		browserLines = append(browserLines, line)
+	}
+
+	searchLines := make([]string, 0, len(req.Searches))
+	for _, q := range req.Searches {
+		if q = strings.TrimSpace(q); q != "" && len(searchLines) < 15 {
+			searchLines = append(searchLines, q)
+		}
+	}
+
+	commandLines := make([]string, 0, len(req.Commands))
+	for _, c := range req.Commands {
+		if c = strings.TrimSpace(c); c != "" && len(commandLines) < 20 {
+			commandLines = append(commandLines, strings.ReplaceAll(firstLines(c, 3, 160), "\n", " ⏎ "))
+		}
+	}
+
+	manualTestLines := make([]string, 0, len(req.ManualTesting))
+	for _, m := range req.ManualTesting {
+		project := strings.TrimSpace(m.Project)
+		if project == "" || len(manualTestLines) >= 5 {
+			continue
+		}
+		line := fmt.Sprintf("%s: %s（%d 次访问", project, strings.Join(m.Hosts, ", "), m.Visits)
+		if d := formatWindowTitleDuration(m.DurationSec); d != "" {
+			line += "，共 " + d
+		}
+		manualTestLines = append(manualTestLines, line+"）")
+	}
+
+	meetingLines := make([]string, 0, len(req.Meetings))
+	for _, m := range req.Meetings {
+		if m = strings.TrimSpace(m); m != "" && len(meetingLines) < 5 {
+			meetingLines = append(meetingLines, m)
+		}
 	}
 
 	skillsHintLines := make([]string, 0, len(req.SkillsHint))
@@ -409,6 +486,10 @@ func (a *DiffAnalyzer) GenerateSessionSummary(ctx context.Context, req *SessionS
 		WindowTitleLines: windowTitleLines,
 		DiffLines:        diffLines,
 		BrowserLines:     browserLines,
+		SearchLines:      searchLines,
+		CommandLines:     commandLines,
+		ManualTestLines:  manualTestLines,
+		MeetingLines:     meetingLines,
 		SkillsHintLines:  skillsHintLines,
 		MemoryLines:      memLines,
 	}, a.lang)
@@ -444,6 +525,25 @@ func (a *DiffAnalyzer) GenerateSessionSummary(ctx context.Context, req *SessionS
 	return &result, nil
 }
 
+// firstLines 截取前 n 个非空行，并限制总长度（按 rune）
+func firstLines(s string, n, maxRunes int) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) >= n {
+			break
+		}
+	}
+	out := strings.Join(lines, "\n")
+	if r := []rune(out); len(r) > maxRunes {
+		out = string(r[:maxRunes]) + "..."
+	}
+	return out
+}
+
 // cleanJSONResponse 清理 JSON 响应（移除 markdown 代码块和额外文本）
 func cleanJSONResponse(response string) string {
 	response = strings.TrimSpace(response)
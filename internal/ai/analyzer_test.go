@@ -177,6 +177,29 @@ func TestGenerateSessionSummary_Language(t *testing.T) {
 	}
 }
 
+// TestGenerateSessionSummary_CumulativeExcerpt 测试会话摘要 prompt 附带累计 diff 节选（有长度上限）
+func TestGenerateSessionSummary_CumulativeExcerpt(t *testing.T) {
+	provider := &mockLLMProvider{configured: true, response: `{"summary":"ok","category":"coding"}`}
+	analyzer := NewDiffAnalyzer(provider, "zh")
+
+	cumulative := "--- a/main.go\n+++ b/main.go\n@@ -1,1 +1,40 @@\n" + strings.Repeat("+func handler() {}\n", 40)
+	_, err := analyzer.GenerateSessionSummary(context.Background(), &SessionSummaryRequest{
+		Date:      "2025-01-01",
+		TimeRange: "10:00-11:00",
+		Diffs:     []DiffInfo{{FileName: "main.go", Language: "Go", Insight: "新增 HTTP 处理函数", DiffContent: cumulative, LinesChanged: 40}},
+	})
+	if err != nil {
+		t.Fatalf("GenerateSessionSummary: %v", err)
+	}
+	prompt := provider.lastMessages[len(provider.lastMessages)-1].Content
+	if !strings.Contains(prompt, "累计改动（节选）") || !strings.Contains(prompt, "+func handler() {}") {
+		t.Fatalf("prompt should include the cumulative excerpt:\n%s", prompt)
+	}
+	if n := strings.Count(prompt, "+func handler() {}"); n >= 40 {
+		t.Fatalf("cumulative excerpt should be capped, got %d lines", n)
+	}
+}
+
 // TestGenerateWeeklySummary_Language 测试周报生成是否正确使用语言参数
 func TestGenerateWeeklySummary_Language(t *testing.T) {
 	tests := []struct {
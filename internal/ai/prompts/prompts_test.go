@@ -49,6 +49,10 @@ func TestSessionSummaryUser(t *testing.T) {
 		AppLines:        []string{"VSCode: 120分钟"},
 		DiffLines:       []string{"main.go (Go): 添加了国际化支持"},
 		BrowserLines:    []string{"github.com: 查看文档"},
+		SearchLines:     []string{"gorm partial unique index"},
+		CommandLines:    []string{"go test ./internal/..."},
+		ManualTestLines: []string{"WorkMirror: localhost:5173"},
+		MeetingLines:    []string{"Sprint planning"},
 	}
 
 	tests := []struct {
@@ -75,6 +79,18 @@ func TestSessionSummaryUser(t *testing.T) {
 				t.Errorf("SessionSummaryUser(%s) 应包含 %q", tt.lang, tt.contains)
 			}
 			// 验证包含输入数据
+			if !strings.Contains(result, "gorm partial unique index") {
+				t.Errorf("SessionSummaryUser(%s) 应包含搜索词", tt.lang)
+			}
+			if !strings.Contains(result, "go test ./internal/...") {
+				t.Errorf("SessionSummaryUser(%s) 应包含终端命令", tt.lang)
+			}
+			if !strings.Contains(result, "WorkMirror: localhost:5173") {
+				t.Errorf("SessionSummaryUser(%s) 应包含手动测试证据", tt.lang)
+			}
+			if !strings.Contains(result, "- Sprint planning") {
+				t.Errorf("SessionSummaryUser(%s) 应包含会议标题", tt.lang)
+			}
 			if !strings.Contains(result, input.Date) {
 				t.Errorf("SessionSummaryUser 应包含日期 %s", input.Date)
 			}
@@ -139,11 +155,16 @@ func TestDailySummaryUser(t *testing.T) {
 				"VSCode: 120分钟",
 				"main.go (Go): 添加功能",
 				"",
+				"\n会议:\n- 10:00-10:30 Sprint planning\n",
+				"",
 				tt.lang,
 			)
 			if !strings.Contains(result, tt.contains) {
 				t.Errorf("DailySummaryUser(%s) 应包含 %q", tt.lang, tt.contains)
 			}
+			if !strings.Contains(result, "Sprint planning") {
+				t.Errorf("DailySummaryUser(%s) 应包含会议", tt.lang)
+			}
 		})
 	}
 }
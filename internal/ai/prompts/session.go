@@ -25,6 +25,10 @@ type SessionSummaryUserInput struct {
 	WindowTitleLines []string
 	DiffLines        []string
 	BrowserLines     []string
+	SearchLines      []string // 搜索过的关键词（已脱敏）
+	CommandLines     []string // 执行过的终端命令（已脱敏）
+	ManualTestLines  []string // 本地开发服务上的手动测试（已关联项目）
+	MeetingLines     []string // 日历上的会议标题（已脱敏）
 	SkillsHintLines  []string
 	MemoryLines      []string
 }
@@ -50,6 +54,14 @@ func sessionSummaryUserZH(in SessionSummaryUserInput) string {
 
 	b.WriteString(fmt.Sprintf("日期: %s\n时间: %s\n主应用: %s\n\n", in.Date, in.TimeRange, in.PrimaryApp))
 
+	if len(in.MeetingLines) > 0 {
+		b.WriteString("会议（这段时间在日历上是会议，summary 应写明会议主题，其它证据多为会上的操作）:\n")
+		for _, line := range in.MeetingLines {
+			b.WriteString("- " + strings.TrimSpace(line) + "\n")
+		}
+		b.WriteString("\n")
+	}
+
 	if len(in.AppLines) > 0 {
 		b.WriteString("应用使用:\n")
 		for _, line := range in.AppLines {
@@ -82,6 +94,30 @@ func sessionSummaryUserZH(in SessionSummaryUserInput) string {
 		b.WriteString("\n")
 	}
 
+	if len(in.SearchLines) > 0 {
+		b.WriteString("搜索过（最能反映在学什么、卡在哪里）:\n")
+		for _, line := range in.SearchLines {
+			b.WriteString("- " + strings.TrimSpace(line) + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(in.CommandLines) > 0 {
+		b.WriteString("终端命令（构建、测试、部署等实际操作）:\n")
+		for _, line := range in.CommandLines {
+			b.WriteString("- " + strings.TrimSpace(line) + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(in.ManualTestLines) > 0 {
+		b.WriteString("手动测试（在 localhost 上验证正在开发的项目，不是查资料）:\n")
+		for _, line := range in.ManualTestLines {
+			b.WriteString("- " + strings.TrimSpace(line) + "\n")
+		}
+		b.WriteString("\n")
+	}
+
 	if len(in.SkillsHintLines) > 0 {
 		b.WriteString("技能提示（可参考）:\n")
 		for _, line := range in.SkillsHintLines {
@@ -121,6 +157,14 @@ func sessionSummaryUserEN(in SessionSummaryUserInput) string {
 
 	b.WriteString(fmt.Sprintf("Date: %s\nTime: %s\nPrimary App: %s\n\n", in.Date, in.TimeRange, in.PrimaryApp))
 
+	if len(in.MeetingLines) > 0 {
+		b.WriteString("Meetings (this time is a meeting on the calendar; summary should name the topic, other evidence is mostly activity during the meeting):\n")
+		for _, line := range in.MeetingLines {
+			b.WriteString("- " + strings.TrimSpace(line) + "\n")
+		}
+		b.WriteString("\n")
+	}
+
 	if len(in.AppLines) > 0 {
 		b.WriteString("App Usage:\n")
 		for _, line := range in.AppLines {
@@ -153,6 +197,30 @@ func sessionSummaryUserEN(in SessionSummaryUserInput) string {
 		b.WriteString("\n")
 	}
 
+	if len(in.SearchLines) > 0 {
+		b.WriteString("Searched For (strongest hint of what was being learned or debugged):\n")
+		for _, line := range in.SearchLines {
+			b.WriteString("- " + strings.TrimSpace(line) + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(in.CommandLines) > 0 {
+		b.WriteString("Terminal Commands (builds, tests, deploys and other hands-on steps):\n")
+		for _, line := range in.CommandLines {
+			b.WriteString("- " + strings.TrimSpace(line) + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(in.ManualTestLines) > 0 {
+		b.WriteString("Manual Testing (checking the project under development on localhost, not research):\n")
+		for _, line := range in.ManualTestLines {
+			b.WriteString("- " + strings.TrimSpace(line) + "\n")
+		}
+		b.WriteString("\n")
+	}
+
 	if len(in.SkillsHintLines) > 0 {
 		b.WriteString("Skill Hints (for reference):\n")
 		for _, line := range in.SkillsHintLines {
@@ -19,13 +19,15 @@ func DailySummaryUser(
 	diffTopN int,
 	windowSummary string,
 	diffSummary string,
+	commitSummary string,
+	meetingSummary string,
 	historySummary string,
 	lang string,
 ) string {
 	if lang == "en" {
-		return dailySummaryUserEN(date, windowTotalMinutes, windowTopN, diffCountTotal, linesChangedTotal, diffTopN, windowSummary, diffSummary, historySummary)
+		return dailySummaryUserEN(date, windowTotalMinutes, windowTopN, diffCountTotal, linesChangedTotal, diffTopN, windowSummary, diffSummary, commitSummary, meetingSummary, historySummary)
 	}
-	return dailySummaryUserZH(date, windowTotalMinutes, windowTopN, diffCountTotal, linesChangedTotal, diffTopN, windowSummary, diffSummary, historySummary)
+	return dailySummaryUserZH(date, windowTotalMinutes, windowTopN, diffCountTotal, linesChangedTotal, diffTopN, windowSummary, diffSummary, commitSummary, meetingSummary, historySummary)
 }
 
 func dailySummaryUserZH(
@@ -37,6 +39,8 @@ func dailySummaryUserZH(
 	diffTopN int,
 	windowSummary string,
 	diffSummary string,
+	commitSummary string,
+	meetingSummary string,
 	historySummary string,
 ) string {
 	return fmt.Sprintf(`根据以下行为数据，生成今日工作/学习总结。
@@ -51,8 +55,7 @@ func dailySummaryUserZH(
 %s
 
 代码变更:
-%s
-
+%s%s%s
 请用 JSON 格式返回（不要 markdown 代码块）:
 {
   "summary": "今日总结（请根据数据量自适应篇幅：轻量日 2-3 句；中等 5-8 句；高强度/多变更 10-16 句。尽量引用具体证据：应用名/文件名/语言/技能，避免套话。）",
@@ -60,7 +63,7 @@ func dailySummaryUserZH(
   "struggles": "今日困难（0-5 条要点，用换行分隔；没有就写'无'）",
   "skills_gained": ["今日涉及的技能（按重要性排序，允许 0-12 个）"],
   "suggestions": "明日建议（2-6 条要点，用换行分隔；优先给可执行的小动作；如涉及编码建议，优先遵循 SOLID/KISS/DRY/YAGNI；证据不足就写'无'）"
-}`, historySummary, date, windowTotalMinutes, windowTopN, diffCountTotal, linesChangedTotal, diffTopN, windowSummary, diffSummary)
+}`, historySummary, date, windowTotalMinutes, windowTopN, diffCountTotal, linesChangedTotal, diffTopN, windowSummary, diffSummary, commitSummary, meetingSummary)
 }
 
 func dailySummaryUserEN(
@@ -72,6 +75,8 @@ func dailySummaryUserEN(
 	diffTopN int,
 	windowSummary string,
 	diffSummary string,
+	commitSummary string,
+	meetingSummary string,
 	historySummary string,
 ) string {
 	return fmt.Sprintf(`Generate a daily work/learning summary based on the following behavioral data.
@@ -86,8 +91,7 @@ App Usage Duration:
 %s
 
 Code Changes:
-%s
-
+%s%s%s
 Return in JSON format (no markdown code blocks):
 {
   "summary": "Daily summary (adapt length to data volume: light day 2-3 sentences; medium 5-8 sentences; high intensity/many changes 10-16 sentences. Reference specific evidence: app names/file names/languages/skills, avoid generic statements.)",
@@ -95,5 +99,5 @@ Return in JSON format (no markdown code blocks):
   "struggles": "Daily challenges (0-5 key points, separated by newlines; if none, write 'None')",
   "skills_gained": ["Skills involved today (sorted by importance, allow 0-12 items)"],
   "suggestions": "Tomorrow's suggestions (2-6 key points, separated by newlines; prioritize actionable small steps; if it includes coding advice, prefer SOLID/KISS/DRY/YAGNI; write 'None' when evidence is insufficient)"
-}`, historySummary, date, windowTotalMinutes, windowTopN, diffCountTotal, linesChangedTotal, diffTopN, windowSummary, diffSummary)
+}`, historySummary, date, windowTotalMinutes, windowTopN, diffCountTotal, linesChangedTotal, diffTopN, windowSummary, diffSummary, commitSummary, meetingSummary)
 }
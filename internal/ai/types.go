@@ -36,6 +36,8 @@ type DailySummaryRequest struct {
 	Date            string            // 日期
 	WindowEvents    []WindowEventInfo // 窗口事件摘要
 	Diffs           []DiffInfo        // Diff 摘要
+	Commits         []CommitInfo      // 当日提交
+	Meetings        []MeetingInfo     // 当日会议（来自导入的日程）
 	HistoryMemories []string          // 相关历史记忆（来自 RAG）
 }
 
@@ -58,10 +60,25 @@ type DiffInfo struct {
 	FileName     string
 	Language     string
 	Insight      string // 预分析的解读（可能为空）
-	DiffContent  string // 原始 diff 内容
+	DiffContent  string // 原始 diff 内容（会话摘要中为同一文件最后一次的累计 diff 节选）
 	LinesChanged int
 }
 
+// CommitInfo 提交信息
+type CommitInfo struct {
+	Repo         string // 仓库名（目录名）
+	Subject      string
+	Body         string
+	FilesChanged int
+	LinesChanged int
+}
+
+// MeetingInfo 会议信息
+type MeetingInfo struct {
+	TimeRange string // 如 10:00-10:30
+	Title     string
+}
+
 // DailySummaryResult 每日总结结果
 type DailySummaryResult struct {
 	Summary      string   `json:"summary"`       // 总结
@@ -82,14 +99,28 @@ type SessionSummaryRequest struct {
 	WindowTitles []WindowTitleInfo `json:"window_titles,omitempty"`
 	Diffs        []DiffInfo        `json:"diffs"`
 	Browser      []BrowserInfo     `json:"browser"`
-	SkillsHint   []string          `json:"skills_hint"`
-	Memories     []string          `json:"memories"`
+	Searches     []string          `json:"searches,omitempty"` // 搜索过的关键词（可选功能）
+	Commands     []string          `json:"commands,omitempty"` // 执行过的终端命令（已脱敏，可选功能）
+	Meetings     []string          `json:"meetings,omitempty"` // 会议会话对应的日程标题（已脱敏）
+	// ManualTesting 为已关联到项目的 localhost 浏览，表示在本地开发服务上手动测试，而不是查资料。
+	ManualTesting []ManualTestingInfo `json:"manual_testing,omitempty"`
+	SkillsHint    []string            `json:"skills_hint"`
+	Memories      []string            `json:"memories"`
 }
 
 type BrowserInfo struct {
-	Domain string `json:"domain"`
-	Title  string `json:"title"`
-	URL    string `json:"url"`
+	Domain      string `json:"domain"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	DurationSec int    `json:"duration_sec,omitempty"` // 停留时长，用于区分细读与一扫而过
+}
+
+// ManualTestingInfo 在本地开发服务上手动测试某个项目
+type ManualTestingInfo struct {
+	Project     string   `json:"project"`
+	Hosts       []string `json:"hosts"` // 例如 localhost:5173
+	Visits      int      `json:"visits"`
+	DurationSec int      `json:"duration_sec,omitempty"`
 }
 
 // SessionSummaryResult 会话摘要结果
@@ -0,0 +1,397 @@
+package bootstrap
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/collector"
+	"github.com/yuqie6/WorkMirror/internal/eventbus"
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+	"github.com/yuqie6/WorkMirror/internal/service"
+)
+
+// AgentRuntime 包含 Agent 二进制需要启动的采集与后台任务
+type AgentRuntime struct {
+	*Core
+	Hub *eventbus.Hub
+
+	Collectors struct {
+		Window   collector.Collector
+		Diff     *collector.DiffCollector
+		Browser  *collector.BrowserCollector
+		Commit   *collector.CommitCollector
+		Terminal *collector.TerminalCollector
+	}
+
+	Services struct {
+		Tracker  *service.TrackerService
+		Diff     *service.DiffService
+		Browser  *service.BrowserService
+		Commit   *service.CommitService
+		Terminal *service.TerminalService
+		Ingest   *service.IngestService // ingest.enabled=false 时为 nil
+		RAG      *service.RAGService
+	}
+}
+
+// NewAgentRuntime 构建 Agent 运行时并启动采集服务
+func NewAgentRuntime(ctx context.Context, cfgPath string) (*AgentRuntime, error) {
+	core, err := NewCore(cfgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	rt := &AgentRuntime{Core: core, Hub: eventbus.NewHub()}
+
+	if core.DB != nil && core.DB.SafeMode {
+		// 安全模式：允许 UI 启动与诊断导出，但不启动任何写库链路（采集/后台任务）。
+		// 具体原因由 /api/status 展示，避免“沉默失败”。
+		return rt, nil
+	}
+
+	sanitizer := privacy.New(core.Cfg.Privacy.Enabled, core.Cfg.Privacy.Patterns)
+
+	// Window collector + tracker
+	rt.Collectors.Window = collector.NewWindowCollector(&collector.CollectorConfig{
+		PollIntervalMs: core.Cfg.Collector.PollIntervalMs,
+		MinDurationSec: core.Cfg.Collector.MinDurationSec,
+		MaxDurationSec: 60,
+		BufferSize:     core.Cfg.Collector.BufferSize,
+	})
+	rt.Services.Tracker = service.NewTrackerService(rt.Collectors.Window, core.Repos.Event, &service.TrackerConfig{
+		FlushBatchSize:   core.Cfg.Collector.FlushBatchSize,
+		FlushIntervalSec: core.Cfg.Collector.FlushIntervalSec,
+		Sanitizer:        sanitizer,
+		OnWriteSuccess: func(count int) {
+			rt.Hub.Publish(eventbus.Event{
+				Type: "data_changed",
+				Data: map[string]any{"source": "events", "count": count},
+			})
+		},
+	})
+	if err := rt.Services.Tracker.Start(ctx); err != nil {
+		core.Close()
+		return nil, err
+	}
+
+	// 外部事件写入复用 Tracker 的脱敏与批量写入（落库后同样发布 data_changed）
+	if core.Cfg.Ingest.Enabled {
+		if strings.TrimSpace(core.Cfg.Ingest.Token) == "" {
+			slog.Warn("ingest.enabled 已开启但未配置 ingest.token，/api/ingest 将拒绝所有请求")
+		}
+		rt.Services.Ingest = service.NewIngestService(rt.Services.Tracker, core.Cfg.Ingest.RateLimitPerMin)
+		rt.Services.Ingest.SetSanitizer(sanitizer)
+	}
+
+	// Diff collector + service (optional)
+	if core.Cfg.Diff.Enabled && len(core.Cfg.Diff.WatchPaths) > 0 {
+		diffCfg := &collector.DiffCollectorConfig{
+			WatchPaths:  core.Cfg.Diff.WatchPaths,
+			Extensions:  core.Cfg.Diff.Extensions,
+			BufferSize:  core.Cfg.Diff.BufferSize,
+			DebounceSec: core.Cfg.Diff.DebounceSec,
+			GitBackend:  core.Cfg.Diff.GitBackend,
+			Exclude:     core.Cfg.Diff.Exclude,
+
+			WatchMode:    core.Cfg.Diff.WatchMode,
+			WatchModes:   make(map[string]string),
+			PollInterval: time.Duration(core.Cfg.Diff.PollIntervalSec) * time.Second,
+			PollMaxFiles: core.Cfg.Diff.PollMaxFiles,
+		}
+		if core.Cfg.Diff.CatchUpEnabled {
+			// 以最后一条 Diff 为界；从未采集过时不补扫，避免把整个仓库当作离线修改
+			if ts, err := core.Repos.Diff.GetLatestTimestamp(ctx); err != nil {
+				slog.Warn("读取最新 Diff 时间失败，跳过启动补扫", "error", err)
+			} else {
+				diffCfg.CatchUpSince = ts
+				diffCfg.CatchUpMaxFiles = core.Cfg.Diff.CatchUpMaxFiles
+			}
+		}
+		diffRepo := core.Repos.Diff
+		diffCfg.LastCumulative = func(filePath string) (string, int64) {
+			last, err := diffRepo.GetLatestLiveByFilePath(ctx, filePath)
+			if err != nil || last == nil {
+				return "", 0
+			}
+			return last.CumulativeContent, last.Timestamp
+		}
+		for _, r := range core.Cfg.Diff.Repos {
+			diffCfg.Rules = append(diffCfg.Rules, collector.PathRule{Path: r.Path, Include: r.Include, Exclude: r.Exclude})
+			if r.WatchMode != "" {
+				diffCfg.WatchModes[r.Path] = r.WatchMode
+			}
+		}
+		if core.Cfg.Diff.SnapshotEnabled {
+			// 快照跟随数据库目录（便携分发时位于 data/snapshots）
+			diffCfg.SnapshotDir = filepath.Join(filepath.Dir(core.Cfg.Storage.DBPath), "snapshots")
+			diffCfg.SnapshotMaxFileBytes = int64(core.Cfg.Diff.SnapshotMaxFileKB) << 10
+			diffCfg.SnapshotMaxTotalBytes = int64(core.Cfg.Diff.SnapshotMaxTotalMB) << 20
+		}
+		diffCollector, err := collector.NewDiffCollector(diffCfg)
+		if err != nil {
+			rt.Close()
+			return nil, err
+		}
+		for _, path := range core.Cfg.Diff.WatchPaths {
+			_ = diffCollector.AddWatchPath(path)
+		}
+		rt.Collectors.Diff = diffCollector
+		rt.Services.Diff = service.NewDiffService(diffCollector, core.Repos.Diff)
+		rt.Services.Diff.SetOnPersisted(func(count int) {
+			rt.Hub.Publish(eventbus.Event{
+				Type: "data_changed",
+				Data: map[string]any{"source": "diffs", "count": count},
+			})
+		})
+		if err := rt.Services.Diff.Start(ctx); err != nil {
+			rt.Close()
+			return nil, err
+		}
+
+		// 提交采集复用同一组监控目录
+		if core.Cfg.Diff.CommitsEnabled {
+			cc := collector.NewCommitCollector(&collector.CommitCollectorConfig{WatchPaths: core.Cfg.Diff.WatchPaths})
+			rt.Collectors.Commit = cc
+			rt.Services.Commit = service.NewCommitService(cc, core.Repos.Commit)
+			rt.Services.Commit.SetSanitizer(sanitizer)
+			rt.Services.Commit.SetOnPersisted(func(count int) {
+				rt.Hub.Publish(eventbus.Event{
+					Type: "data_changed",
+					Data: map[string]any{"source": "commits", "count": count},
+				})
+			})
+			_ = rt.Services.Commit.Start(ctx)
+		}
+	}
+
+	// RAG (optional)
+	if core.Clients.SiliconFlow != nil {
+		rag, err := service.NewRAGService(core.Clients.SiliconFlow, nil)
+		if err == nil {
+			rt.Services.RAG = rag
+			core.Services.AI.SetRAGService(rag)
+			if core.Services.SessionSemantic != nil {
+				core.Services.SessionSemantic.SetRAG(rag)
+			}
+		}
+	}
+
+	// Browser collector + service (optional)
+	if core.Cfg.Browser.Enabled {
+		browserCfg := &collector.BrowserCollectorConfig{
+			Browsers:           core.Cfg.Browser.Browsers,
+			HistoryPath:        core.Cfg.Browser.HistoryPath,
+			FirefoxProfilesDir: core.Cfg.Browser.FirefoxProfilesDir,
+			DisabledProfiles:   core.Cfg.Browser.DisabledProfiles,
+			SearchTerms:        core.Cfg.Browser.SearchTermsEnabled,
+			FocusTracked:       core.Cfg.Browser.NativeHostBrowsers,
+			PollInterval:       time.Duration(core.Cfg.Browser.PollIntervalSec) * time.Second,
+			Cursors:            make(map[string]collector.VisitCursor),
+		}
+		// 从已入库的最后一次访问继续读取；没有游标的 profile 以最新事件时间为界
+		if cursors, err := core.Repos.Browser.GetVisitCursors(ctx); err != nil {
+			slog.Warn("读取浏览器访问游标失败", "error", err)
+		} else {
+			for _, cur := range cursors {
+				key := collector.BrowserProfile{Browser: cur.Browser, Profile: cur.Profile}.Key()
+				browserCfg.Cursors[key] = collector.VisitCursor{VisitID: cur.VisitID, Timestamp: cur.Timestamp}
+			}
+		}
+		if ts, err := core.Repos.Browser.GetLatestTimestamp(ctx); err != nil {
+			slog.Warn("读取最新浏览器事件时间失败", "error", err)
+		} else {
+			browserCfg.Since = ts
+		}
+		bc, err := collector.NewBrowserCollector(browserCfg)
+		if err == nil {
+			rt.Collectors.Browser = bc
+			rt.Services.Browser = service.NewBrowserService(bc, core.Repos.Browser)
+			rt.Services.Browser.SetSanitizer(sanitizer)
+			if core.Cfg.Browser.SearchTermsEnabled {
+				rt.Services.Browser.SetSearchTermRepository(core.Repos.SearchTerm)
+			}
+			rt.Services.Browser.SetOnPersisted(func(count int) {
+				rt.Hub.Publish(eventbus.Event{
+					Type: "data_changed",
+					Data: map[string]any{"source": "browser_events", "count": count},
+				})
+			})
+			_ = rt.Services.Browser.Start(ctx)
+		}
+	}
+
+	// Terminal shell 历史采集（optional，默认关闭）
+	if core.Cfg.Terminal.Enabled {
+		termCfg := &collector.TerminalCollectorConfig{
+			Shells:         core.Cfg.Terminal.Shells,
+			HistoryFiles:   core.Cfg.Terminal.HistoryFiles,
+			IgnoreCommands: core.Cfg.Terminal.IgnoreCommands,
+			PollInterval:   time.Duration(core.Cfg.Terminal.PollIntervalSec) * time.Second,
+		}
+		// 带时间的历史从已入库的最新命令继续，避免重启后重复补录
+		if since, err := core.Repos.Terminal.GetLatestTimestamps(ctx); err != nil {
+			slog.Warn("读取终端采集游标失败", "error", err)
+		} else {
+			termCfg.Since = since
+		}
+		// 命令脱敏始终开启：内置凭据规则 + 隐私规则 + terminal.secret_patterns
+		var patterns []string
+		if core.Cfg.Privacy.Enabled {
+			patterns = append(patterns, core.Cfg.Privacy.Patterns...)
+		}
+		patterns = append(patterns, core.Cfg.Terminal.SecretPatterns...)
+
+		tc := collector.NewTerminalCollector(termCfg)
+		rt.Collectors.Terminal = tc
+		rt.Services.Terminal = service.NewTerminalService(tc, core.Repos.Terminal)
+		rt.Services.Terminal.SetSanitizer(privacy.NewCommandSanitizer(patterns))
+		rt.Services.Terminal.SetOnPersisted(func(count int) {
+			rt.Hub.Publish(eventbus.Event{
+				Type: "data_changed",
+				Data: map[string]any{"source": "terminal_commands", "count": count},
+			})
+		})
+		_ = rt.Services.Terminal.Start(ctx)
+	}
+
+	// 编辑器插件心跳由 HTTP 接口写入，这里只负责通知 UI 刷新
+	if core.Services.Editor != nil {
+		core.Services.Editor.SetOnPersisted(func(count int) {
+			rt.Hub.Publish(eventbus.Event{
+				Type: "data_changed",
+				Data: map[string]any{"source": "editor_heartbeats", "count": count},
+			})
+		})
+	}
+
+	// AI 定时分析（optional）
+	if core.Clients.LLM != nil && core.Clients.LLM.IsConfigured() {
+		go runPeriodic(ctx, 5*time.Minute, func() {
+			withWriteLock(core.Cfg, "analyze", func() { analyzeWithRetry(ctx, core.Services.AI, core.Services.SessionSemantic) })
+		})
+	}
+
+	// Session 定时切分（可离线，无需 AI）
+	if core.Services.Sessions != nil {
+		go runPeriodic(ctx, 5*time.Minute, func() {
+			withWriteLock(core.Cfg, "split_sessions", func() { splitWithRetry(ctx, core.Services.Sessions, core.Services.SessionSemantic) })
+		})
+	}
+
+	// Session 语义补全（用于证据链，LLM 未配置时自动降级为规则摘要）
+	if core.Services.SessionSemantic != nil {
+		go runPeriodic(ctx, 10*time.Minute, func() {
+			withWriteLock(core.Cfg, "enrich_sessions", func() { enrichWithRetry(ctx, core.Services.SessionSemantic) })
+		})
+	}
+
+	// Skill 衰减（本地规则，可离线）
+	if core.Services.Skills != nil {
+		go runPeriodic(ctx, 24*time.Hour, func() {
+			withWriteLock(core.Cfg, "skill_decay", func() { _ = core.Services.Skills.ApplyDecayToAll(context.Background()) })
+		})
+	}
+
+	return rt, nil
+}
+
+// Close 关闭 Agent 运行时资源
+func (rt *AgentRuntime) Close() error {
+	if rt == nil {
+		return nil
+	}
+	if rt.Services.Tracker != nil {
+		_ = rt.Services.Tracker.Stop()
+	}
+	if rt.Services.Diff != nil {
+		_ = rt.Services.Diff.Stop()
+	}
+	if rt.Services.Browser != nil {
+		_ = rt.Services.Browser.Stop()
+	}
+	if rt.Services.Commit != nil {
+		_ = rt.Services.Commit.Stop()
+	}
+	if rt.Services.Terminal != nil {
+		_ = rt.Services.Terminal.Stop()
+	}
+	if rt.Services.RAG != nil {
+		_ = rt.Services.RAG.Close()
+	}
+	return rt.Core.Close()
+}
+
+// runPeriodic 定时执行函数
+func runPeriodic(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	fn()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
+
+// analyzeWithRetry 带重试的 Diff 分析
+func analyzeWithRetry(ctx context.Context, aiService *service.AIService, semantic *service.SessionSemanticService) {
+	if aiService == nil {
+		return
+	}
+	analyzed, _ := aiService.AnalyzePendingDiffs(ctx, 10)
+	// Diff 解读产出后，尽快触发会话语义升级（例如 diff_insight_pending -> ai）。
+	if analyzed > 0 && semantic != nil {
+		limit := analyzed
+		if limit < 10 {
+			limit = 10
+		}
+		if limit > 50 {
+			limit = 50
+		}
+		_, _ = semantic.EnrichSessionsIncremental(ctx, &service.SessionSemanticServiceConfig{
+			Lookback: 48 * time.Hour,
+			Limit:    limit,
+		})
+	}
+}
+
+// splitWithRetry 带重试的会话切分
+func splitWithRetry(ctx context.Context, sessionService *service.SessionService, semantic *service.SessionSemanticService) {
+	if sessionService == nil {
+		return
+	}
+	created, _ := sessionService.BuildSessionsIncremental(ctx)
+	// 避免“切分完成但 summary 为空”的空窗期：当产生新 session 时，立即补全最近会话语义（AI 可用则用 AI，否则走规则）。
+	if created > 0 && semantic != nil {
+		limit := created
+		if limit < 10 {
+			limit = 10
+		}
+		if limit > 50 {
+			limit = 50
+		}
+		_, _ = semantic.EnrichSessionsIncremental(ctx, &service.SessionSemanticServiceConfig{
+			Lookback: 48 * time.Hour,
+			Limit:    limit,
+		})
+	}
+}
+
+// enrichWithRetry 带重试的会话语义补全
+func enrichWithRetry(ctx context.Context, svc *service.SessionSemanticService) {
+	if svc == nil {
+		return
+	}
+	_, _ = svc.EnrichSessionsIncremental(ctx, &service.SessionSemanticServiceConfig{
+		Lookback: 48 * time.Hour,
+		Limit:    20,
+	})
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/yuqie6/WorkMirror/internal/ai"
 	"github.com/yuqie6/WorkMirror/internal/pkg/config"
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
 	"github.com/yuqie6/WorkMirror/internal/repository"
 	"github.com/yuqie6/WorkMirror/internal/service"
 )
@@ -19,8 +20,10 @@ func ptrBool(v bool) *bool { return &v }
 // Core 持有跨二进制共享的核心依赖
 type Core struct {
 	Cfg       *config.Config
+	CfgPath   string // 实际加载的配置文件路径（设置页读写同一份文件）
 	DB        *repository.Database
 	LogCloser io.Closer
+	Domains   *service.DomainClassifier // 浏览域名分类（内置 + browser.domain_rules）
 
 	Repos struct {
 		Diff          *repository.DiffRepository
@@ -29,9 +32,13 @@ type Core struct {
 		Skill         *repository.SkillRepository
 		SkillActivity *repository.SkillActivityRepository
 		Browser       *repository.BrowserEventRepository
+		Commit        *repository.CommitRepository
 		Session       *repository.SessionRepository
 		SessionDiff   *repository.SessionDiffRepository
 		PeriodSummary *repository.PeriodSummaryRepository
+		SearchTerm    *repository.SearchTermRepository
+		Terminal      *repository.TerminalCommandRepository
+		Calendar      *repository.CalendarEventRepository
 	}
 
 	Services struct {
@@ -40,6 +47,10 @@ type Core struct {
 		Trends          *service.TrendService
 		Sessions        *service.SessionService
 		SessionSemantic *service.SessionSemanticService
+		GitImport       *service.GitImportService
+		HistoryImport   *service.HistoryImportService
+		CalendarImport  *service.CalendarImportService
+		Editor          *service.EditorHeartbeatService // editor.enabled=false 时为 nil
 	}
 
 	Clients struct {
@@ -48,8 +59,18 @@ type Core struct {
 	}
 }
 
+// CoreOptions NewCoreWithOptions 的可选参数
+type CoreOptions struct {
+	LogConsole io.Writer // 控制台日志输出（nil=stdout；CLI 用 stderr，避免混入命令输出）
+}
+
 // NewCore 构建核心依赖（不启动采集）
 func NewCore(cfgPath string) (*Core, error) {
+	return NewCoreWithOptions(cfgPath, CoreOptions{})
+}
+
+// NewCoreWithOptions 同 NewCore，可定制日志输出
+func NewCoreWithOptions(cfgPath string, opts CoreOptions) (*Core, error) {
 	cfg, err := config.Load(cfgPath)
 	if err != nil {
 		return nil, err
@@ -58,6 +79,7 @@ func NewCore(cfgPath string) (*Core, error) {
 		Level:     cfg.App.LogLevel,
 		Path:      cfg.App.LogPath,
 		Component: filepath.Base(os.Args[0]),
+		Console:   opts.LogConsole,
 	})
 
 	db, err := repository.NewDatabase(cfg.Storage.DBPath)
@@ -68,7 +90,10 @@ func NewCore(cfgPath string) (*Core, error) {
 		return nil, err
 	}
 
-	c := &Core{Cfg: cfg, DB: db, LogCloser: logCloser}
+	if cfgPath == "" {
+		cfgPath, _ = config.DefaultConfigPath()
+	}
+	c := &Core{Cfg: cfg, CfgPath: cfgPath, DB: db, LogCloser: logCloser}
 
 	// Repos
 	c.Repos.Diff = repository.NewDiffRepository(db.DB)
@@ -77,9 +102,13 @@ func NewCore(cfgPath string) (*Core, error) {
 	c.Repos.Skill = repository.NewSkillRepository(db.DB)
 	c.Repos.SkillActivity = repository.NewSkillActivityRepository(db.DB)
 	c.Repos.Browser = repository.NewBrowserEventRepository(db.DB)
+	c.Repos.Commit = repository.NewCommitRepository(db.DB)
 	c.Repos.Session = repository.NewSessionRepository(db.DB)
 	c.Repos.SessionDiff = repository.NewSessionDiffRepository(db.DB)
 	c.Repos.PeriodSummary = repository.NewPeriodSummaryRepository(db.DB)
+	c.Repos.SearchTerm = repository.NewSearchTermRepository(db.DB)
+	c.Repos.Terminal = repository.NewTerminalCommandRepository(db.DB)
+	c.Repos.Calendar = repository.NewCalendarEventRepository(db.DB)
 
 	// Clients / Analyzer
 	c.Clients.LLM = selectLLMProvider(cfg)
@@ -91,10 +120,14 @@ func NewCore(cfgPath string) (*Core, error) {
 		slog.Warn("LLM Provider 未配置，AI 功能将不可用")
 	}
 
+	c.Domains = newDomainClassifier(cfg)
+
 	// Services
 	c.Services.Skills = service.NewSkillService(c.Repos.Skill, c.Repos.Diff, c.Repos.SkillActivity, service.DefaultExpPolicy{})
 	c.Services.AI = service.NewAIService(analyzer, c.Repos.Diff, c.Repos.Event, c.Repos.Summary, c.Services.Skills)
 	c.Services.Trends = service.NewTrendService(c.Repos.Skill, c.Repos.SkillActivity, c.Repos.Diff, c.Repos.Event, c.Repos.Session)
+	c.Services.Trends.SetBrowserRepository(c.Repos.Browser)
+	c.Services.Trends.SetDomainClassifier(c.Domains)
 	c.Services.Sessions = service.NewSessionService(
 		c.Repos.Event,
 		c.Repos.Diff,
@@ -103,6 +136,15 @@ func NewCore(cfgPath string) (*Core, error) {
 		c.Repos.SessionDiff,
 		&service.SessionServiceConfig{IdleGapMinutes: cfg.Collector.SessionIdleMin},
 	)
+	c.Services.Sessions.SetCommitRepository(c.Repos.Commit)
+	c.Services.Sessions.SetSearchTermRepository(c.Repos.SearchTerm)
+	c.Services.Sessions.SetTerminalCommandRepository(c.Repos.Terminal)
+	c.Services.Sessions.SetCalendarRepository(c.Repos.Calendar)
+	if cfg.Browser.ReadingExpEnabled {
+		c.Services.Sessions.SetReadingExp(c.Services.Skills, c.Domains)
+	}
+	c.Services.AI.SetCommitRepository(c.Repos.Commit)
+	c.Services.AI.SetCalendarRepository(c.Repos.Calendar)
 	c.Services.SessionSemantic = service.NewSessionSemanticService(
 		analyzer,
 		c.Repos.Session,
@@ -110,6 +152,31 @@ func NewCore(cfgPath string) (*Core, error) {
 		c.Repos.Event,
 		c.Repos.Browser,
 	)
+	c.Services.SessionSemantic.SetSearchTermRepository(c.Repos.SearchTerm)
+	c.Services.SessionSemantic.SetTerminalCommandRepository(c.Repos.Terminal)
+	c.Services.SessionSemantic.SetCalendarRepository(c.Repos.Calendar)
+	c.Services.SessionSemantic.SetDevServerHints(c.Domains, devPortHints(cfg))
+
+	c.Services.GitImport = service.NewGitImportService(
+		c.Repos.Diff,
+		c.Repos.Commit,
+		c.Services.Skills,
+		c.Services.Sessions,
+		cfg.Diff.Extensions,
+	)
+	c.Services.GitImport.SetAnalyzer(c.Services.AI)
+	c.Services.GitImport.SetSanitizer(privacy.New(cfg.Privacy.Enabled, cfg.Privacy.Patterns))
+
+	c.Services.HistoryImport = service.NewHistoryImportService(c.Repos.Event, c.Repos.Browser, c.Services.Sessions)
+	c.Services.HistoryImport.SetSanitizer(privacy.New(cfg.Privacy.Enabled, cfg.Privacy.Patterns))
+
+	c.Services.CalendarImport = service.NewCalendarImportService(c.Repos.Calendar, c.Services.Sessions)
+	c.Services.CalendarImport.SetSanitizer(privacy.New(cfg.Privacy.Enabled, cfg.Privacy.Patterns))
+
+	if cfg.Editor.Enabled {
+		c.Services.Editor = service.NewEditorHeartbeatService(c.Repos.Event)
+		c.Services.Editor.SetSanitizer(privacy.New(cfg.Privacy.Enabled, cfg.Privacy.Patterns))
+	}
 
 	// Optional SiliconFlow client 由 Agent 侧按需启动 RAG
 	if cfg.AI.SiliconFlow.APIKey != "" {
@@ -147,6 +214,27 @@ func (c *Core) RequireAIConfigured() error {
 	return nil
 }
 
+// newDomainClassifier 内置域名分类叠加用户配置的规则
+func newDomainClassifier(cfg *config.Config) *service.DomainClassifier {
+	rules := make([]service.DomainRule, 0, len(cfg.Browser.DomainRules))
+	for _, r := range cfg.Browser.DomainRules {
+		rules = append(rules, service.DomainRule{Domain: r.Domain, Category: r.Category, Skill: r.Skill})
+	}
+	return service.NewDomainClassifier(rules)
+}
+
+// devPortHints 从 diff.repos 收集各项目的本地开发服务端口
+func devPortHints(cfg *config.Config) []service.DevPortHint {
+	var hints []service.DevPortHint
+	for _, r := range cfg.Diff.Repos {
+		if strings.TrimSpace(r.Path) == "" || len(r.DevPorts) == 0 {
+			continue
+		}
+		hints = append(hints, service.DevPortHint{Project: filepath.Clean(r.Path), Ports: r.DevPorts})
+	}
+	return hints
+}
+
 // selectLLMProvider 根据配置选择 LLM 供应商
 func selectLLMProvider(cfg *config.Config) ai.LLMProvider {
 	provider := strings.ToLower(strings.TrimSpace(cfg.AI.Provider))
@@ -0,0 +1,46 @@
+package bootstrap
+
+import (
+	"errors"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/yuqie6/WorkMirror/internal/pkg/config"
+	"github.com/yuqie6/WorkMirror/internal/pkg/lockfile"
+)
+
+// AgentLockPath Agent 单实例锁（与数据库同目录；CLI 据此判断是否有 Agent 在运行）
+func AgentLockPath(cfg *config.Config) string {
+	return filepath.Join(filepath.Dir(cfg.Storage.DBPath), "agent.lock")
+}
+
+// WriteLockPath 批量写任务（会话切分/语义补全/总结生成）的协调锁
+// Agent 的后台任务、CLI 的写命令与会话重建接口互斥，避免同一天的会话被并发重建。
+func WriteLockPath(cfg *config.Config) string {
+	return filepath.Join(filepath.Dir(cfg.Storage.DBPath), "write.lock")
+}
+
+// AgentRunning 判断是否有 Agent 持有单实例锁
+func AgentRunning(cfg *config.Config) bool {
+	l, err := lockfile.TryLock(AgentLockPath(cfg))
+	if err != nil {
+		return errors.Is(err, lockfile.ErrLocked)
+	}
+	_ = l.Unlock()
+	return false
+}
+
+// withWriteLock 后台任务专用：写锁被占用（如 CLI 正在重建）时跳过本轮，不阻塞定时器
+func withWriteLock(cfg *config.Config, name string, fn func()) {
+	l, err := lockfile.TryLock(WriteLockPath(cfg))
+	if err != nil {
+		if errors.Is(err, lockfile.ErrLocked) {
+			slog.Info("写锁被占用，跳过本轮后台任务", "job", name)
+		} else {
+			slog.Warn("获取写锁失败，跳过本轮后台任务", "job", name, "error", err)
+		}
+		return
+	}
+	defer l.Unlock()
+	fn()
+}
@@ -0,0 +1,197 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ActivityWatch bucket 类型（bucket.type）
+const (
+	ActivityWatchWindow = "currentwindow"   // aw-watcher-window
+	ActivityWatchAFK    = "afkstatus"       // aw-watcher-afk
+	ActivityWatchWeb    = "web.tab.current" // aw-watcher-web-*
+)
+
+// ActivityWatchBucket 导出文件中的一个 bucket
+type ActivityWatchBucket struct {
+	ID       string
+	Type     string
+	Client   string
+	Hostname string
+	Events   []ActivityWatchEvent
+}
+
+// ActivityWatchEvent bucket 中的一条事件（只保留导入用到的字段）
+type ActivityWatchEvent struct {
+	Timestamp int64   // 毫秒
+	Duration  float64 // 秒
+	App       string  // currentwindow
+	Title     string  // currentwindow / web.tab.current
+	URL       string  // web.tab.current
+	Incognito bool    // web.tab.current
+	Status    string  // afkstatus: afk | not-afk
+}
+
+type awBucketJSON struct {
+	ID       string        `json:"id"`
+	Type     string        `json:"type"`
+	Client   string        `json:"client"`
+	Hostname string        `json:"hostname"`
+	Events   []awEventJSON `json:"events"`
+}
+
+type awEventJSON struct {
+	Timestamp string  `json:"timestamp"`
+	Duration  float64 `json:"duration"`
+	Data      struct {
+		App       string `json:"app"`
+		Title     string `json:"title"`
+		URL       string `json:"url"`
+		Incognito bool   `json:"incognito"`
+		Status    string `json:"status"`
+	} `json:"data"`
+}
+
+// ReadActivityWatchExport 逐个 bucket 读取 ActivityWatch 导出文件：
+// 支持完整导出（{"buckets": {"<id>": {...}}}）与单个 bucket 的导出（{"id": ..., "events": [...]}）。
+// 时间无法解析的事件跳过。
+func ReadActivityWatchExport(r io.Reader, fn func(*ActivityWatchBucket) error) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return fmt.Errorf("解析 ActivityWatch 导出失败: %w", err)
+	}
+
+	// 单个 bucket 的导出没有 buckets 包装：字段逐个读入后按一个 bucket 处理
+	var single awBucketJSON
+	isSingle := false
+	for dec.More() {
+		key, err := readKey(dec)
+		if err != nil {
+			return fmt.Errorf("解析 ActivityWatch 导出失败: %w", err)
+		}
+		switch key {
+		case "buckets":
+			if err := readAWBuckets(dec, fn); err != nil {
+				return err
+			}
+		case "id", "type", "client", "hostname", "events":
+			isSingle = true
+			if err := decodeAWField(dec, key, &single); err != nil {
+				return fmt.Errorf("解析 ActivityWatch bucket 失败: %w", err)
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return fmt.Errorf("解析 ActivityWatch 导出失败: %w", err)
+			}
+		}
+	}
+	if isSingle {
+		return fn(single.toBucket(single.ID))
+	}
+	return nil
+}
+
+func readAWBuckets(dec *json.Decoder, fn func(*ActivityWatchBucket) error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return fmt.Errorf("解析 ActivityWatch buckets 失败: %w", err)
+	}
+	for dec.More() {
+		id, err := readKey(dec)
+		if err != nil {
+			return fmt.Errorf("解析 ActivityWatch buckets 失败: %w", err)
+		}
+		var b awBucketJSON
+		if err := dec.Decode(&b); err != nil {
+			return fmt.Errorf("解析 ActivityWatch bucket %s 失败: %w", id, err)
+		}
+		if err := fn(b.toBucket(id)); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Token()
+	return err
+}
+
+func decodeAWField(dec *json.Decoder, key string, b *awBucketJSON) error {
+	switch key {
+	case "id":
+		return dec.Decode(&b.ID)
+	case "type":
+		return dec.Decode(&b.Type)
+	case "client":
+		return dec.Decode(&b.Client)
+	case "hostname":
+		return dec.Decode(&b.Hostname)
+	default:
+		return dec.Decode(&b.Events)
+	}
+}
+
+func (b awBucketJSON) toBucket(id string) *ActivityWatchBucket {
+	if strings.TrimSpace(b.ID) != "" {
+		id = b.ID
+	}
+	out := &ActivityWatchBucket{
+		ID:       id,
+		Type:     b.Type,
+		Client:   b.Client,
+		Hostname: b.Hostname,
+		Events:   make([]ActivityWatchEvent, 0, len(b.Events)),
+	}
+	for _, e := range b.Events {
+		t, err := time.Parse(time.RFC3339Nano, e.Timestamp)
+		if err != nil {
+			continue
+		}
+		out.Events = append(out.Events, ActivityWatchEvent{
+			Timestamp: t.UnixMilli(),
+			Duration:  e.Duration,
+			App:       e.Data.App,
+			Title:     e.Data.Title,
+			URL:       e.Data.URL,
+			Incognito: e.Data.Incognito,
+			Status:    e.Data.Status,
+		})
+	}
+	return out
+}
+
+// ActivityWatchWebBrowser 由 web bucket 推断浏览器（aw-watcher-web-chrome_host -> chrome）
+func ActivityWatchWebBrowser(bucketID string) string {
+	id := strings.ToLower(bucketID)
+	if i := strings.IndexByte(id, '_'); i >= 0 {
+		id = id[:i]
+	}
+	if name, ok := strings.CutPrefix(id, "aw-watcher-web-"); ok && name != "" {
+		return name
+	}
+	return BrowserChrome
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("期望 %q，实际为 %v", want, tok)
+	}
+	return nil
+}
+
+func readKey(dec *json.Decoder) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", errors.New("期望对象键")
+	}
+	return key, nil
+}
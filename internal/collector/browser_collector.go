@@ -1,9 +1,8 @@
-//go:build windows
-
 package collector
 
 import (
 	"context"
+	"crypto/sha1"
 	"database/sql"
 	"fmt"
 	"io"
@@ -19,15 +18,104 @@ import (
 	"github.com/yuqie6/WorkMirror/internal/schema"
 )
 
+// chromeHistoryQuery Chrome History 访问记录；visit_time 为 WebKit 时间戳（微秒 from 1601-01-01），visit_duration 为微秒。
+// 按访问 ID 翻页：同步写入的旧访问 ID 更大但时间更早，按时间游标会漏掉。
+// 末列与 Firefox 查询对齐（重定向源标记），Chrome 从 transition 限定符判断
+const chromeHistoryQuery = `
+	SELECT visits.id, urls.url, urls.title, visits.visit_time,
+		COALESCE(visits.from_visit, 0), COALESCE(visits.transition, 0), COALESCE(visits.visit_duration, 0), 0
+	FROM visits
+	JOIN urls ON visits.url = urls.id
+	WHERE visits.id > ? AND visits.visit_time > ?
+	ORDER BY visits.id ASC
+	LIMIT ?
+`
+
+const chromeMaxVisitQuery = `SELECT COALESCE(MAX(id), 0) FROM visits`
+
+// chromeVisitDurationQuery 回查此前读到时尚未结束的访问（%s 为 ID 占位符列表）
+const chromeVisitDurationQuery = `SELECT id, visit_duration FROM visits WHERE visit_duration > 0 AND id IN (%s)`
+
+// Chrome transition：低 8 位为核心类型，高位为限定符
+const (
+	chromeTransitionCoreMask  = 0xFF
+	chromeTransitionChainFrom = 0x10000000 // 重定向链起点
+	chromeTransitionChainEnd  = 0x20000000 // 重定向链终点（最终落地页）
+)
+
+const (
+	browserPageSize        = 500
+	browserMaxPagesPerPoll = 40 // 单次轮询最多读取的页数，积压更多时下次继续
+
+	browserMaxPendingDurations = 500            // 每个 profile 最多等待回查时长的访问数
+	browserPendingDurationTTL  = 12 * time.Hour // 超过该时间仍未结束的访问不再回查
+)
+
+// webkitEpochOffset WebKit 纪元（1601-01-01）与 Unix 纪元之间的微秒数
+const webkitEpochOffset = 11644473600000000
+
+// VisitCursor 已入库的采集进度
+type VisitCursor struct {
+	VisitID   int64 // 历史库中的访问 ID
+	Timestamp int64 // 该访问的时间（毫秒）
+}
+
+// VisitDuration 访问结束后浏览器补写的停留时长
+type VisitDuration struct {
+	Browser  string
+	Profile  string
+	VisitID  int64
+	Duration int // 秒
+}
+
+// historySource 一个 profile 的历史数据库及其读取游标
+type historySource struct {
+	profile       BrowserProfile
+	path          string
+	tempPath      string
+	query         string
+	maxQuery      string
+	durationQuery string // 为空表示不读取浏览器记录的停留时长（Firefox，或由扩展上报前台时间）
+	focusTracked  bool   // 停留时长由扩展上报（browser.native_host_browsers），历史访问不再计时
+
+	lastID   int64 // 已读取的最大访问 ID
+	minVisit int64 // 只读取晚于该时间的访问（浏览器原生单位；没有 ID 游标时使用）
+	lastAt   int64 // 已读取的最新访问时间（毫秒）
+
+	pending []pendingVisit // 已发送但停留时长仍为 0 的访问
+}
+
+type pendingVisit struct {
+	id int64
+	at int64 // 访问时间（毫秒）
+}
+
+// toUnixMilli 浏览器原生时间戳转换为 Unix 毫秒
+func (s *historySource) toUnixMilli(v int64) int64 {
+	if s.profile.Browser == BrowserFirefox {
+		return v / 1000
+	}
+	return (v - webkitEpochOffset) / 1000
+}
+
+// fromTime Unix 时间转换为浏览器原生时间戳
+func (s *historySource) fromTime(t time.Time) int64 {
+	if s.profile.Browser == BrowserFirefox {
+		return t.UnixMicro()
+	}
+	return t.UnixMicro() + webkitEpochOffset
+}
+
 // BrowserCollector 浏览器历史采集器
 type BrowserCollector struct {
-	historyPath   string
-	tempPath      string
-	pollInterval  time.Duration
-	lastVisitTime int64
-	eventChan     chan *schema.BrowserEvent
-	stopChan      chan struct{}
-	running       bool
+	sources      []*historySource
+	pollInterval time.Duration
+	eventChan    chan *schema.BrowserEvent
+	durationChan chan VisitDuration
+	searchChan   chan *SearchQuery
+	searchTerms  bool
+	stopChan     chan struct{}
+	running      bool
 
 	lastEmitAt atomic.Int64
 	dropped    atomic.Int64
@@ -35,29 +123,58 @@ type BrowserCollector struct {
 
 // BrowserCollectorConfig 配置
 type BrowserCollectorConfig struct {
-	HistoryPath  string        // Chrome History 文件路径（可选，自动检测）
-	PollInterval time.Duration // 轮询间隔
+	Browsers           []string      // 采集的浏览器（chrome/edge/.../firefox，chromium-family 表示全部 Chromium 系），为空时全部
+	HistoryPath        string        // 显式指定的 Chromium History 文件（可选，替代自动发现）
+	FirefoxProfilesDir string        // Firefox 数据目录（含 profiles.ini，可选，自动检测）
+	DisabledProfiles   []string      // 不采集的 profile（BrowserProfile.Key）
+	PollInterval       time.Duration // 轮询间隔
+	SearchTerms        bool          // 提取搜索词（可选）
+	FocusTracked       []string      // 由扩展上报前台停留的浏览器：历史访问只作导航证据，停留时长记为 0
+
+	// Cursors 各 profile（BrowserProfile.Key）已入库的最新访问，从其后继续读取
+	Cursors map[string]VisitCursor
+	// Since 没有游标的 profile 从该时间（毫秒）之后读取；为 0 时从一小时前开始
+	Since int64
 }
 
-// NewBrowserCollector 创建浏览器采集器
+// NewBrowserCollector 创建浏览器采集器：每个发现的 profile 独立轮询、独立游标
 func NewBrowserCollector(cfg *BrowserCollectorConfig) (*BrowserCollector, error) {
 	if cfg == nil {
 		cfg = &BrowserCollectorConfig{}
 	}
-
-	historyPath := cfg.HistoryPath
-	if historyPath == "" {
-		// 自动检测 Chrome History 路径
-		historyPath = getChromeHistoryPath()
+	if cfg.HistoryPath != "" {
+		if _, err := os.Stat(cfg.HistoryPath); os.IsNotExist(err) {
+			return nil, fmt.Errorf("chrome history 文件不存在: %s", cfg.HistoryPath)
+		}
 	}
 
-	if historyPath == "" {
-		return nil, fmt.Errorf("未找到 chrome history 文件")
+	disabled := make(map[string]bool, len(cfg.DisabledProfiles))
+	for _, k := range cfg.DisabledProfiles {
+		disabled[k] = true
 	}
-
-	// 检查文件是否存在
-	if _, err := os.Stat(historyPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("chrome history 文件不存在: %s", historyPath)
+	focusTracked := make(map[string]bool, len(cfg.FocusTracked))
+	for _, b := range cfg.FocusTracked {
+		focusTracked[strings.ToLower(strings.TrimSpace(b))] = true
+	}
+	var sources []*historySource
+	for _, p := range DiscoverBrowserProfiles(BrowserDiscoveryConfig{
+		Browsers:           cfg.Browsers,
+		HistoryPath:        cfg.HistoryPath,
+		FirefoxProfilesDir: cfg.FirefoxProfilesDir,
+	}) {
+		if disabled[p.Key()] {
+			slog.Debug("浏览器 profile 已禁用", "profile", p.Key())
+			continue
+		}
+		src := newHistorySource(p)
+		if focusTracked[p.Browser] {
+			src.focusTracked, src.durationQuery = true, ""
+		}
+		src.resume(cfg.Cursors[p.Key()], cfg.Since)
+		sources = append(sources, src)
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("未找到浏览器历史文件")
 	}
 
 	pollInterval := cfg.PollInterval
@@ -65,42 +182,45 @@ func NewBrowserCollector(cfg *BrowserCollectorConfig) (*BrowserCollector, error)
 		pollInterval = 30 * time.Second // 默认 30 秒轮询一次
 	}
 
-	// 临时文件路径
-	tempDir := os.TempDir()
-	tempPath := filepath.Join(tempDir, "mirror_chrome_history.db")
-
 	return &BrowserCollector{
-		historyPath:  historyPath,
-		tempPath:     tempPath,
+		sources:      sources,
 		pollInterval: pollInterval,
 		eventChan:    make(chan *schema.BrowserEvent, 256),
+		durationChan: make(chan VisitDuration, 256),
+		searchChan:   make(chan *SearchQuery, 64),
+		searchTerms:  cfg.SearchTerms,
 		stopChan:     make(chan struct{}),
 	}, nil
 }
 
-// getChromeHistoryPath 获取 Chrome History 文件路径
-func getChromeHistoryPath() string {
-	localAppData := os.Getenv("LOCALAPPDATA")
-	if localAppData == "" {
-		return ""
+func newHistorySource(p BrowserProfile) *historySource {
+	query, maxQuery, durationQuery := chromeHistoryQuery, chromeMaxVisitQuery, chromeVisitDurationQuery
+	if p.Browser == BrowserFirefox {
+		query, maxQuery, durationQuery = firefoxHistoryQuery, firefoxMaxVisitQuery, ""
 	}
-
-	// 检查常见的 Chrome 路径
-	paths := []string{
-		filepath.Join(localAppData, "Google", "Chrome", "User Data", "Default", "History"),
-		filepath.Join(localAppData, "Google", "Chrome Beta", "User Data", "Default", "History"),
-		filepath.Join(localAppData, "Chromium", "User Data", "Default", "History"),
-		filepath.Join(localAppData, "Microsoft", "Edge", "User Data", "Default", "History"),
+	// 每个历史文件单独的临时副本（多个 profile 同时轮询）
+	sum := sha1.Sum([]byte(p.HistoryPath))
+	name := fmt.Sprintf("mirror_%s_history_%x.db", p.Browser, sum[:4])
+	return &historySource{
+		profile:       p,
+		path:          p.HistoryPath,
+		tempPath:      filepath.Join(os.TempDir(), name),
+		query:         query,
+		maxQuery:      maxQuery,
+		durationQuery: durationQuery,
 	}
+}
 
-	for _, path := range paths {
-		if _, err := os.Stat(path); err == nil {
-			slog.Debug("发现浏览器历史文件", "path", path)
-			return path
-		}
+// resume 设置起始游标：优先已入库的访问 ID，其次 since（毫秒），都没有时从一小时前开始
+func (s *historySource) resume(cur VisitCursor, since int64) {
+	switch {
+	case cur.VisitID > 0:
+		s.lastID, s.lastAt = cur.VisitID, cur.Timestamp
+	case since > 0:
+		s.minVisit, s.lastAt = s.fromTime(time.UnixMilli(since)), since
+	default:
+		s.minVisit = s.fromTime(time.Now().Add(-1 * time.Hour))
 	}
-
-	return ""
 }
 
 // Start 启动采集
@@ -110,7 +230,7 @@ func (c *BrowserCollector) Start(ctx context.Context) error {
 	}
 
 	c.running = true
-	slog.Info("浏览器历史采集器启动", "history_path", c.historyPath)
+	slog.Info("浏览器历史采集器启动", "history_paths", c.historyPaths())
 
 	go c.pollLoop(ctx)
 	return nil
@@ -126,7 +246,9 @@ func (c *BrowserCollector) Stop() error {
 	c.running = false
 
 	// 清理临时文件
-	os.Remove(c.tempPath)
+	for _, src := range c.sources {
+		removeHistoryCopy(src.tempPath)
+	}
 
 	slog.Info("浏览器历史采集器已停止")
 	return nil
@@ -137,14 +259,23 @@ func (c *BrowserCollector) Events() <-chan *schema.BrowserEvent {
 	return c.eventChan
 }
 
+// SearchQueries 返回搜索词通道（仅启用 SearchTerms 时有数据）
+func (c *BrowserCollector) SearchQueries() <-chan *SearchQuery {
+	return c.searchChan
+}
+
+// Durations 返回停留时长更新通道（访问已作为事件发送过）
+func (c *BrowserCollector) Durations() <-chan VisitDuration {
+	return c.durationChan
+}
+
 // pollLoop 轮询循环
 func (c *BrowserCollector) pollLoop(ctx context.Context) {
 	ticker := time.NewTicker(c.pollInterval)
 	defer ticker.Stop()
 
-	// 首次获取设置基准时间
-	c.lastVisitTime = time.Now().Add(-1*time.Hour).UnixMicro() * 10 // Chrome 使用 WebKit 时间戳
-
+	// 启动后立即补读离线期间的历史
+	c.pollAll(ctx)
 	for {
 		select {
 		case <-ctx.Done():
@@ -152,58 +283,85 @@ func (c *BrowserCollector) pollLoop(ctx context.Context) {
 		case <-c.stopChan:
 			return
 		case <-ticker.C:
-			c.collectHistory()
+			c.pollAll(ctx)
 		}
 	}
 }
 
-// collectHistory 采集历史记录
-func (c *BrowserCollector) collectHistory() {
-	// 复制 History 文件（避免锁定问题）
-	if err := copyFile(c.historyPath, c.tempPath); err != nil {
-		slog.Debug("复制 History 文件失败", "error", err)
+func (c *BrowserCollector) pollAll(ctx context.Context) {
+	for _, src := range c.sources {
+		c.collectHistory(ctx, src)
+	}
+}
+
+// collectHistory 按访问 ID 分页读取单个历史数据库的新访问
+func (c *BrowserCollector) collectHistory(ctx context.Context, src *historySource) {
+	// 复制历史文件（避免锁定问题）；Firefox 使用 WAL，未合并的新记录在 -wal 中
+	if err := copyFile(src.path, src.tempPath); err != nil {
+		slog.Debug("复制浏览器历史文件失败", "path", src.path, "error", err)
 		return
 	}
-	defer os.Remove(c.tempPath)
+	defer removeHistoryCopy(src.tempPath)
+	if err := copyFile(src.path+"-wal", src.tempPath+"-wal"); err != nil && !os.IsNotExist(err) {
+		slog.Debug("复制浏览器历史 WAL 失败", "path", src.path, "error", err)
+	}
 
 	// 打开临时数据库
-	db, err := sql.Open("sqlite", c.tempPath)
+	db, err := sql.Open("sqlite", src.tempPath)
 	if err != nil {
-		slog.Debug("打开 History 数据库失败", "error", err)
+		slog.Debug("打开浏览器历史数据库失败", "path", src.path, "error", err)
 		return
 	}
 	defer db.Close()
 
-	// 查询最新的访问记录
-	// Chrome 使用 WebKit 时间戳：微秒 from 1601-01-01
-	query := `
-		SELECT urls.url, urls.title, visits.visit_time
-		FROM visits
-		JOIN urls ON visits.url = urls.id
-		WHERE visits.visit_time > ?
-		ORDER BY visits.visit_time ASC
-		LIMIT 100
-	`
-
-	rows, err := db.Query(query, c.lastVisitTime)
+	// 历史被清空后访问 ID 会从头分配：回退到按时间读取
+	var maxID int64
+	if err := db.QueryRow(src.maxQuery).Scan(&maxID); err == nil && maxID < src.lastID {
+		slog.Info("浏览器历史访问 ID 回退，改为按时间继续", "profile", src.profile.Key())
+		src.lastID = 0
+		src.minVisit = src.fromTime(time.UnixMilli(src.lastAt))
+	}
+
+	count := 0
+	for page := 0; page < browserMaxPagesPerPoll; page++ {
+		n, emitted, ok := c.readPage(ctx, db, src)
+		count += emitted
+		if !ok || n < browserPageSize {
+			break
+		}
+	}
+
+	if count > 0 {
+		slog.Debug("采集到浏览器历史", "profile", src.profile.Key(), "count", count)
+	}
+	c.refreshDurations(ctx, db, src)
+}
+
+// readPage 读取一页访问，返回读取行数、发送事件数；ok=false 表示出错或已停止
+func (c *BrowserCollector) readPage(ctx context.Context, db *sql.DB, src *historySource) (int, int, bool) {
+	keywords := c.keywordTerms(ctx, db, src)
+	rows, err := db.Query(src.query, src.lastID, src.minVisit, browserPageSize)
 	if err != nil {
-		slog.Debug("查询历史记录失败", "error", err)
-		return
+		slog.Debug("查询历史记录失败", "path", src.path, "error", err)
+		return 0, 0, false
 	}
 	defer rows.Close()
 
-	count := 0
+	n, emitted := 0, 0
 	for rows.Next() {
+		var visitID, visitTime, fromVisit, transition, durationMicros int64
 		var urlStr, title string
-		var visitTime int64
-
-		if err := rows.Scan(&urlStr, &title, &visitTime); err != nil {
+		var redirectSource bool
+		if err := rows.Scan(&visitID, &urlStr, &title, &visitTime, &fromVisit, &transition, &durationMicros, &redirectSource); err != nil {
 			continue
 		}
+		n++
 
-		// 更新最后访问时间
-		if visitTime > c.lastVisitTime {
-			c.lastVisitTime = visitTime
+		// 更新游标；有了 ID 锚点后不再需要时间条件
+		src.lastID, src.minVisit = visitID, 0
+		unixMilli := src.toUnixMilli(visitTime)
+		if unixMilli > src.lastAt {
+			src.lastAt = unixMilli
 		}
 
 		// 解析 URL 获取域名
@@ -217,50 +375,196 @@ func (c *BrowserCollector) collectHistory() {
 			continue
 		}
 
-		// 转换 WebKit 时间戳为 Unix 时间戳
-		// WebKit: 微秒 from 1601-01-01
-		// Unix: 毫秒 from 1970-01-01
-		unixMilli := (visitTime - 11644473600000000) / 1000
+		if src.focusTracked {
+			durationMicros = 0
+		}
 
 		event := &schema.BrowserEvent{
-			Timestamp: unixMilli,
-			URL:       urlStr,
-			Title:     title,
-			Domain:    domain,
+			Timestamp:   unixMilli,
+			URL:         urlStr,
+			Title:       title,
+			Domain:      domain,
+			Duration:    microsToSeconds(durationMicros),
+			Browser:     src.profile.Browser,
+			Profile:     src.profile.Profile,
+			VisitID:     visitID,
+			FromVisitID: fromVisit,
+			Transition:  src.transition(transition, redirectSource),
 		}
 
+		// 积压补读时可能远超缓冲区，阻塞等待消费而不是丢弃
 		select {
 		case c.eventChan <- event:
 			c.lastEmitAt.Store(unixMilli)
-			count++
+			emitted++
+			if event.Duration == 0 && event.Transition != schema.BrowserTransitionRedirect {
+				src.trackPending(visitID, unixMilli)
+			}
+		case <-ctx.Done():
+			return n, emitted, false
+		case <-c.stopChan:
+			return n, emitted, false
+		}
+
+		if q := c.searchQuery(src, visitID, unixMilli, urlStr, domain, keywords); q != nil {
+			select {
+			case c.searchChan <- q:
+			case <-ctx.Done():
+				return n, emitted, false
+			case <-c.stopChan:
+				return n, emitted, false
+			}
+		}
+	}
+	return n, emitted, rows.Err() == nil
+}
+
+// transition 归一化浏览器原生的进入方式
+func (s *historySource) transition(raw int64, redirectSource bool) string {
+	if s.profile.Browser == BrowserFirefox {
+		return firefoxTransition(raw, redirectSource)
+	}
+	return chromeTransition(raw)
+}
+
+// chromeTransition 重定向链中非终点的访问只是途经页
+func chromeTransition(raw int64) string {
+	if raw&(chromeTransitionChainFrom|chromeTransitionChainEnd) != 0 && raw&chromeTransitionChainEnd == 0 {
+		return schema.BrowserTransitionRedirect
+	}
+	switch raw & chromeTransitionCoreMask {
+	case 0:
+		return schema.BrowserTransitionLink
+	case 1:
+		return schema.BrowserTransitionTyped
+	case 2:
+		return schema.BrowserTransitionBookmark
+	case 3, 4:
+		return schema.BrowserTransitionSubframe
+	case 5, 9, 10:
+		return schema.BrowserTransitionGenerated
+	case 7:
+		return schema.BrowserTransitionForm
+	case 8:
+		return schema.BrowserTransitionReload
+	default:
+		return schema.BrowserTransitionOther
+	}
+}
+
+// trackPending 记录待回查时长的访问，超出上限时丢弃最早的
+func (s *historySource) trackPending(id, at int64) {
+	if s.durationQuery == "" {
+		return
+	}
+	s.pending = append(s.pending, pendingVisit{id: id, at: at})
+	if n := len(s.pending) - browserMaxPendingDurations; n > 0 {
+		s.pending = append(s.pending[:0], s.pending[n:]...)
+	}
+}
+
+// refreshDurations Chrome 在离开页面后才写入 visit_duration：回查此前读到时仍为 0 的访问
+func (c *BrowserCollector) refreshDurations(ctx context.Context, db *sql.DB, src *historySource) {
+	if src.durationQuery == "" || len(src.pending) == 0 {
+		return
+	}
+	expire := time.Now().Add(-browserPendingDurationTTL).UnixMilli()
+	live := make([]pendingVisit, 0, len(src.pending))
+	args := make([]any, 0, len(src.pending))
+	for _, p := range src.pending {
+		if p.at >= expire {
+			live = append(live, p)
+			args = append(args, p.id)
+		}
+	}
+	src.pending = live
+	if len(args) == 0 {
+		return
+	}
+
+	query := fmt.Sprintf(src.durationQuery, strings.TrimSuffix(strings.Repeat("?,", len(args)), ","))
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.Debug("查询访问时长失败", "path", src.path, "error", err)
+		return
+	}
+	defer rows.Close()
+
+	done := make(map[int64]bool)
+	for rows.Next() {
+		var id, micros int64
+		if err := rows.Scan(&id, &micros); err != nil {
+			continue
+		}
+		d := VisitDuration{Browser: src.profile.Browser, Profile: src.profile.Profile, VisitID: id, Duration: microsToSeconds(micros)}
+		if d.Duration <= 0 {
+			continue
+		}
+		// 通道满时保留待回查，下次轮询重试
+		select {
+		case c.durationChan <- d:
+			done[id] = true
 		default:
-			c.dropped.Add(1)
-			slog.Warn("浏览器事件缓冲区已满")
 		}
 	}
+	if len(done) == 0 {
+		return
+	}
+	kept := src.pending[:0]
+	for _, p := range src.pending {
+		if !done[p.id] {
+			kept = append(kept, p)
+		}
+	}
+	src.pending = kept
+}
 
-	if count > 0 {
-		slog.Debug("采集到浏览器历史", "count", count)
+// microsToSeconds 微秒四舍五入为秒
+func microsToSeconds(v int64) int {
+	if v <= 0 {
+		return 0
+	}
+	return int((v + 500_000) / 1_000_000)
+}
+
+func (c *BrowserCollector) historyPaths() []string {
+	paths := make([]string, 0, len(c.sources))
+	for _, src := range c.sources {
+		paths = append(paths, src.path)
+	}
+	return paths
+}
+
+// removeHistoryCopy 删除临时副本及 SQLite 旁路文件
+func removeHistoryCopy(path string) {
+	for _, suffix := range []string{"", "-wal", "-shm", "-journal"} {
+		_ = os.Remove(path + suffix)
 	}
 }
 
 type BrowserCollectorStats struct {
-	Running     bool   `json:"running"`
-	HistoryPath string `json:"history_path"`
-	LastEmitAt  int64  `json:"last_emit_at"`
-	Dropped     int64  `json:"dropped"`
+	Running      bool     `json:"running"`
+	HistoryPath  string   `json:"history_path"` // 第一个历史文件（兼容旧字段）
+	HistoryPaths []string `json:"history_paths"`
+	LastEmitAt   int64    `json:"last_emit_at"`
+	Dropped      int64    `json:"dropped"`
 }
 
 func (c *BrowserCollector) Stats() BrowserCollectorStats {
 	if c == nil {
 		return BrowserCollectorStats{}
 	}
-	return BrowserCollectorStats{
-		Running:     c.running,
-		HistoryPath: c.historyPath,
-		LastEmitAt:  c.lastEmitAt.Load(),
-		Dropped:     c.dropped.Load(),
+	paths := c.historyPaths()
+	st := BrowserCollectorStats{
+		Running:      c.running,
+		HistoryPaths: paths,
+		LastEmitAt:   c.lastEmitAt.Load(),
+		Dropped:      c.dropped.Load(),
+	}
+	if len(paths) > 0 {
+		st.HistoryPath = paths[0]
 	}
+	return st
 }
 
 // extractDomain 从 URL 提取域名
@@ -278,6 +582,10 @@ func shouldSkipURL(urlStr string) bool {
 		"chrome://",
 		"chrome-extension://",
 		"edge://",
+		"moz-extension://",
+		"resource://",
+		"place:",
+		"view-source:",
 		"about:",
 		"file://",
 		"data:",
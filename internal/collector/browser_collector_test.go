@@ -0,0 +1,398 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+// writeSQLiteFixture 创建浏览器历史数据库夹具
+func writeSQLiteFixture(t *testing.T, path string, stmts ...string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer db.Close()
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+}
+
+// chromeVisitsTable Chrome History 中 visits 表用到的列
+const chromeVisitsTable = `CREATE TABLE visits (id INTEGER PRIMARY KEY, url INTEGER, visit_time INTEGER,
+	from_visit INTEGER DEFAULT 0, transition INTEGER DEFAULT 0, visit_duration INTEGER DEFAULT 0)`
+
+func writeFirefoxPlaces(t *testing.T, path string, visits ...string) {
+	t.Helper()
+	stmts := []string{
+		`CREATE TABLE moz_places (id INTEGER PRIMARY KEY, url LONGVARCHAR, title LONGVARCHAR)`,
+		`CREATE TABLE moz_historyvisits (id INTEGER PRIMARY KEY, from_visit INTEGER, place_id INTEGER, visit_date INTEGER, visit_type INTEGER)`,
+	}
+	writeSQLiteFixture(t, path, append(stmts, visits...)...)
+}
+
+func drainBrowserEvents(c *BrowserCollector) []*schema.BrowserEvent {
+	var out []*schema.BrowserEvent
+	for {
+		select {
+		case e := <-c.eventChan:
+			out = append(out, e)
+		default:
+			return out
+		}
+	}
+}
+
+func TestDiscoverFirefoxProfiles(t *testing.T) {
+	dir := t.TempDir()
+	external := t.TempDir()
+	writeFirefoxPlaces(t, filepath.Join(dir, "Profiles", "old.default", "places.sqlite"))
+	writeFirefoxPlaces(t, filepath.Join(dir, "Profiles", "main.default-release", "places.sqlite"))
+	writeFirefoxPlaces(t, filepath.Join(external, "places.sqlite"))
+	writeTestFile(t, filepath.Join(dir, "profiles.ini"), `[General]
+StartWithLastProfile=1
+
+[Profile0]
+Name=default
+IsRelative=1
+Path=Profiles/old.default
+Default=1
+
+[Profile1]
+Name=default-release
+IsRelative=1
+Path=Profiles/main.default-release
+
+[Profile2]
+Name=work
+IsRelative=0
+Path=`+external+`
+
+[Profile3]
+Name=empty
+IsRelative=1
+Path=Profiles/missing
+
+[Install4F96D1932A9F858E]
+Default=Profiles/main.default-release
+Locked=1
+`)
+
+	profiles := discoverFirefoxProfiles(dir)
+	if len(profiles) != 3 {
+		t.Fatalf("expected 3 profiles with places.sqlite, got %+v", profiles)
+	}
+	if profiles[0].Name != "default-release" || !profiles[0].IsDefault {
+		t.Fatalf("install default should come first, got %+v", profiles[0])
+	}
+	if profiles[2].PlacesPath != filepath.Join(external, "places.sqlite") {
+		t.Fatalf("absolute profile path not resolved: %+v", profiles[2])
+	}
+}
+
+func TestBrowserCollector_collectsFirefoxHistory(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	us := func(d time.Duration) int64 { return base.Add(d).UnixMicro() }
+	writeFirefoxPlaces(t, filepath.Join(dir, "p1", "places.sqlite"),
+		`INSERT INTO moz_places (id, url, title) VALUES (1, 'https://go.dev/doc/', 'Documentation'), (2, 'https://example.com/x', NULL), (3, 'about:newtab', 'New Tab')`,
+		`INSERT INTO moz_historyvisits (place_id, visit_date) VALUES (1, `+itoa(us(-time.Hour))+`), (1, `+itoa(us(time.Minute))+`), (3, `+itoa(us(2*time.Minute))+`), (2, `+itoa(us(3*time.Minute))+`)`,
+	)
+	writeTestFile(t, filepath.Join(dir, "profiles.ini"), "[Profile0]\nName=default\nIsRelative=1\nPath=p1\n")
+
+	c, err := NewBrowserCollector(&BrowserCollectorConfig{Browsers: []string{"firefox"}, FirefoxProfilesDir: dir})
+	if err != nil {
+		t.Fatalf("NewBrowserCollector: %v", err)
+	}
+	src := c.sources[0]
+	src.resume(VisitCursor{}, base.UnixMilli())
+	c.collectHistory(context.Background(), src)
+
+	events := drainBrowserEvents(c)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events after cursor (about: skipped), got %d", len(events))
+	}
+	if events[0].URL != "https://go.dev/doc/" || events[0].Domain != "go.dev" || events[0].Timestamp != base.Add(time.Minute).UnixMilli() {
+		t.Fatalf("unexpected first event: %+v", events[0])
+	}
+	if events[0].Browser != BrowserFirefox || events[0].Profile != "p1" {
+		t.Fatalf("event should record browser and profile: %+v", events[0])
+	}
+	if events[1].Title != "" || events[1].Domain != "example.com" {
+		t.Fatalf("NULL title should become empty: %+v", events[1])
+	}
+	if src.lastID != 4 || src.lastAt != base.Add(3*time.Minute).UnixMilli() {
+		t.Fatalf("cursor should advance to last visit, got id=%d at=%d", src.lastID, src.lastAt)
+	}
+
+	// 游标之后没有新记录
+	c.collectHistory(context.Background(), src)
+	if n := len(drainBrowserEvents(c)); n != 0 {
+		t.Fatalf("expected no duplicates on second poll, got %d", n)
+	}
+}
+
+func TestBrowserCollector_chromeAndFirefoxTogether(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	chromePath := filepath.Join(dir, "Chrome", "History")
+	writeSQLiteFixture(t, chromePath,
+		`CREATE TABLE urls (id INTEGER PRIMARY KEY, url LONGVARCHAR, title LONGVARCHAR)`,
+		chromeVisitsTable,
+		`INSERT INTO urls VALUES (1, 'https://pkg.go.dev/net/http', 'http package')`,
+		`INSERT INTO visits (url, visit_time) VALUES (1, `+itoa(base.Add(time.Minute).UnixMicro()+webkitEpochOffset)+`)`,
+	)
+	ffDir := filepath.Join(dir, "Firefox")
+	writeFirefoxPlaces(t, filepath.Join(ffDir, "p", "places.sqlite"),
+		`INSERT INTO moz_places VALUES (1, 'https://developer.mozilla.org/', 'MDN')`,
+		`INSERT INTO moz_historyvisits (place_id, visit_date) VALUES (1, `+itoa(base.Add(2*time.Minute).UnixMicro())+`)`,
+	)
+	writeTestFile(t, filepath.Join(ffDir, "profiles.ini"), "[Profile0]\nName=default\nIsRelative=1\nPath=p\n")
+
+	c, err := NewBrowserCollector(&BrowserCollectorConfig{HistoryPath: chromePath, FirefoxProfilesDir: ffDir})
+	if err != nil {
+		t.Fatalf("NewBrowserCollector: %v", err)
+	}
+	if st := c.Stats(); len(st.HistoryPaths) != 2 || st.HistoryPath != chromePath {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+	for _, src := range c.sources {
+		src.resume(VisitCursor{}, base.UnixMilli())
+		c.collectHistory(context.Background(), src)
+	}
+	events := drainBrowserEvents(c)
+	if len(events) != 2 {
+		t.Fatalf("expected one event per browser, got %d", len(events))
+	}
+	if events[0].Domain != "pkg.go.dev" || events[0].Timestamp != base.Add(time.Minute).UnixMilli() {
+		t.Fatalf("unexpected chrome event: %+v", events[0])
+	}
+	if events[1].Domain != "developer.mozilla.org" || events[1].Timestamp != base.Add(2*time.Minute).UnixMilli() {
+		t.Fatalf("unexpected firefox event: %+v", events[1])
+	}
+}
+
+func TestDiscoverChromiumProfiles(t *testing.T) {
+	userData := t.TempDir()
+	for _, p := range []string{"Default", "Profile 1", "System Profile", "Guest Profile"} {
+		writeTestFile(t, filepath.Join(userData, p, "History"), "")
+	}
+	if err := os.MkdirAll(filepath.Join(userData, "Profile 2"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(userData, "Local State"),
+		`{"profile":{"info_cache":{"Default":{"name":"Personal"},"Profile 1":{"name":"Work"}}}}`)
+
+	profiles := discoverChromiumProfiles([]chromiumInstall{{BrowserEdge, userData}, {BrowserBrave, filepath.Join(userData, "missing")}})
+	if len(profiles) != 2 {
+		t.Fatalf("expected Default and Profile 1, got %+v", profiles)
+	}
+	if profiles[0].Key() != "edge/Default" || profiles[0].Name != "Personal" {
+		t.Fatalf("unexpected first profile: %+v", profiles[0])
+	}
+	if profiles[1].Key() != "edge/Profile 1" || profiles[1].Name != "Work" {
+		t.Fatalf("unexpected second profile: %+v", profiles[1])
+	}
+}
+
+func TestBrowserSelected(t *testing.T) {
+	cases := []struct {
+		selected []string
+		browser  string
+		want     bool
+	}{
+		{nil, BrowserArc, true},
+		{[]string{BrowserFamilyChromium}, BrowserBrave, true},
+		{[]string{BrowserFamilyChromium}, BrowserFirefox, false},
+		{[]string{"Edge"}, BrowserEdge, true},
+		{[]string{BrowserChrome}, BrowserEdge, false},
+	}
+	for _, c := range cases {
+		if got := browserSelected(c.selected, c.browser); got != c.want {
+			t.Errorf("browserSelected(%v, %s) = %v, want %v", c.selected, c.browser, got, c.want)
+		}
+	}
+}
+
+func TestBrowserCollector_skipsDisabledProfiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFirefoxPlaces(t, filepath.Join(dir, "personal", "places.sqlite"))
+	writeFirefoxPlaces(t, filepath.Join(dir, "work", "places.sqlite"))
+	writeTestFile(t, filepath.Join(dir, "profiles.ini"),
+		"[Profile0]\nName=personal\nPath=personal\n\n[Profile1]\nName=work\nPath=work\n")
+
+	c, err := NewBrowserCollector(&BrowserCollectorConfig{
+		Browsers:           []string{BrowserFirefox},
+		FirefoxProfilesDir: dir,
+		DisabledProfiles:   []string{"firefox/personal"},
+	})
+	if err != nil {
+		t.Fatalf("NewBrowserCollector: %v", err)
+	}
+	if len(c.sources) != 1 || c.sources[0].profile.Key() != "firefox/work" {
+		t.Fatalf("expected only the work profile, got %+v", c.Stats().HistoryPaths)
+	}
+
+	if _, err := NewBrowserCollector(&BrowserCollectorConfig{
+		Browsers:           []string{BrowserFirefox},
+		FirefoxProfilesDir: dir,
+		DisabledProfiles:   []string{"firefox/personal", "firefox/work"},
+	}); err == nil {
+		t.Fatalf("expected error when every profile is disabled")
+	}
+}
+
+func TestBrowserCollector_pagesFromPersistedCursor(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Default", "History")
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	stmts := []string{
+		`CREATE TABLE urls (id INTEGER PRIMARY KEY, url LONGVARCHAR, title LONGVARCHAR)`,
+		chromeVisitsTable,
+		`INSERT INTO urls VALUES (1, 'https://go.dev/', 'Go')`,
+	}
+	// 超过一页的积压；访问 ID 与时间无关（同步写入的旧访问）
+	total := browserPageSize + 20
+	for i := 1; i <= total; i++ {
+		ts := base.Add(time.Duration(total-i)*time.Second).UnixMicro() + webkitEpochOffset
+		stmts = append(stmts, `INSERT INTO visits (id, url, visit_time) VALUES (`+strconv.Itoa(i)+`, 1, `+itoa(ts)+`)`)
+	}
+	writeSQLiteFixture(t, path, stmts...)
+
+	c, err := NewBrowserCollector(&BrowserCollectorConfig{
+		HistoryPath: path,
+		Cursors:     map[string]VisitCursor{"chrome/Default": {VisitID: 10, Timestamp: base.UnixMilli()}},
+		Since:       base.Add(time.Hour).UnixMilli(), // 有游标时不使用
+	})
+	if err != nil {
+		t.Fatalf("NewBrowserCollector: %v", err)
+	}
+	done := make(chan []*schema.BrowserEvent)
+	go func() {
+		var got []*schema.BrowserEvent
+		for e := range c.eventChan {
+			got = append(got, e)
+			if len(got) == total-10 {
+				break
+			}
+		}
+		done <- got
+	}()
+	src := c.sources[0]
+	c.collectHistory(context.Background(), src)
+	got := <-done
+	if got[0].VisitID != 11 || got[len(got)-1].VisitID != int64(total) || src.lastID != int64(total) {
+		t.Fatalf("expected visits 11..%d, got %d..%d (cursor %d)", total, got[0].VisitID, got[len(got)-1].VisitID, src.lastID)
+	}
+
+	// 历史被清空后 ID 从头分配：按已读到的最新时间继续
+	writeSQLiteFixture(t, path,
+		`DELETE FROM visits`,
+		`INSERT INTO visits (id, url, visit_time) VALUES (1, 1, `+itoa(base.Add(-time.Minute).UnixMicro()+webkitEpochOffset)+`)`,
+		`INSERT INTO visits (id, url, visit_time) VALUES (2, 1, `+itoa(base.Add(time.Hour).UnixMicro()+webkitEpochOffset)+`)`,
+	)
+	c.collectHistory(context.Background(), src)
+	events := drainBrowserEvents(c)
+	if len(events) != 1 || events[0].VisitID != 2 {
+		t.Fatalf("expected only the visit after the last seen time, got %+v", events)
+	}
+}
+
+func itoa(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+func TestBrowserCollector_chromeTransitionsAndDurations(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Default", "History")
+	base := time.Now().Add(-10 * time.Minute).Truncate(time.Second) // 回查只针对近期访问
+	at := func(d time.Duration) string { return itoa(base.Add(d).UnixMicro() + webkitEpochOffset) }
+	writeSQLiteFixture(t, path,
+		`CREATE TABLE urls (id INTEGER PRIMARY KEY, url LONGVARCHAR, title LONGVARCHAR)`,
+		chromeVisitsTable,
+		`INSERT INTO urls VALUES (1, 'https://t.co/x', ''), (2, 'https://go.dev/blog/', 'Blog'), (3, 'https://go.dev/doc/', 'Docs')`,
+		// 1 -> 2 为重定向链（1 只是途经页），2 已结束，3 仍在浏览
+		`INSERT INTO visits VALUES (1, 1, `+at(time.Minute)+`, 0, `+itoa(chromeTransitionChainFrom)+`, 0)`,
+		`INSERT INTO visits VALUES (2, 2, `+at(time.Minute)+`, 1, `+itoa(chromeTransitionChainEnd|0x80000000)+`, 95400000)`,
+		`INSERT INTO visits VALUES (3, 3, `+at(3*time.Minute)+`, 2, `+itoa(chromeTransitionChainFrom|chromeTransitionChainEnd)+`, 0)`,
+	)
+
+	c, err := NewBrowserCollector(&BrowserCollectorConfig{HistoryPath: path})
+	if err != nil {
+		t.Fatalf("NewBrowserCollector: %v", err)
+	}
+	src := c.sources[0]
+	src.resume(VisitCursor{}, base.UnixMilli())
+	c.collectHistory(context.Background(), src)
+
+	events := drainBrowserEvents(c)
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	if events[0].Transition != schema.BrowserTransitionRedirect || events[0].Duration != 0 {
+		t.Fatalf("redirect hop should be marked: %+v", events[0])
+	}
+	if events[1].Transition != schema.BrowserTransitionLink || events[1].FromVisitID != 1 || events[1].Duration != 95 {
+		t.Fatalf("unexpected landing visit: %+v", events[1])
+	}
+	if events[2].FromVisitID != 2 || events[2].Duration != 0 || len(src.pending) != 1 {
+		t.Fatalf("open visit should wait for its duration: %+v pending=%v", events[2], src.pending)
+	}
+
+	// 离开页面后 Chrome 补写 visit_duration
+	writeSQLiteFixture(t, path, `UPDATE visits SET visit_duration = 1800000000 WHERE id = 3`)
+	c.collectHistory(context.Background(), src)
+	select {
+	case d := <-c.Durations():
+		if d.VisitID != 3 || d.Duration != 1800 || d.Browser != BrowserChrome || d.Profile != "Default" {
+			t.Fatalf("unexpected duration update: %+v", d)
+		}
+	default:
+		t.Fatal("expected a duration update")
+	}
+	if len(src.pending) != 0 {
+		t.Fatalf("resolved visit should leave pending, got %v", src.pending)
+	}
+}
+
+func TestFirefoxTransition_marksRedirectSource(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	writeFirefoxPlaces(t, filepath.Join(dir, "p", "places.sqlite"),
+		`INSERT INTO moz_places VALUES (1, 'https://bit.ly/a', ''), (2, 'https://go.dev/', 'Go')`,
+		`INSERT INTO moz_historyvisits VALUES (1, 0, 1, `+itoa(base.Add(time.Minute).UnixMicro())+`, 2)`,
+		`INSERT INTO moz_historyvisits VALUES (2, 1, 2, `+itoa(base.Add(time.Minute).UnixMicro())+`, 6)`,
+	)
+	writeTestFile(t, filepath.Join(dir, "profiles.ini"), "[Profile0]\nName=default\nIsRelative=1\nPath=p\n")
+
+	c, err := NewBrowserCollector(&BrowserCollectorConfig{Browsers: []string{BrowserFirefox}, FirefoxProfilesDir: dir})
+	if err != nil {
+		t.Fatalf("NewBrowserCollector: %v", err)
+	}
+	src := c.sources[0]
+	src.resume(VisitCursor{}, base.UnixMilli())
+	c.collectHistory(context.Background(), src)
+
+	events := drainBrowserEvents(c)
+	if len(events) != 2 || events[0].Transition != schema.BrowserTransitionRedirect {
+		t.Fatalf("typed URL that redirected should be a redirect hop: %+v", events)
+	}
+	if events[1].Transition != schema.BrowserTransitionLink || events[1].FromVisitID != 1 {
+		t.Fatalf("unexpected landing visit: %+v", events[1])
+	}
+	if len(src.pending) != 0 {
+		t.Fatal("firefox has no visit_duration to wait for")
+	}
+}
@@ -0,0 +1,160 @@
+package collector
+
+import (
+	"bufio"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+// firefoxHistoryQuery places.sqlite 访问记录；visit_date 为 PRTime（Unix 微秒）。
+// Firefox 不记录停留时长；重定向类型标在落地页上，途经页通过被谁 from_visit 引用来识别
+const firefoxHistoryQuery = `
+	SELECT moz_historyvisits.id, moz_places.url, COALESCE(moz_places.title, ''), moz_historyvisits.visit_date,
+		COALESCE(moz_historyvisits.from_visit, 0), COALESCE(moz_historyvisits.visit_type, 0), 0,
+		EXISTS (SELECT 1 FROM moz_historyvisits AS r WHERE r.from_visit = moz_historyvisits.id AND r.visit_type IN (5, 6))
+	FROM moz_historyvisits
+	JOIN moz_places ON moz_historyvisits.place_id = moz_places.id
+	WHERE moz_historyvisits.id > ? AND moz_historyvisits.visit_date > ?
+	ORDER BY moz_historyvisits.id ASC
+	LIMIT ?
+`
+
+const firefoxMaxVisitQuery = `SELECT COALESCE(MAX(id), 0) FROM moz_historyvisits`
+
+// firefoxTransition visit_type：1 链接、2 输入、3 书签、4 嵌入、5/6 经重定向到达、7 下载、8 框架内链接、9 重新加载
+func firefoxTransition(visitType int64, redirectSource bool) string {
+	if redirectSource {
+		return schema.BrowserTransitionRedirect
+	}
+	switch visitType {
+	case 1, 5, 6:
+		return schema.BrowserTransitionLink
+	case 2:
+		return schema.BrowserTransitionTyped
+	case 3:
+		return schema.BrowserTransitionBookmark
+	case 4, 8:
+		return schema.BrowserTransitionSubframe
+	case 9:
+		return schema.BrowserTransitionReload
+	default:
+		return schema.BrowserTransitionOther
+	}
+}
+
+// firefoxProfile profiles.ini 中的一个 profile
+type firefoxProfile struct {
+	Name       string
+	Dir        string
+	IsDefault  bool
+	PlacesPath string
+}
+
+// discoverFirefoxProfiles 在 Firefox 数据目录（含 profiles.ini）下枚举有 places.sqlite 的 profile；
+// dir 为空时按平台默认位置查找
+func discoverFirefoxProfiles(dir string) []firefoxProfile {
+	dirs := []string{dir}
+	if dir == "" {
+		dirs = firefoxDirCandidates()
+	}
+	var out []firefoxProfile
+	seen := make(map[string]bool)
+	for _, d := range dirs {
+		profiles, err := parseProfilesINI(d)
+		if err != nil {
+			continue
+		}
+		// 默认 profile 排在前面
+		sort.SliceStable(profiles, func(i, j int) bool { return profiles[i].IsDefault && !profiles[j].IsDefault })
+		for _, p := range profiles {
+			if seen[p.PlacesPath] {
+				continue
+			}
+			if _, err := os.Stat(p.PlacesPath); err != nil {
+				continue
+			}
+			seen[p.PlacesPath] = true
+			slog.Debug("发现 Firefox profile", "name", p.Name, "path", p.PlacesPath)
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// parseProfilesINI 解析 profiles.ini 的 [ProfileN] 段；Path 可为相对（IsRelative=1）或绝对路径。
+// [Install*] 段的 Default 是当前安装实际使用的 profile，优先于 [ProfileN] 的 Default=1。
+func parseProfilesINI(dir string) ([]firefoxProfile, error) {
+	f, err := os.Open(filepath.Join(dir, "profiles.ini"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		profiles       []firefoxProfile
+		installDefault string
+		section        string
+		cur            map[string]string
+	)
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		switch {
+		case strings.HasPrefix(section, "Profile"):
+			path := cur["Path"]
+			if path == "" {
+				break
+			}
+			profileDir := filepath.FromSlash(path)
+			if cur["IsRelative"] != "0" {
+				profileDir = filepath.Join(dir, profileDir)
+			}
+			profiles = append(profiles, firefoxProfile{
+				Name:       cur["Name"],
+				Dir:        profileDir,
+				IsDefault:  cur["Default"] == "1",
+				PlacesPath: filepath.Join(profileDir, "places.sqlite"),
+			})
+		case strings.HasPrefix(section, "Install"):
+			if installDefault == "" {
+				installDefault = cur["Default"]
+			}
+		}
+	}
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			flush()
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			cur = make(map[string]string)
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok && cur != nil {
+			cur[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	flush()
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	if installDefault != "" {
+		for i := range profiles {
+			rel, err := filepath.Rel(dir, profiles[i].Dir)
+			profiles[i].IsDefault = profiles[i].Dir == filepath.FromSlash(installDefault) ||
+				(err == nil && filepath.ToSlash(rel) == installDefault)
+		}
+	}
+	return profiles, nil
+}
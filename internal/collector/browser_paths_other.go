@@ -0,0 +1,58 @@
+//go:build !windows
+
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// chromiumInstallCandidates Linux/macOS 下 Chromium 系浏览器的 User Data 目录
+func chromiumInstallCandidates() []chromiumInstall {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return nil
+	}
+	if runtime.GOOS == "darwin" {
+		base := filepath.Join(home, "Library", "Application Support")
+		return []chromiumInstall{
+			{BrowserChrome, filepath.Join(base, "Google", "Chrome")},
+			{BrowserChromeBeta, filepath.Join(base, "Google", "Chrome Beta")},
+			{BrowserChromium, filepath.Join(base, "Chromium")},
+			{BrowserEdge, filepath.Join(base, "Microsoft Edge")},
+			{BrowserBrave, filepath.Join(base, "BraveSoftware", "Brave-Browser")},
+			{BrowserVivaldi, filepath.Join(base, "Vivaldi")},
+			{BrowserArc, filepath.Join(base, "Arc", "User Data")},
+		}
+	}
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		base = filepath.Join(home, ".config")
+	}
+	return []chromiumInstall{
+		{BrowserChrome, filepath.Join(base, "google-chrome")},
+		{BrowserChromeBeta, filepath.Join(base, "google-chrome-beta")},
+		{BrowserChromium, filepath.Join(base, "chromium")},
+		{BrowserChromium, filepath.Join(home, "snap", "chromium", "common", "chromium")},
+		{BrowserEdge, filepath.Join(base, "microsoft-edge")},
+		{BrowserBrave, filepath.Join(base, "BraveSoftware", "Brave-Browser")},
+		{BrowserVivaldi, filepath.Join(base, "vivaldi")},
+	}
+}
+
+// firefoxDirCandidates Linux/macOS 下 Firefox 数据目录（含 profiles.ini）
+func firefoxDirCandidates() []string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return nil
+	}
+	if runtime.GOOS == "darwin" {
+		return []string{filepath.Join(home, "Library", "Application Support", "Firefox")}
+	}
+	return []string{
+		filepath.Join(home, ".mozilla", "firefox"),
+		filepath.Join(home, "snap", "firefox", "common", ".mozilla", "firefox"),
+		filepath.Join(home, ".var", "app", "org.mozilla.firefox", ".mozilla", "firefox"),
+	}
+}
@@ -0,0 +1,37 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// chromiumInstallCandidates Windows 下 Chromium 系浏览器的 User Data 目录
+func chromiumInstallCandidates() []chromiumInstall {
+	localAppData := os.Getenv("LOCALAPPDATA")
+	if localAppData == "" {
+		return nil
+	}
+	installs := []chromiumInstall{
+		{BrowserChrome, filepath.Join(localAppData, "Google", "Chrome", "User Data")},
+		{BrowserChromeBeta, filepath.Join(localAppData, "Google", "Chrome Beta", "User Data")},
+		{BrowserChromium, filepath.Join(localAppData, "Chromium", "User Data")},
+		{BrowserEdge, filepath.Join(localAppData, "Microsoft", "Edge", "User Data")},
+		{BrowserBrave, filepath.Join(localAppData, "BraveSoftware", "Brave-Browser", "User Data")},
+		{BrowserVivaldi, filepath.Join(localAppData, "Vivaldi", "User Data")},
+	}
+	// Arc 以 MSIX 包安装，包目录名带发布者哈希
+	dirs, _ := filepath.Glob(filepath.Join(localAppData, "Packages", "TheBrowserCompany.Arc_*", "LocalCache", "Local", "Arc", "User Data"))
+	for _, d := range dirs {
+		installs = append(installs, chromiumInstall{BrowserArc, d})
+	}
+	return installs
+}
+
+// firefoxDirCandidates Windows 下 Firefox 数据目录（含 profiles.ini）
+func firefoxDirCandidates() []string {
+	appData := os.Getenv("APPDATA")
+	if appData == "" {
+		return nil
+	}
+	return []string{filepath.Join(appData, "Mozilla", "Firefox")}
+}
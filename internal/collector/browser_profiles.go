@@ -0,0 +1,169 @@
+package collector
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 浏览器标识（BrowserEvent.Browser）
+const (
+	BrowserChrome     = "chrome"
+	BrowserChromeBeta = "chrome-beta"
+	BrowserChromium   = "chromium"
+	BrowserEdge       = "edge"
+	BrowserBrave      = "brave"
+	BrowserVivaldi    = "vivaldi"
+	BrowserArc        = "arc"
+	BrowserFirefox    = "firefox"
+)
+
+// BrowserFamilyChromium 配置中选择全部 Chromium 系浏览器
+const BrowserFamilyChromium = "chromium-family"
+
+var chromiumBrowsers = []string{BrowserChrome, BrowserChromeBeta, BrowserChromium, BrowserEdge, BrowserBrave, BrowserVivaldi, BrowserArc}
+
+// IsKnownBrowser 是否为支持的浏览器标识或 Chromium 系总称
+func IsKnownBrowser(b string) bool {
+	return b == BrowserFirefox || b == BrowserFamilyChromium || isChromium(b)
+}
+
+func isChromium(b string) bool {
+	for _, c := range chromiumBrowsers {
+		if b == c {
+			return true
+		}
+	}
+	return false
+}
+
+// browserSelected selected 为空时全部选中
+func browserSelected(selected []string, browser string) bool {
+	if len(selected) == 0 {
+		return true
+	}
+	for _, s := range selected {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == browser || (s == BrowserFamilyChromium && isChromium(browser)) {
+			return true
+		}
+	}
+	return false
+}
+
+// chromiumInstall 一个 Chromium 系浏览器的 User Data 目录
+type chromiumInstall struct {
+	browser string
+	dir     string
+}
+
+// BrowserProfile 一个浏览器 profile 的历史数据库
+type BrowserProfile struct {
+	Browser     string `json:"browser"`
+	Profile     string `json:"profile"` // profile 目录名（Default、Profile 1、xxxx.default-release）
+	Name        string `json:"name"`    // 浏览器中显示的名称
+	HistoryPath string `json:"history_path"`
+}
+
+// Key 启用/禁用列表中的标识：<browser>/<profile>
+func (p BrowserProfile) Key() string {
+	return p.Browser + "/" + p.Profile
+}
+
+// BrowserDiscoveryConfig profile 发现参数
+type BrowserDiscoveryConfig struct {
+	Browsers           []string // 为空时全部
+	HistoryPath        string   // 显式指定的 Chromium History 文件（替代 Chromium 系自动发现）
+	FirefoxProfilesDir string   // Firefox 数据目录（为空则自动检测）
+}
+
+// DiscoverBrowserProfiles 枚举所有选中浏览器中存在历史数据库的 profile
+func DiscoverBrowserProfiles(cfg BrowserDiscoveryConfig) []BrowserProfile {
+	var out []BrowserProfile
+	if cfg.HistoryPath != "" {
+		if _, err := os.Stat(cfg.HistoryPath); err == nil {
+			out = append(out, BrowserProfile{
+				Browser:     BrowserChrome,
+				Profile:     filepath.Base(filepath.Dir(cfg.HistoryPath)),
+				HistoryPath: cfg.HistoryPath,
+			})
+		}
+	} else {
+		var installs []chromiumInstall
+		for _, in := range chromiumInstallCandidates() {
+			if browserSelected(cfg.Browsers, in.browser) {
+				installs = append(installs, in)
+			}
+		}
+		out = append(out, discoverChromiumProfiles(installs)...)
+	}
+
+	if browserSelected(cfg.Browsers, BrowserFirefox) {
+		for _, p := range discoverFirefoxProfiles(cfg.FirefoxProfilesDir) {
+			out = append(out, BrowserProfile{
+				Browser:     BrowserFirefox,
+				Profile:     filepath.Base(p.Dir),
+				Name:        p.Name,
+				HistoryPath: p.PlacesPath,
+			})
+		}
+	}
+	return out
+}
+
+// discoverChromiumProfiles 枚举 User Data 下含 History 的 profile 目录（Default、Profile N 等）
+func discoverChromiumProfiles(installs []chromiumInstall) []BrowserProfile {
+	var out []BrowserProfile
+	seen := make(map[string]bool)
+	for _, in := range installs {
+		entries, err := os.ReadDir(in.dir)
+		if err != nil {
+			continue
+		}
+		names := chromiumProfileNames(in.dir)
+		for _, e := range entries {
+			if !e.IsDir() || e.Name() == "System Profile" || e.Name() == "Guest Profile" {
+				continue
+			}
+			history := filepath.Join(in.dir, e.Name(), "History")
+			if seen[history] {
+				continue
+			}
+			if info, err := os.Stat(history); err != nil || info.IsDir() {
+				continue
+			}
+			seen[history] = true
+			out = append(out, BrowserProfile{
+				Browser:     in.browser,
+				Profile:     e.Name(),
+				Name:        names[e.Name()],
+				HistoryPath: history,
+			})
+		}
+	}
+	return out
+}
+
+// chromiumProfileNames 从 Local State 读取 profile 目录 -> 显示名称
+func chromiumProfileNames(userDataDir string) map[string]string {
+	b, err := os.ReadFile(filepath.Join(userDataDir, "Local State"))
+	if err != nil {
+		return nil
+	}
+	var state struct {
+		Profile struct {
+			InfoCache map[string]struct {
+				Name string `json:"name"`
+			} `json:"info_cache"`
+		} `json:"profile"`
+	}
+	if json.Unmarshal(b, &state) != nil {
+		return nil
+	}
+	names := make(map[string]string, len(state.Profile.InfoCache))
+	for dir, info := range state.Profile.InfoCache {
+		names[dir] = info.Name
+	}
+	return names
+}
@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+// chromeKeywordTermsQuery Chrome 记录的地址栏搜索词（含自定义搜索引擎），按与访问页相同的分页范围读取
+const chromeKeywordTermsQuery = `
+	SELECT visits.id, keyword_search_terms.term
+	FROM visits
+	JOIN keyword_search_terms ON keyword_search_terms.url_id = visits.url
+	WHERE visits.id > ? AND visits.visit_time > ?
+	ORDER BY visits.id ASC
+	LIMIT ?
+`
+
+// SearchEngineKeyword 来自浏览器 keyword_search_terms、无法从 URL 识别的搜索引擎
+const SearchEngineKeyword = "keyword"
+
+// SearchQuery 从浏览记录中提取的搜索词（原文，由服务层脱敏后入库）
+type SearchQuery struct {
+	Browser   string
+	Profile   string
+	VisitID   int64
+	Timestamp int64 // 毫秒
+	Term      string
+	Engine    string
+	Domain    string
+}
+
+// searchEngine 搜索结果页的识别规则
+type searchEngine struct {
+	name   string
+	match  func(host, path string) bool
+	params []string
+}
+
+var searchEngines = []searchEngine{
+	{"google", func(h, p string) bool { return googleHost(h) && p == "/search" }, []string{"q"}},
+	{"bing", func(h, p string) bool { return hostIs(h, "bing.com") && p == "/search" }, []string{"q"}},
+	{"duckduckgo", func(h, p string) bool { return hostIs(h, "duckduckgo.com") }, []string{"q"}},
+	{"baidu", func(h, p string) bool { return hostIs(h, "baidu.com") && p == "/s" }, []string{"wd", "word"}},
+	{"github", func(h, p string) bool { return hostIs(h, "github.com") && p == "/search" }, []string{"q"}},
+	{"stackoverflow", func(h, p string) bool { return hostIs(h, "stackoverflow.com") && p == "/search" }, []string{"q"}},
+}
+
+// ExtractSearchQuery 从搜索结果页 URL 提取搜索词；不是已知搜索引擎时返回空
+func ExtractSearchQuery(rawURL string) (term, engine string) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", ""
+	}
+	host := strings.ToLower(u.Hostname())
+	path := strings.TrimSuffix(u.Path, "/")
+	if path == "" {
+		path = "/"
+	}
+	for _, e := range searchEngines {
+		if !e.match(host, path) {
+			continue
+		}
+		q := u.Query()
+		for _, p := range e.params {
+			if v := strings.TrimSpace(q.Get(p)); v != "" {
+				return v, e.name
+			}
+		}
+		return "", ""
+	}
+	return "", ""
+}
+
+func hostIs(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// googleHost google.com、google.co.jp、www.google.de 等
+func googleHost(host string) bool {
+	host = strings.TrimPrefix(host, "www.")
+	return strings.HasPrefix(host, "google.")
+}
+
+// keywordTerms 读取当前页访问对应的 keyword_search_terms（visit id -> term）；表不存在时静默跳过
+func (c *BrowserCollector) keywordTerms(ctx context.Context, db *sql.DB, src *historySource) map[int64]string {
+	if !c.searchTerms || src.profile.Browser == BrowserFirefox {
+		return nil
+	}
+	rows, err := db.QueryContext(ctx, chromeKeywordTermsQuery, src.lastID, src.minVisit, browserPageSize)
+	if err != nil {
+		slog.Debug("查询地址栏搜索词失败", "path", src.path, "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	out := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var term string
+		if err := rows.Scan(&id, &term); err == nil && strings.TrimSpace(term) != "" {
+			out[id] = strings.TrimSpace(term)
+		}
+	}
+	return out
+}
+
+// searchQuery 从访问中提取搜索词：优先 URL 参数，其次浏览器记录的关键字搜索
+func (c *BrowserCollector) searchQuery(src *historySource, visitID, at int64, rawURL, domain string, keywords map[int64]string) *SearchQuery {
+	if !c.searchTerms {
+		return nil
+	}
+	term, engine := ExtractSearchQuery(rawURL)
+	if term == "" {
+		if kw := keywords[visitID]; kw != "" {
+			term, engine = kw, SearchEngineKeyword
+		}
+	}
+	if term == "" {
+		return nil
+	}
+	return &SearchQuery{
+		Browser:   src.profile.Browser,
+		Profile:   src.profile.Profile,
+		VisitID:   visitID,
+		Timestamp: at,
+		Term:      term,
+		Engine:    engine,
+		Domain:    domain,
+	}
+}
@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExtractSearchQuery(t *testing.T) {
+	cases := []struct {
+		url, term, engine string
+	}{
+		{"https://www.google.com/search?q=go+generics&hl=en", "go generics", "google"},
+		{"https://www.google.co.jp/search?q=%E6%B3%9B%E5%9E%8B", "泛型", "google"},
+		{"https://cn.bing.com/search?q=gorm+upsert", "gorm upsert", "bing"},
+		{"https://duckduckgo.com/?q=sqlite+wal&ia=web", "sqlite wal", "duckduckgo"},
+		{"https://www.baidu.com/s?ie=utf-8&wd=goroutine+%E6%B3%84%E6%BC%8F", "goroutine 泄漏", "baidu"},
+		{"https://github.com/search?q=fsnotify+debounce&type=code", "fsnotify debounce", "github"},
+		{"https://stackoverflow.com/search?q=context+deadline", "context deadline", "stackoverflow"},
+		{"https://www.google.com/maps?q=cafe", "", ""},
+		{"https://github.com/golang/go/issues?q=is%3Aopen", "", ""},
+		{"https://www.google.com/search?q=+", "", ""},
+		{"https://example.com/search?q=x", "", ""},
+		{"file:///search?q=x", "", ""},
+	}
+	for _, tc := range cases {
+		term, engine := ExtractSearchQuery(tc.url)
+		if term != tc.term || engine != tc.engine {
+			t.Errorf("ExtractSearchQuery(%q) = (%q, %q), want (%q, %q)", tc.url, term, engine, tc.term, tc.engine)
+		}
+	}
+}
+
+func TestBrowserCollector_searchTerms(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Default", "History")
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) string { return itoa(base.Add(d).UnixMicro() + webkitEpochOffset) }
+	fixture := []string{
+		`CREATE TABLE urls (id INTEGER PRIMARY KEY, url LONGVARCHAR, title LONGVARCHAR)`,
+		chromeVisitsTable,
+		`CREATE TABLE keyword_search_terms (keyword_id INTEGER, url_id INTEGER, term LONGVARCHAR, normalized_term LONGVARCHAR)`,
+		`INSERT INTO urls VALUES (1, 'https://www.google.com/search?q=gorm+upsert', 'gorm upsert - Google'),
+			(2, 'https://gorm.io/docs/create.html', 'Create'), (3, 'https://search.corp.local/find?k=ci', 'Find')`,
+		`INSERT INTO keyword_search_terms VALUES (7, 3, 'ci flaky test', 'ci flaky test')`,
+		`INSERT INTO visits (id, url, visit_time) VALUES (1, 1, ` + at(time.Minute) + `), (2, 2, ` + at(2*time.Minute) + `), (3, 3, ` + at(3*time.Minute) + `)`,
+	}
+	writeSQLiteFixture(t, path, fixture...)
+
+	collect := func(enabled bool) []*SearchQuery {
+		c, err := NewBrowserCollector(&BrowserCollectorConfig{HistoryPath: path, SearchTerms: enabled})
+		if err != nil {
+			t.Fatalf("NewBrowserCollector: %v", err)
+		}
+		src := c.sources[0]
+		src.resume(VisitCursor{}, base.UnixMilli())
+		c.collectHistory(context.Background(), src)
+		if got := len(drainBrowserEvents(c)); got != 3 {
+			t.Fatalf("expected 3 events, got %d", got)
+		}
+		var out []*SearchQuery
+		for {
+			select {
+			case q := <-c.SearchQueries():
+				out = append(out, q)
+			default:
+				return out
+			}
+		}
+	}
+
+	if got := collect(false); len(got) != 0 {
+		t.Fatalf("search terms are opt-in, got %+v", got)
+	}
+	got := collect(true)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 search queries, got %+v", got)
+	}
+	if got[0].VisitID != 1 || got[0].Term != "gorm upsert" || got[0].Engine != "google" || got[0].Domain != "www.google.com" {
+		t.Fatalf("unexpected url query: %+v", got[0])
+	}
+	if got[1].VisitID != 3 || got[1].Term != "ci flaky test" || got[1].Engine != SearchEngineKeyword || got[1].Profile != "Default" {
+		t.Fatalf("unexpected keyword query: %+v", got[1])
+	}
+	if got[1].Timestamp != base.Add(3*time.Minute).UnixMilli() {
+		t.Fatalf("unexpected timestamp: %d", got[1].Timestamp)
+	}
+}
@@ -1,5 +1,3 @@
-//go:build windows
-
 package collector
 
 import (
@@ -32,8 +30,18 @@ type Collector interface {
 	Events() <-chan *schema.Event
 }
 
-// WindowCollector Windows 窗口采集器
+// ForegroundSource 前台窗口数据源（Win32 / X11 等平台实现；测试可注入 fake）
+type ForegroundSource interface {
+	// ForegroundWindow 返回当前前台窗口；无前台窗口或应忽略的系统窗口返回 ErrNoForegroundWindow
+	ForegroundWindow() (*WindowInfo, error)
+	// IdleDuration 返回系统空闲时长（距最后一次用户输入）；不支持时返回 error，跳过空闲检测
+	IdleDuration() (time.Duration, error)
+}
+
+// WindowCollector 前台窗口采集器（切段状态机与平台无关，窗口数据来自 ForegroundSource）
 type WindowCollector struct {
+	source        ForegroundSource
+	now           func() time.Time
 	pollInterval  time.Duration // 轮询间隔
 	minDuration   time.Duration // 最小记录时长
 	maxDuration   time.Duration // 单段最大时长（用于持续窗口的心跳落库）
@@ -72,8 +80,13 @@ func DefaultCollectorConfig() *CollectorConfig {
 	}
 }
 
-// NewWindowCollector 创建窗口采集器
+// NewWindowCollector 创建窗口采集器（使用当前平台的前台窗口数据源）
 func NewWindowCollector(cfg *CollectorConfig) *WindowCollector {
+	return NewWindowCollectorWithSource(cfg, DefaultForegroundSource(), nil)
+}
+
+// NewWindowCollectorWithSource 使用指定数据源与时钟创建窗口采集器（now 为 nil 时使用 time.Now）
+func NewWindowCollectorWithSource(cfg *CollectorConfig, source ForegroundSource, now func() time.Time) *WindowCollector {
 	if cfg == nil {
 		cfg = DefaultCollectorConfig()
 	}
@@ -83,8 +96,13 @@ func NewWindowCollector(cfg *CollectorConfig) *WindowCollector {
 	if cfg.IdleThresholdSec <= 0 {
 		cfg.IdleThresholdSec = 6 * 60
 	}
+	if now == nil {
+		now = time.Now
+	}
 
 	return &WindowCollector{
+		source:        source,
+		now:           now,
 		pollInterval:  time.Duration(cfg.PollIntervalMs) * time.Millisecond,
 		minDuration:   time.Duration(cfg.MinDurationSec) * time.Second,
 		maxDuration:   time.Duration(cfg.MaxDurationSec) * time.Second,
@@ -124,7 +142,7 @@ func (c *WindowCollector) Stop() error {
 
 		// 记录最后一个窗口的时长
 		if c.lastWindow != nil && !c.idleMode {
-			c.emitEvent(c.lastWindow, time.Since(c.currentStart))
+			c.emitEvent(c.lastWindow, c.now().Sub(c.currentStart))
 		}
 
 		slog.Info("窗口采集器已停止")
@@ -157,11 +175,11 @@ func (c *WindowCollector) pollLoop(ctx context.Context) {
 
 // poll 执行一次轮询
 func (c *WindowCollector) poll() {
-	now := time.Now()
+	now := c.now()
 
 	// 系统空闲检测：当用户长时间无输入时，不应把 idle 时间记到窗口时长里。
 	if c.idleThreshold > 0 {
-		if idleDur, err := GetIdleDuration(); err == nil && idleDur >= c.idleThreshold {
+		if idleDur, err := c.source.IdleDuration(); err == nil && idleDur >= c.idleThreshold {
 			if !c.idleMode && c.lastWindow != nil && !c.currentStart.IsZero() {
 				lastActiveAt := now.Add(-idleDur)
 				if lastActiveAt.Before(c.currentStart) {
@@ -185,9 +203,9 @@ func (c *WindowCollector) poll() {
 		c.currentStart = time.Time{}
 	}
 
-	current, err := GetForegroundWindowInfo()
+	// 无法获取前台窗口/暂时不可用/系统窗口：应当结束上一段并形成空洞，而不是让上一窗口继续累加。
+	current, err := c.source.ForegroundWindow()
 	if err != nil {
-		// 无法获取前台窗口/暂时不可用：应当结束上一段并形成空洞，而不是让上一窗口继续累加。
 		if !errors.Is(err, ErrNoForegroundWindow) {
 			slog.Debug("获取窗口信息失败", "error", err)
 		}
@@ -195,12 +213,6 @@ func (c *WindowCollector) poll() {
 		return
 	}
 
-	// 忽略系统窗口：也需要切段/暂停，避免上一个窗口持续累计
-	if current.IsSystemWindow() {
-		c.pauseTracking(now)
-		return
-	}
-
 	// 首次记录
 	if c.lastWindow == nil {
 		c.lastWindow = current
@@ -268,7 +280,7 @@ func (c *WindowCollector) pauseTracking(now time.Time) {
 func (c *WindowCollector) emitEvent(w *WindowInfo, duration time.Duration) {
 	event := &schema.Event{
 		Timestamp: c.currentStart.UnixMilli(),
-		Source:    "window",
+		Source:    schema.EventSourceWindow,
 		AppName:   w.AppName,
 		Title:     w.Title,
 		Duration:  durationToSecondsRounded(duration),
@@ -281,7 +293,7 @@ func (c *WindowCollector) emitEvent(w *WindowInfo, duration time.Duration) {
 
 	select {
 	case c.eventChan <- event:
-		c.lastEmitAt.Store(time.Now().UnixMilli())
+		c.lastEmitAt.Store(c.now().UnixMilli())
 		slog.Debug("事件已发送",
 			"app", w.AppName,
 			"title", w.Title,
@@ -0,0 +1,216 @@
+package collector
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+// fakeForegroundSource 由测试脚本控制的前台窗口与空闲时长
+type fakeForegroundSource struct {
+	window  *WindowInfo
+	err     error
+	idle    time.Duration
+	idleErr error
+}
+
+func (f *fakeForegroundSource) ForegroundWindow() (*WindowInfo, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.window == nil {
+		return nil, ErrNoForegroundWindow
+	}
+	return f.window, nil
+}
+
+func (f *fakeForegroundSource) IdleDuration() (time.Duration, error) {
+	return f.idle, f.idleErr
+}
+
+var testEpoch = time.Unix(1_700_000_000, 0)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.t }
+func (c *fakeClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestWindowCollector(t *testing.T, bufferSize int) (*WindowCollector, *fakeForegroundSource, *fakeClock) {
+	t.Helper()
+	src := &fakeForegroundSource{}
+	clock := &fakeClock{t: testEpoch}
+	c := NewWindowCollectorWithSource(&CollectorConfig{
+		PollIntervalMs:   500,
+		MinDurationSec:   3,
+		MaxDurationSec:   60,
+		IdleThresholdSec: 6 * 60,
+		BufferSize:       bufferSize,
+	}, src, clock.Now)
+	return c, src, clock
+}
+
+func drainEvents(c *WindowCollector) []*schema.Event {
+	var out []*schema.Event
+	for {
+		select {
+		case ev := <-c.Events():
+			out = append(out, ev)
+		default:
+			return out
+		}
+	}
+}
+
+var (
+	winEditor  = &WindowInfo{HWND: 1, Title: "main.go - VS Code", ProcessID: 10, AppName: "Code.exe"}
+	winBrowser = &WindowInfo{HWND: 2, Title: "Go docs - Chrome", ProcessID: 20, AppName: "chrome.exe"}
+)
+
+func TestWindowCollector_MinDurationDropsShortSegments(t *testing.T) {
+	c, src, clock := newTestWindowCollector(t, 16)
+
+	src.window = winEditor
+	c.poll()
+	clock.Advance(2 * time.Second)
+	src.window = winBrowser
+	c.poll()
+	clock.Advance(5 * time.Second)
+	src.window = winEditor
+	c.poll()
+
+	events := drainEvents(c)
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event (short editor segment ignored), got %d", len(events))
+	}
+	ev := events[0]
+	if ev.AppName != "chrome.exe" || ev.Duration != 5 {
+		t.Fatalf("unexpected event: app=%s duration=%d", ev.AppName, ev.Duration)
+	}
+	if ev.Timestamp != testEpoch.Add(2*time.Second).UnixMilli() {
+		t.Fatalf("timestamp=%d, want segment start", ev.Timestamp)
+	}
+}
+
+func TestWindowCollector_HeartbeatEvery60s(t *testing.T) {
+	c, src, clock := newTestWindowCollector(t, 16)
+
+	src.window = winEditor
+	c.poll()
+	for i := 0; i < 150; i++ { // 150s，每秒轮询一次
+		clock.Advance(time.Second)
+		c.poll()
+	}
+
+	events := drainEvents(c)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 heartbeat events, got %d", len(events))
+	}
+	for i, ev := range events {
+		if ev.Duration != 60 {
+			t.Fatalf("event %d duration=%d, want 60", i, ev.Duration)
+		}
+		if want := testEpoch.Add(time.Duration(i*60) * time.Second).UnixMilli(); ev.Timestamp != want {
+			t.Fatalf("event %d timestamp=%d, want %d", i, ev.Timestamp, want)
+		}
+	}
+
+	// Stop 落库剩余的 30s
+	c.mu.Lock()
+	c.running = true
+	c.mu.Unlock()
+	_ = c.Stop()
+	rest := drainEvents(c)
+	if len(rest) != 1 || rest[0].Duration != 30 {
+		t.Fatalf("expected trailing 30s event on stop, got %+v", rest)
+	}
+}
+
+func TestWindowCollector_IdleCreatesHole(t *testing.T) {
+	c, src, clock := newTestWindowCollector(t, 16)
+
+	src.window = winEditor
+	c.poll()
+	clock.Advance(50 * time.Second)
+	c.poll()
+
+	// 用户离开：到达阈值时只记录离开前的活跃部分
+	clock.Advance(6 * time.Minute)
+	src.idle = 6 * time.Minute
+	c.poll()
+	if !c.idleMode {
+		t.Fatalf("expected idle mode")
+	}
+	clock.Advance(10 * time.Minute)
+	src.idle = 16 * time.Minute
+	c.poll()
+
+	events := drainEvents(c)
+	if len(events) != 1 || events[0].Duration != 50 {
+		t.Fatalf("expected single 50s active segment before idle, got %+v", events)
+	}
+
+	// 恢复：重新开始计时，不把 idle 时间补记到窗口
+	src.idle = 0
+	c.poll()
+	resumeAt := clock.Now()
+	clock.Advance(10 * time.Second)
+	src.window = winBrowser
+	c.poll()
+
+	events = drainEvents(c)
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event after resume, got %d", len(events))
+	}
+	if events[0].Timestamp != resumeAt.UnixMilli() || events[0].Duration != 10 {
+		t.Fatalf("unexpected resumed segment: ts=%d duration=%d", events[0].Timestamp, events[0].Duration)
+	}
+}
+
+func TestWindowCollector_NoForegroundWindowPausesTracking(t *testing.T) {
+	c, src, clock := newTestWindowCollector(t, 16)
+
+	src.window = winEditor
+	c.poll()
+	clock.Advance(20 * time.Second)
+	src.window = nil
+	c.poll()
+	clock.Advance(time.Minute)
+	src.err = errors.New("boom")
+	c.poll()
+	clock.Advance(time.Minute)
+	src.err = nil
+	src.window = winEditor
+	c.poll()
+
+	events := drainEvents(c)
+	if len(events) != 1 || events[0].Duration != 20 {
+		t.Fatalf("expected 20s segment before pause, got %+v", events)
+	}
+	if c.currentStart != clock.Now() {
+		t.Fatalf("expected tracking to restart after pause")
+	}
+}
+
+func TestWindowCollector_DroppedEventsCounted(t *testing.T) {
+	c, src, clock := newTestWindowCollector(t, 1)
+
+	windows := []*WindowInfo{winEditor, winBrowser}
+	for i := 0; i < 4; i++ {
+		src.window = windows[i%2]
+		c.poll()
+		clock.Advance(10 * time.Second)
+	}
+
+	stats := c.Stats()
+	if stats.BufferLen != 1 || stats.BufferCap != 1 {
+		t.Fatalf("unexpected buffer stats: %+v", stats)
+	}
+	if stats.Dropped != 2 {
+		t.Fatalf("dropped=%d, want 2", stats.Dropped)
+	}
+	if stats.LastEmitAt != testEpoch.Add(10*time.Second).UnixMilli() {
+		t.Fatalf("last_emit_at=%d, want first successful emit", stats.LastEmitAt)
+	}
+}
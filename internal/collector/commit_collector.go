@@ -0,0 +1,442 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+const (
+	// maxCommitsPerScan 单次扫描最多读取的提交数（首次扫描/大批量 rebase 时防止输出过大）
+	maxCommitsPerScan = 200
+	// maxRepoDiscoverDepth 在监控目录下查找仓库的最大深度
+	maxRepoDiscoverDepth = 3
+)
+
+// CommitCollector Git 提交采集器：轮询监控目录下各仓库的分支引用，发现新提交后读取提交信息。
+// 引用直接从 .git 读取（不启动进程），只有分支指针变化时才调用 git log。
+type CommitCollector struct {
+	watchPaths   []string
+	pollInterval time.Duration
+	lookback     time.Duration
+	eventChan    chan *schema.GitCommit
+	stopChan     chan struct{}
+	stopOnce     sync.Once
+
+	mu      sync.Mutex
+	running bool
+	repos   map[string]map[string]string // repoRoot -> 分支 -> 提交哈希（上次扫描时）
+
+	lastEmitAt atomic.Int64
+	dropped    atomic.Int64
+	scanErrors atomic.Int64
+}
+
+// CommitCollectorConfig 配置
+type CommitCollectorConfig struct {
+	WatchPaths   []string      // 与 diff.watch_paths 相同
+	PollInterval time.Duration // 引用轮询间隔
+	Lookback     time.Duration // 首次发现仓库时回溯的时间（补录 Agent 未运行期间的提交，入库按哈希去重）
+}
+
+// NewCommitCollector 创建提交采集器
+func NewCommitCollector(cfg *CommitCollectorConfig) *CommitCollector {
+	if cfg == nil {
+		cfg = &CommitCollectorConfig{}
+	}
+	poll := cfg.PollInterval
+	if poll <= 0 {
+		poll = 10 * time.Second
+	}
+	lookback := cfg.Lookback
+	if lookback <= 0 {
+		lookback = 24 * time.Hour
+	}
+	return &CommitCollector{
+		watchPaths:   append([]string(nil), cfg.WatchPaths...),
+		pollInterval: poll,
+		lookback:     lookback,
+		eventChan:    make(chan *schema.GitCommit, 256),
+		stopChan:     make(chan struct{}),
+		repos:        make(map[string]map[string]string),
+	}
+}
+
+// Start 启动采集
+func (c *CommitCollector) Start(ctx context.Context) error {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return nil
+	}
+	c.running = true
+	c.mu.Unlock()
+
+	slog.Info("Git 提交采集器启动", "watch_paths", c.watchPaths)
+	go c.pollLoop(ctx)
+	return nil
+}
+
+// Stop 停止采集
+func (c *CommitCollector) Stop() error {
+	c.stopOnce.Do(func() {
+		c.mu.Lock()
+		c.running = false
+		c.mu.Unlock()
+		close(c.stopChan)
+		slog.Info("Git 提交采集器已停止")
+	})
+	return nil
+}
+
+// Events 返回事件通道
+func (c *CommitCollector) Events() <-chan *schema.GitCommit {
+	return c.eventChan
+}
+
+func (c *CommitCollector) pollLoop(ctx context.Context) {
+	defer close(c.eventChan)
+
+	// 仓库列表每 10 轮重新发现一次（新 clone 的项目无需重启）
+	const rediscoverEvery = 10
+	repos := DiscoverGitRepos(c.watchPaths)
+	c.scanAll(ctx, repos)
+
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+	for round := 1; ; round++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.stopChan:
+			return
+		case <-ticker.C:
+			if round%rediscoverEvery == 0 {
+				repos = DiscoverGitRepos(c.watchPaths)
+			}
+			c.scanAll(ctx, repos)
+		}
+	}
+}
+
+func (c *CommitCollector) scanAll(ctx context.Context, repos []string) {
+	for _, repo := range repos {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.stopChan:
+			return
+		default:
+		}
+		commits, tips, err := c.scanRepo(ctx, repo)
+		if err != nil {
+			c.scanErrors.Add(1)
+			slog.Debug("扫描 Git 提交失败", "repo", repo, "error", err)
+			continue
+		}
+		queued := true
+		for _, commit := range commits {
+			select {
+			case c.eventChan <- commit:
+				c.lastEmitAt.Store(time.Now().UnixMilli())
+			default:
+				queued = false
+				c.dropped.Add(1)
+				slog.Warn("提交事件缓冲区已满，丢弃事件", "repo", repo, "hash", commit.Hash)
+			}
+		}
+		// 全部入队后才记录分支指针；否则保留上次的指针，下轮重新读取（入库按哈希去重）
+		if queued {
+			c.mu.Lock()
+			c.repos[repo] = tips
+			c.mu.Unlock()
+		}
+	}
+}
+
+// scanRepo 对比分支指针，返回新出现的提交与当前的分支指针（由调用方在提交全部入队后记录）。
+// 首次扫描返回回溯窗口内的提交。
+func (c *CommitCollector) scanRepo(ctx context.Context, repo string) ([]*schema.GitCommit, map[string]string, error) {
+	gitDir := gitDirOf(repo)
+	if gitDir == "" {
+		return nil, nil, fmt.Errorf("不是 Git 仓库: %s", repo)
+	}
+	tips := readBranchTips(gitDir)
+
+	c.mu.Lock()
+	prev, seen := c.repos[repo]
+	c.mu.Unlock()
+
+	args := []string{"log", "--no-merges", "--source", fmt.Sprintf("--max-count=%d", maxCommitsPerScan)}
+	if !seen {
+		if len(tips) == 0 {
+			return nil, tips, nil
+		}
+		args = append(args, fmt.Sprintf("--since=%d", time.Now().Add(-c.lookback).Unix()))
+		args = append(args, branchRefs(tips, nil)...)
+	} else {
+		// 指向已知提交的分支（新建分支、回退、快进到其它分支）不产生新提交
+		old := make(map[string]struct{}, len(prev))
+		for _, h := range prev {
+			old[h] = struct{}{}
+		}
+		changed := branchRefs(tips, func(branch, hash string) bool {
+			_, known := old[hash]
+			return !known
+		})
+		if len(changed) == 0 {
+			return nil, tips, nil
+		}
+		args = append(args, changed...)
+		if len(old) > 0 {
+			args = append(args, "--not")
+			args = append(args, sortedValues(prev)...)
+		}
+	}
+
+	out, err := runGitLog(ctx, repo, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	commits := parseGitLog(out)
+	if len(commits) == 0 {
+		return nil, tips, nil
+	}
+
+	// 只记录本人的提交（pull 下来的他人提交不算作自己的工作证据）
+	email := gitUserEmail(ctx, repo)
+	filtered := commits[:0]
+	for _, commit := range commits {
+		if email != "" && !strings.EqualFold(commit.AuthorEmail, email) {
+			continue
+		}
+		commit.RepoPath = repo
+		filtered = append(filtered, commit)
+	}
+	// git log 按时间倒序，发送时改为正序
+	for i, j := 0, len(filtered)-1; i < j; i, j = i+1, j-1 {
+		filtered[i], filtered[j] = filtered[j], filtered[i]
+	}
+	return filtered, tips, nil
+}
+
+// branchRefs 返回满足条件的分支全名；按引用名传给 git log，--source 才能给出分支名
+func branchRefs(tips map[string]string, keep func(branch, hash string) bool) []string {
+	out := make([]string, 0, len(tips))
+	for branch, hash := range tips {
+		if keep == nil || keep(branch, hash) {
+			out = append(out, "refs/heads/"+branch)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sortedValues(m map[string]string) []string {
+	set := make(map[string]struct{}, len(m))
+	for _, v := range m {
+		set[v] = struct{}{}
+	}
+	out := make([]string, 0, len(set))
+	for v := range set {
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// git log 输出格式：记录以 \x1e 开头，字段以 \x1f 分隔，最后是 --numstat 的文件统计
+const gitLogFormat = "--format=%x1e%H%x1f%S%x1f%an%x1f%ae%x1f%at%x1f%s%x1f%b%x1f"
+
+func runGitLog(ctx context.Context, repo string, args []string) (string, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	full := append([]string{"-c", "core.quotepath=off"}, args...)
+	full = append(full, gitLogFormat, "--numstat", "--no-color")
+	cmd := exec.CommandContext(timeoutCtx, "git", full...)
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(), "LC_ALL=C.UTF-8", "LANG=C.UTF-8")
+	hideWindow(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("执行 git log 失败: %w", err)
+	}
+	return string(out), nil
+}
+
+func gitUserEmail(ctx context.Context, repo string) string {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(timeoutCtx, "git", "config", "--get", "user.email")
+	cmd.Dir = repo
+	hideWindow(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// parseGitLog 解析 gitLogFormat + --numstat 的输出
+func parseGitLog(out string) []*schema.GitCommit {
+	var commits []*schema.GitCommit
+	for _, rec := range strings.Split(out, "\x1e") {
+		if strings.TrimSpace(rec) == "" {
+			continue
+		}
+		fields := strings.SplitN(rec, "\x1f", 8)
+		if len(fields) < 8 {
+			continue
+		}
+		authorSec, _ := strconv.ParseInt(strings.TrimSpace(fields[4]), 10, 64)
+		commit := &schema.GitCommit{
+			Hash:        strings.TrimSpace(fields[0]),
+			Branch:      strings.TrimPrefix(strings.TrimSpace(fields[1]), "refs/heads/"),
+			AuthorName:  strings.TrimSpace(fields[2]),
+			AuthorEmail: strings.TrimSpace(fields[3]),
+			Timestamp:   authorSec * 1000,
+			Subject:     strings.TrimSpace(fields[5]),
+			Body:        strings.TrimSpace(fields[6]),
+			Files:       schema.JSONArray{},
+		}
+		if commit.Hash == "" {
+			continue
+		}
+
+		for _, line := range strings.Split(fields[7], "\n") {
+			parts := strings.SplitN(strings.TrimSpace(line), "\t", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			// 二进制文件的行数为 "-"
+			if n, err := strconv.Atoi(parts[0]); err == nil {
+				commit.LinesAdded += n
+			}
+			if n, err := strconv.Atoi(parts[1]); err == nil {
+				commit.LinesDeleted += n
+			}
+			commit.Files = append(commit.Files, parts[2])
+		}
+		commit.FilesChanged = len(commit.Files)
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+// readBranchTips 读取所有本地分支指向的提交（loose refs 覆盖 packed-refs）
+func readBranchTips(gitDir string) map[string]string {
+	common := gitDir
+	if b, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common = strings.TrimSpace(string(b))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+	}
+
+	tips := make(map[string]string)
+	if b, err := os.ReadFile(filepath.Join(common, "packed-refs")); err == nil {
+		for _, line := range strings.Split(string(b), "\n") {
+			hash, name, ok := strings.Cut(strings.TrimSpace(line), " ")
+			if ok && strings.HasPrefix(name, "refs/heads/") {
+				tips[strings.TrimPrefix(name, "refs/heads/")] = hash
+			}
+		}
+	}
+
+	headsDir := filepath.Join(common, "refs", "heads")
+	_ = filepath.WalkDir(headsDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasSuffix(path, ".lock") {
+			return nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		if rel, err := filepath.Rel(headsDir, path); err == nil {
+			if hash := strings.TrimSpace(string(b)); hash != "" {
+				tips[filepath.ToSlash(rel)] = hash
+			}
+		}
+		return nil
+	})
+	return tips
+}
+
+// DiscoverGitRepos 找出监控目录对应的仓库：监控目录本身所在的仓库，以及其下若干层内的仓库
+func DiscoverGitRepos(watchPaths []string) []string {
+	seen := make(map[string]struct{})
+	var repos []string
+	add := func(p string) {
+		if _, ok := seen[p]; !ok {
+			seen[p] = struct{}{}
+			repos = append(repos, p)
+		}
+	}
+
+	for _, wp := range watchPaths {
+		root, err := filepath.Abs(wp)
+		if err != nil {
+			continue
+		}
+		// findGitRoot 从文件所在目录开始向上查找，传入 root 下的路径即从 root 本身开始
+		if repo, ok := (&DiffCollector{}).findGitRoot(filepath.Join(root, ".git")); ok {
+			add(repo)
+		}
+		baseDepth := strings.Count(root, string(filepath.Separator))
+		_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			name := d.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			if gitDirOf(path) != "" {
+				add(path)
+			}
+			if strings.Count(path, string(filepath.Separator))-baseDepth >= maxRepoDiscoverDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+type CommitCollectorStats struct {
+	Running    bool  `json:"running"`
+	Repos      int   `json:"repos"`
+	LastEmitAt int64 `json:"last_emit_at"`
+	Dropped    int64 `json:"dropped"`
+	ScanErrors int64 `json:"scan_errors"`
+}
+
+func (c *CommitCollector) Stats() CommitCollectorStats {
+	if c == nil {
+		return CommitCollectorStats{}
+	}
+	c.mu.Lock()
+	running := c.running
+	repos := len(c.repos)
+	c.mu.Unlock()
+	return CommitCollectorStats{
+		Running:    running,
+		Repos:      repos,
+		LastEmitAt: c.lastEmitAt.Load(),
+		Dropped:    c.dropped.Load(),
+		ScanErrors: c.scanErrors.Load(),
+	}
+}
@@ -0,0 +1,171 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+func TestParseGitLog(t *testing.T) {
+	out := "\x1eabc123\x1frefs/heads/main\x1fAlice\x1falice@example.com\x1f1700000000\x1ffeat: add parser\x1fdetails line\n\x1f\n" +
+		"3\t1\tinternal/a.go\n-\t-\tassets/logo.png\n" +
+		"\x1edef456\x1frefs/heads/feature/x\x1fAlice\x1falice@example.com\x1f1700000100\x1ffix: typo\x1f\x1f\n"
+
+	commits := parseGitLog(out)
+	if len(commits) != 2 {
+		t.Fatalf("commits = %d, want 2", len(commits))
+	}
+	c := commits[0]
+	if c.Hash != "abc123" || c.Branch != "main" || c.Subject != "feat: add parser" || c.Body != "details line" {
+		t.Fatalf("unexpected commit: %+v", c)
+	}
+	if c.Timestamp != 1700000000*1000 {
+		t.Fatalf("timestamp = %d", c.Timestamp)
+	}
+	if c.FilesChanged != 2 || c.LinesAdded != 3 || c.LinesDeleted != 1 {
+		t.Fatalf("stats = files %d +%d -%d", c.FilesChanged, c.LinesAdded, c.LinesDeleted)
+	}
+	if commits[1].Branch != "feature/x" || commits[1].FilesChanged != 0 {
+		t.Fatalf("unexpected second commit: %+v", commits[1])
+	}
+}
+
+func TestReadBranchTips_looseOverridesPacked(t *testing.T) {
+	gitDir := t.TempDir()
+	packed := "# pack-refs with: peeled fully-peeled sorted\n" +
+		"1111111111111111111111111111111111111111 refs/heads/main\n" +
+		"2222222222222222222222222222222222222222 refs/heads/old\n" +
+		"3333333333333333333333333333333333333333 refs/tags/v1\n"
+	if err := os.WriteFile(filepath.Join(gitDir, "packed-refs"), []byte(packed), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loose := filepath.Join(gitDir, "refs", "heads", "feature")
+	if err := os.MkdirAll(loose, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(gitDir, "refs", "heads", "main"), []byte("4444444444444444444444444444444444444444\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(loose, "x"), []byte("5555555555555555555555555555555555555555\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tips := readBranchTips(gitDir)
+	want := map[string]string{
+		"main":      "4444444444444444444444444444444444444444",
+		"old":       "2222222222222222222222222222222222222222",
+		"feature/x": "5555555555555555555555555555555555555555",
+	}
+	if len(tips) != len(want) {
+		t.Fatalf("tips = %v", tips)
+	}
+	for k, v := range want {
+		if tips[k] != v {
+			t.Fatalf("tips[%s] = %q, want %q", k, tips[k], v)
+		}
+	}
+}
+
+// drainCommits 执行一轮扫描并取出本轮入队的提交
+func drainCommits(ctx context.Context, c *CommitCollector, repo string) []*schema.GitCommit {
+	c.scanAll(ctx, []string{repo})
+	var out []*schema.GitCommit
+	for {
+		select {
+		case commit := <-c.eventChan:
+			out = append(out, commit)
+		default:
+			return out
+		}
+	}
+}
+
+func TestCommitCollector_scanAll_detectsNewCommits(t *testing.T) {
+	repo := initTestRepo(t, map[string]string{"main.go": "package main\n"})
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	c := NewCommitCollector(&CommitCollectorConfig{WatchPaths: []string{repo}})
+	ctx := context.Background()
+
+	first := drainCommits(ctx, c, repo)
+	if len(first) != 1 || first[0].Subject != "init" || first[0].RepoPath != repo {
+		t.Fatalf("first scan = %+v", first)
+	}
+
+	if again := drainCommits(ctx, c, repo); len(again) != 0 {
+		t.Fatalf("unchanged scan = %v", again)
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "commit", "-q", "-am", "add main")
+	// 他人的提交不计入
+	runGit(t, repo, "-c", "user.email=other@example.com", "commit", "-q", "--allow-empty", "--author", "other <other@example.com>", "-m", "someone else")
+
+	next := drainCommits(ctx, c, repo)
+	if len(next) != 1 {
+		t.Fatalf("next scan = %d commits, want 1", len(next))
+	}
+	got := next[0]
+	if got.Subject != "add main" || got.FilesChanged != 1 || got.LinesAdded != 2 || got.Branch == "" {
+		t.Fatalf("unexpected commit: %+v", got)
+	}
+}
+
+func TestCommitCollector_scanAll_retriesAfterFailure(t *testing.T) {
+	repo := initTestRepo(t, map[string]string{"main.go": "package main\n"})
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	c := NewCommitCollector(&CommitCollectorConfig{WatchPaths: []string{repo}})
+	ctx := context.Background()
+
+	// git log 失败（引用指向不存在的对象）：不记录分支指针，修复后仍按首次扫描回溯
+	broken := filepath.Join(repo, ".git", "refs", "heads", "broken")
+	if err := os.WriteFile(broken, []byte("0123456789012345678901234567890123456789\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := drainCommits(ctx, c, repo); len(got) != 0 || c.scanErrors.Load() != 1 {
+		t.Fatalf("broken scan = %v, errors=%d", got, c.scanErrors.Load())
+	}
+	if err := os.Remove(broken); err != nil {
+		t.Fatal(err)
+	}
+	if got := drainCommits(ctx, c, repo); len(got) != 1 || got[0].Subject != "init" {
+		t.Fatalf("scan after failure = %+v", got)
+	}
+
+	// 缓冲区已满时丢弃的提交：下一轮重新读取
+	runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "second")
+	c.eventChan = make(chan *schema.GitCommit, 1)
+	c.eventChan <- &schema.GitCommit{Hash: "placeholder"}
+	c.scanAll(ctx, []string{repo})
+	if c.dropped.Load() != 1 {
+		t.Fatalf("dropped = %d, want 1", c.dropped.Load())
+	}
+	<-c.eventChan
+	if got := drainCommits(ctx, c, repo); len(got) != 1 || got[0].Subject != "second" {
+		t.Fatalf("scan after drop = %+v", got)
+	}
+}
+
+func TestDiscoverGitRepos(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a", filepath.Join("group", "b"), filepath.Join("a", "node_modules", "dep")} {
+		if err := os.MkdirAll(filepath.Join(root, dir, ".git"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repos := DiscoverGitRepos([]string{root})
+	want := []string{filepath.Join(root, "a"), filepath.Join(root, "group", "b")}
+	if len(repos) != len(want) {
+		t.Fatalf("repos = %v", repos)
+	}
+	for i := range want {
+		if repos[i] != want[i] {
+			t.Fatalf("repos = %v, want %v", repos, want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// maxBaselineFiles 增量基线最多缓存的文件数（超出时淘汰最久未保存的）
+	maxBaselineFiles = 2000
+	// maxBaselineBytes 单文件基线上限；更大的文件直接使用累计 diff
+	maxBaselineBytes = 1 << 20
+)
+
+// fileBaseline 文件上次采集时的内容，以及当时所在的 HEAD（提交后基线失效）
+type fileBaseline struct {
+	head    string
+	content string
+	seenAt  time.Time
+}
+
+// incrementalDiff 计算相对上次采集的增量。
+// 内存中没有基线时（如重启后）先尝试用落库的累计 diff 还原；仍没有基线或 HEAD 变化（发生提交/切分支）时
+// 基线重置为 HEAD，增量即 git 给出的累计 diff。
+func (c *DiffCollector) incrementalDiff(repoRoot, filePath, cumulative string, cumAdded, cumDeleted int) (string, int, int) {
+	current, err := os.ReadFile(filePath)
+	if err != nil || len(current) > maxBaselineBytes {
+		c.forgetBaseline(filePath)
+		return cumulative, cumAdded, cumDeleted
+	}
+	head := readGitHead(repoRoot)
+
+	c.mu.Lock()
+	prev := c.baselines[filePath]
+	c.rememberBaselineLocked(filePath, &fileBaseline{head: head, content: string(current), seenAt: time.Now()})
+	c.mu.Unlock()
+
+	if prev == nil && head != "" {
+		prev = c.seedBaseline(repoRoot, filePath, head, string(current), cumulative)
+	}
+	if prev == nil || head == "" || prev.head != head {
+		return cumulative, cumAdded, cumDeleted
+	}
+
+	rel := filepath.Base(filePath)
+	if p, err := filepath.Rel(repoRoot, filePath); err == nil {
+		rel = filepath.ToSlash(p)
+	}
+	return unifiedDiff("a/"+rel, "b/"+rel, prev.content, string(current))
+}
+
+// seedBaseline 用该文件最后一条落库 Diff 的累计内容还原上次采集时的文件：当前内容反向应用当前累计 diff
+// 得到 HEAD 版本，再正向应用上次的累计 diff。记录早于最近一次 HEAD 变动（提交/切分支）或对不上时返回 nil。
+func (c *DiffCollector) seedBaseline(repoRoot, filePath, head, current, cumulative string) *fileBaseline {
+	if c.lastCumulative == nil {
+		return nil
+	}
+	last, ts := c.lastCumulative(filePath)
+	if last == "" {
+		return nil
+	}
+	movedAt := headMovedAt(repoRoot)
+	if movedAt.IsZero() || ts < movedAt.UnixMilli() {
+		return nil
+	}
+	base, ok := applyUnifiedDiff(splitLines(current), cumulative, true)
+	if !ok {
+		return nil
+	}
+	prev, ok := applyUnifiedDiff(base, last, false)
+	if !ok {
+		return nil
+	}
+	return &fileBaseline{head: head, content: strings.Join(prev, "\n")}
+}
+
+// headMovedAt HEAD 最近一次变动的时间（reflog 的修改时间）；没有 reflog 时返回零值
+func headMovedAt(repoRoot string) time.Time {
+	gitDir := gitDirOf(repoRoot)
+	if gitDir == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(filepath.Join(gitDir, "logs", "HEAD"))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+func (c *DiffCollector) rememberBaselineLocked(filePath string, b *fileBaseline) {
+	if _, ok := c.baselines[filePath]; !ok && len(c.baselines) >= maxBaselineFiles {
+		var oldestKey string
+		var oldest time.Time
+		for k, v := range c.baselines {
+			if oldestKey == "" || v.seenAt.Before(oldest) {
+				oldestKey, oldest = k, v.seenAt
+			}
+		}
+		delete(c.baselines, oldestKey)
+	}
+	c.baselines[filePath] = b
+}
+
+func (c *DiffCollector) forgetBaseline(filePath string) {
+	c.mu.Lock()
+	delete(c.baselines, filePath)
+	c.mu.Unlock()
+}
+
+// gitDirOf 返回仓库的 git 目录（兼容 worktree/submodule 的 .git 文件）
+func gitDirOf(repoRoot string) string {
+	gitPath := filepath.Join(repoRoot, ".git")
+	info, err := os.Stat(gitPath)
+	if err != nil {
+		return ""
+	}
+	if info.IsDir() {
+		return gitPath
+	}
+	b, err := os.ReadFile(gitPath)
+	if err != nil {
+		return ""
+	}
+	dir := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(b)), "gitdir:"))
+	if dir == "" {
+		return ""
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repoRoot, dir)
+	}
+	return dir
+}
+
+// readGitHead 直接读取 .git/HEAD 解析当前提交哈希（不启动 git 进程）；失败返回空字符串
+func readGitHead(repoRoot string) string {
+	gitDir := gitDirOf(repoRoot)
+	if gitDir == "" {
+		return ""
+	}
+	b, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(b))
+	ref, ok := strings.CutPrefix(head, "ref:")
+	if !ok {
+		return head // detached HEAD
+	}
+	ref = strings.TrimSpace(ref)
+	if hash := resolveGitRef(gitDir, ref); hash != "" {
+		return hash
+	}
+	// 尚无提交的分支：以引用名作为基线标识，首次提交后自然失效
+	return head
+}
+
+// resolveGitRef 解析引用（loose ref 优先，其次 packed-refs）；worktree 的分支引用位于 commondir
+func resolveGitRef(gitDir, ref string) string {
+	dirs := []string{gitDir}
+	if b, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(b))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		dirs = append(dirs, common)
+	}
+
+	for _, dir := range dirs {
+		if b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(b))
+		}
+	}
+	for _, dir := range dirs {
+		b, err := os.ReadFile(filepath.Join(dir, "packed-refs"))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(b), "\n") {
+			hash, name, ok := strings.Cut(strings.TrimSpace(line), " ")
+			if ok && name == ref {
+				return hash
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,104 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultCatchUpMaxFiles 启动补扫默认最多采集的文件数
+const defaultCatchUpMaxFiles = 200
+
+type catchUpFile struct {
+	path     string
+	ruleRoot string
+	modTime  time.Time
+}
+
+// catchUp 启动补扫：采集器离线期间被修改的文件按 mtime 先后生成 Diff，并标记为 backfilled。
+// 增量相对该文件最后一条落库记录（见 seedBaseline），离线期间没有新改动的文件不产生记录。
+func (c *DiffCollector) catchUp(ctx context.Context) {
+	since := time.UnixMilli(c.catchUpSince)
+	limit := c.catchUpMaxFiles
+	if limit <= 0 {
+		limit = defaultCatchUpMaxFiles
+	}
+
+	c.mu.Lock()
+	roots := append([]string(nil), c.watchPaths...)
+	c.mu.Unlock()
+
+	var files []catchUpFile
+	for _, root := range roots {
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+		c.collectModifiedSince(root, c.ruleRootOf(root, true), since, &files)
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	// 超出上限时保留最近修改的文件，再按时间顺序发送
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+	if len(files) > limit {
+		slog.Warn("离线期间修改的文件过多，只补扫最近的部分", "found", len(files), "limit", limit)
+		files = files[:limit]
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	emitted := 0
+	for _, f := range files {
+		if ctx.Err() != nil {
+			return
+		}
+		if c.filter.skipFile(f.ruleRoot, f.path) {
+			continue
+		}
+		diff, err := c.captureDiff(ctx, f.path)
+		if err != nil {
+			slog.Debug("补扫获取 Diff 失败", "file", f.path, "error", err)
+			continue
+		}
+		if diff == nil {
+			continue
+		}
+		diff.Timestamp = f.modTime.UnixMilli()
+		diff.Backfilled = true
+		c.emit(diff)
+		emitted++
+	}
+	slog.Info("启动补扫完成", "since", since.Format(time.RFC3339), "files", len(files), "emitted", emitted)
+}
+
+// collectModifiedSince 遍历未被忽略的目录，收集 mtime 晚于 since 的受监控文件
+func (c *DiffCollector) collectModifiedSince(dir, ruleRoot string, since time.Time, out *[]catchUpFile) {
+	if gitDirOf(dir) != "" {
+		ruleRoot = dir
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if e.IsDir() {
+			if !strings.HasPrefix(e.Name(), ".") && !c.filter.ignoredEntry(ruleRoot, path, true) {
+				c.collectModifiedSince(path, ruleRoot, since, out)
+			}
+			continue
+		}
+		if !c.extensions[strings.ToLower(filepath.Ext(path))] {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || !info.ModTime().After(since) {
+			continue
+		}
+		*out = append(*out, catchUpFile{path: path, ruleRoot: ruleRoot, modTime: info.ModTime()})
+	}
+}
@@ -0,0 +1,132 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffCollector_catchUpEmitsOfflineEdits(t *testing.T) {
+	repo := initTestRepo(t, map[string]string{
+		"a.go": "package a\n",
+		"b.go": "package a\n",
+		"c.go": "package a\n",
+	})
+	since := time.Now().Add(-time.Hour)
+	old := since.Add(-time.Hour)
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		if err := os.Chtimes(filepath.Join(repo, name), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 离线期间：b 先改、c 后改，a 未动
+	edit := func(name string, at time.Time) {
+		t.Helper()
+		path := filepath.Join(repo, name)
+		if err := os.WriteFile(path, []byte("package a\n\nvar X = 1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bAt, cAt := since.Add(10*time.Minute), since.Add(20*time.Minute)
+	edit("b.go", bAt)
+	edit("c.go", cAt)
+
+	newCollector := func(maxFiles int) *DiffCollector {
+		cfg := DefaultDiffCollectorConfig()
+		cfg.CatchUpSince = since.UnixMilli()
+		cfg.CatchUpMaxFiles = maxFiles
+		c, err := NewDiffCollector(cfg)
+		if err != nil {
+			t.Fatalf("NewDiffCollector: %v", err)
+		}
+		t.Cleanup(func() { _ = c.watcher.Close() })
+		if err := c.AddWatchPath(repo); err != nil {
+			t.Fatalf("AddWatchPath: %v", err)
+		}
+		c.catchUp(context.Background())
+		return c
+	}
+
+	c := newCollector(0)
+	if got := len(c.eventChan); got != 2 {
+		t.Fatalf("expected 2 backfilled diffs, got %d", got)
+	}
+	for _, want := range []struct {
+		name string
+		at   time.Time
+	}{{"b.go", bAt}, {"c.go", cAt}} {
+		d := <-c.eventChan
+		if d.FileName != want.name || !d.Backfilled || d.Timestamp != want.at.UnixMilli() {
+			t.Fatalf("got %s backfilled=%v ts=%d, want %s at %d", d.FileName, d.Backfilled, d.Timestamp, want.name, want.at.UnixMilli())
+		}
+		if d.LinesAdded != 2 {
+			t.Fatalf("%s: expected diff against HEAD, got +%d:\n%s", d.FileName, d.LinesAdded, d.DiffContent)
+		}
+	}
+
+	// 超出上限时只保留最近修改的
+	c = newCollector(1)
+	if got := len(c.eventChan); got != 1 {
+		t.Fatalf("expected 1 backfilled diff, got %d", got)
+	}
+	if d := <-c.eventChan; d.FileName != "c.go" {
+		t.Fatalf("expected newest file c.go, got %s", d.FileName)
+	}
+}
+
+func TestDiffCollector_catchUpDiffsAgainstStoredCumulative(t *testing.T) {
+	repo := initTestRepo(t, map[string]string{
+		"a.go": "package a\n",
+		"b.go": "package a\n",
+	})
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(repo, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	// 上次运行时已记录：a 之后未再修改，b 离线期间又追加了一行
+	a := write("a.go", "package a\n\nvar A = 1\n")
+	b := write("b.go", "package a\n\nvar B = 1\n")
+	stored := make(map[string]string)
+	probe := &DiffCollector{baselines: make(map[string]*fileBaseline), git: newGitBackend(GitBackendAuto)}
+	for _, path := range []string{a, b} {
+		d, err := probe.captureDiff(context.Background(), path)
+		if err != nil || d == nil {
+			t.Fatalf("capture %s: diff=%v err=%v", path, d, err)
+		}
+		stored[path] = d.CumulativeContent
+	}
+	write("b.go", "package a\n\nvar B = 1\n\nvar C = 2\n")
+
+	cfg := DefaultDiffCollectorConfig()
+	cfg.CatchUpSince = time.Now().Add(-time.Hour).UnixMilli()
+	cfg.LastCumulative = func(filePath string) (string, int64) {
+		return stored[filePath], time.Now().UnixMilli()
+	}
+	c, err := NewDiffCollector(cfg)
+	if err != nil {
+		t.Fatalf("NewDiffCollector: %v", err)
+	}
+	t.Cleanup(func() { _ = c.watcher.Close() })
+	if err := c.AddWatchPath(repo); err != nil {
+		t.Fatalf("AddWatchPath: %v", err)
+	}
+	c.catchUp(context.Background())
+
+	if got := len(c.eventChan); got != 1 {
+		t.Fatalf("expected 1 backfilled diff, got %d", got)
+	}
+	d := <-c.eventChan
+	if d.FileName != "b.go" || d.LinesAdded != 2 || d.LinesDeleted != 0 || strings.Contains(d.DiffContent, "+var B") {
+		t.Fatalf("expected only the offline edit of b.go, got %s +%d/-%d:\n%s", d.FileName, d.LinesAdded, d.LinesDeleted, d.DiffContent)
+	}
+}
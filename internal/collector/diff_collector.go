@@ -1,5 +1,3 @@
-//go:build windows
-
 package collector
 
 import (
@@ -7,26 +5,16 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
-	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/yuqie6/WorkMirror/internal/schema"
 )
 
-// hideWindow 设置 Windows 下隐藏命令行窗口
-func hideWindow(cmd *exec.Cmd) {
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		HideWindow:    true,
-		CreationFlags: 0x08000000, // CREATE_NO_WINDOW
-	}
-}
-
 // DiffCollector 文件 Diff 采集器
 type DiffCollector struct {
 	watcher     *fsnotify.Watcher
@@ -39,6 +27,23 @@ type DiffCollector struct {
 	stopOnce    sync.Once
 	debounceMap map[string]time.Time // 防抖：file -> lastSave
 	debounceDur time.Duration
+	baselines   map[string]*fileBaseline // 增量基线：file -> 上次采集时的内容
+	snapshots   *snapshotStore           // 非 Git 目录的影子快照（nil 表示不采集非 Git 目录）
+	git         GitBackend
+	filter      *pathFilter
+
+	watchMu      sync.Mutex            // 串行化目录同步与轮询扫描
+	watched      map[string]struct{}   // 已加入 fsnotify 的目录
+	roots        map[string]*watchRoot // 监控根 -> 实际监控方式
+	watchMode    string
+	watchModes   map[string]string // 按路径覆盖的监控方式
+	pollInterval time.Duration
+	pollMaxFiles int
+
+	catchUpSince    int64
+	catchUpMaxFiles int
+
+	lastCumulative func(filePath string) (string, int64)
 
 	lastEmitAt    atomic.Int64
 	dropped       atomic.Int64
@@ -51,6 +56,29 @@ type DiffCollectorConfig struct {
 	Extensions  []string // 监控的文件扩展名
 	BufferSize  int      // 事件缓冲区大小
 	DebounceSec int      // 防抖时间（秒）
+	GitBackend  string   // auto | native | exec
+
+	// 在 .gitignore / .git/info/exclude 之外的过滤规则（gitignore 语法）
+	Exclude []string   // 相对每个仓库根（非 Git 目录为监控根）匹配
+	Rules   []PathRule // 按目录的 include/exclude
+
+	// 监控方式：auto | notify | poll；WatchModes 按路径覆盖（包含监控根的最深路径生效）
+	WatchMode    string
+	WatchModes   map[string]string
+	PollInterval time.Duration // 轮询间隔
+	PollMaxFiles int           // 每次轮询最多检查的文件数，未扫完的下次继续
+
+	// 启动补扫：采集 mtime 晚于 CatchUpSince（毫秒，通常为最后一条 Diff 的时间）的文件，0 表示不补扫
+	CatchUpSince    int64
+	CatchUpMaxFiles int // 只补扫最近修改的若干文件（切分支等会触碰大量文件）
+
+	// LastCumulative 返回文件最后一条落库 Diff 的累计内容与时间戳（毫秒），重启后用于还原增量基线；可为 nil
+	LastCumulative func(filePath string) (string, int64)
+
+	// 非 Git 目录：SnapshotDir 为空时跳过这类文件
+	SnapshotDir           string
+	SnapshotMaxFileBytes  int64 // 单文件快照上限，超出则不采集该文件
+	SnapshotMaxTotalBytes int64 // 快照总量上限，超出时淘汰最久未更新的
 }
 
 // DefaultDiffCollectorConfig 默认配置
@@ -60,6 +88,7 @@ func DefaultDiffCollectorConfig() *DiffCollectorConfig {
 		Extensions:  []string{".go", ".py", ".js", ".ts", ".jsx", ".tsx", ".vue", ".java", ".rs", ".c", ".cpp"},
 		BufferSize:  512,
 		DebounceSec: 2,
+		Exclude:     []string{"node_modules/", "vendor/", "__pycache__/", "dist/", "build/"},
 	}
 }
 
@@ -80,6 +109,29 @@ func NewDiffCollector(cfg *DiffCollectorConfig) (*DiffCollector, error) {
 		extMap[strings.ToLower(ext)] = true
 	}
 
+	watchModes := make(map[string]string, len(cfg.WatchModes))
+	for p, m := range cfg.WatchModes {
+		if abs, err := filepath.Abs(p); err == nil {
+			watchModes[filepath.Clean(abs)] = m
+		}
+	}
+	pollInterval := cfg.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+	pollMaxFiles := cfg.PollMaxFiles
+	if pollMaxFiles <= 0 {
+		pollMaxFiles = defaultPollMaxFiles
+	}
+
+	var snapshots *snapshotStore
+	if cfg.SnapshotDir != "" {
+		snapshots, err = newSnapshotStore(cfg.SnapshotDir, cfg.SnapshotMaxFileBytes, cfg.SnapshotMaxTotalBytes)
+		if err != nil {
+			slog.Warn("初始化快照存储失败，非 Git 目录将被跳过", "dir", cfg.SnapshotDir, "error", err)
+		}
+	}
+
 	return &DiffCollector{
 		watcher:     watcher,
 		watchPaths:  cfg.WatchPaths,
@@ -87,49 +139,55 @@ func NewDiffCollector(cfg *DiffCollectorConfig) (*DiffCollector, error) {
 		eventChan:   make(chan *schema.Diff, cfg.BufferSize),
 		stopChan:    make(chan struct{}),
 		debounceMap: make(map[string]time.Time),
+		baselines:   make(map[string]*fileBaseline),
+		snapshots:   snapshots,
+		git:         newGitBackend(cfg.GitBackend),
+		filter:      newPathFilter(cfg.Exclude, cfg.Rules),
+		watched:     make(map[string]struct{}),
 		debounceDur: time.Duration(cfg.DebounceSec) * time.Second,
+
+		roots:        make(map[string]*watchRoot),
+		watchMode:    cfg.WatchMode,
+		watchModes:   watchModes,
+		pollInterval: pollInterval,
+		pollMaxFiles: pollMaxFiles,
+
+		catchUpSince:    cfg.CatchUpSince,
+		catchUpMaxFiles: cfg.CatchUpMaxFiles,
+
+		lastCumulative: cfg.LastCumulative,
 	}, nil
 }
 
-// AddWatchPath 添加监控路径
+// AddWatchPath 添加监控路径（递归监控未被忽略的子目录）
 func (c *DiffCollector) AddWatchPath(path string) error {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		return fmt.Errorf("获取绝对路径失败: %w", err)
 	}
-
-	// 递归添加所有子目录
-	err = filepath.Walk(absPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			// 跳过隐藏目录和常见的忽略目录
-			base := filepath.Base(path)
-			if strings.HasPrefix(base, ".") ||
-				base == "node_modules" ||
-				base == "vendor" ||
-				base == "__pycache__" ||
-				base == "dist" ||
-				base == "build" {
-				return filepath.SkipDir
-			}
-
-			if err := c.watcher.Add(path); err != nil {
-				slog.Warn("添加监控目录失败", "path", path, "error", err)
-			} else {
-				slog.Debug("添加监控目录", "path", path)
-			}
-		}
-		return nil
-	})
-
+	info, err := os.Stat(absPath)
 	if err != nil {
 		return fmt.Errorf("遍历目录失败: %w", err)
 	}
+	if !info.IsDir() {
+		return fmt.Errorf("监控路径不是目录: %s", absPath)
+	}
 
-	c.watchPaths = append(c.watchPaths, absPath)
-	slog.Info("添加 Diff 监控路径", "path", absPath)
+	c.mu.Lock()
+	known := false
+	for _, p := range c.watchPaths {
+		if abs, err := filepath.Abs(p); err == nil && abs == absPath {
+			known = true
+			break
+		}
+	}
+	if !known {
+		c.watchPaths = append(c.watchPaths, absPath)
+	}
+	c.mu.Unlock()
+
+	c.startWatchRoot(absPath)
+	slog.Info("添加 Diff 监控路径", "path", absPath, "mode", c.effectiveWatchMode(absPath))
 	return nil
 }
 
@@ -174,8 +232,16 @@ func (c *DiffCollector) Events() <-chan *schema.Diff {
 // watchLoop 监控循环
 func (c *DiffCollector) watchLoop(ctx context.Context) {
 	defer close(c.eventChan)
+	// 轮询与 fsnotify 事件在同一协程处理，避免并发写入已关闭的 eventChan
+	pollTicker := time.NewTicker(c.pollInterval)
+	defer pollTicker.Stop()
+	if c.catchUpSince > 0 {
+		c.catchUp(ctx)
+	}
 	for {
 		select {
+		case <-pollTicker.C:
+			c.pollOnce(ctx)
 		case <-ctx.Done():
 			return
 		case <-c.stopChan:
@@ -196,6 +262,9 @@ func (c *DiffCollector) watchLoop(ctx context.Context) {
 
 // handleFsEvent 处理文件系统事件
 func (c *DiffCollector) handleFsEvent(ctx context.Context, event fsnotify.Event) {
+	if c.handleTreeEvent(event) {
+		return
+	}
 	// 只处理写入事件
 	if !event.Has(fsnotify.Write) {
 		return
@@ -208,6 +277,9 @@ func (c *DiffCollector) handleFsEvent(ctx context.Context, event fsnotify.Event)
 	if !c.extensions[ext] {
 		return
 	}
+	if c.filter.skipFile(c.ruleRootOf(filePath, false), filePath) {
+		return
+	}
 
 	// 防抖检查
 	c.mu.Lock()
@@ -231,7 +303,11 @@ func (c *DiffCollector) handleFsEvent(ctx context.Context, event fsnotify.Event)
 		return
 	}
 
-	// 发送事件
+	c.emit(diff)
+}
+
+// emit 发送事件（缓冲区满时丢弃）
+func (c *DiffCollector) emit(diff *schema.Diff) {
 	select {
 	case c.eventChan <- diff:
 		c.lastEmitAt.Store(time.Now().UnixMilli())
@@ -242,7 +318,7 @@ func (c *DiffCollector) handleFsEvent(ctx context.Context, event fsnotify.Event)
 		)
 	default:
 		c.dropped.Add(1)
-		slog.Warn("Diff 缓冲区已满，丢弃事件", "file", filePath)
+		slog.Warn("Diff 缓冲区已满，丢弃事件", "file", diff.FilePath)
 	}
 }
 
@@ -251,23 +327,27 @@ func (c *DiffCollector) captureDiff(ctx context.Context, filePath string) (*sche
 	// 检查是否在 Git 仓库中
 	projectPath, isGit := c.findGitRoot(filePath)
 
-	var diffContent string
+	var diffContent, cumulativeContent string
 	var linesAdded, linesDeleted int
 
 	if isGit {
-		// 使用 git diff
-		content, added, deleted, err := c.gitDiff(ctx, projectPath, filePath)
+		// git diff 给出相对 HEAD/index 的累计改动；落库的是相对上次采集的增量，累计视图保留给会话证据
+		content, added, deleted, err := c.git.FileDiff(ctx, projectPath, filePath)
 		if err != nil {
 			return nil, err
 		}
-		diffContent = content
-		linesAdded = added
-		linesDeleted = deleted
+		cumulativeContent = content
+		diffContent, linesAdded, linesDeleted = c.incrementalDiff(projectPath, filePath, content, added, deleted)
 	} else {
-		// 非 Git 仓库，暂时跳过（或实现简单的文件快照对比）
-		c.skippedNonGit.Add(1)
-		slog.Debug("非 Git 仓库，跳过 Diff", "file", filePath)
-		return nil, nil
+		// 非 Git 目录：与影子快照对比
+		root, content, added, deleted, ok := c.snapshotDiff(filePath)
+		if !ok {
+			c.skippedNonGit.Add(1)
+			slog.Debug("非 Git 目录且无法快照，跳过 Diff", "file", filePath)
+			return nil, nil
+		}
+		projectPath = root
+		diffContent, linesAdded, linesDeleted = content, added, deleted
 	}
 
 	if diffContent == "" {
@@ -278,24 +358,32 @@ func (c *DiffCollector) captureDiff(ctx context.Context, filePath string) (*sche
 	language := GetLanguageFromExt(ext)
 
 	return &schema.Diff{
-		Timestamp:    time.Now().UnixMilli(),
-		FilePath:     filePath,
-		FileName:     filepath.Base(filePath),
-		Language:     language,
-		DiffContent:  diffContent,
-		LinesAdded:   linesAdded,
-		LinesDeleted: linesDeleted,
-		ProjectPath:  projectPath,
-		IsGitRepo:    isGit,
+		Timestamp:         time.Now().UnixMilli(),
+		FilePath:          filePath,
+		FileName:          filepath.Base(filePath),
+		Language:          language,
+		DiffContent:       diffContent,
+		LinesAdded:        linesAdded,
+		LinesDeleted:      linesDeleted,
+		ProjectPath:       projectPath,
+		IsGitRepo:         isGit,
+		CumulativeContent: cumulativeContent,
 	}, nil
 }
 
 type DiffCollectorStats struct {
-	Running       bool     `json:"running"`
-	LastEmitAt    int64    `json:"last_emit_at"`
-	Dropped       int64    `json:"dropped"`
-	SkippedNonGit int64    `json:"skipped_non_git"`
-	WatchPaths    []string `json:"watch_paths"`
+	Running        bool              `json:"running"`
+	LastEmitAt     int64             `json:"last_emit_at"`
+	Dropped        int64             `json:"dropped"`
+	SkippedNonGit  int64             `json:"skipped_non_git"`
+	WatchPaths     []string          `json:"watch_paths"`
+	WatchedDirs    int               `json:"watched_dirs"`
+	WatchModes     map[string]string `json:"watch_modes"`     // 监控根 -> 实际方式（notify | poll）
+	WatchFallbacks int               `json:"watch_fallbacks"` // auto 回退为轮询的监控根数
+	SnapshotFiles  int               `json:"snapshot_files"`
+	SnapshotBytes  int64             `json:"snapshot_bytes"`
+	GitBackend     string            `json:"git_backend"`
+	GitFallbacks   int64             `json:"git_fallbacks"` // auto 模式下回退到 git 命令的次数
 }
 
 func (c *DiffCollector) Stats() DiffCollectorStats {
@@ -307,13 +395,38 @@ func (c *DiffCollector) Stats() DiffCollectorStats {
 	paths := append([]string(nil), c.watchPaths...)
 	c.mu.Unlock()
 
-	return DiffCollectorStats{
-		Running:       running,
-		LastEmitAt:    c.lastEmitAt.Load(),
-		Dropped:       c.dropped.Load(),
-		SkippedNonGit: c.skippedNonGit.Load(),
-		WatchPaths:    paths,
+	c.watchMu.Lock()
+	watchedDirs := len(c.watched)
+	modes := make(map[string]string, len(c.roots))
+	fallbacks := 0
+	for root, r := range c.roots {
+		modes[root] = r.mode
+		if r.fallback {
+			fallbacks++
+		}
+	}
+	c.watchMu.Unlock()
+
+	st := DiffCollectorStats{
+		Running:        running,
+		WatchedDirs:    watchedDirs,
+		WatchModes:     modes,
+		WatchFallbacks: fallbacks,
+		LastEmitAt:     c.lastEmitAt.Load(),
+		Dropped:        c.dropped.Load(),
+		SkippedNonGit:  c.skippedNonGit.Load(),
+		WatchPaths:     paths,
+	}
+	if c.git != nil {
+		st.GitBackend = c.git.Name()
+		if fb, ok := c.git.(interface{ Fallbacks() int64 }); ok {
+			st.GitFallbacks = fb.Fallbacks()
+		}
+	}
+	if c.snapshots != nil {
+		st.SnapshotFiles, st.SnapshotBytes = c.snapshots.Size()
 	}
+	return st
 }
 
 // findGitRoot 查找 Git 仓库根目录
@@ -338,98 +451,3 @@ func (c *DiffCollector) findGitRoot(filePath string) (string, bool) {
 
 	return "", false
 }
-
-// gitDiff 使用 git diff 获取差异（带超时）
-func (c *DiffCollector) gitDiff(ctx context.Context, repoRoot, filePath string) (string, int, int, error) {
-	// 统一在仓库根目录执行 git
-	dir := repoRoot
-	if dir == "" {
-		dir = filepath.Dir(filePath)
-	}
-
-	// 超时保护
-	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	// 检查文件是否被 Git 跟踪
-	relPath := filePath
-	if repoRoot != "" {
-		if p, err := filepath.Rel(repoRoot, filePath); err == nil {
-			relPath = p
-		}
-	}
-
-	// Windows 下将路径转换为正斜杠（Git 兼容格式）
-	relPath = filepath.ToSlash(relPath)
-
-	checkCmd := exec.CommandContext(timeoutCtx, "git", "ls-files", "--", relPath)
-	checkCmd.Dir = dir
-	// 设置 UTF-8 环境变量，解决中文路径问题
-	checkCmd.Env = append(os.Environ(), "LC_ALL=C.UTF-8", "LANG=C.UTF-8")
-	hideWindow(checkCmd)
-	checkOutput, err := checkCmd.CombinedOutput()
-	if err != nil {
-		return "", 0, 0, fmt.Errorf("执行 git ls-files 失败: %w: %s", err, strings.TrimSpace(string(checkOutput)))
-	}
-
-	isTracked := strings.TrimSpace(string(checkOutput)) != ""
-
-	if !isTracked {
-		// 新文件：读取整个文件内容作为 diff
-		content, err := os.ReadFile(filePath)
-		if err != nil {
-			return "", 0, 0, fmt.Errorf("读取新文件失败: %w", err)
-		}
-
-		lines := strings.Split(string(content), "\n")
-		diffContent := fmt.Sprintf("--- /dev/null\n+++ b/%s\n@@ -0,0 +1,%d @@\n",
-			filepath.Base(filePath), len(lines))
-		for _, line := range lines {
-			diffContent += "+" + line + "\n"
-		}
-
-		slog.Debug("检测到新文件", "file", filePath, "lines", len(lines))
-		return diffContent, len(lines), 0, nil
-	}
-
-	// 获取未暂存的改动
-	cmd := exec.CommandContext(timeoutCtx, "git", "diff", "--", relPath)
-	cmd.Dir = dir
-	cmd.Env = append(os.Environ(), "LC_ALL=C.UTF-8", "LANG=C.UTF-8")
-	hideWindow(cmd)
-	output, err := cmd.Output()
-	if err != nil {
-		// 尝试获取已暂存的改动
-		cmd = exec.CommandContext(timeoutCtx, "git", "diff", "--cached", "--", relPath)
-		cmd.Dir = dir
-		cmd.Env = append(os.Environ(), "LC_ALL=C.UTF-8", "LANG=C.UTF-8")
-		hideWindow(cmd)
-		output, err = cmd.Output()
-		if err != nil {
-			return "", 0, 0, fmt.Errorf("执行 git diff 失败: %w", err)
-		}
-	}
-
-	diffContent := string(output)
-	if diffContent == "" {
-		return "", 0, 0, nil
-	}
-
-	// 统计行数
-	linesAdded, linesDeleted := c.countDiffLines(diffContent)
-
-	return diffContent, linesAdded, linesDeleted, nil
-}
-
-// countDiffLines 统计 Diff 的增删行数
-func (c *DiffCollector) countDiffLines(diff string) (added, deleted int) {
-	lines := strings.Split(diff, "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
-			added++
-		} else if strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---") {
-			deleted++
-		}
-	}
-	return
-}
@@ -1,11 +1,14 @@
-//go:build windows
-
 package collector
 
 import (
+	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
 )
 
 func TestDiffCollector_findGitRoot_supportsGitFile(t *testing.T) {
@@ -62,3 +65,198 @@ func TestDiffCollector_findGitRoot_supportsGitDir(t *testing.T) {
 	}
 }
 
+// initTestRepo 创建带一次提交的临时仓库；环境中没有 git 时跳过
+func initTestRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	repo := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	runGit(t, repo, "init", "-q")
+	runGit(t, repo, "add", "-A")
+	runGit(t, repo, "commit", "-q", "-m", "init")
+	return repo
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	if out, err := gitCmd(dir, args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+// gitCmd 以固定身份执行 git（不依赖全局配置）
+func gitCmd(dir string, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	return cmd
+}
+
+func TestDiffCollector_captureDiff_emitsIncrementalDelta(t *testing.T) {
+	repo := initTestRepo(t, map[string]string{"main.go": "package main\n\nfunc a() {}\n"})
+	target := filepath.Join(repo, "main.go")
+	c := &DiffCollector{baselines: make(map[string]*fileBaseline), git: newGitBackend(GitBackendAuto)}
+	ctx := context.Background()
+
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			t.Fatalf("write target: %v", err)
+		}
+	}
+
+	// 第一次保存：没有基线，增量即相对 HEAD 的累计 diff
+	write("package main\n\nfunc a() {}\n\nfunc b() {}\n")
+	first, err := c.captureDiff(ctx, target)
+	if err != nil || first == nil {
+		t.Fatalf("first capture: diff=%v err=%v", first, err)
+	}
+	if first.LinesAdded != 2 || first.DiffContent != first.CumulativeContent {
+		t.Fatalf("first capture should equal cumulative diff, got +%d\n%s", first.LinesAdded, first.DiffContent)
+	}
+
+	// 第二次保存：只包含新增的 c()，累计视图仍包含 b()
+	write("package main\n\nfunc a() {}\n\nfunc b() {}\n\nfunc c() {}\n")
+	second, err := c.captureDiff(ctx, target)
+	if err != nil || second == nil {
+		t.Fatalf("second capture: diff=%v err=%v", second, err)
+	}
+	if second.LinesAdded != 2 || second.LinesDeleted != 0 {
+		t.Fatalf("expected +2/-0 delta, got +%d/-%d\n%s", second.LinesAdded, second.LinesDeleted, second.DiffContent)
+	}
+	if strings.Contains(second.DiffContent, "+func b()") || !strings.Contains(second.DiffContent, "+func c()") {
+		t.Fatalf("delta should only contain c():\n%s", second.DiffContent)
+	}
+	if !strings.Contains(second.CumulativeContent, "+func b()") {
+		t.Fatalf("cumulative view should keep b():\n%s", second.CumulativeContent)
+	}
+
+	// 未改动再次保存：不产生记录
+	if again, err := c.captureDiff(ctx, target); err != nil || again != nil {
+		t.Fatalf("unchanged save should emit nothing, got diff=%v err=%v", again, err)
+	}
+
+	// 回退到 HEAD：累计 diff 为空，但仍应记录删除的增量
+	write("package main\n\nfunc a() {}\n")
+	reverted, err := c.captureDiff(ctx, target)
+	if err != nil || reverted == nil {
+		t.Fatalf("revert capture: diff=%v err=%v", reverted, err)
+	}
+	if reverted.LinesDeleted != 4 || reverted.CumulativeContent != "" {
+		t.Fatalf("expected -4 delta with empty cumulative, got -%d cumulative=%q", reverted.LinesDeleted, reverted.CumulativeContent)
+	}
+
+	// 提交后基线重置为新的 HEAD
+	write("package main\n\nfunc a() {}\n\nfunc d() {}\n")
+	if _, err := c.captureDiff(ctx, target); err != nil {
+		t.Fatalf("capture before commit: %v", err)
+	}
+	runGit(t, repo, "commit", "-q", "-am", "add d")
+	write("package main\n\nfunc a() {}\n\nfunc d() {}\n\nfunc e() {}\n")
+	afterCommit, err := c.captureDiff(ctx, target)
+	if err != nil || afterCommit == nil {
+		t.Fatalf("capture after commit: diff=%v err=%v", afterCommit, err)
+	}
+	if afterCommit.DiffContent != afterCommit.CumulativeContent || strings.Contains(afterCommit.DiffContent, "+func d()") {
+		t.Fatalf("baseline should reset to new HEAD:\n%s", afterCommit.DiffContent)
+	}
+}
+
+func TestDiffCollector_captureDiff_seedsBaselineAfterRestart(t *testing.T) {
+	repo := initTestRepo(t, map[string]string{"main.go": "package main\n\nfunc a() {}\n"})
+	target := filepath.Join(repo, "main.go")
+	ctx := context.Background()
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			t.Fatalf("write target: %v", err)
+		}
+	}
+	// 模拟落库：记录每个文件最后一条 Diff
+	stored := make(map[string]*schema.Diff)
+	newCollector := func() *DiffCollector {
+		return &DiffCollector{
+			baselines: make(map[string]*fileBaseline),
+			git:       newGitBackend(GitBackendAuto),
+			lastCumulative: func(filePath string) (string, int64) {
+				if d := stored[filePath]; d != nil {
+					return d.CumulativeContent, d.Timestamp
+				}
+				return "", 0
+			},
+		}
+	}
+
+	write("package main\n\nfunc a() {}\n\nfunc b() {}\n")
+	first, err := newCollector().captureDiff(ctx, target)
+	if err != nil || first == nil {
+		t.Fatalf("first capture: diff=%v err=%v", first, err)
+	}
+	stored[target] = first
+
+	// 重启后未改动再次保存：不产生记录
+	if again, err := newCollector().captureDiff(ctx, target); err != nil || again != nil {
+		t.Fatalf("unchanged save after restart should emit nothing, got diff=%v err=%v", again, err)
+	}
+
+	// 重启后继续修改：只包含新增的 c()
+	write("package main\n\nfunc a() {}\n\nfunc b() {}\n\nfunc c() {}\n")
+	next, err := newCollector().captureDiff(ctx, target)
+	if err != nil || next == nil {
+		t.Fatalf("capture after restart: diff=%v err=%v", next, err)
+	}
+	if next.LinesAdded != 2 || next.LinesDeleted != 0 || strings.Contains(next.DiffContent, "+func b()") {
+		t.Fatalf("expected only c() after restart, got +%d/-%d\n%s", next.LinesAdded, next.LinesDeleted, next.DiffContent)
+	}
+	stored[target] = next
+
+	// 提交后旧记录不再作为基线
+	runGit(t, repo, "commit", "-q", "-am", "add b c")
+	write("package main\n\nfunc a() {}\n\nfunc b() {}\n\nfunc c() {}\n\nfunc d() {}\n")
+	afterCommit, err := newCollector().captureDiff(ctx, target)
+	if err != nil || afterCommit == nil {
+		t.Fatalf("capture after commit: diff=%v err=%v", afterCommit, err)
+	}
+	if afterCommit.DiffContent != afterCommit.CumulativeContent {
+		t.Fatalf("baseline should reset to new HEAD:\n%s", afterCommit.DiffContent)
+	}
+}
+
+func TestReadGitHead_resolvesPackedRefs(t *testing.T) {
+	repo := t.TempDir()
+	gitDir := filepath.Join(repo, ".git")
+	if err := os.MkdirAll(filepath.Join(gitDir, "refs", "heads"), 0o755); err != nil {
+		t.Fatalf("mkdir refs: %v", err)
+	}
+	mustWrite := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+
+	mustWrite(filepath.Join(gitDir, "HEAD"), "ref: refs/heads/main\n")
+	if got := readGitHead(repo); got != "ref: refs/heads/main" {
+		t.Fatalf("unborn branch should fall back to ref name, got %q", got)
+	}
+
+	mustWrite(filepath.Join(gitDir, "packed-refs"), "# pack-refs with: peeled fully-peeled sorted\n1111111111111111111111111111111111111111 refs/heads/main\n")
+	if got := readGitHead(repo); got != "1111111111111111111111111111111111111111" {
+		t.Fatalf("expected packed ref hash, got %q", got)
+	}
+
+	// loose ref 优先于 packed-refs
+	mustWrite(filepath.Join(gitDir, "refs", "heads", "main"), "2222222222222222222222222222222222222222\n")
+	if got := readGitHead(repo); got != "2222222222222222222222222222222222222222" {
+		t.Fatalf("expected loose ref hash, got %q", got)
+	}
+}
@@ -0,0 +1,167 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// 监控方式
+const (
+	WatchModeAuto   = "auto"   // 优先 fsnotify，注册失败（如 inotify 上限）时回退轮询
+	WatchModeNotify = "notify" // 仅 fsnotify
+	WatchModePoll   = "poll"   // 仅轮询 mtime（网络盘、WSL 挂载等收不到事件的目录）
+)
+
+const (
+	defaultPollInterval = 10 * time.Second
+	defaultPollMaxFiles = 20000
+)
+
+// normalizeWatchMode 未知取值按 auto 处理
+func normalizeWatchMode(mode string) string {
+	switch m := strings.ToLower(strings.TrimSpace(mode)); m {
+	case WatchModeNotify, WatchModePoll:
+		return m
+	default:
+		return WatchModeAuto
+	}
+}
+
+// watchRoot 一个监控根的实际监控方式
+type watchRoot struct {
+	mode     string // notify | poll
+	fallback bool   // 由 auto 回退而来
+	poll     *pollState
+}
+
+// pollState 轮询扫描进度。每轮从根目录广度遍历，单次 tick 用完预算后下次从队列继续
+type pollState struct {
+	root   string
+	queue  []pollDir
+	files  map[string]pollEntry
+	cycle  int
+	primed bool // 完成过一整轮后才上报变化，避免首轮把所有文件当作修改
+}
+
+type pollDir struct {
+	dir      string
+	ruleRoot string
+	offset   int // 预算在目录中途用完时，下次从该条目继续（ReadDir 按名称排序）
+}
+
+type pollEntry struct {
+	modTime time.Time
+	size    int64
+	cycle   int
+}
+
+func newPollState(root string) *pollState {
+	return &pollState{root: root, files: make(map[string]pollEntry)}
+}
+
+// configuredWatchMode 监控根的配置方式：取包含该路径的最深一条覆盖，否则为全局设置
+func (c *DiffCollector) configuredWatchMode(root string) string {
+	mode, best := c.watchMode, ""
+	for p, m := range c.watchModes {
+		if _, ok := relUnder(p, root); ok && len(p) > len(best) {
+			mode, best = m, p
+		}
+	}
+	return normalizeWatchMode(mode)
+}
+
+// pollOnce 扫描所有轮询根，把 mtime/大小变化的文件当作写入事件处理
+func (c *DiffCollector) pollOnce(ctx context.Context) {
+	c.watchMu.Lock()
+	budget := c.pollMaxFiles
+	var changed []string
+	for _, r := range c.roots {
+		if r.poll == nil || budget <= 0 {
+			continue
+		}
+		files, used := c.scanPoll(r.poll, budget)
+		changed = append(changed, files...)
+		budget -= used
+	}
+	c.watchMu.Unlock()
+
+	for _, f := range changed {
+		c.handleFsEvent(ctx, fsnotify.Event{Name: f, Op: fsnotify.Write})
+	}
+}
+
+// scanPoll 在预算内继续本轮扫描，返回有变化的文件与已检查的文件数
+func (c *DiffCollector) scanPoll(p *pollState, budget int) ([]string, int) {
+	if len(p.queue) == 0 {
+		p.cycle++
+		p.queue = append(p.queue, pollDir{dir: p.root, ruleRoot: c.ruleRootOf(p.root, true)})
+	}
+
+	var changed []string
+	used := 0
+	for len(p.queue) > 0 && used < budget {
+		d := p.queue[0]
+		p.queue = p.queue[1:]
+		entries, err := os.ReadDir(d.dir)
+		if err != nil {
+			continue
+		}
+		ruleRoot := d.ruleRoot
+		if gitDirOf(d.dir) != "" {
+			ruleRoot = d.dir
+		}
+		for i := d.offset; i < len(entries); i++ {
+			if used >= budget {
+				p.queue = append([]pollDir{{dir: d.dir, ruleRoot: d.ruleRoot, offset: i}}, p.queue...)
+				break
+			}
+			e := entries[i]
+			path := filepath.Join(d.dir, e.Name())
+			if e.IsDir() {
+				if !strings.HasPrefix(e.Name(), ".") && !c.filter.ignoredEntry(ruleRoot, path, true) {
+					p.queue = append(p.queue, pollDir{dir: path, ruleRoot: ruleRoot})
+				}
+				continue
+			}
+			if !c.extensions[strings.ToLower(filepath.Ext(path))] {
+				continue
+			}
+			used++
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			prev, seen := p.files[path]
+			p.files[path] = pollEntry{modTime: info.ModTime(), size: info.Size(), cycle: p.cycle}
+			if p.primed && (!seen || !prev.modTime.Equal(info.ModTime()) || prev.size != info.Size()) {
+				changed = append(changed, path)
+			}
+		}
+	}
+
+	if len(p.queue) == 0 {
+		// 一轮结束：清理本轮未见到的（已删除）文件
+		for path, e := range p.files {
+			if e.cycle != p.cycle {
+				delete(p.files, path)
+			}
+		}
+		p.primed = true
+	}
+	return changed, used
+}
+
+// effectiveWatchMode 监控根当前的实际方式
+func (c *DiffCollector) effectiveWatchMode(root string) string {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	if r, ok := c.roots[root]; ok {
+		return r.mode
+	}
+	return ""
+}
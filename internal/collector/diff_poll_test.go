@@ -0,0 +1,104 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffCollector_pollDetectsChangesWithinBudget(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a/one.go", "b/two.go", "c/three.go", "node_modules/x/dep.js", "notes.txt"} {
+		writeTestFile(t, filepath.Join(root, filepath.FromSlash(name)), "package x\n")
+	}
+
+	cfg := DefaultDiffCollectorConfig()
+	cfg.WatchMode = WatchModePoll
+	cfg.PollMaxFiles = 1
+	c, err := NewDiffCollector(cfg)
+	if err != nil {
+		t.Fatalf("NewDiffCollector: %v", err)
+	}
+	t.Cleanup(func() { _ = c.watcher.Close() })
+	if err := c.AddWatchPath(root); err != nil {
+		t.Fatalf("AddWatchPath: %v", err)
+	}
+	st := c.Stats()
+	if st.WatchModes[root] != WatchModePoll || st.WatchedDirs != 0 {
+		t.Fatalf("expected poll mode without fsnotify watches, got %+v", st)
+	}
+
+	p := c.roots[root].poll
+	scanCycle := func() []string {
+		t.Helper()
+		var changed []string
+		start := p.cycle
+		for i := 0; i < 10; i++ {
+			files, used := c.scanPoll(p, c.pollMaxFiles)
+			if used > c.pollMaxFiles {
+				t.Fatalf("scan used %d files, budget %d", used, c.pollMaxFiles)
+			}
+			changed = append(changed, files...)
+			if len(p.queue) == 0 && p.cycle > start {
+				return changed
+			}
+		}
+		t.Fatalf("poll cycle did not finish")
+		return nil
+	}
+
+	// 首轮只建立基线
+	if changed := scanCycle(); len(changed) != 0 {
+		t.Fatalf("first cycle should not report changes, got %v", changed)
+	}
+	if len(p.files) != 3 {
+		t.Fatalf("expected 3 tracked files (ignored dirs and other extensions skipped), got %d", len(p.files))
+	}
+
+	writeTestFile(t, filepath.Join(root, "b", "two.go"), "package x\n\nvar Y = 2\n")
+	writeTestFile(t, filepath.Join(root, "c", "four.go"), "package x\n")
+	if err := os.Remove(filepath.Join(root, "a", "one.go")); err != nil {
+		t.Fatal(err)
+	}
+	changed := scanCycle()
+	want := map[string]bool{filepath.Join(root, "b", "two.go"): true, filepath.Join(root, "c", "four.go"): true}
+	if len(changed) != len(want) {
+		t.Fatalf("changed = %v", changed)
+	}
+	for _, f := range changed {
+		if !want[f] {
+			t.Fatalf("unexpected change %s", f)
+		}
+	}
+	if _, ok := p.files[filepath.Join(root, "a", "one.go")]; ok {
+		t.Fatalf("deleted file should be dropped from poll state")
+	}
+}
+
+func TestDiffCollector_autoFallsBackToPoll(t *testing.T) {
+	root := t.TempDir()
+	notifyRoot := t.TempDir()
+
+	cfg := DefaultDiffCollectorConfig()
+	cfg.WatchModes = map[string]string{notifyRoot: WatchModeNotify}
+	c, err := NewDiffCollector(cfg)
+	if err != nil {
+		t.Fatalf("NewDiffCollector: %v", err)
+	}
+	// 关闭后 watcher.Add 必然失败，模拟 inotify 上限或网络盘不支持
+	_ = c.watcher.Close()
+
+	if err := c.AddWatchPath(root); err != nil {
+		t.Fatalf("AddWatchPath: %v", err)
+	}
+	if err := c.AddWatchPath(notifyRoot); err != nil {
+		t.Fatalf("AddWatchPath: %v", err)
+	}
+	st := c.Stats()
+	if st.WatchModes[root] != WatchModePoll || st.WatchFallbacks != 1 {
+		t.Fatalf("auto should fall back to poll, got %+v", st)
+	}
+	if st.WatchModes[notifyRoot] != WatchModeNotify {
+		t.Fatalf("notify mode should not fall back, got %+v", st)
+	}
+}
@@ -0,0 +1,146 @@
+package collector
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// startWatchRoot 按配置的监控方式注册监控根；auto 模式下 fsnotify 注册失败时改为轮询
+func (c *DiffCollector) startWatchRoot(root string) {
+	mode := c.configuredWatchMode(root)
+
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	if r, ok := c.roots[root]; ok && r.mode == WatchModePoll {
+		return
+	}
+	if mode == WatchModePoll {
+		c.roots[root] = &watchRoot{mode: WatchModePoll, poll: newPollState(root)}
+		return
+	}
+	c.roots[root] = &watchRoot{mode: WatchModeNotify}
+	if err := c.walkWatchTreeLocked(root, c.ruleRootOf(root, true)); err != nil && mode == WatchModeAuto {
+		c.fallbackToPollLocked(root, err)
+	}
+}
+
+// syncWatchTree 使 dir 子树的 fsnotify 监控与过滤规则一致：补充新目录、移除已被忽略的目录
+func (c *DiffCollector) syncWatchTree(dir string) {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+
+	watchRoot := c.watchRootOf(dir)
+	if r, ok := c.roots[watchRoot]; ok && r.mode == WatchModePoll {
+		return
+	}
+	root := c.ruleRootOf(dir, true)
+	if dir != watchRoot {
+		parentRoot := c.ruleRootOf(filepath.Dir(dir), true)
+		if strings.HasPrefix(filepath.Base(dir), ".") || c.filter.ignored(parentRoot, dir, true) {
+			c.unwatchTreeLocked(dir)
+			return
+		}
+	}
+	if err := c.walkWatchTreeLocked(dir, root); err != nil && c.configuredWatchMode(watchRoot) == WatchModeAuto {
+		c.fallbackToPollLocked(watchRoot, err)
+	}
+}
+
+// walkWatchTreeLocked 自上而下遍历，父目录已判定未忽略，子目录只需判断自身；返回首个注册失败
+func (c *DiffCollector) walkWatchTreeLocked(dir, root string) error {
+	if gitDirOf(dir) != "" {
+		// 嵌套仓库内只适用该仓库自己的忽略规则
+		root = dir
+	}
+	if _, ok := c.watched[dir]; !ok {
+		if err := c.watcher.Add(dir); err != nil {
+			slog.Warn("添加监控目录失败", "path", dir, "error", err)
+			return fmt.Errorf("添加监控目录失败: %w", err)
+		}
+		c.watched[dir] = struct{}{}
+		slog.Debug("添加监控目录", "path", dir)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var firstErr error
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		sub := filepath.Join(dir, e.Name())
+		// 隐藏目录（.git/.idea/.vscode 等）始终跳过
+		if strings.HasPrefix(e.Name(), ".") || c.filter.ignoredEntry(root, sub, true) {
+			c.unwatchTreeLocked(sub)
+			continue
+		}
+		if err := c.walkWatchTreeLocked(sub, root); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// fallbackToPollLocked 放弃该监控根的 fsnotify 监控，改为轮询
+func (c *DiffCollector) fallbackToPollLocked(root string, cause error) {
+	slog.Warn("文件监控注册失败，改为轮询", "path", root, "error", cause)
+	c.unwatchTreeLocked(root)
+	c.roots[root] = &watchRoot{mode: WatchModePoll, fallback: true, poll: newPollState(root)}
+}
+
+// unwatchTreeLocked 移除 dir 及其子目录的监控
+func (c *DiffCollector) unwatchTreeLocked(dir string) {
+	prefix := dir + string(filepath.Separator)
+	for d := range c.watched {
+		if d == dir || strings.HasPrefix(d, prefix) {
+			// 目录已删除时 fsnotify 会自行移除，错误可忽略
+			_ = c.watcher.Remove(d)
+			delete(c.watched, d)
+			slog.Debug("移除监控目录", "path", d)
+		}
+	}
+}
+
+// handleTreeEvent 处理影响监控范围的事件（目录增删改名、.gitignore 变化）；返回 true 表示事件已消费
+func (c *DiffCollector) handleTreeEvent(event fsnotify.Event) bool {
+	name := event.Name
+	if filepath.Base(name) == ".gitignore" {
+		c.filter.invalidate(name)
+		c.syncWatchTree(filepath.Dir(name))
+		return true
+	}
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		// 改名在新位置会产生 Create 事件，由下方分支重新加入
+		c.watchMu.Lock()
+		c.unwatchTreeLocked(name)
+		c.watchMu.Unlock()
+		return false
+	}
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(name); err == nil && info.IsDir() {
+			c.syncWatchTree(name)
+			return true
+		}
+	}
+	return false
+}
+
+// ruleRootOf 返回匹配忽略规则的根目录：所在仓库根，非 Git 目录为监控根
+func (c *DiffCollector) ruleRootOf(path string, isDir bool) string {
+	probe := path
+	if isDir {
+		// findGitRoot 从参数的父目录开始查找，目录本身可能就是仓库根
+		probe = filepath.Join(path, ".git")
+	}
+	if root, ok := c.findGitRoot(probe); ok {
+		return root
+	}
+	return c.watchRootOf(path)
+}
@@ -0,0 +1,95 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func newWatchTestCollector(t *testing.T) *DiffCollector {
+	t.Helper()
+	c, err := NewDiffCollector(DefaultDiffCollectorConfig())
+	if err != nil {
+		t.Fatalf("NewDiffCollector: %v", err)
+	}
+	t.Cleanup(func() { _ = c.watcher.Close() })
+	return c
+}
+
+func isWatched(c *DiffCollector, dir string) bool {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	_, ok := c.watched[dir]
+	return ok
+}
+
+func TestDiffCollector_watchTreeFollowsIgnoreRules(t *testing.T) {
+	repo := t.TempDir()
+	for _, d := range []string{".git", "src", "node_modules/lib", "tmp/cache", ".idea"} {
+		if err := os.MkdirAll(filepath.Join(repo, filepath.FromSlash(d)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(repo, ".gitignore"), "tmp/\n")
+
+	c := newWatchTestCollector(t)
+	if err := c.AddWatchPath(repo); err != nil {
+		t.Fatalf("AddWatchPath: %v", err)
+	}
+	for dir, want := range map[string]bool{
+		repo:                                true,
+		filepath.Join(repo, "src"):          true,
+		filepath.Join(repo, ".git"):         false,
+		filepath.Join(repo, ".idea"):        false,
+		filepath.Join(repo, "node_modules"): false,
+		filepath.Join(repo, "tmp"):          false,
+		filepath.Join(repo, "tmp", "cache"): false,
+	} {
+		if got := isWatched(c, dir); got != want {
+			t.Errorf("watched(%s) = %v, want %v", dir, got, want)
+		}
+	}
+
+	// 运行时新建的目录（含已存在的子目录）加入监控
+	created := filepath.Join(repo, "src", "feature", "impl")
+	if err := os.MkdirAll(created, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if !c.handleTreeEvent(fsnotify.Event{Name: filepath.Dir(created), Op: fsnotify.Create}) {
+		t.Fatalf("directory create event should be consumed")
+	}
+	if !isWatched(c, created) {
+		t.Fatalf("expected %s to be watched after create", created)
+	}
+
+	// 新建的被忽略目录不监控
+	ignored := filepath.Join(repo, "src", "build")
+	if err := os.MkdirAll(ignored, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	c.handleTreeEvent(fsnotify.Event{Name: ignored, Op: fsnotify.Create})
+	if isWatched(c, ignored) {
+		t.Fatalf("ignored directory %s should not be watched", ignored)
+	}
+
+	// 删除目录后移除整棵子树
+	if err := os.RemoveAll(filepath.Join(repo, "src", "feature")); err != nil {
+		t.Fatal(err)
+	}
+	c.handleTreeEvent(fsnotify.Event{Name: filepath.Join(repo, "src", "feature"), Op: fsnotify.Remove})
+	if isWatched(c, created) || isWatched(c, filepath.Dir(created)) {
+		t.Fatalf("removed directories should be unwatched")
+	}
+
+	// .gitignore 变化后重新同步：新忽略的 src 被移除，tmp 恢复监控
+	writeTestFile(t, filepath.Join(repo, ".gitignore"), "src/\n")
+	c.handleTreeEvent(fsnotify.Event{Name: filepath.Join(repo, ".gitignore"), Op: fsnotify.Write})
+	if isWatched(c, filepath.Join(repo, "src")) {
+		t.Fatalf("src should be unwatched after being ignored")
+	}
+	if !isWatched(c, filepath.Join(repo, "tmp", "cache")) {
+		t.Fatalf("tmp/cache should be watched after un-ignoring")
+	}
+}
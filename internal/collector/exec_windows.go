@@ -0,0 +1,14 @@
+package collector
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// hideWindow 设置 Windows 下隐藏命令行窗口
+func hideWindow(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: 0x08000000, // CREATE_NO_WINDOW
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !windows && !linux
+
+package collector
+
+import (
+	"errors"
+	"time"
+)
+
+var errForegroundUnsupported = errors.New("当前平台不支持前台窗口采集")
+
+// unsupportedSource 不支持的平台：始终无前台窗口，采集器只会形成空洞
+type unsupportedSource struct{}
+
+// DefaultForegroundSource 当前平台的前台窗口数据源
+func DefaultForegroundSource() ForegroundSource {
+	return unsupportedSource{}
+}
+
+func (unsupportedSource) ForegroundWindow() (*WindowInfo, error) {
+	return nil, ErrNoForegroundWindow
+}
+
+func (unsupportedSource) IdleDuration() (time.Duration, error) {
+	return 0, errForegroundUnsupported
+}
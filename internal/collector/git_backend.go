@@ -0,0 +1,166 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// GitBackend 计算 Git 仓库内文件的累计 diff：已跟踪文件为工作区相对暂存区，未跟踪文件视为整体新增。
+// 返回 unified diff 内容与增删行数；无改动时返回空字符串。
+type GitBackend interface {
+	Name() string
+	FileDiff(ctx context.Context, repoRoot, filePath string) (string, int, int, error)
+}
+
+// Git 后端名称（diff.git_backend）
+const (
+	GitBackendAuto   = "auto"   // 优先纯 Go，遇到不支持的仓库形态时回退到 git 命令
+	GitBackendNative = "native" // 仅纯 Go
+	GitBackendExec   = "exec"   // 仅 git 命令
+)
+
+// newGitBackend 按名称创建后端；未知名称按 auto 处理
+func newGitBackend(name string) GitBackend {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case GitBackendNative:
+		return newNativeGitBackend()
+	case GitBackendExec:
+		return execGitBackend{}
+	case GitBackendAuto, "":
+	default:
+		slog.Warn("未知的 Git 后端，使用 auto", "backend", name)
+	}
+	return &fallbackGitBackend{primary: newNativeGitBackend(), fallback: execGitBackend{}}
+}
+
+// fallbackGitBackend primary 出错时改用 fallback
+type fallbackGitBackend struct {
+	primary   GitBackend
+	fallback  GitBackend
+	fallbacks atomic.Int64
+}
+
+func (b *fallbackGitBackend) Name() string { return GitBackendAuto }
+
+func (b *fallbackGitBackend) FileDiff(ctx context.Context, repoRoot, filePath string) (string, int, int, error) {
+	content, added, deleted, err := b.primary.FileDiff(ctx, repoRoot, filePath)
+	if err == nil {
+		return content, added, deleted, nil
+	}
+	b.fallbacks.Add(1)
+	slog.Debug("Git 后端回退", "from", b.primary.Name(), "to", b.fallback.Name(), "file", filePath, "error", err)
+	return b.fallback.FileDiff(ctx, repoRoot, filePath)
+}
+
+// Fallbacks 回退次数（用于状态页诊断）
+func (b *fallbackGitBackend) Fallbacks() int64 { return b.fallbacks.Load() }
+
+// execGitBackend 调用 git 命令（ls-files + diff），依赖 PATH 中的 git
+type execGitBackend struct{}
+
+func (execGitBackend) Name() string { return GitBackendExec }
+
+func (execGitBackend) FileDiff(ctx context.Context, repoRoot, filePath string) (string, int, int, error) {
+	// 统一在仓库根目录执行 git
+	dir := repoRoot
+	if dir == "" {
+		dir = filepath.Dir(filePath)
+	}
+
+	// 超时保护
+	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	relPath := gitRelPath(repoRoot, filePath)
+
+	checkCmd := exec.CommandContext(timeoutCtx, "git", "ls-files", "--", relPath)
+	checkCmd.Dir = dir
+	// 设置 UTF-8 环境变量，解决中文路径问题
+	checkCmd.Env = append(os.Environ(), "LC_ALL=C.UTF-8", "LANG=C.UTF-8")
+	hideWindow(checkCmd)
+	checkOutput, err := checkCmd.CombinedOutput()
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("执行 git ls-files 失败: %w: %s", err, strings.TrimSpace(string(checkOutput)))
+	}
+
+	if strings.TrimSpace(string(checkOutput)) == "" {
+		return untrackedFileDiff(filePath)
+	}
+
+	// 获取未暂存的改动
+	cmd := exec.CommandContext(timeoutCtx, "git", "diff", "--", relPath)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "LC_ALL=C.UTF-8", "LANG=C.UTF-8")
+	hideWindow(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		// 尝试获取已暂存的改动
+		cmd = exec.CommandContext(timeoutCtx, "git", "diff", "--cached", "--", relPath)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "LC_ALL=C.UTF-8", "LANG=C.UTF-8")
+		hideWindow(cmd)
+		output, err = cmd.Output()
+		if err != nil {
+			return "", 0, 0, fmt.Errorf("执行 git diff 失败: %w", err)
+		}
+	}
+
+	diffContent := string(output)
+	if diffContent == "" {
+		return "", 0, 0, nil
+	}
+
+	linesAdded, linesDeleted := countDiffLines(diffContent)
+	return diffContent, linesAdded, linesDeleted, nil
+}
+
+// gitRelPath 仓库内相对路径（正斜杠，Git 兼容格式）
+func gitRelPath(repoRoot, filePath string) string {
+	relPath := filePath
+	if repoRoot != "" {
+		if p, err := filepath.Rel(repoRoot, filePath); err == nil {
+			relPath = p
+		}
+	}
+	return filepath.ToSlash(relPath)
+}
+
+// untrackedFileDiff 新文件：读取整个文件内容作为 diff
+func untrackedFileDiff(filePath string) (string, int, int, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("读取新文件失败: %w", err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- /dev/null\n+++ b/%s\n@@ -0,0 +1,%d @@\n", filepath.Base(filePath), len(lines))
+	for _, line := range lines {
+		sb.WriteString("+")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	slog.Debug("检测到新文件", "file", filePath, "lines", len(lines))
+	return sb.String(), len(lines), 0, nil
+}
+
+// countDiffLines 统计 Diff 的增删行数
+func countDiffLines(diff string) (added, deleted int) {
+	lines := strings.Split(diff, "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
+			added++
+		} else if strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---") {
+			deleted++
+		}
+	}
+	return
+}
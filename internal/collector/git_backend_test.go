@@ -0,0 +1,267 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// changedLines 只保留 +/- 行，用于比较两种后端的输出（hunk 划分可能因算法不同而略有差异）
+func changedLines(diff string) []string {
+	var out []string
+	for _, line := range strings.Split(diff, "\n") {
+		if (strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++")) ||
+			(strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---")) {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+func assertBackendsAgree(t *testing.T, repo, file string) string {
+	t.Helper()
+	ctx := context.Background()
+	native := newNativeGitBackend()
+	want, wantAdded, wantDeleted, err := execGitBackend{}.FileDiff(ctx, repo, file)
+	if err != nil {
+		t.Fatalf("exec FileDiff: %v", err)
+	}
+	got, added, deleted, err := native.FileDiff(ctx, repo, file)
+	if err != nil {
+		t.Fatalf("native FileDiff: %v", err)
+	}
+	if added != wantAdded || deleted != wantDeleted {
+		t.Fatalf("native +%d/-%d, exec +%d/-%d\nnative:\n%s\nexec:\n%s", added, deleted, wantAdded, wantDeleted, got, want)
+	}
+	if g, w := strings.Join(changedLines(got), "\n"), strings.Join(changedLines(want), "\n"); g != w {
+		t.Fatalf("changed lines differ\nnative:\n%s\nexec:\n%s", got, want)
+	}
+	if (got == "") != (want == "") {
+		t.Fatalf("emptiness differs: native=%q exec=%q", got, want)
+	}
+	return got
+}
+
+func TestNativeGitBackend_matchesExec(t *testing.T) {
+	repo := initTestRepo(t, map[string]string{
+		"main.go": "package main\n\nfunc a() {}\n\nfunc b() {}\n",
+		"util.go": "package main\n",
+	})
+	target := filepath.Join(repo, "main.go")
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	t.Run("clean", func(t *testing.T) {
+		if got := assertBackendsAgree(t, repo, target); got != "" {
+			t.Fatalf("clean file should have no diff, got:\n%s", got)
+		}
+	})
+
+	t.Run("loose objects", func(t *testing.T) {
+		write("main.go", "package main\n\nfunc a() { return }\n\nfunc c() {}\n")
+		got := assertBackendsAgree(t, repo, target)
+		if !strings.HasPrefix(got, "diff --git a/main.go b/main.go\nindex ") {
+			t.Fatalf("missing git-style header:\n%s", got)
+		}
+	})
+
+	t.Run("untracked", func(t *testing.T) {
+		write("new.go", "package main\n\nfunc n() {}\n")
+		assertBackendsAgree(t, repo, filepath.Join(repo, "new.go"))
+	})
+
+	t.Run("staged then edited", func(t *testing.T) {
+		runGit(t, repo, "add", "main.go")
+		write("main.go", "package main\n\nfunc a() { return }\n\nfunc c() {}\n\nfunc d() {}\n")
+		assertBackendsAgree(t, repo, target)
+	})
+
+	t.Run("packed with deltas", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			var sb strings.Builder
+			sb.WriteString("package main\n\n")
+			for j := 0; j < 40; j++ {
+				fmt.Fprintf(&sb, "func f%d_%d() {}\n", j, i%2)
+			}
+			write("util.go", sb.String())
+			runGit(t, repo, "commit", "-q", "-am", fmt.Sprintf("rev %d", i))
+		}
+		runGit(t, repo, "gc", "-q", "--aggressive")
+		if matches, _ := filepath.Glob(filepath.Join(repo, ".git", "objects", "pack", "*.idx")); len(matches) == 0 {
+			t.Fatalf("expected a packfile after gc")
+		}
+		write("util.go", "package main\n\nfunc only() {}\n")
+		assertBackendsAgree(t, repo, filepath.Join(repo, "util.go"))
+	})
+
+	t.Run("index v4", func(t *testing.T) {
+		runGit(t, repo, "update-index", "--index-version", "4")
+		write("util.go", "package main\n\nfunc v4() {}\n")
+		assertBackendsAgree(t, repo, filepath.Join(repo, "util.go"))
+		assertBackendsAgree(t, repo, target)
+	})
+}
+
+func TestNativeGitBackend_subdirectoryAndBinary(t *testing.T) {
+	repo := initTestRepo(t, map[string]string{"README.md": "# demo\n"})
+	if err := os.MkdirAll(filepath.Join(repo, "pkg", "deep"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	nested := filepath.Join(repo, "pkg", "deep", "x.go")
+	if err := os.WriteFile(nested, []byte("package deep\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bin := filepath.Join(repo, "blob.bin")
+	if err := os.WriteFile(bin, []byte{0, 1, 2, 3}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "add", "-A")
+	runGit(t, repo, "commit", "-q", "-m", "more")
+
+	if err := os.WriteFile(nested, []byte("package deep\n\nvar X = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	assertBackendsAgree(t, repo, nested)
+
+	if err := os.WriteFile(bin, []byte{0, 9, 9}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got := assertBackendsAgree(t, repo, bin)
+	if !strings.Contains(got, "Binary files a/blob.bin and b/blob.bin differ") {
+		t.Fatalf("binary change should be reported without hunks:\n%s", got)
+	}
+}
+
+func TestNativeGitBackend_conflictDiffsAgainstHead(t *testing.T) {
+	repo := initTestRepo(t, map[string]string{"a.go": "package a\n\nvar V = 0\n"})
+	target := filepath.Join(repo, "a.go")
+	runGit(t, repo, "checkout", "-q", "-b", "other")
+	if err := os.WriteFile(target, []byte("package a\n\nvar V = 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "commit", "-q", "-am", "other")
+	runGit(t, repo, "checkout", "-q", "-")
+	if err := os.WriteFile(target, []byte("package a\n\nvar V = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "commit", "-q", "-am", "ours")
+	// 合并冲突：git merge 以非零状态退出，忽略错误
+	cmd := gitCmd(repo, "merge", "-q", "other")
+	_ = cmd.Run()
+
+	if err := os.WriteFile(target, []byte("package a\n\nvar V = 3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, added, deleted, err := newNativeGitBackend().FileDiff(context.Background(), repo, target)
+	if err != nil {
+		t.Fatalf("native FileDiff: %v", err)
+	}
+	if added != 1 || deleted != 1 || !strings.Contains(got, "-var V = 1") || !strings.Contains(got, "+var V = 3") {
+		t.Fatalf("expected diff against HEAD, got +%d/-%d:\n%s", added, deleted, got)
+	}
+}
+
+func TestNewGitBackend(t *testing.T) {
+	cases := map[string]string{
+		"":       GitBackendAuto,
+		"auto":   GitBackendAuto,
+		"native": GitBackendNative,
+		"EXEC":   GitBackendExec,
+		"bogus":  GitBackendAuto,
+	}
+	for in, want := range cases {
+		if got := newGitBackend(in).Name(); got != want {
+			t.Fatalf("newGitBackend(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestApplyGitDelta(t *testing.T) {
+	base := []byte("hello, world\n")
+	// src=13 dst=19；copy base[0:7]，insert "there, "，copy base[7:13]
+	delta := []byte{13, 20, 0x90, 7, 7}
+	delta = append(delta, []byte("there, ")...)
+	delta = append(delta, 0x91, 7, 6)
+	got, err := applyGitDelta(base, delta)
+	if err != nil {
+		t.Fatalf("applyGitDelta: %v", err)
+	}
+	if string(got) != "hello, there, world\n" {
+		t.Fatalf("got %q", got)
+	}
+	if _, err := applyGitDelta([]byte("short"), delta); err == nil {
+		t.Fatalf("expected error for mismatched base size")
+	}
+}
+
+// newBenchRepo 生成较大的仓库（大量文件、已打包），并修改其中一个文件
+func newBenchRepo(b *testing.B, files int) (string, string) {
+	b.Helper()
+	repo := b.TempDir()
+	if out, err := gitCmd(repo, "init", "-q").CombinedOutput(); err != nil {
+		b.Skipf("git init: %v: %s", err, out)
+	}
+	for i := 0; i < files; i++ {
+		dir := filepath.Join(repo, fmt.Sprintf("pkg%03d", i/100))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			b.Fatal(err)
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "package pkg%03d\n\n", i/100)
+		for j := 0; j < 60; j++ {
+			fmt.Fprintf(&sb, "func F%d_%d() int { return %d }\n", i, j, i*j)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%05d.go", i)), []byte(sb.String()), 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", "init"}, {"gc", "-q"}} {
+		if out, err := gitCmd(repo, args...).CombinedOutput(); err != nil {
+			b.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	target := filepath.Join(repo, "pkg000", "f00042.go")
+	content, err := os.ReadFile(target)
+	if err != nil {
+		b.Fatal(err)
+	}
+	edited := strings.Replace(string(content), "return 42", "return -42", 1) + "\nfunc Added() {}\n"
+	if err := os.WriteFile(target, []byte(edited), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	return repo, target
+}
+
+// BenchmarkGitBackend_FileDiff 单次保存的 diff 耗时（go test -bench GitBackend -run ^$ ./internal/collector/）
+func BenchmarkGitBackend_FileDiff(b *testing.B) {
+	repo, target := newBenchRepo(b, 5000)
+	ctx := context.Background()
+
+	run := func(b *testing.B, backend func() GitBackend) {
+		be := backend()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, added, _, err := be.FileDiff(ctx, repo, target); err != nil || added == 0 {
+				b.Fatalf("FileDiff: added=%d err=%v", added, err)
+			}
+		}
+	}
+	b.Run("exec", func(b *testing.B) { run(b, func() GitBackend { return execGitBackend{} }) })
+	b.Run("native", func(b *testing.B) { run(b, func() GitBackend { return newNativeGitBackend() }) })
+	// 每次都重新解析 index 与 pack 索引（等价于每次保存前都发生了 git add/commit）
+	b.Run("native_cold", func(b *testing.B) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, _, _, err := newNativeGitBackend().FileDiff(ctx, repo, target); err != nil {
+				b.Fatalf("FileDiff: %v", err)
+			}
+		}
+	})
+}
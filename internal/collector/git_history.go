@@ -0,0 +1,223 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+// defaultHistoryMaxFileDiff 单个文件 diff 超出该大小时只保留统计（生成代码、锁文件等）
+const defaultHistoryMaxFileDiff = 64 << 10
+
+// GitHistoryOptions 历史提交读取范围
+type GitHistoryOptions struct {
+	Since       time.Time
+	Until       time.Time
+	AuthorEmail string   // 为空时使用仓库配置的 user.email；仍为空则不过滤作者
+	Extensions  []string // 只保留这些扩展名的文件 diff（为空不过滤）
+	MaxFileDiff int      // 单文件 diff 字节上限
+}
+
+// HistoricalCommit 一个历史提交及其逐文件 diff
+type HistoricalCommit struct {
+	Commit *schema.GitCommit
+	Files  []HistoricalFileDiff
+}
+
+// HistoricalFileDiff 提交中单个文件的改动
+type HistoricalFileDiff struct {
+	Path         string // 相对仓库根目录（正斜杠）
+	Diff         string // 超出上限时为空
+	LinesAdded   int
+	LinesDeleted int
+}
+
+// ReadGitHistory 按时间倒序流式读取仓库历史（不含合并提交），每个提交回调一次；fn 返回错误时中止
+func ReadGitHistory(ctx context.Context, repo string, opts GitHistoryOptions, fn func(*HistoricalCommit) error) error {
+	email := strings.TrimSpace(opts.AuthorEmail)
+	if email == "" {
+		email = gitUserEmail(ctx, repo)
+	}
+	maxDiff := opts.MaxFileDiff
+	if maxDiff <= 0 {
+		maxDiff = defaultHistoryMaxFileDiff
+	}
+	exts := make(map[string]bool, len(opts.Extensions))
+	for _, ext := range opts.Extensions {
+		exts[strings.ToLower(ext)] = true
+	}
+
+	args := []string{"-c", "core.quotepath=off", "log", "--no-merges", "--branches",
+		gitLogFormat, "-p", "--no-color", "--no-ext-diff", "--no-renames", "-U3"}
+	if !opts.Since.IsZero() {
+		args = append(args, fmt.Sprintf("--since=%d", opts.Since.Unix()))
+	}
+	if !opts.Until.IsZero() {
+		args = append(args, fmt.Sprintf("--until=%d", opts.Until.Unix()))
+	}
+	if email != "" {
+		// --author 只做粗筛（子串匹配“姓名 <邮箱>”），精确比较在下面
+		args = append(args, "--author="+email, "--fixed-strings", "--regexp-ignore-case")
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(), "LC_ALL=C.UTF-8", "LANG=C.UTF-8")
+	hideWindow(cmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("执行 git log 失败: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("执行 git log 失败: %w", err)
+	}
+
+	readErr := func() error {
+		r := bufio.NewReaderSize(stdout, 64<<10)
+		for {
+			rec, err := r.ReadString('\x1e')
+			rec = strings.TrimSuffix(rec, "\x1e")
+			if strings.TrimSpace(rec) != "" {
+				hc := parseHistoryRecord(rec, exts, maxDiff)
+				if hc != nil && (email == "" || strings.EqualFold(hc.Commit.AuthorEmail, email)) {
+					hc.Commit.RepoPath = repo
+					if err := fn(hc); err != nil {
+						return err
+					}
+				}
+			}
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("读取 git log 输出失败: %w", err)
+			}
+		}
+	}()
+	if readErr != nil {
+		// 提前中止时结束子进程，避免阻塞在写管道上
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return readErr
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("执行 git log 失败: %w", err)
+	}
+	return nil
+}
+
+// parseHistoryRecord 解析 gitLogFormat + -p 的单条记录
+func parseHistoryRecord(rec string, exts map[string]bool, maxDiff int) *HistoricalCommit {
+	fields := strings.SplitN(rec, "\x1f", 8)
+	if len(fields) < 8 {
+		return nil
+	}
+	authorSec, _ := strconv.ParseInt(strings.TrimSpace(fields[4]), 10, 64)
+	commit := &schema.GitCommit{
+		Hash:        strings.TrimSpace(fields[0]),
+		Branch:      strings.TrimPrefix(strings.TrimSpace(fields[1]), "refs/heads/"),
+		AuthorName:  strings.TrimSpace(fields[2]),
+		AuthorEmail: strings.TrimSpace(fields[3]),
+		Timestamp:   authorSec * 1000,
+		Subject:     strings.TrimSpace(fields[5]),
+		Body:        strings.TrimSpace(fields[6]),
+		Files:       schema.JSONArray{},
+	}
+	if commit.Hash == "" {
+		return nil
+	}
+
+	hc := &HistoricalCommit{Commit: commit}
+	for _, section := range splitPatchFiles(fields[7]) {
+		path, added, deleted := parsePatchSection(section)
+		if path == "" {
+			continue
+		}
+		commit.Files = append(commit.Files, path)
+		commit.LinesAdded += added
+		commit.LinesDeleted += deleted
+		if len(exts) > 0 && !exts[strings.ToLower(filepath.Ext(path))] {
+			continue
+		}
+		if added+deleted == 0 {
+			// 二进制文件或仅权限变化
+			continue
+		}
+		fd := HistoricalFileDiff{Path: path, LinesAdded: added, LinesDeleted: deleted}
+		if len(section) <= maxDiff {
+			fd.Diff = section
+		}
+		hc.Files = append(hc.Files, fd)
+	}
+	commit.FilesChanged = len(commit.Files)
+	return hc
+}
+
+// splitPatchFiles 按 "diff --git" 切分为逐文件的 patch
+func splitPatchFiles(patch string) []string {
+	var out []string
+	for {
+		start := strings.Index(patch, "diff --git ")
+		if start < 0 {
+			return out
+		}
+		patch = patch[start:]
+		next := strings.Index(patch[1:], "\ndiff --git ")
+		if next < 0 {
+			out = append(out, strings.TrimRight(patch, "\n")+"\n")
+			return out
+		}
+		out = append(out, patch[:next+2])
+		patch = patch[next+2:]
+	}
+}
+
+// parsePatchSection 取出文件路径（删除的文件取旧路径）与增删行数
+func parsePatchSection(section string) (string, int, int) {
+	var oldPath, newPath string
+	added, deleted := 0, 0
+	inHunk := false
+	for _, line := range strings.Split(section, "\n") {
+		switch {
+		case !inHunk && strings.HasPrefix(line, "--- "):
+			oldPath = patchPath(line[4:], "a/")
+		case !inHunk && strings.HasPrefix(line, "+++ "):
+			newPath = patchPath(line[4:], "b/")
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case inHunk && strings.HasPrefix(line, "+"):
+			added++
+		case inHunk && strings.HasPrefix(line, "-"):
+			deleted++
+		}
+	}
+	if newPath != "" {
+		return newPath, added, deleted
+	}
+	if oldPath != "" {
+		return oldPath, added, deleted
+	}
+	// 没有 ---/+++ 行（二进制、空文件、仅模式变化）：从首行 "diff --git a/x b/x" 取路径
+	header, _, _ := strings.Cut(section, "\n")
+	if i := strings.LastIndex(header, " b/"); i >= 0 {
+		return header[i+3:], 0, 0
+	}
+	return "", 0, 0
+}
+
+func patchPath(s, prefix string) string {
+	s = strings.TrimRight(s, "\t\r")
+	if s == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(s, prefix)
+}
@@ -0,0 +1,90 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadGitHistory_filtersAuthorAndExtensions(t *testing.T) {
+	repo := initTestRepo(t, map[string]string{"main.go": "package main\n"})
+	writeTestFile(t, filepath.Join(repo, "main.go"), "package main\n\nfunc main() {}\n")
+	writeTestFile(t, filepath.Join(repo, "README.md"), "# demo\n")
+	runGit(t, repo, "add", "-A")
+	runGit(t, repo, "commit", "-q", "-m", "add main", "-m", "details")
+	writeTestFile(t, filepath.Join(repo, "main.go"), "package main\n\nfunc main() { println() }\n")
+	runGit(t, repo, "commit", "-q", "-a", "-m", "other change", "--author", "Other <other@example.com>")
+
+	var got []*HistoricalCommit
+	err := ReadGitHistory(context.Background(), repo, GitHistoryOptions{
+		AuthorEmail: "TEST@example.com",
+		Extensions:  []string{".go"},
+	}, func(hc *HistoricalCommit) error {
+		got = append(got, hc)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadGitHistory: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 commits by test@example.com, got %d", len(got))
+	}
+
+	latest := got[0].Commit
+	if latest.Subject != "add main" || latest.Body != "details" || latest.RepoPath != repo {
+		t.Fatalf("unexpected commit: %+v", latest)
+	}
+	if latest.FilesChanged != 2 || latest.LinesAdded != 3 || latest.LinesDeleted != 0 {
+		t.Fatalf("stats should cover all files: files=%d +%d -%d", latest.FilesChanged, latest.LinesAdded, latest.LinesDeleted)
+	}
+	if len(got[0].Files) != 1 {
+		t.Fatalf("README.md should be filtered by extension, got %+v", got[0].Files)
+	}
+	f := got[0].Files[0]
+	if f.Path != "main.go" || f.LinesAdded != 2 || !strings.HasPrefix(f.Diff, "diff --git a/main.go b/main.go") {
+		t.Fatalf("unexpected file diff: %+v", f)
+	}
+	if got[1].Commit.Subject != "init" {
+		t.Fatalf("expected init commit last, got %q", got[1].Commit.Subject)
+	}
+
+	// 时间范围外没有提交
+	n := 0
+	err = ReadGitHistory(context.Background(), repo, GitHistoryOptions{
+		AuthorEmail: "test@example.com",
+		Since:       time.Now().Add(time.Hour),
+	}, func(*HistoricalCommit) error { n++; return nil })
+	if err != nil || n != 0 {
+		t.Fatalf("expected no commits in the future, got %d (err=%v)", n, err)
+	}
+}
+
+func TestReadGitHistory_largeDiffKeepsStats(t *testing.T) {
+	repo := initTestRepo(t, map[string]string{"a.go": "package a\n"})
+	if err := os.Remove(filepath.Join(repo, "a.go")); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "commit", "-q", "-a", "-m", "remove a")
+
+	var got []*HistoricalCommit
+	err := ReadGitHistory(context.Background(), repo, GitHistoryOptions{
+		AuthorEmail: "test@example.com",
+		MaxFileDiff: 10,
+	}, func(hc *HistoricalCommit) error {
+		got = append(got, hc)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadGitHistory: %v", err)
+	}
+	if len(got) != 2 || len(got[0].Files) != 1 {
+		t.Fatalf("unexpected history: %+v", got)
+	}
+	f := got[0].Files[0]
+	if f.Path != "a.go" || f.LinesDeleted != 1 || f.Diff != "" {
+		t.Fatalf("deleted file should keep old path and stats without diff body: %+v", f)
+	}
+}
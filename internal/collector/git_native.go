@@ -0,0 +1,330 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// nativeGitBackend 纯 Go 后端：直接读取 .git/index 与对象库，在进程内生成 diff。
+// 暂存区与对象库按仓库缓存，index 文件变化（add/commit/checkout）时重新解析。
+type nativeGitBackend struct {
+	mu      sync.Mutex
+	indexes map[string]*gitIndex       // gitDir -> 已解析的 index
+	stores  map[string]*gitObjectStore // commonDir -> 对象库
+}
+
+func newNativeGitBackend() *nativeGitBackend {
+	return &nativeGitBackend{
+		indexes: make(map[string]*gitIndex),
+		stores:  make(map[string]*gitObjectStore),
+	}
+}
+
+func (b *nativeGitBackend) Name() string { return GitBackendNative }
+
+func (b *nativeGitBackend) FileDiff(ctx context.Context, repoRoot, filePath string) (string, int, int, error) {
+	gitDir := gitDirOf(repoRoot)
+	if gitDir == "" {
+		return "", 0, 0, fmt.Errorf("不是 Git 仓库: %s", repoRoot)
+	}
+	rel := gitRelPath(repoRoot, filePath)
+
+	idx, err := b.index(gitDir)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	entry, tracked := idx.entries[rel]
+	if !tracked {
+		if idx.conflicts[rel] {
+			// 冲突中的文件没有 stage 0 条目，改为与 HEAD 对比
+			return b.headFileDiff(gitDir, rel, filePath)
+		}
+		if idx.sparse {
+			return "", 0, 0, fmt.Errorf("%w: sparse index", errGitUnsupported)
+		}
+		return untrackedFileDiff(filePath)
+	}
+
+	current, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("读取文件失败: %w", err)
+	}
+	newHash := gitBlobHash(current)
+	if newHash == entry.hash {
+		return "", 0, 0, nil
+	}
+
+	store, err := b.store(gitDir)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	typ, old, err := store.read(entry.hash)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	if typ != gitObjBlob {
+		return "", 0, 0, fmt.Errorf("暂存区条目不是 blob: %s", rel)
+	}
+	return blobFileDiff(rel, entry.hash, newHash, entry.mode, old, current)
+}
+
+// headFileDiff 工作区文件相对 HEAD 中同路径 blob 的 diff
+func (b *nativeGitBackend) headFileDiff(gitDir, rel, filePath string) (string, int, int, error) {
+	head, ok := parseGitHash(resolveHeadCommit(gitDir))
+	if !ok {
+		return untrackedFileDiff(filePath)
+	}
+	store, err := b.store(gitDir)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	blob, mode, err := store.treeBlob(head, rel)
+	if os.IsNotExist(err) {
+		return untrackedFileDiff(filePath)
+	}
+	if err != nil {
+		return "", 0, 0, err
+	}
+	_, old, err := store.read(blob)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	current, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("读取文件失败: %w", err)
+	}
+	return blobFileDiff(rel, blob, gitBlobHash(current), mode, old, current)
+}
+
+// resolveHeadCommit 解析 HEAD 指向的提交；未出生的分支返回空字符串
+func resolveHeadCommit(gitDir string) string {
+	b, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(b))
+	if ref, ok := strings.CutPrefix(head, "ref:"); ok {
+		return resolveGitRef(gitDir, strings.TrimSpace(ref))
+	}
+	return head
+}
+
+// blobFileDiff 生成与 git diff 相同格式的文件头 + hunk
+func blobFileDiff(rel string, oldHash, newHash gitHash, mode uint32, old, current []byte) (string, int, int, error) {
+	header := fmt.Sprintf("diff --git a/%s b/%s\nindex %s..%s %o\n", rel, rel, oldHash.String()[:7], newHash.String()[:7], mode)
+	if isBinaryContent(old) || isBinaryContent(current) {
+		return header + fmt.Sprintf("Binary files a/%s and b/%s differ\n", rel, rel), 0, 0, nil
+	}
+	body, added, deleted := unifiedDiff("a/"+rel, "b/"+rel, string(old), string(current))
+	if body == "" {
+		// 仅换行符不同（autocrlf）等情况，git diff 同样不输出
+		return "", 0, 0, nil
+	}
+	return header + body, added, deleted, nil
+}
+
+// isBinaryContent 与 git 的判定一致：前 8000 字节内出现 NUL 即视为二进制
+func isBinaryContent(b []byte) bool {
+	if len(b) > 8000 {
+		b = b[:8000]
+	}
+	return bytes.IndexByte(b, 0) >= 0
+}
+
+func (b *nativeGitBackend) index(gitDir string) (*gitIndex, error) {
+	path := filepath.Join(gitDir, "index")
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// 尚未 add 过任何文件
+			return &gitIndex{}, nil
+		}
+		return nil, fmt.Errorf("读取 Git 暂存区失败: %w", err)
+	}
+
+	b.mu.Lock()
+	cached := b.indexes[gitDir]
+	b.mu.Unlock()
+	if cached != nil && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 Git 暂存区失败: %w", err)
+	}
+	idx, err := parseGitIndex(data)
+	if err != nil {
+		return nil, err
+	}
+	idx.modTime, idx.size = info.ModTime(), info.Size()
+
+	b.mu.Lock()
+	b.indexes[gitDir] = idx
+	b.mu.Unlock()
+	return idx, nil
+}
+
+func (b *nativeGitBackend) store(gitDir string) (*gitObjectStore, error) {
+	common := gitCommonDir(gitDir)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s, ok := b.stores[common]; ok {
+		return s, nil
+	}
+	s, err := newGitObjectStore(common)
+	if err != nil {
+		return nil, err
+	}
+	b.stores[common] = s
+	return s, nil
+}
+
+// gitCommonDir worktree 的对象库与配置位于 commondir
+func gitCommonDir(gitDir string) string {
+	b, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	common := strings.TrimSpace(string(b))
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(gitDir, common)
+	}
+	return filepath.Clean(common)
+}
+
+// gitIndex 暂存区中 stage 0 的条目
+type gitIndex struct {
+	entries   map[string]gitIndexEntry
+	conflicts map[string]bool // 只有冲突 stage 的路径
+	sparse    bool            // sparse index：部分目录折叠为单个条目
+	modTime   time.Time
+	size      int64
+}
+
+type gitIndexEntry struct {
+	hash gitHash
+	mode uint32
+}
+
+const (
+	gitIndexEntryFixedLen = 62 // ctime/mtime/dev/ino/mode/uid/gid/size + hash + flags
+	gitIndexFlagExtended  = 0x4000
+	gitIndexNameMask      = 0x0fff
+)
+
+// parseGitIndex 解析 .git/index（v2/v3/v4）
+func parseGitIndex(data []byte) (*gitIndex, error) {
+	if len(data) < 12+sha1.Size || string(data[:4]) != "DIRC" {
+		return nil, fmt.Errorf("Git 暂存区格式无效")
+	}
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("%w: index v%d", errGitUnsupported, version)
+	}
+	count := int(binary.BigEndian.Uint32(data[8:12]))
+	end := len(data) - sha1.Size
+
+	idx := &gitIndex{
+		entries:   make(map[string]gitIndexEntry, count),
+		conflicts: make(map[string]bool),
+	}
+	pos := 12
+	var prevName []byte
+	for i := 0; i < count; i++ {
+		if pos+gitIndexEntryFixedLen > end {
+			return nil, fmt.Errorf("Git 暂存区被截断")
+		}
+		e := data[pos:]
+		mode := binary.BigEndian.Uint32(e[24:28])
+		var h gitHash
+		copy(h[:], e[40:60])
+		flags := binary.BigEndian.Uint16(e[60:62])
+		headerLen := gitIndexEntryFixedLen
+		if version >= 3 && flags&gitIndexFlagExtended != 0 {
+			headerLen += 2
+		}
+		nameStart := pos + headerLen
+
+		var name []byte
+		if version == 4 {
+			// 路径前缀压缩：先是需从上一条路径末尾去掉的字节数，再是以 NUL 结尾的后缀
+			strip, n := decodeGitOffsetVarint(data[nameStart:end])
+			if n == 0 || int(strip) > len(prevName) {
+				return nil, fmt.Errorf("Git 暂存区路径无效")
+			}
+			nul := bytes.IndexByte(data[nameStart+n:end], 0)
+			if nul < 0 {
+				return nil, fmt.Errorf("Git 暂存区路径无效")
+			}
+			name = append(append([]byte(nil), prevName[:len(prevName)-int(strip)]...), data[nameStart+n:nameStart+n+nul]...)
+			pos = nameStart + n + nul + 1
+		} else {
+			nameLen := int(flags & gitIndexNameMask)
+			if nameLen == gitIndexNameMask {
+				nameLen = bytes.IndexByte(data[nameStart:end], 0)
+			}
+			if nameLen < 0 || nameStart+nameLen > end {
+				return nil, fmt.Errorf("Git 暂存区路径无效")
+			}
+			name = data[nameStart : nameStart+nameLen]
+			// 条目按 8 字节对齐，至少一个 NUL
+			pos += (headerLen + nameLen + 8) &^ 7
+		}
+		prevName = name
+
+		path := string(name)
+		if stage := (flags >> 12) & 3; stage != 0 {
+			idx.conflicts[path] = true
+			continue
+		}
+		idx.entries[path] = gitIndexEntry{hash: h, mode: mode}
+	}
+	for path := range idx.conflicts {
+		if _, ok := idx.entries[path]; ok {
+			delete(idx.conflicts, path)
+		}
+	}
+
+	// 扩展区：split index 的条目在共享文件中，无法只读本文件
+	for pos+8 <= end {
+		sig := string(data[pos : pos+4])
+		size := int(binary.BigEndian.Uint32(data[pos+4 : pos+8]))
+		switch sig {
+		case "link":
+			return nil, fmt.Errorf("%w: split index", errGitUnsupported)
+		case "sdir":
+			idx.sparse = true
+		}
+		pos += 8 + size
+	}
+	return idx, nil
+}
+
+// decodeGitOffsetVarint git 的“偏移型”变长整数（index v4 与 OFS_DELTA 共用）
+func decodeGitOffsetVarint(b []byte) (uint64, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	c := b[0]
+	val := uint64(c & 0x7f)
+	n := 1
+	for c&0x80 != 0 {
+		if n >= len(b) {
+			return 0, 0
+		}
+		c = b[n]
+		n++
+		val = ((val + 1) << 7) | uint64(c&0x7f)
+	}
+	return val, n
+}
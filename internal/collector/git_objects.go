@@ -0,0 +1,493 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errGitUnsupported 仓库形态超出纯 Go 实现的范围（sha256 对象、split index 等），由调用方回退到 git 命令
+var errGitUnsupported = errors.New("纯 Go 后端不支持该仓库")
+
+type gitHash [sha1.Size]byte
+
+func (h gitHash) String() string { return hex.EncodeToString(h[:]) }
+
+func parseGitHash(s string) (gitHash, bool) {
+	var h gitHash
+	if len(s) != 2*sha1.Size {
+		return h, false
+	}
+	if _, err := hex.Decode(h[:], []byte(s)); err != nil {
+		return h, false
+	}
+	return h, true
+}
+
+// gitBlobHash 计算内容作为 blob 的对象哈希
+func gitBlobHash(content []byte) gitHash {
+	hasher := sha1.New()
+	fmt.Fprintf(hasher, "blob %d\x00", len(content))
+	hasher.Write(content)
+	var h gitHash
+	copy(h[:], hasher.Sum(nil))
+	return h
+}
+
+// 对象类型（与 pack 中的类型编号一致）
+const (
+	gitObjCommit   = 1
+	gitObjTree     = 2
+	gitObjBlob     = 3
+	gitObjTag      = 4
+	gitObjOfsDelta = 6
+	gitObjRefDelta = 7
+)
+
+// maxDeltaDepth delta 链最大深度（git 默认打包深度为 50）
+const maxDeltaDepth = 100
+
+// gitObjectStore 只读对象库：loose 对象 + packfile（含 alternates）
+type gitObjectStore struct {
+	dirs []string // objects 目录（首个为仓库自身，其后为 alternates）
+
+	mu        sync.Mutex
+	packs     []*gitPackIndex
+	packStamp map[string]time.Time // objects/pack 目录的 mtime，变化时重新加载索引
+}
+
+func newGitObjectStore(commonDir string) (*gitObjectStore, error) {
+	if cfg, err := os.ReadFile(filepath.Join(commonDir, "config")); err == nil {
+		if strings.Contains(strings.ToLower(string(cfg)), "objectformat = sha256") {
+			return nil, fmt.Errorf("%w: sha256 对象格式", errGitUnsupported)
+		}
+	}
+	objects := filepath.Join(commonDir, "objects")
+	dirs := []string{objects}
+	if b, err := os.ReadFile(filepath.Join(objects, "info", "alternates")); err == nil {
+		for _, line := range strings.Split(string(b), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if !filepath.IsAbs(line) {
+				line = filepath.Join(objects, line)
+			}
+			dirs = append(dirs, line)
+		}
+	}
+	return &gitObjectStore{dirs: dirs, packStamp: make(map[string]time.Time)}, nil
+}
+
+// read 读取对象，返回类型与内容
+func (s *gitObjectStore) read(h gitHash) (int, []byte, error) {
+	return s.readDepth(h, 0)
+}
+
+func (s *gitObjectStore) readDepth(h gitHash, depth int) (int, []byte, error) {
+	for _, dir := range s.dirs {
+		typ, data, err := readLooseObject(dir, h)
+		if err == nil {
+			return typ, data, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return 0, nil, err
+		}
+	}
+
+	// 未命中时强制重新扫描一次（mtime 精度不足时可能漏掉刚完成的 gc/fetch）
+	for _, force := range []bool{false, true} {
+		for _, p := range s.loadPacks(force) {
+			if off, ok := p.find(h); ok {
+				return p.readObject(s, off, depth)
+			}
+		}
+	}
+	return 0, nil, fmt.Errorf("对象不存在: %s", h)
+}
+
+// loadPacks 返回 pack 索引；force 或 pack 目录变化时重新扫描（已加载的索引复用）
+func (s *gitObjectStore) loadPacks(force bool) []*gitPackIndex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := s.packs == nil
+	for _, dir := range s.dirs {
+		info, err := os.Stat(filepath.Join(dir, "pack"))
+		if err == nil && !info.ModTime().Equal(s.packStamp[dir]) {
+			changed = true
+		}
+	}
+	if !changed && !force {
+		return s.packs
+	}
+
+	existing := make(map[string]*gitPackIndex, len(s.packs))
+	for _, p := range s.packs {
+		existing[p.idxPath] = p
+	}
+	var packs []*gitPackIndex
+	for _, dir := range s.dirs {
+		packDir := filepath.Join(dir, "pack")
+		if info, err := os.Stat(packDir); err == nil {
+			s.packStamp[dir] = info.ModTime()
+		}
+		matches, _ := filepath.Glob(filepath.Join(packDir, "*.idx"))
+		sort.Strings(matches)
+		for _, idxPath := range matches {
+			if p, ok := existing[idxPath]; ok {
+				packs = append(packs, p)
+				continue
+			}
+			p, err := loadGitPackIndex(idxPath)
+			if err != nil {
+				continue
+			}
+			packs = append(packs, p)
+		}
+	}
+	if packs == nil {
+		packs = []*gitPackIndex{} // 与“尚未加载”区分
+	}
+	s.packs = packs
+	return packs
+}
+
+func readLooseObject(objectsDir string, h gitHash) (int, []byte, error) {
+	name := h.String()
+	f, err := os.Open(filepath.Join(objectsDir, name[:2], name[2:]))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+
+	zr, err := zlib.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return 0, nil, fmt.Errorf("解压对象失败: %w", err)
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, nil, fmt.Errorf("解压对象失败: %w", err)
+	}
+
+	header, data, ok := bytes.Cut(raw, []byte{0})
+	if !ok {
+		return 0, nil, fmt.Errorf("对象头无效: %s", name)
+	}
+	kind, sizeStr, _ := strings.Cut(string(header), " ")
+	if size, err := strconv.Atoi(sizeStr); err != nil || size != len(data) {
+		return 0, nil, fmt.Errorf("对象长度不符: %s", name)
+	}
+	switch kind {
+	case "commit":
+		return gitObjCommit, data, nil
+	case "tree":
+		return gitObjTree, data, nil
+	case "blob":
+		return gitObjBlob, data, nil
+	case "tag":
+		return gitObjTag, data, nil
+	}
+	return 0, nil, fmt.Errorf("未知对象类型 %q: %s", kind, name)
+}
+
+// gitPackIndex pack 索引（v2），整体读入内存
+type gitPackIndex struct {
+	idxPath  string
+	packPath string
+	data     []byte
+	count    int
+}
+
+const (
+	packIdxHeaderLen = 8
+	packIdxFanoutLen = 256 * 4
+)
+
+func loadGitPackIndex(idxPath string) (*gitPackIndex, error) {
+	data, err := os.ReadFile(idxPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < packIdxHeaderLen+packIdxFanoutLen || !bytes.Equal(data[:4], []byte{0xff, 't', 'O', 'c'}) ||
+		binary.BigEndian.Uint32(data[4:8]) != 2 {
+		return nil, fmt.Errorf("%w: pack 索引版本", errGitUnsupported)
+	}
+	count := int(binary.BigEndian.Uint32(data[packIdxHeaderLen+packIdxFanoutLen-4:]))
+	if len(data) < packIdxHeaderLen+packIdxFanoutLen+count*(sha1.Size+8) {
+		return nil, fmt.Errorf("pack 索引被截断: %s", idxPath)
+	}
+	return &gitPackIndex{
+		idxPath:  idxPath,
+		packPath: strings.TrimSuffix(idxPath, ".idx") + ".pack",
+		data:     data,
+		count:    count,
+	}, nil
+}
+
+// find 在索引中二分查找对象，返回其在 pack 中的偏移
+func (p *gitPackIndex) find(h gitHash) (int64, bool) {
+	fanout := p.data[packIdxHeaderLen : packIdxHeaderLen+packIdxFanoutLen]
+	lo := 0
+	if h[0] > 0 {
+		lo = int(binary.BigEndian.Uint32(fanout[(int(h[0])-1)*4:]))
+	}
+	hi := int(binary.BigEndian.Uint32(fanout[int(h[0])*4:]))
+	names := p.data[packIdxHeaderLen+packIdxFanoutLen:]
+
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		return bytes.Compare(names[(lo+i)*sha1.Size:(lo+i+1)*sha1.Size], h[:]) >= 0
+	})
+	if i >= hi || !bytes.Equal(names[i*sha1.Size:(i+1)*sha1.Size], h[:]) {
+		return 0, false
+	}
+
+	offsets := names[p.count*(sha1.Size+4):] // 跳过 names 与 crc32
+	off := binary.BigEndian.Uint32(offsets[i*4:])
+	if off&0x80000000 == 0 {
+		return int64(off), true
+	}
+	large := offsets[p.count*4:]
+	idx := int(off & 0x7fffffff)
+	if len(large) < (idx+1)*8 {
+		return 0, false
+	}
+	return int64(binary.BigEndian.Uint64(large[idx*8:])), true
+}
+
+func (p *gitPackIndex) readObject(s *gitObjectStore, offset int64, depth int) (int, []byte, error) {
+	f, err := os.Open(p.packPath)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+	return readPackObject(f, offset, s, depth)
+}
+
+// readPackObject 读取 pack 中 offset 处的对象并展开 delta
+func readPackObject(r io.ReaderAt, offset int64, s *gitObjectStore, depth int) (int, []byte, error) {
+	if depth > maxDeltaDepth {
+		return 0, nil, fmt.Errorf("delta 链过深")
+	}
+	br := bufio.NewReader(io.NewSectionReader(r, offset, 1<<62))
+
+	c, err := br.ReadByte()
+	if err != nil {
+		return 0, nil, fmt.Errorf("读取 pack 对象失败: %w", err)
+	}
+	typ := int(c>>4) & 7
+	size := int64(c & 0x0f)
+	for shift := uint(4); c&0x80 != 0; shift += 7 {
+		if c, err = br.ReadByte(); err != nil {
+			return 0, nil, fmt.Errorf("读取 pack 对象失败: %w", err)
+		}
+		size |= int64(c&0x7f) << shift
+	}
+
+	switch typ {
+	case gitObjCommit, gitObjTree, gitObjBlob, gitObjTag:
+		data, err := inflateExact(br, size)
+		return typ, data, err
+
+	case gitObjOfsDelta:
+		c, err := br.ReadByte()
+		if err != nil {
+			return 0, nil, fmt.Errorf("读取 delta 偏移失败: %w", err)
+		}
+		rel := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = br.ReadByte(); err != nil {
+				return 0, nil, fmt.Errorf("读取 delta 偏移失败: %w", err)
+			}
+			rel = ((rel + 1) << 7) | int64(c&0x7f)
+		}
+		delta, err := inflateExact(br, size)
+		if err != nil {
+			return 0, nil, err
+		}
+		baseType, base, err := readPackObject(r, offset-rel, s, depth+1)
+		if err != nil {
+			return 0, nil, err
+		}
+		data, err := applyGitDelta(base, delta)
+		return baseType, data, err
+
+	case gitObjRefDelta:
+		var baseHash gitHash
+		if _, err := io.ReadFull(br, baseHash[:]); err != nil {
+			return 0, nil, fmt.Errorf("读取 delta 基准失败: %w", err)
+		}
+		delta, err := inflateExact(br, size)
+		if err != nil {
+			return 0, nil, err
+		}
+		baseType, base, err := s.readDepth(baseHash, depth+1)
+		if err != nil {
+			return 0, nil, err
+		}
+		data, err := applyGitDelta(base, delta)
+		return baseType, data, err
+	}
+	return 0, nil, fmt.Errorf("未知 pack 对象类型 %d", typ)
+}
+
+func inflateExact(r io.Reader, size int64) ([]byte, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("解压 pack 对象失败: %w", err)
+	}
+	defer zr.Close()
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(zr, buf); err != nil {
+		return nil, fmt.Errorf("解压 pack 对象失败: %w", err)
+	}
+	return buf, nil
+}
+
+// applyGitDelta 按 git delta 指令（copy/insert）由 base 还原目标内容
+func applyGitDelta(base, delta []byte) ([]byte, error) {
+	pos := 0
+	readSize := func() (int, bool) {
+		n, shift := 0, uint(0)
+		for pos < len(delta) {
+			c := delta[pos]
+			pos++
+			n |= int(c&0x7f) << shift
+			shift += 7
+			if c&0x80 == 0 {
+				return n, true
+			}
+		}
+		return 0, false
+	}
+	srcSize, ok1 := readSize()
+	dstSize, ok2 := readSize()
+	if !ok1 || !ok2 || srcSize != len(base) {
+		return nil, fmt.Errorf("delta 头无效")
+	}
+
+	out := make([]byte, 0, dstSize)
+	for pos < len(delta) {
+		op := delta[pos]
+		pos++
+		if op&0x80 != 0 {
+			var off, n int
+			for i := 0; i < 4; i++ {
+				if op&(1<<i) != 0 {
+					if pos >= len(delta) {
+						return nil, fmt.Errorf("delta 指令被截断")
+					}
+					off |= int(delta[pos]) << (8 * i)
+					pos++
+				}
+			}
+			for i := 0; i < 3; i++ {
+				if op&(0x10<<i) != 0 {
+					if pos >= len(delta) {
+						return nil, fmt.Errorf("delta 指令被截断")
+					}
+					n |= int(delta[pos]) << (8 * i)
+					pos++
+				}
+			}
+			if n == 0 {
+				n = 0x10000
+			}
+			if off+n > len(base) {
+				return nil, fmt.Errorf("delta 复制越界")
+			}
+			out = append(out, base[off:off+n]...)
+		} else if op != 0 {
+			n := int(op)
+			if pos+n > len(delta) {
+				return nil, fmt.Errorf("delta 插入越界")
+			}
+			out = append(out, delta[pos:pos+n]...)
+			pos += n
+		} else {
+			return nil, fmt.Errorf("delta 指令无效")
+		}
+	}
+	if len(out) != dstSize {
+		return nil, fmt.Errorf("delta 结果长度不符")
+	}
+	return out, nil
+}
+
+// treeBlob 从提交的根树出发按路径查找 blob，返回其哈希与模式
+func (s *gitObjectStore) treeBlob(commit gitHash, rel string) (gitHash, uint32, error) {
+	typ, data, err := s.read(commit)
+	if err != nil {
+		return gitHash{}, 0, err
+	}
+	if typ != gitObjCommit {
+		return gitHash{}, 0, fmt.Errorf("不是提交对象: %s", commit)
+	}
+	line, _, _ := bytes.Cut(data, []byte{'\n'})
+	treeHex, ok := strings.CutPrefix(string(line), "tree ")
+	if !ok {
+		return gitHash{}, 0, fmt.Errorf("提交缺少 tree: %s", commit)
+	}
+	tree, ok := parseGitHash(treeHex)
+	if !ok {
+		return gitHash{}, 0, fmt.Errorf("提交 tree 无效: %s", commit)
+	}
+
+	parts := strings.Split(rel, "/")
+	for i, part := range parts {
+		typ, data, err := s.read(tree)
+		if err != nil {
+			return gitHash{}, 0, err
+		}
+		if typ != gitObjTree {
+			return gitHash{}, 0, fmt.Errorf("不是 tree 对象: %s", tree)
+		}
+		h, mode, found := findTreeEntry(data, part)
+		if !found {
+			return gitHash{}, 0, os.ErrNotExist
+		}
+		if i == len(parts)-1 {
+			return h, mode, nil
+		}
+		tree = h
+	}
+	return gitHash{}, 0, os.ErrNotExist
+}
+
+// findTreeEntry 解析 tree 对象（"<mode> <name>\0<hash>" 序列）查找条目
+func findTreeEntry(data []byte, name string) (gitHash, uint32, bool) {
+	for len(data) > 0 {
+		sp := bytes.IndexByte(data, ' ')
+		if sp < 0 {
+			break
+		}
+		nul := bytes.IndexByte(data[sp:], 0)
+		if nul < 0 || sp+nul+1+sha1.Size > len(data) {
+			break
+		}
+		nul += sp
+		entryName := string(data[sp+1 : nul])
+		if entryName == name {
+			mode, _ := strconv.ParseUint(string(data[:sp]), 8, 32)
+			var h gitHash
+			copy(h[:], data[nul+1:nul+1+sha1.Size])
+			return h, uint32(mode), true
+		}
+		data = data[nul+1+sha1.Size:]
+	}
+	return gitHash{}, 0, false
+}
@@ -0,0 +1,586 @@
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Windows 没有系统时区库，IANA 时区名（Google/Apple 日历）需要内嵌数据
+)
+
+// ICSInstance 日历事件的一次发生（重复事件已展开为各次实例）
+type ICSInstance struct {
+	UID     string
+	Start   time.Time
+	End     time.Time
+	Summary string
+}
+
+// icsProp 一条内容行：NAME;PARAM=V:VALUE
+type icsProp struct {
+	params map[string]string
+	value  string
+}
+
+// icsComponent BEGIN/END 包围的组件，属性按名称分组（同名属性可多次出现，如 EXDATE）
+type icsComponent struct {
+	name     string
+	props    map[string][]icsProp
+	children []*icsComponent
+}
+
+func (c *icsComponent) first(name string) (icsProp, bool) {
+	if ps := c.props[name]; len(ps) > 0 {
+		return ps[0], true
+	}
+	return icsProp{}, false
+}
+
+func (c *icsComponent) text(name string) string {
+	p, _ := c.first(name)
+	return strings.TrimSpace(unescapeICSText(p.value))
+}
+
+// ReadICS 解析 iCalendar 文件，返回与 [from, to) 有重叠的事件实例：
+// 按 RRULE/RDATE/EXDATE 展开重复事件，用 RECURRENCE-ID 覆盖单次修改，时间按 TZID/VTIMEZONE 换算。
+// 全天事件、已取消与标记为“空闲”（TRANSP:TRANSPARENT）的事件不返回，计入 skipped。
+func ReadICS(r io.Reader, from, to time.Time) (instances []ICSInstance, skipped int, err error) {
+	root, err := parseICS(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	cal := newICSCalendar(root)
+
+	masters := make(map[string]*icsComponent)
+	overrides := make(map[string]map[int64]*icsComponent)
+	var uids []string
+	for _, ev := range root.children {
+		if ev.name != "VEVENT" {
+			continue
+		}
+		uid := ev.text("UID")
+		if uid == "" {
+			skipped++
+			continue
+		}
+		if rid, ok := ev.first("RECURRENCE-ID"); ok {
+			t, _, err := cal.parseTime(rid)
+			if err != nil {
+				skipped++
+				continue
+			}
+			if overrides[uid] == nil {
+				overrides[uid] = make(map[int64]*icsComponent)
+			}
+			overrides[uid][t.UnixMilli()] = ev
+			continue
+		}
+		if _, dup := masters[uid]; !dup {
+			uids = append(uids, uid)
+		}
+		masters[uid] = ev
+	}
+
+	emit := func(uid string, ev *icsComponent, start, end time.Time) {
+		if !end.After(start) || !start.Before(to) || !end.After(from) {
+			return
+		}
+		instances = append(instances, ICSInstance{UID: uid, Start: start, End: end, Summary: ev.text("SUMMARY")})
+	}
+
+	for _, uid := range uids {
+		ev := masters[uid]
+		occ, dur, ok := cal.occurrences(ev, to)
+		if !ok {
+			skipped++
+			continue
+		}
+		mods := overrides[uid]
+		for _, start := range occ {
+			if mod, found := mods[start.UnixMilli()]; found {
+				delete(mods, start.UnixMilli())
+				if s, e, ok := cal.single(mod); ok {
+					emit(uid, mod, s, e)
+				}
+				continue
+			}
+			emit(uid, ev, start, start.Add(dur))
+		}
+	}
+	// 找不到对应原始实例的修改（原始实例在范围外或主事件缺失）按单次事件处理
+	for uid, mods := range overrides {
+		for _, mod := range mods {
+			if s, e, ok := cal.single(mod); ok {
+				emit(uid, mod, s, e)
+			} else {
+				skipped++
+			}
+		}
+	}
+
+	sort.Slice(instances, func(i, j int) bool {
+		if !instances[i].Start.Equal(instances[j].Start) {
+			return instances[i].Start.Before(instances[j].Start)
+		}
+		return instances[i].UID < instances[j].UID
+	})
+	return instances, skipped, nil
+}
+
+// parseICS 展开折行并解析组件树，返回 VCALENDAR
+func parseICS(r io.Reader) (*icsComponent, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 4<<20)
+
+	var lines []string
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if len(lines) == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if line == "" {
+			continue
+		}
+		// RFC 5545 折行：以空格或制表符开头的行接在上一行后面
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("读取日历文件失败: %w", err)
+	}
+
+	var (
+		stack []*icsComponent
+		root  *icsComponent
+	)
+	for _, line := range lines {
+		name, prop, ok := parseICSLine(line)
+		if !ok {
+			continue
+		}
+		switch name {
+		case "BEGIN":
+			c := &icsComponent{name: strings.ToUpper(prop.value), props: make(map[string][]icsProp)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, c)
+			} else if c.name == "VCALENDAR" && root == nil {
+				root = c
+			} else {
+				continue
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		default:
+			if len(stack) > 0 {
+				cur := stack[len(stack)-1]
+				cur.props[name] = append(cur.props[name], prop)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("不是 iCalendar 文件（缺少 BEGIN:VCALENDAR）")
+	}
+	return root, nil
+}
+
+// parseICSLine 拆分内容行；参数值可加引号，引号内的 ; : 不作分隔
+func parseICSLine(line string) (string, icsProp, bool) {
+	inQuote := false
+	colon := -1
+	var cuts []int
+	for i := 0; i < len(line) && colon < 0; i++ {
+		switch line[i] {
+		case '"':
+			inQuote = !inQuote
+		case ';':
+			if !inQuote {
+				cuts = append(cuts, i)
+			}
+		case ':':
+			if !inQuote {
+				colon = i
+			}
+		}
+	}
+	if colon <= 0 {
+		return "", icsProp{}, false
+	}
+	head := line[:colon]
+	prop := icsProp{value: line[colon+1:]}
+	nameEnd := len(head)
+	if len(cuts) > 0 {
+		nameEnd = cuts[0]
+	}
+	cuts = append(cuts, len(head))
+	for i := 0; i+1 < len(cuts); i++ {
+		k, v, ok := strings.Cut(head[cuts[i]+1:cuts[i+1]], "=")
+		if !ok {
+			continue
+		}
+		if prop.params == nil {
+			prop.params = make(map[string]string)
+		}
+		prop.params[strings.ToUpper(strings.TrimSpace(k))] = strings.Trim(v, `"`)
+	}
+	return strings.ToUpper(strings.TrimSpace(head[:nameEnd])), prop, true
+}
+
+func unescapeICSText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// icsCalendar 解析时间所需的上下文（文件内定义的 VTIMEZONE）
+type icsCalendar struct {
+	tzdefs map[string]*icsComponent
+	zones  map[string]icsZone
+}
+
+func newICSCalendar(root *icsComponent) *icsCalendar {
+	c := &icsCalendar{tzdefs: make(map[string]*icsComponent), zones: make(map[string]icsZone)}
+	for _, child := range root.children {
+		if child.name == "VTIMEZONE" {
+			if id := child.text("TZID"); id != "" {
+				c.tzdefs[id] = child
+			}
+		}
+	}
+	return c
+}
+
+// icsZone 把本地时间（墙上时间）换算为时刻
+type icsZone interface {
+	at(civil time.Time) time.Time
+}
+
+type locationZone struct{ loc *time.Location }
+
+func (z locationZone) at(c time.Time) time.Time {
+	return time.Date(c.Year(), c.Month(), c.Day(), c.Hour(), c.Minute(), c.Second(), 0, z.loc)
+}
+
+// zone 按 TZID 查找时区：优先 IANA 时区库（含历史规则），其次文件内的 VTIMEZONE
+// （Outlook 导出使用 "China Standard Time" 这类 Windows 名称），都没有时按本机时区
+func (c *icsCalendar) zone(tzid string) icsZone {
+	if z, ok := c.zones[tzid]; ok {
+		return z
+	}
+	var z icsZone = locationZone{time.Local}
+	if tzid != "" {
+		if loc, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			z = locationZone{loc}
+		} else if def, ok := c.tzdefs[tzid]; ok {
+			if vz := newVTimezone(def); vz != nil {
+				z = vz
+			}
+		}
+	}
+	c.zones[tzid] = z
+	return z
+}
+
+// parseTime 解析 DATE-TIME / DATE 值；返回的 bool 表示是否为全天（DATE）
+func (c *icsCalendar) parseTime(p icsProp) (time.Time, bool, error) {
+	v := strings.TrimSpace(p.value)
+	if i := strings.IndexByte(v, ','); i >= 0 {
+		v = v[:i]
+	}
+	return c.parseTimeValue(v, p.params["TZID"], p.params["VALUE"] == "DATE")
+}
+
+func (c *icsCalendar) parseTimeValue(v, tzid string, isDate bool) (time.Time, bool, error) {
+	if isDate || len(v) == 8 {
+		t, err := time.ParseInLocation("20060102", v, time.Local)
+		return t, true, err
+	}
+	if strings.HasSuffix(v, "Z") {
+		t, err := time.Parse("20060102T150405Z", v)
+		return t, false, err
+	}
+	civil, err := time.Parse("20060102T150405", v)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return c.zone(tzid).at(civil), false, nil
+}
+
+// parseTimeList 解析 EXDATE/RDATE（可多行、逗号分隔）
+func (c *icsCalendar) parseTimeList(props []icsProp) []time.Time {
+	var out []time.Time
+	for _, p := range props {
+		if p.params["VALUE"] == "DATE" || p.params["VALUE"] == "PERIOD" {
+			continue
+		}
+		for _, v := range strings.Split(p.value, ",") {
+			if t, allDay, err := c.parseTimeValue(strings.TrimSpace(v), p.params["TZID"], false); err == nil && !allDay {
+				out = append(out, t)
+			}
+		}
+	}
+	return out
+}
+
+// eventSpan 事件的开始时间与时长；全天、取消、空闲与缺少结束时间的事件返回 ok=false
+func (c *icsCalendar) eventSpan(ev *icsComponent) (start time.Time, dur time.Duration, ok bool) {
+	if strings.EqualFold(ev.text("STATUS"), "CANCELLED") || strings.EqualFold(ev.text("TRANSP"), "TRANSPARENT") {
+		return time.Time{}, 0, false
+	}
+	p, found := ev.first("DTSTART")
+	if !found {
+		return time.Time{}, 0, false
+	}
+	start, allDay, err := c.parseTime(p)
+	if err != nil || allDay {
+		return time.Time{}, 0, false
+	}
+	if endProp, found := ev.first("DTEND"); found {
+		end, allDay, err := c.parseTime(endProp)
+		if err != nil || allDay {
+			return time.Time{}, 0, false
+		}
+		dur = end.Sub(start)
+	} else if durProp, found := ev.first("DURATION"); found {
+		if dur, err = parseICSDuration(durProp.value); err != nil {
+			return time.Time{}, 0, false
+		}
+	}
+	if dur <= 0 {
+		return time.Time{}, 0, false
+	}
+	return start, dur, true
+}
+
+// single 单次事件（或重复事件中被修改的一次）的时间
+func (c *icsCalendar) single(ev *icsComponent) (time.Time, time.Time, bool) {
+	start, dur, ok := c.eventSpan(ev)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, start.Add(dur), true
+}
+
+// occurrences 主事件在 until 之前的所有开始时间（含 DTSTART 本身），已去掉 EXDATE
+func (c *icsCalendar) occurrences(ev *icsComponent, until time.Time) ([]time.Time, time.Duration, bool) {
+	start, dur, ok := c.eventSpan(ev)
+	if !ok {
+		return nil, 0, false
+	}
+	out := []time.Time{start}
+	if p, found := ev.first("RRULE"); found {
+		if rule, err := parseRRule(p.value, start); err == nil {
+			// 重复按 DTSTART 所在时区的墙上时间展开，跨夏令时切换时本地时间不变
+			dtstart, _ := ev.first("DTSTART")
+			zone := c.zone(dtstart.params["TZID"])
+			if strings.HasSuffix(strings.TrimSpace(dtstart.value), "Z") {
+				zone = locationZone{time.UTC}
+			}
+			out = rule.expand(start, zone, until)
+		}
+	}
+	out = append(out, c.parseTimeList(ev.props["RDATE"])...)
+
+	excluded := make(map[int64]bool)
+	for _, t := range c.parseTimeList(ev.props["EXDATE"]) {
+		excluded[t.UnixMilli()] = true
+	}
+	seen := make(map[int64]bool, len(out))
+	kept := out[:0]
+	for _, t := range out {
+		ms := t.UnixMilli()
+		if excluded[ms] || seen[ms] {
+			continue
+		}
+		seen[ms] = true
+		kept = append(kept, t)
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].Before(kept[j]) })
+	return kept, dur, true
+}
+
+// parseICSDuration 解析 DURATION（如 PT1H30M、P1D、P1W）
+func parseICSDuration(v string) (time.Duration, error) {
+	v = strings.TrimSpace(strings.ToUpper(v))
+	neg := strings.HasPrefix(v, "-")
+	v = strings.TrimLeft(v, "+-")
+	if !strings.HasPrefix(v, "P") {
+		return 0, fmt.Errorf("无效的 DURATION: %q", v)
+	}
+	var (
+		d      time.Duration
+		num    string
+		inTime bool
+	)
+	for _, r := range v[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			num += string(r)
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			return 0, fmt.Errorf("无效的 DURATION: %q", v)
+		}
+		num = ""
+		switch {
+		case r == 'W':
+			d += time.Duration(n) * 7 * 24 * time.Hour
+		case r == 'D':
+			d += time.Duration(n) * 24 * time.Hour
+		case r == 'H' && inTime:
+			d += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			d += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			d += time.Duration(n) * time.Second
+		default:
+			return 0, fmt.Errorf("无效的 DURATION: %q", v)
+		}
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+// vtimezone 由 VTIMEZONE 的 STANDARD/DAYLIGHT 定义换算的时区
+type vtimezone struct {
+	observances []tzObservance
+}
+
+type tzObservance struct {
+	start    time.Time // 本地时间（以 UTC 表示的墙上时间）
+	offsetTo int       // 秒
+	rule     *rrule
+	rdates   []time.Time
+}
+
+func newVTimezone(def *icsComponent) *vtimezone {
+	z := &vtimezone{}
+	for _, ob := range def.children {
+		if ob.name != "STANDARD" && ob.name != "DAYLIGHT" {
+			continue
+		}
+		dt, _ := ob.first("DTSTART")
+		start, err := time.Parse("20060102T150405", strings.TrimSpace(dt.value))
+		if err != nil {
+			continue
+		}
+		to, ok := parseUTCOffset(ob.text("TZOFFSETTO"))
+		if !ok {
+			continue
+		}
+		o := tzObservance{start: start, offsetTo: to}
+		if p, found := ob.first("RRULE"); found {
+			if rule, err := parseRRule(p.value, start); err == nil {
+				o.rule = rule
+			}
+		}
+		for _, p := range ob.props["RDATE"] {
+			for _, v := range strings.Split(p.value, ",") {
+				if t, err := time.Parse("20060102T150405", strings.TrimSpace(v)); err == nil {
+					o.rdates = append(o.rdates, t)
+				}
+			}
+		}
+		z.observances = append(z.observances, o)
+	}
+	if len(z.observances) == 0 {
+		return nil
+	}
+	return z
+}
+
+// at 取不晚于该本地时间的最近一次切换所对应的偏移
+func (z *vtimezone) at(c time.Time) time.Time {
+	civil := time.Date(c.Year(), c.Month(), c.Day(), c.Hour(), c.Minute(), c.Second(), 0, time.UTC)
+	var (
+		best     time.Time
+		offset   int
+		found    bool
+		earliest = z.observances[0]
+	)
+	for _, o := range z.observances {
+		if o.start.Before(earliest.start) {
+			earliest = o
+		}
+		for _, onset := range o.onsetsNear(civil) {
+			if onset.After(civil) {
+				continue
+			}
+			if !found || onset.After(best) {
+				best, offset, found = onset, o.offsetTo, true
+			}
+		}
+	}
+	if !found {
+		offset = earliest.offsetTo
+	}
+	return civil.Add(-time.Duration(offset) * time.Second).In(time.FixedZone("", offset))
+}
+
+// onsetsNear 该定义在 civil 所在年及前一年的切换时间
+func (o tzObservance) onsetsNear(civil time.Time) []time.Time {
+	out := []time.Time{o.start}
+	out = append(out, o.rdates...)
+	if o.rule != nil {
+		from := time.Date(civil.Year()-1, 1, 1, 0, 0, 0, 0, time.UTC)
+		out = append(out, o.rule.civilBetween(o.start, from, civil.AddDate(0, 0, 1))...)
+	}
+	return out
+}
+
+func parseUTCOffset(v string) (int, bool) {
+	v = strings.TrimSpace(v)
+	if len(v) != 5 && len(v) != 7 {
+		return 0, false
+	}
+	sign := 1
+	switch v[0] {
+	case '-':
+		sign = -1
+	case '+':
+	default:
+		return 0, false
+	}
+	h, err1 := strconv.Atoi(v[1:3])
+	m, err2 := strconv.Atoi(v[3:5])
+	s := 0
+	var err3 error
+	if len(v) == 7 {
+		s, err3 = strconv.Atoi(v[5:7])
+	}
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, false
+	}
+	return sign * (h*3600 + m*60 + s), true
+}
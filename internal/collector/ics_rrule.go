@@ -0,0 +1,357 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rrule RFC 5545 重复规则（支持 DAILY/WEEKLY/MONTHLY/YEARLY 与常用的 BYDAY/BYMONTHDAY/BYMONTH/BYSETPOS）
+type rrule struct {
+	freq       string
+	interval   int
+	count      int
+	until      time.Time // 时刻
+	untilCivil time.Time // 墙上时间（VTIMEZONE 的切换规则按墙上时间比较）
+	hasUntil   bool
+	byDay      []weekdayNum
+	byMonthDay []int
+	byMonth    []time.Month
+	bySetPos   []int
+	wkst       time.Weekday
+}
+
+// weekdayNum BYDAY 的一项：n=0 表示每个该星期几，n>0 第 n 个，n<0 倒数第 n 个
+type weekdayNum struct {
+	n  int
+	wd time.Weekday
+}
+
+// 展开上限：防止异常规则（如 FREQ=DAILY 无结束）在很长的范围内无限展开
+const rruleMaxPeriods = 100000
+
+var icsWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+func parseRRule(v string, start time.Time) (*rrule, error) {
+	r := &rrule{interval: 1, wkst: time.Monday}
+	for _, part := range strings.Split(strings.TrimSpace(v), ";") {
+		k, val, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		val = strings.ToUpper(strings.TrimSpace(val))
+		switch strings.ToUpper(strings.TrimSpace(k)) {
+		case "FREQ":
+			r.freq = val
+		case "INTERVAL":
+			if n, err := strconv.Atoi(val); err == nil && n > 0 {
+				r.interval = n
+			}
+		case "COUNT":
+			if n, err := strconv.Atoi(val); err == nil && n > 0 {
+				r.count = n
+			}
+		case "UNTIL":
+			if err := r.parseUntil(val, start); err != nil {
+				return nil, err
+			}
+		case "WKST":
+			if wd, ok := icsWeekdays[val]; ok {
+				r.wkst = wd
+			}
+		case "BYDAY":
+			for _, item := range strings.Split(val, ",") {
+				item = strings.TrimSpace(item)
+				if len(item) < 2 {
+					return nil, fmt.Errorf("无效的 BYDAY: %q", val)
+				}
+				wd, ok := icsWeekdays[item[len(item)-2:]]
+				if !ok {
+					return nil, fmt.Errorf("无效的 BYDAY: %q", val)
+				}
+				n := 0
+				if num := item[:len(item)-2]; num != "" {
+					var err error
+					if n, err = strconv.Atoi(num); err != nil {
+						return nil, fmt.Errorf("无效的 BYDAY: %q", val)
+					}
+				}
+				r.byDay = append(r.byDay, weekdayNum{n: n, wd: wd})
+			}
+		case "BYMONTHDAY":
+			ints, err := parseIntList(val)
+			if err != nil {
+				return nil, err
+			}
+			r.byMonthDay = ints
+		case "BYMONTH":
+			ints, err := parseIntList(val)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range ints {
+				r.byMonth = append(r.byMonth, time.Month(m))
+			}
+		case "BYSETPOS":
+			ints, err := parseIntList(val)
+			if err != nil {
+				return nil, err
+			}
+			r.bySetPos = ints
+		case "BYYEARDAY", "BYWEEKNO", "BYHOUR", "BYMINUTE", "BYSECOND":
+			return nil, fmt.Errorf("不支持的重复规则: %s", k)
+		}
+	}
+	switch r.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return nil, fmt.Errorf("不支持的重复频率: %q", r.freq)
+	}
+	return r, nil
+}
+
+func (r *rrule) parseUntil(v string, start time.Time) error {
+	switch {
+	case strings.HasSuffix(v, "Z"):
+		t, err := time.Parse("20060102T150405Z", v)
+		if err != nil {
+			return fmt.Errorf("无效的 UNTIL: %q", v)
+		}
+		r.until, r.untilCivil = t, t
+	case len(v) == 8:
+		d, err := time.Parse("20060102", v)
+		if err != nil {
+			return fmt.Errorf("无效的 UNTIL: %q", v)
+		}
+		r.untilCivil = d.Add(24*time.Hour - time.Second)
+		r.until = time.Date(d.Year(), d.Month(), d.Day(), 23, 59, 59, 0, start.Location())
+	default:
+		t, err := time.Parse("20060102T150405", v)
+		if err != nil {
+			return fmt.Errorf("无效的 UNTIL: %q", v)
+		}
+		r.untilCivil = t
+		r.until = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, start.Location())
+	}
+	r.hasUntil = true
+	return nil
+}
+
+func parseIntList(v string) ([]int, error) {
+	var out []int
+	for _, item := range strings.Split(v, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil {
+			return nil, fmt.Errorf("无效的数字列表: %q", v)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// expand 按规则展开 before 之前的所有开始时间（从 start 起，遵守 COUNT/UNTIL）
+func (r *rrule) expand(start time.Time, zone icsZone, before time.Time) []time.Time {
+	var out []time.Time
+	n := 0
+	r.each(civilOf(start), func(civil time.Time) bool {
+		t := zone.at(civil)
+		if (r.hasUntil && t.After(r.until)) || !t.Before(before) {
+			return false
+		}
+		out = append(out, t)
+		n++
+		return r.count == 0 || n < r.count
+	})
+	return out
+}
+
+// civilBetween 以墙上时间展开 [from, to) 内的实例（用于 VTIMEZONE 的切换时间）
+func (r *rrule) civilBetween(start, from, to time.Time) []time.Time {
+	var out []time.Time
+	n := 0
+	r.each(start, func(civil time.Time) bool {
+		if (r.hasUntil && civil.After(r.untilCivil)) || !civil.Before(to) {
+			return false
+		}
+		if !civil.Before(from) {
+			out = append(out, civil)
+		}
+		n++
+		return r.count == 0 || n < r.count
+	})
+	return out
+}
+
+// each 按时间顺序逐个产生不早于 start 的实例（墙上时间），fn 返回 false 时停止
+func (r *rrule) each(start time.Time, fn func(time.Time) bool) {
+	tod := start.Sub(time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC))
+	day0 := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	weekStart := day0.AddDate(0, 0, -((int(day0.Weekday()) - int(r.wkst) + 7) % 7))
+
+	for k := 0; k < rruleMaxPeriods; k++ {
+		var days []time.Time
+		switch r.freq {
+		case "DAILY":
+			d := day0.AddDate(0, 0, k*r.interval)
+			if r.matchMonth(d.Month()) && r.matchMonthDay(d) && r.matchWeekday(d.Weekday()) {
+				days = []time.Time{d}
+			}
+		case "WEEKLY":
+			base := weekStart.AddDate(0, 0, 7*r.interval*k)
+			wds := []time.Weekday{start.Weekday()}
+			if len(r.byDay) > 0 {
+				wds = wds[:0]
+				for _, w := range r.byDay {
+					wds = append(wds, w.wd)
+				}
+			}
+			for _, wd := range wds {
+				d := base.AddDate(0, 0, (int(wd)-int(r.wkst)+7)%7)
+				if r.matchMonth(d.Month()) {
+					days = append(days, d)
+				}
+			}
+		case "MONTHLY":
+			first := time.Date(start.Year(), start.Month()+time.Month(k*r.interval), 1, 0, 0, 0, 0, time.UTC)
+			if r.matchMonth(first.Month()) {
+				days = r.monthDays(first, start.Day())
+			}
+		case "YEARLY":
+			year := start.Year() + k*r.interval
+			months := r.byMonth
+			if len(months) == 0 {
+				months = []time.Month{start.Month()}
+			}
+			for _, m := range months {
+				days = append(days, r.monthDays(time.Date(year, m, 1, 0, 0, 0, 0, time.UTC), start.Day())...)
+			}
+		}
+		sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+		days = dedupeDays(days)
+		if len(r.bySetPos) > 0 {
+			days = selectSetPos(days, r.bySetPos)
+		}
+		for _, d := range days {
+			t := d.Add(tod)
+			if t.Before(start) {
+				continue
+			}
+			if !fn(t) {
+				return
+			}
+		}
+	}
+}
+
+// monthDays 某月中符合 BYMONTHDAY/BYDAY 的日期；都未指定时取 DTSTART 的日（该月没有这一天则跳过）
+func (r *rrule) monthDays(first time.Time, startDay int) []time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+	if len(r.byMonthDay) == 0 && len(r.byDay) == 0 {
+		if startDay > last {
+			return nil
+		}
+		return []time.Time{first.AddDate(0, 0, startDay-1)}
+	}
+	var out []time.Time
+	for day := 1; day <= last; day++ {
+		d := first.AddDate(0, 0, day-1)
+		if !r.matchMonthDay(d) {
+			continue
+		}
+		if len(r.byDay) > 0 && !matchWeekdayNum(r.byDay, d, day, last) {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func (r *rrule) matchMonth(m time.Month) bool {
+	if len(r.byMonth) == 0 {
+		return true
+	}
+	for _, bm := range r.byMonth {
+		if bm == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *rrule) matchMonthDay(d time.Time) bool {
+	if len(r.byMonthDay) == 0 {
+		return true
+	}
+	last := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, md := range r.byMonthDay {
+		if md == d.Day() || (md < 0 && last+1+md == d.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *rrule) matchWeekday(wd time.Weekday) bool {
+	if len(r.byDay) == 0 {
+		return true
+	}
+	for _, w := range r.byDay {
+		if w.wd == wd {
+			return true
+		}
+	}
+	return false
+}
+
+func matchWeekdayNum(byDay []weekdayNum, d time.Time, day, last int) bool {
+	for _, w := range byDay {
+		if w.wd != d.Weekday() {
+			continue
+		}
+		switch {
+		case w.n == 0:
+			return true
+		case w.n > 0 && (day-1)/7+1 == w.n:
+			return true
+		case w.n < 0 && (last-day)/7+1 == -w.n:
+			return true
+		}
+	}
+	return false
+}
+
+func selectSetPos(days []time.Time, pos []int) []time.Time {
+	var out []time.Time
+	for _, p := range pos {
+		i := p - 1
+		if p < 0 {
+			i = len(days) + p
+		}
+		if i >= 0 && i < len(days) {
+			out = append(out, days[i])
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return dedupeDays(out)
+}
+
+func dedupeDays(days []time.Time) []time.Time {
+	out := days[:0]
+	for i, d := range days {
+		if i > 0 && d.Equal(days[i-1]) {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// civilOf 时刻在其所属时区的墙上时间（以 UTC 表示，便于按日历日期运算）
+func civilOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
@@ -0,0 +1,152 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:W. Europe Standard Time\r\n" +
+	"BEGIN:STANDARD\r\n" +
+	"DTSTART:16010101T030000\r\n" +
+	"TZOFFSETFROM:+0200\r\n" +
+	"TZOFFSETTO:+0100\r\n" +
+	"RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=10\r\n" +
+	"END:STANDARD\r\n" +
+	"BEGIN:DAYLIGHT\r\n" +
+	"DTSTART:16010101T020000\r\n" +
+	"TZOFFSETFROM:+0100\r\n" +
+	"TZOFFSETTO:+0200\r\n" +
+	"RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=3\r\n" +
+	"END:DAYLIGHT\r\n" +
+	"END:VTIMEZONE\r\n" +
+	// 每周一、三 10:00（柏林），跨 3 月 29 日夏令时切换；3/25 取消一次，4/1 改到 11:00 并改名
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"SUMMARY:Daily standup\\, team A\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20260323T100000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20260323T101500\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=6\r\n" +
+	"EXDATE;TZID=Europe/Berlin:20260325T100000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Berlin:20260401T100000\r\n" +
+	"SUMMARY:Standup (moved)\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20260401T110000\r\n" +
+	"DURATION:PT30M\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Berlin:20260406T100000\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20260406T100000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20260406T101500\r\n" +
+	"END:VEVENT\r\n" +
+	// Outlook 风格的 Windows 时区名，只能靠文件内的 VTIMEZONE 换算；折行的标题
+	"BEGIN:VEVENT\r\n" +
+	"UID:review@example.com\r\n" +
+	"SUMMARY:Quarterly re\r\n" +
+	" view\r\n" +
+	"DTSTART;TZID=\"W. Europe Standard Time\":20260330T140000\r\n" +
+	"DTEND;TZID=\"W. Europe Standard Time\":20260330T150000\r\n" +
+	"END:VEVENT\r\n" +
+	// 每月最后一个周五（UTC）
+	"BEGIN:VEVENT\r\n" +
+	"UID:retro@example.com\r\n" +
+	"SUMMARY:Retro\r\n" +
+	"DTSTART:20260130T160000Z\r\n" +
+	"DTEND:20260130T170000Z\r\n" +
+	"RRULE:FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20260430T235959Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:holiday@example.com\r\n" +
+	"SUMMARY:Holiday\r\n" +
+	"DTSTART;VALUE=DATE:20260401\r\n" +
+	"DTEND;VALUE=DATE:20260402\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:focus@example.com\r\n" +
+	"SUMMARY:Focus time\r\n" +
+	"TRANSP:TRANSPARENT\r\n" +
+	"DTSTART:20260402T080000Z\r\n" +
+	"DTEND:20260402T100000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestReadICS(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	got, skipped, err := ReadICS(strings.NewReader(testICS), from, to)
+	if err != nil {
+		t.Fatalf("ReadICS: %v", err)
+	}
+	if skipped != 2 {
+		t.Fatalf("skipped = %d, want 2 (all-day + transparent)", skipped)
+	}
+
+	utc := func(mo time.Month, d, h, m int) time.Time { return time.Date(2026, mo, d, h, m, 0, 0, time.UTC) }
+	want := []struct {
+		uid     string
+		start   time.Time
+		minutes int
+		summary string
+	}{
+		{"retro@example.com", utc(3, 27, 16, 0), 60, "Retro"},
+		{"standup@example.com", utc(3, 23, 9, 0), 15, "Daily standup, team A"}, // CET
+		{"standup@example.com", utc(3, 30, 8, 0), 15, "Daily standup, team A"}, // CEST：本地仍为 10:00
+		{"review@example.com", utc(3, 30, 12, 0), 60, "Quarterly review"},
+		{"standup@example.com", utc(4, 1, 9, 0), 30, "Standup (moved)"},
+		{"standup@example.com", utc(4, 8, 8, 0), 15, "Daily standup, team A"},
+		{"retro@example.com", utc(4, 24, 16, 0), 60, "Retro"},
+	}
+	if len(got) != len(want) {
+		for _, g := range got {
+			t.Logf("%s %s %s", g.UID, g.Start.UTC(), g.Summary)
+		}
+		t.Fatalf("instances = %d, want %d", len(got), len(want))
+	}
+	byStart := make(map[int64]ICSInstance, len(got))
+	for _, g := range got {
+		byStart[g.Start.Unix()] = g
+	}
+	for _, w := range want {
+		g, ok := byStart[w.start.Unix()]
+		if !ok {
+			t.Fatalf("missing %s at %s", w.uid, w.start)
+		}
+		if g.UID != w.uid || g.End.Sub(g.Start) != time.Duration(w.minutes)*time.Minute || g.Summary != w.summary {
+			t.Fatalf("instance at %s = %+v, want %+v", w.start, g, w)
+		}
+	}
+}
+
+func TestReadICS_rejectsNonCalendar(t *testing.T) {
+	if _, _, err := ReadICS(strings.NewReader("hello"), time.Time{}, time.Now()); err == nil {
+		t.Fatal("expected error for non-iCalendar input")
+	}
+}
+
+func TestParseICSDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"PT45M":     45 * time.Minute,
+		"PT1H30M":   90 * time.Minute,
+		"P1D":       24 * time.Hour,
+		"P1W":       7 * 24 * time.Hour,
+		"P1DT2H":    26 * time.Hour,
+		"-PT15M":    -15 * time.Minute,
+		"PT0H0M30S": 30 * time.Second,
+	}
+	for in, want := range cases {
+		got, err := parseICSDuration(in)
+		if err != nil || got != want {
+			t.Fatalf("parseICSDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseICSDuration("1H"); err == nil {
+		t.Fatal("expected error for invalid duration")
+	}
+}
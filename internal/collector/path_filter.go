@@ -0,0 +1,332 @@
+package collector
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PathRule 某个目录（通常是仓库根）下的采集规则，模式为 gitignore 语法、相对 Path 匹配。
+// Include 非空时只采集匹配的文件；Exclude 在 .gitignore 之后生效，优先级最高。
+type PathRule struct {
+	Path    string
+	Include []string
+	Exclude []string
+}
+
+// ignoreCacheTTL 规则文件的复查间隔（.gitignore 变更另有文件事件即时失效）
+const ignoreCacheTTL = 2 * time.Second
+
+// ignorePattern 一条 gitignore 规则
+type ignorePattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreRules 一组按顺序生效的规则（后出现的优先）
+type ignoreRules []ignorePattern
+
+// parseIgnoreRules 解析 gitignore 文本
+func parseIgnoreRules(lines []string) ignoreRules {
+	var rules ignoreRules
+	for _, line := range lines {
+		if p, ok := compileIgnorePattern(line); ok {
+			rules = append(rules, p)
+		}
+	}
+	return rules
+}
+
+// match 返回最后一条匹配规则的结论；rel 为相对规则目录的正斜杠路径
+func (r ignoreRules) match(rel string, isDir bool) (matched, ignored bool) {
+	for _, p := range r {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(rel) {
+			matched, ignored = true, !p.negate
+		}
+	}
+	return matched, ignored
+}
+
+func compileIgnorePattern(line string) (ignorePattern, bool) {
+	line = strings.TrimRight(line, "\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+	// 行尾空格忽略，除非以反斜杠转义
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+
+	var p ignorePattern
+	switch {
+	case strings.HasPrefix(line, "!"):
+		p.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false
+	}
+
+	// 含斜杠（开头或中间）的模式相对规则目录锚定；否则匹配任意层级的同名项
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	expr := globToRegexp(line)
+	if anchored {
+		expr = "^" + expr + "$"
+	} else {
+		expr = "^(?:.*/)?" + expr + "$"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return ignorePattern{}, false
+	}
+	p.re = re
+	return p, true
+}
+
+// globToRegexp 将 gitignore 通配（*、?、[...]、**）转换为正则
+func globToRegexp(glob string) string {
+	segs := strings.Split(glob, "/")
+	var sb strings.Builder
+	for i, seg := range segs {
+		last := i == len(segs)-1
+		if seg == "**" {
+			if last {
+				sb.WriteString(".*")
+			} else {
+				sb.WriteString("(?:.*/)?")
+			}
+			continue
+		}
+		for j := 0; j < len(seg); j++ {
+			switch ch := seg[j]; ch {
+			case '*':
+				sb.WriteString("[^/]*")
+			case '?':
+				sb.WriteString("[^/]")
+			case '\\':
+				if j+1 < len(seg) {
+					j++
+					sb.WriteString(regexp.QuoteMeta(seg[j : j+1]))
+				}
+			case '[':
+				end := strings.IndexByte(seg[j+1:], ']')
+				if end < 0 {
+					sb.WriteString(`\[`)
+					continue
+				}
+				class := seg[j+1 : j+1+end]
+				if strings.HasPrefix(class, "!") {
+					class = "^" + class[1:]
+				}
+				sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+				j += end + 1
+			default:
+				sb.WriteString(regexp.QuoteMeta(string(ch)))
+			}
+		}
+		if !last {
+			sb.WriteString("/")
+		}
+	}
+	return sb.String()
+}
+
+// pathFilter 决定哪些目录需要监控、哪些文件需要采集。
+// 优先级由低到高：全局 exclude 配置 → .git/info/exclude → 各级 .gitignore（由浅到深）→ 目录规则的 exclude。
+// 被忽略目录下的文件无法被重新包含（与 git 一致）。
+type pathFilter struct {
+	global ignoreRules
+	rules  []compiledPathRule
+
+	mu    sync.Mutex
+	files map[string]*cachedIgnoreFile // 规则文件路径 -> 解析结果
+}
+
+type compiledPathRule struct {
+	dir     string
+	include ignoreRules
+	exclude ignoreRules
+}
+
+type cachedIgnoreFile struct {
+	rules     ignoreRules
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func newPathFilter(exclude []string, rules []PathRule) *pathFilter {
+	f := &pathFilter{
+		global: parseIgnoreRules(exclude),
+		files:  make(map[string]*cachedIgnoreFile),
+	}
+	for _, r := range rules {
+		dir := strings.TrimSpace(r.Path)
+		if dir == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		f.rules = append(f.rules, compiledPathRule{
+			dir:     filepath.Clean(dir),
+			include: parseIgnoreRules(r.Include),
+			exclude: parseIgnoreRules(r.Exclude),
+		})
+	}
+	return f
+}
+
+// ignored 判断 path 是否被忽略（逐级检查父目录）；root 为仓库根或监控根
+func (f *pathFilter) ignored(root, path string, isDir bool) bool {
+	rel, ok := relUnder(root, path)
+	if !ok || rel == "" {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	for i := range parts {
+		sub := filepath.Join(root, filepath.FromSlash(strings.Join(parts[:i+1], "/")))
+		if f.ignoredEntry(root, sub, isDir || i < len(parts)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredEntry 只判断 path 本身（调用方保证父目录未被忽略，例如自上而下遍历时）
+func (f *pathFilter) ignoredEntry(root, path string, isDir bool) bool {
+	rel, ok := relUnder(root, path)
+	if !ok || rel == "" {
+		return false
+	}
+	ignored := false
+	apply := func(rules ignoreRules, base string) {
+		r, ok := relUnder(base, path)
+		if !ok || r == "" {
+			return
+		}
+		if matched, ign := rules.match(r, isDir); matched {
+			ignored = ign
+		}
+	}
+
+	apply(f.global, root)
+	if gitDir := gitDirOf(root); gitDir != "" {
+		apply(f.ignoreFile(filepath.Join(gitDir, "info", "exclude")), root)
+	}
+	// 从根到父目录逐级的 .gitignore
+	dir := root
+	apply(f.ignoreFile(filepath.Join(dir, ".gitignore")), dir)
+	parentParts := strings.Split(rel, "/")
+	for _, part := range parentParts[:len(parentParts)-1] {
+		dir = filepath.Join(dir, part)
+		apply(f.ignoreFile(filepath.Join(dir, ".gitignore")), dir)
+	}
+	for _, r := range f.rules {
+		apply(r.exclude, r.dir)
+	}
+	return ignored
+}
+
+// included 检查目录规则的 include 白名单（文件或其任一父目录匹配即可）
+func (f *pathFilter) included(path string) bool {
+	for _, r := range f.rules {
+		if len(r.include) == 0 {
+			continue
+		}
+		rel, ok := relUnder(r.dir, path)
+		if !ok || rel == "" {
+			continue
+		}
+		parts := strings.Split(rel, "/")
+		hit := false
+		for i := range parts {
+			if matched, ign := r.include.match(strings.Join(parts[:i+1], "/"), i < len(parts)-1); matched && ign {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			return false
+		}
+	}
+	return true
+}
+
+// skipFile 文件是否不采集
+func (f *pathFilter) skipFile(root, path string) bool {
+	return f.ignored(root, path, false) || !f.included(path)
+}
+
+// invalidate 规则文件变化时丢弃缓存
+func (f *pathFilter) invalidate(ruleFile string) {
+	f.mu.Lock()
+	delete(f.files, ruleFile)
+	f.mu.Unlock()
+}
+
+// ignoreFile 读取并缓存规则文件；不存在时返回空规则
+func (f *pathFilter) ignoreFile(path string) ignoreRules {
+	now := time.Now()
+	f.mu.Lock()
+	cached := f.files[path]
+	fresh := cached != nil && now.Sub(cached.checkedAt) < ignoreCacheTTL
+	f.mu.Unlock()
+	if fresh {
+		return cached.rules
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		f.mu.Lock()
+		f.files[path] = &cachedIgnoreFile{checkedAt: now}
+		f.mu.Unlock()
+		return nil
+	}
+	if cached != nil && cached.modTime.Equal(info.ModTime()) {
+		f.mu.Lock()
+		cached.checkedAt = now
+		f.mu.Unlock()
+		return cached.rules
+	}
+
+	var lines []string
+	if file, err := os.Open(path); err == nil {
+		sc := bufio.NewScanner(file)
+		for sc.Scan() {
+			lines = append(lines, sc.Text())
+		}
+		_ = file.Close()
+	}
+	entry := &cachedIgnoreFile{rules: parseIgnoreRules(lines), modTime: info.ModTime(), checkedAt: now}
+	f.mu.Lock()
+	f.files[path] = entry
+	f.mu.Unlock()
+	return entry.rules
+}
+
+// relUnder 返回 path 相对 base 的正斜杠路径；不在 base 之下时 ok=false
+func relUnder(base, path string) (string, bool) {
+	rel, err := filepath.Rel(base, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if rel == "." {
+		return "", true
+	}
+	return filepath.ToSlash(rel), true
+}
@@ -0,0 +1,116 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIgnoreRules_gitignoreSemantics(t *testing.T) {
+	rules := parseIgnoreRules([]string{
+		"# comment",
+		"*.log",
+		"!keep.log",
+		"/root_only.go",
+		"build/",
+		"docs/**/*.md",
+		"**/gen",
+		`\#literal`,
+	})
+	cases := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"a.log", false, true},
+		{"deep/nested/a.log", false, true},
+		{"keep.log", false, false},
+		{"root_only.go", false, true},
+		{"sub/root_only.go", false, false},
+		{"build", true, true},
+		{"build", false, false}, // 目录模式不匹配同名文件
+		{"src/build", true, true},
+		{"docs/a.md", false, true},
+		{"docs/x/y/a.md", false, true},
+		{"other/docs/a.md", false, false},
+		{"a/b/gen", true, true},
+		{"#literal", false, true},
+		{"main.go", false, false},
+	}
+	for _, tc := range cases {
+		if _, got := rules.match(tc.rel, tc.isDir); got != tc.want {
+			t.Errorf("match(%q, dir=%v) = %v, want %v", tc.rel, tc.isDir, got, tc.want)
+		}
+	}
+}
+
+func TestPathFilter_layersRuleFiles(t *testing.T) {
+	repo := t.TempDir()
+	writeTestFile(t, filepath.Join(repo, ".git", "info", "exclude"), "secret.go\n")
+	writeTestFile(t, filepath.Join(repo, ".gitignore"), "*.tmp.go\ngenerated/\n")
+	writeTestFile(t, filepath.Join(repo, "pkg", ".gitignore"), "!keep.tmp.go\nlocal.go\n")
+
+	f := newPathFilter([]string{"node_modules/"}, []PathRule{
+		{Path: repo, Exclude: []string{"**/*_mock.go"}},
+	})
+	cases := []struct {
+		rel  string
+		want bool
+	}{
+		{"main.go", false},
+		{"secret.go", true},                 // .git/info/exclude
+		{"a.tmp.go", true},                  // 根 .gitignore
+		{"pkg/keep.tmp.go", false},          // 子目录 .gitignore 取反
+		{"pkg/local.go", true},              // 子目录规则
+		{"local.go", false},                 // 子目录规则不影响上层
+		{"generated/x.go", true},            // 父目录被忽略
+		{"web/node_modules/lib/a.js", true}, // 全局 exclude
+		{"svc/user_mock.go", true},          // 目录规则 exclude
+	}
+	for _, tc := range cases {
+		path := filepath.Join(repo, filepath.FromSlash(tc.rel))
+		if got := f.skipFile(repo, path); got != tc.want {
+			t.Errorf("skipFile(%s) = %v, want %v", tc.rel, got, tc.want)
+		}
+	}
+
+	// 修改 .gitignore 后失效缓存即可生效
+	writeTestFile(t, filepath.Join(repo, ".gitignore"), "main.go\n")
+	f.invalidate(filepath.Join(repo, ".gitignore"))
+	if !f.skipFile(repo, filepath.Join(repo, "main.go")) {
+		t.Fatalf("expected main.go to be ignored after .gitignore change")
+	}
+}
+
+func TestPathFilter_includeWhitelist(t *testing.T) {
+	repo := t.TempDir()
+	f := newPathFilter(nil, []PathRule{
+		{Path: repo, Include: []string{"services/api/", "*.proto"}},
+	})
+	cases := map[string]bool{
+		"services/api/main.go":     false,
+		"services/api/x/y/util.go": false,
+		"services/web/main.go":     true,
+		"shared/types.proto":       false,
+		"main.go":                  true,
+	}
+	for rel, want := range cases {
+		if got := f.skipFile(repo, filepath.Join(repo, filepath.FromSlash(rel))); got != want {
+			t.Errorf("skipFile(%s) = %v, want %v", rel, got, want)
+		}
+	}
+	// 规则外的目录不受白名单影响
+	if f.skipFile(repo, filepath.Join(t.TempDir(), "main.go")) {
+		t.Fatalf("include rule should not apply outside its path")
+	}
+}
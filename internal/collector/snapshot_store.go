@@ -0,0 +1,215 @@
+package collector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const snapshotExt = ".snap"
+
+// snapshotStore 非 Git 目录的影子快照：每个文件保存上一次采集时的内容，用于进程内生成 diff。
+// 快照落盘（跨重启保留），按文件名哈希存放；总量超限时按最近访问时间淘汰。
+type snapshotStore struct {
+	dir           string
+	maxFileBytes  int64
+	maxTotalBytes int64
+
+	mu      sync.Mutex
+	entries map[string]snapshotEntry // 快照文件名 -> 元信息
+	total   int64
+}
+
+type snapshotEntry struct {
+	size    int64
+	touched time.Time
+}
+
+// newSnapshotStore 打开（或创建）快照目录并加载已有快照的索引
+func newSnapshotStore(dir string, maxFileBytes, maxTotalBytes int64) (*snapshotStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建快照目录失败: %w", err)
+	}
+	s := &snapshotStore{
+		dir:           dir,
+		maxFileBytes:  maxFileBytes,
+		maxTotalBytes: maxTotalBytes,
+		entries:       make(map[string]snapshotEntry),
+	}
+
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取快照目录失败: %w", err)
+	}
+	for _, it := range items {
+		if it.IsDir() || !strings.HasSuffix(it.Name(), snapshotExt) {
+			continue
+		}
+		info, err := it.Info()
+		if err != nil {
+			continue
+		}
+		s.entries[it.Name()] = snapshotEntry{size: info.Size(), touched: info.ModTime()}
+		s.total += info.Size()
+	}
+	s.mu.Lock()
+	s.evictLocked("")
+	s.mu.Unlock()
+	return s, nil
+}
+
+// Get 返回文件上一次的快照内容
+func (s *snapshotStore) Get(filePath string) (string, bool) {
+	name := snapshotName(filePath)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[name]; !ok {
+		return "", false
+	}
+	b, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		s.dropLocked(name)
+		return "", false
+	}
+	return string(b), true
+}
+
+// Put 更新快照；超过单文件上限时删除旧快照并返回 false
+func (s *snapshotStore) Put(filePath string, content []byte) bool {
+	name := snapshotName(filePath)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxFileBytes > 0 && int64(len(content)) > s.maxFileBytes {
+		s.removeLocked(name)
+		return false
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, name), content, 0o600); err != nil {
+		s.removeLocked(name)
+		return false
+	}
+	if old, ok := s.entries[name]; ok {
+		s.total -= old.size
+	}
+	s.entries[name] = snapshotEntry{size: int64(len(content)), touched: time.Now()}
+	s.total += int64(len(content))
+	s.evictLocked(name)
+	return true
+}
+
+// Remove 删除文件的快照
+func (s *snapshotStore) Remove(filePath string) {
+	s.mu.Lock()
+	s.removeLocked(snapshotName(filePath))
+	s.mu.Unlock()
+}
+
+// Size 返回快照数量与总字节数
+func (s *snapshotStore) Size() (int, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries), s.total
+}
+
+// evictLocked 总量超限时从最久未更新的快照开始淘汰（keep 为刚写入的快照，不参与淘汰）
+func (s *snapshotStore) evictLocked(keep string) {
+	if s.maxTotalBytes <= 0 || s.total <= s.maxTotalBytes {
+		return
+	}
+	names := make([]string, 0, len(s.entries))
+	for name := range s.entries {
+		if name != keep {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return s.entries[names[i]].touched.Before(s.entries[names[j]].touched)
+	})
+	for _, name := range names {
+		if s.total <= s.maxTotalBytes {
+			return
+		}
+		s.removeLocked(name)
+	}
+}
+
+func (s *snapshotStore) removeLocked(name string) {
+	_ = os.Remove(filepath.Join(s.dir, name))
+	s.dropLocked(name)
+}
+
+func (s *snapshotStore) dropLocked(name string) {
+	if e, ok := s.entries[name]; ok {
+		s.total -= e.size
+		delete(s.entries, name)
+	}
+}
+
+// snapshotName 以绝对路径哈希作为快照文件名（避免路径字符与长度问题）
+func snapshotName(filePath string) string {
+	key := filepath.Clean(filePath)
+	if abs, err := filepath.Abs(key); err == nil {
+		key = abs
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16]) + snapshotExt
+}
+
+// snapshotDiff 非 Git 目录的 diff：与上一次快照对比后更新快照。
+// 首次见到的文件只记录快照作为基线、不产生 diff（无法区分新建文件与早已存在的文件）。
+// ok=false 表示未启用快照或文件超限。
+func (c *DiffCollector) snapshotDiff(filePath string) (root, content string, added, deleted int, ok bool) {
+	if c.snapshots == nil {
+		return "", "", 0, 0, false
+	}
+	current, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", "", 0, 0, false
+	}
+	prev, hadPrev := c.snapshots.Get(filePath)
+	if !c.snapshots.Put(filePath, current) {
+		return "", "", 0, 0, false
+	}
+
+	root = c.watchRootOf(filePath)
+	if !hadPrev {
+		return root, "", 0, 0, true
+	}
+	rel := filepath.Base(filePath)
+	if p, err := filepath.Rel(root, filePath); err == nil {
+		rel = filepath.ToSlash(p)
+	}
+	content, added, deleted = unifiedDiff("a/"+rel, "b/"+rel, prev, string(current))
+	return root, content, added, deleted, true
+}
+
+// watchRootOf 返回包含该文件的最深监控目录，作为非 Git 文件的项目路径
+func (c *DiffCollector) watchRootOf(filePath string) string {
+	c.mu.Lock()
+	paths := append([]string(nil), c.watchPaths...)
+	c.mu.Unlock()
+
+	best := ""
+	for _, p := range paths {
+		if abs, err := filepath.Abs(p); err == nil {
+			p = abs
+		}
+		rel, err := filepath.Rel(p, filePath)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(p) > len(best) {
+			best = p
+		}
+	}
+	if best == "" {
+		return filepath.Dir(filePath)
+	}
+	return best
+}
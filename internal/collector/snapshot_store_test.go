@@ -0,0 +1,100 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotStore_capsAndEviction(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSnapshotStore(dir, 10, 20)
+	if err != nil {
+		t.Fatalf("newSnapshotStore: %v", err)
+	}
+
+	if s.Put("/w/big.txt", []byte("0123456789abc")) {
+		t.Fatalf("file over per-file cap should be rejected")
+	}
+	if !s.Put("/w/a.txt", []byte("aaaaaaaa")) || !s.Put("/w/b.txt", []byte("bbbbbbbb")) {
+		t.Fatalf("small files should be stored")
+	}
+	// 总量 24 > 20：最早的 a.txt 被淘汰
+	if !s.Put("/w/c.txt", []byte("cccccccc")) {
+		t.Fatalf("c.txt should be stored")
+	}
+	if _, ok := s.Get("/w/a.txt"); ok {
+		t.Fatalf("oldest snapshot should be evicted")
+	}
+	if got, ok := s.Get("/w/c.txt"); !ok || got != "cccccccc" {
+		t.Fatalf("expected c.txt snapshot, got %q ok=%v", got, ok)
+	}
+	if n, total := s.Size(); n != 2 || total != 16 {
+		t.Fatalf("expected 2 files / 16 bytes, got %d / %d", n, total)
+	}
+
+	// 重新打开：快照跨重启保留
+	reopened, err := newSnapshotStore(dir, 10, 20)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if got, ok := reopened.Get("/w/b.txt"); !ok || got != "bbbbbbbb" {
+		t.Fatalf("expected persisted b.txt snapshot, got %q ok=%v", got, ok)
+	}
+}
+
+func TestDiffCollector_captureDiff_nonGitUsesSnapshots(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(root, "notes", "scratch.py")
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	store, err := newSnapshotStore(filepath.Join(t.TempDir(), "snapshots"), 1<<20, 1<<20)
+	if err != nil {
+		t.Fatalf("newSnapshotStore: %v", err)
+	}
+	c := &DiffCollector{watchPaths: []string{root}, snapshots: store}
+	ctx := context.Background()
+
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			t.Fatalf("write target: %v", err)
+		}
+	}
+
+	// 首次见到的文件（可能早已存在）只记录基线，不产生 diff
+	write("x = 1\n")
+	if first, err := c.captureDiff(ctx, target); err != nil || first != nil {
+		t.Fatalf("first capture should only seed the snapshot: diff=%v err=%v", first, err)
+	}
+	if n, _ := store.Size(); n != 1 {
+		t.Fatalf("snapshots=%d, want 1", n)
+	}
+
+	write("x = 1\ny = 2\n")
+	second, err := c.captureDiff(ctx, target)
+	if err != nil || second == nil {
+		t.Fatalf("second capture: diff=%v err=%v", second, err)
+	}
+	if second.IsGitRepo || second.ProjectPath != root {
+		t.Fatalf("unexpected diff: git=%v project=%q", second.IsGitRepo, second.ProjectPath)
+	}
+	if !strings.HasPrefix(second.DiffContent, "--- a/notes/scratch.py\n+++ b/notes/scratch.py\n") {
+		t.Fatalf("diff should be against the baseline:\n%s", second.DiffContent)
+	}
+	if second.LinesAdded != 1 || second.LinesDeleted != 0 || !strings.Contains(second.DiffContent, "+y = 2") {
+		t.Fatalf("expected only the new line, got +%d/-%d\n%s", second.LinesAdded, second.LinesDeleted, second.DiffContent)
+	}
+
+	// 未启用快照时保持原行为：跳过并计数
+	skip := &DiffCollector{}
+	if d, err := skip.captureDiff(ctx, target); err != nil || d != nil {
+		t.Fatalf("expected skip without snapshot store, got diff=%v err=%v", d, err)
+	}
+	if skip.skippedNonGit.Load() != 1 {
+		t.Fatalf("expected skippedNonGit=1, got %d", skip.skippedNonGit.Load())
+	}
+}
@@ -0,0 +1,365 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+const (
+	terminalReadMax        = 4 << 20 // 单次读取历史文件的上限；首次读取只读末尾这么多
+	terminalFingerprintLen = 64      // 已读位置之前保留的字节数，用于识别文件被整体重写
+)
+
+// TerminalCollector shell 历史采集器：轮询 bash/zsh/fish/PowerShell 的历史文件，增量读取新命令。
+// 带时间的历史（bash HISTTIMEFORMAT、zsh EXTENDED_HISTORY、fish）按文件里的时间入库；
+// 不带时间的历史以读到新行的时间为准，且无法判断重写后哪些是新命令，只采集追加的部分。
+type TerminalCollector struct {
+	files        []*historyFile
+	ignore       map[string]struct{}
+	pollInterval time.Duration
+	now          func() time.Time
+	eventChan    chan *schema.TerminalCommand
+	stopChan     chan struct{}
+	stopOnce     sync.Once
+
+	mu      sync.Mutex
+	running bool
+
+	lastEmitAt atomic.Int64
+	dropped    atomic.Int64
+	readErrors atomic.Int64
+}
+
+// TerminalCollectorConfig 配置
+type TerminalCollectorConfig struct {
+	Shells         []string          // bash | zsh | fish | powershell，为空时全部
+	HistoryFiles   map[string]string // shell -> 历史文件（覆盖默认路径）
+	IgnoreCommands []string          // 按第一个词忽略的命令（ls、cd 等噪音）
+	PollInterval   time.Duration
+	Since          map[string]int64 // shell -> 已入库的最新带时间命令（毫秒）
+	Lookback       time.Duration    // 没有入库记录时，带时间的历史回溯多久
+	Now            func() time.Time // 为空时使用 time.Now
+}
+
+// historyFile 单个历史文件的读取状态
+type historyFile struct {
+	shell       string
+	path        string
+	parse       historyParser
+	offset      int64
+	fingerprint []byte
+	since       int64 // 整体重新读取时只接受不早于此时间的带时间条目（已入库的游标）
+	primed      bool
+}
+
+// NewTerminalCollector 创建 shell 历史采集器
+func NewTerminalCollector(cfg *TerminalCollectorConfig) *TerminalCollector {
+	if cfg == nil {
+		cfg = &TerminalCollectorConfig{}
+	}
+	poll := cfg.PollInterval
+	if poll <= 0 {
+		poll = 15 * time.Second
+	}
+	lookback := cfg.Lookback
+	if lookback <= 0 {
+		lookback = 24 * time.Hour
+	}
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	enabled := make(map[string]bool)
+	for _, s := range cfg.Shells {
+		if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+			enabled[s] = true
+		}
+	}
+	candidates := defaultHistoryFiles()
+	for shell, path := range cfg.HistoryFiles {
+		if path = strings.TrimSpace(path); path != "" {
+			candidates[strings.ToLower(strings.TrimSpace(shell))] = []string{expandHome(path)}
+		}
+	}
+
+	floor := now().Add(-lookback).UnixMilli()
+	var files []*historyFile
+	for _, shell := range []string{ShellBash, ShellZsh, ShellFish, ShellPowerShell} {
+		if len(enabled) > 0 && !enabled[shell] {
+			continue
+		}
+		since := cfg.Since[shell]
+		if since <= 0 {
+			since = floor
+		}
+		for _, path := range candidates[shell] {
+			files = append(files, &historyFile{shell: shell, path: filepath.Clean(path), parse: parserForShell(shell), since: since})
+		}
+	}
+
+	ignore := make(map[string]struct{}, len(cfg.IgnoreCommands))
+	for _, c := range cfg.IgnoreCommands {
+		if c = strings.ToLower(strings.TrimSpace(c)); c != "" {
+			ignore[c] = struct{}{}
+		}
+	}
+
+	return &TerminalCollector{
+		files:        files,
+		ignore:       ignore,
+		pollInterval: poll,
+		now:          now,
+		eventChan:    make(chan *schema.TerminalCommand, 512),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// Start 启动采集
+func (c *TerminalCollector) Start(ctx context.Context) error {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return nil
+	}
+	c.running = true
+	c.mu.Unlock()
+
+	slog.Info("终端历史采集器启动", "files", len(c.files))
+	go c.pollLoop(ctx)
+	return nil
+}
+
+// Stop 停止采集
+func (c *TerminalCollector) Stop() error {
+	c.stopOnce.Do(func() {
+		c.mu.Lock()
+		c.running = false
+		c.mu.Unlock()
+		close(c.stopChan)
+		slog.Info("终端历史采集器已停止")
+	})
+	return nil
+}
+
+// Events 返回命令通道（原文，由服务层脱敏后入库）
+func (c *TerminalCollector) Events() <-chan *schema.TerminalCommand {
+	return c.eventChan
+}
+
+func (c *TerminalCollector) pollLoop(ctx context.Context) {
+	defer close(c.eventChan)
+
+	c.scanAll()
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.stopChan:
+			return
+		case <-ticker.C:
+			c.scanAll()
+		}
+	}
+}
+
+func (c *TerminalCollector) scanAll() {
+	for _, f := range c.files {
+		c.scanFile(f)
+	}
+}
+
+// scanFile 读取历史文件新增的部分；首次读取或文件被重写时从头（末尾 terminalReadMax）重新解析，
+// 重新解析出的已入库命令由唯一索引去重
+func (c *TerminalCollector) scanFile(f *historyFile) {
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		// 文件还不存在：之后出现的内容都是新命令
+		f.primed, f.offset, f.fingerprint = true, 0, nil
+		return
+	}
+	size := fi.Size()
+	if f.primed && size == f.offset && c.fingerprintMatches(f) {
+		return
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		c.readErrors.Add(1)
+		slog.Debug("打开 shell 历史失败", "path", f.path, "error", err)
+		return
+	}
+	defer file.Close()
+
+	fresh := !f.primed || size < f.offset || !c.fingerprintMatchesFile(file, f)
+	start := f.offset
+	if fresh {
+		start = max(size-terminalReadMax, 0)
+	}
+	end := min(size, start+terminalReadMax)
+	data := make([]byte, end-start)
+	if _, err := file.ReadAt(data, start); err != nil && err != io.EOF {
+		c.readErrors.Add(1)
+		slog.Debug("读取 shell 历史失败", "path", f.path, "error", err)
+		return
+	}
+	if fresh && start > 0 {
+		// 从文件中间开始读：丢掉第一行残片
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data, start = data[i+1:], start+int64(i+1)
+		}
+	}
+
+	entries, consumed := f.parse(data)
+	f.offset = start + int64(consumed)
+	f.primed = true
+	f.fingerprint = tailBytes(data[:consumed], f.fingerprint, terminalFingerprintLen)
+
+	now := c.now().UnixMilli()
+	var untimed int
+	for _, e := range entries {
+		if e.Timestamp == 0 {
+			untimed++
+		}
+	}
+	for _, e := range entries {
+		cmd := &schema.TerminalCommand{Shell: f.shell, Command: e.Command, Duration: e.Duration}
+		switch {
+		case e.Timestamp > 0:
+			// 追加的内容按偏移量增量读取，不再按时间过滤：多个 shell 退出时各自追加，时间会早于已读条目
+			if fresh && e.Timestamp < f.since {
+				continue
+			}
+			cmd.Timestamp, cmd.Timed = e.Timestamp, true
+		case fresh:
+			// 首次读取/重写后的不带时间的条目无法判断执行时间，跳过
+			continue
+		default:
+			// 同一批读到的命令按顺序错开 1ms，保持先后且避免相同命令被唯一索引合并
+			untimed--
+			cmd.Timestamp = now - int64(untimed)
+		}
+		if c.ignored(cmd.Command) {
+			continue
+		}
+		c.emit(cmd)
+	}
+}
+
+// fingerprintMatches 不读文件时的快速判断：没有记录指纹（空文件）视为一致
+func (c *TerminalCollector) fingerprintMatches(f *historyFile) bool {
+	if len(f.fingerprint) == 0 {
+		return true
+	}
+	file, err := os.Open(f.path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	return c.fingerprintMatchesFile(file, f)
+}
+
+func (c *TerminalCollector) fingerprintMatchesFile(file *os.File, f *historyFile) bool {
+	n := int64(len(f.fingerprint))
+	if n == 0 {
+		return true
+	}
+	if f.offset < n {
+		return false
+	}
+	buf := make([]byte, n)
+	if _, err := file.ReadAt(buf, f.offset-n); err != nil {
+		return false
+	}
+	return bytes.Equal(buf, f.fingerprint)
+}
+
+// expandHome 展开配置里以 ~ 开头的路径
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// tailBytes 已读位置之前的最后 n 个字节（本次读到的不够时接上旧指纹）
+func tailBytes(data, prev []byte, n int) []byte {
+	joined := append(append([]byte{}, prev...), data...)
+	if len(joined) > n {
+		joined = joined[len(joined)-n:]
+	}
+	return joined
+}
+
+// ignored 按第一个词（去掉路径与 .exe）判断是否为忽略的命令
+func (c *TerminalCollector) ignored(cmd string) bool {
+	if len(c.ignore) == 0 {
+		return false
+	}
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return true
+	}
+	name := strings.ToLower(filepath.Base(strings.ReplaceAll(fields[0], "\\", "/")))
+	_, ok := c.ignore[strings.TrimSuffix(name, ".exe")]
+	return ok
+}
+
+func (c *TerminalCollector) emit(cmd *schema.TerminalCommand) {
+	select {
+	case c.eventChan <- cmd:
+		c.lastEmitAt.Store(c.now().UnixMilli())
+	default:
+		c.dropped.Add(1)
+		slog.Warn("终端命令缓冲区已满，丢弃命令", "shell", cmd.Shell)
+	}
+}
+
+type TerminalCollectorStats struct {
+	Running      bool     `json:"running"`
+	LastEmitAt   int64    `json:"last_emit_at"`
+	Dropped      int64    `json:"dropped"`
+	ReadErrors   int64    `json:"read_errors"`
+	HistoryFiles []string `json:"history_files"` // 已存在、正在跟踪的历史文件
+}
+
+func (c *TerminalCollector) Stats() TerminalCollectorStats {
+	if c == nil {
+		return TerminalCollectorStats{}
+	}
+	c.mu.Lock()
+	running := c.running
+	c.mu.Unlock()
+
+	var paths []string
+	for _, f := range c.files {
+		if _, err := os.Stat(f.path); err == nil {
+			paths = append(paths, f.path)
+		}
+	}
+	sort.Strings(paths)
+	return TerminalCollectorStats{
+		Running:      running,
+		LastEmitAt:   c.lastEmitAt.Load(),
+		Dropped:      c.dropped.Load(),
+		ReadErrors:   c.readErrors.Load(),
+		HistoryFiles: paths,
+	}
+}
@@ -0,0 +1,228 @@
+package collector
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// 支持的 shell
+const (
+	ShellBash       = "bash"
+	ShellZsh        = "zsh"
+	ShellFish       = "fish"
+	ShellPowerShell = "powershell"
+)
+
+// historyEntry 历史文件中的一条命令；Timestamp 为 0 表示文件本身不带时间
+type historyEntry struct {
+	Command   string
+	Timestamp int64 // 毫秒
+	Duration  int   // 秒
+}
+
+// historyParser 解析一段历史文件内容，返回完整的条目与已消费的字节数（末尾不完整的条目留到下次）
+type historyParser func(data []byte) ([]historyEntry, int)
+
+func parserForShell(shell string) historyParser {
+	switch shell {
+	case ShellZsh:
+		return parseZshHistory
+	case ShellFish:
+		return parseFishHistory
+	case ShellPowerShell:
+		return parsePowerShellHistory
+	default:
+		return parseBashHistory
+	}
+}
+
+// completeLines 按行切分到最后一个换行符为止；行内保留 \r，便于按原始长度回退未消费的字节
+func completeLines(data []byte) ([]string, int) {
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil, 0
+	}
+	return strings.Split(string(data[:end]), "\n"), end + 1
+}
+
+// parseBashHistory 每行一条命令；设置了 HISTTIMEFORMAT 时命令前有 "#<unix 秒>" 行
+func parseBashHistory(data []byte) ([]historyEntry, int) {
+	lines, consumed := completeLines(data)
+	var out []historyEntry
+	var ts int64
+	for i, raw := range lines {
+		line := strings.TrimSuffix(raw, "\r")
+		if sec, ok := bashTimestampLine(line); ok {
+			if i == len(lines)-1 {
+				// 时间行后面的命令还没写入：留到下次一起解析
+				consumed -= len(raw) + 1
+				break
+			}
+			ts = sec * 1000
+			continue
+		}
+		if cmd := strings.TrimSpace(line); cmd != "" {
+			out = append(out, historyEntry{Command: cmd, Timestamp: ts})
+		}
+		ts = 0
+	}
+	return out, consumed
+}
+
+func bashTimestampLine(line string) (int64, bool) {
+	if len(line) < 2 || line[0] != '#' {
+		return 0, false
+	}
+	sec, err := strconv.ParseInt(strings.TrimSpace(line[1:]), 10, 64)
+	return sec, err == nil && sec > 0
+}
+
+// parseZshHistory 支持 EXTENDED_HISTORY（": <开始时间>:<耗时>;<命令>"）与普通格式；
+// 多行命令以反斜杠续行，非 ASCII 字节经过 zsh 的 metafy 编码。
+func parseZshHistory(data []byte) ([]historyEntry, int) {
+	lines, consumed := completeLines(data)
+	var out []historyEntry
+	var buf []string
+	pending := 0
+	for _, raw := range lines {
+		line := string(unmetafyZsh([]byte(strings.TrimSuffix(raw, "\r"))))
+		pending += len(raw) + 1
+		if strings.HasSuffix(line, "\\") {
+			buf = append(buf, strings.TrimSuffix(line, "\\"))
+			continue
+		}
+		buf = append(buf, line)
+		out = append(out, zshEntry(strings.Join(buf, "\n")))
+		buf, pending = buf[:0], 0
+	}
+	// 续行尚未写完
+	consumed -= pending
+	filtered := out[:0]
+	for _, e := range out {
+		if e.Command != "" {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered, consumed
+}
+
+func zshEntry(raw string) historyEntry {
+	if strings.HasPrefix(raw, ": ") {
+		if meta, cmd, ok := strings.Cut(raw[2:], ";"); ok {
+			startStr, durStr, _ := strings.Cut(meta, ":")
+			start, err1 := strconv.ParseInt(strings.TrimSpace(startStr), 10, 64)
+			dur, err2 := strconv.Atoi(strings.TrimSpace(durStr))
+			if err1 == nil && start > 0 {
+				if err2 != nil || dur < 0 {
+					dur = 0
+				}
+				return historyEntry{Command: strings.TrimSpace(cmd), Timestamp: start * 1000, Duration: dur}
+			}
+		}
+	}
+	return historyEntry{Command: strings.TrimSpace(raw)}
+}
+
+// unmetafyZsh 还原 zsh 历史文件的 metafied 字节：0x83 后的字节与 0x20 异或
+func unmetafyZsh(data []byte) []byte {
+	if bytes.IndexByte(data, 0x83) < 0 {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == 0x83 && i+1 < len(data) {
+			i++
+			out = append(out, data[i]^0x20)
+			continue
+		}
+		out = append(out, data[i])
+	}
+	return out
+}
+
+// parseFishHistory 解析 fish 的伪 YAML 历史：每条记录以 "- cmd: <命令>" 开头，
+// 随后是 "  when: <unix 秒>" 与可选的 "  paths:" 列表
+func parseFishHistory(data []byte) ([]historyEntry, int) {
+	lines, consumed := completeLines(data)
+	var out []historyEntry
+	var cur *historyEntry
+	recordStart, offset := 0, 0
+	for _, raw := range lines {
+		line := strings.TrimSuffix(raw, "\r")
+		lineLen := len(raw) + 1
+		switch {
+		case strings.HasPrefix(line, "- cmd: "):
+			if cur != nil && cur.Timestamp > 0 {
+				out = append(out, *cur)
+			}
+			cur = &historyEntry{Command: unescapeFish(strings.TrimPrefix(line, "- cmd: "))}
+			recordStart = offset
+		case cur != nil && strings.HasPrefix(line, "  when: "):
+			if sec, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, "  when: ")), 10, 64); err == nil {
+				cur.Timestamp = sec * 1000
+			}
+		}
+		offset += lineLen
+	}
+	switch {
+	case cur == nil:
+	case cur.Timestamp > 0:
+		out = append(out, *cur)
+	default:
+		// when 行还没写入
+		consumed = recordStart
+	}
+
+	filtered := out[:0]
+	for _, e := range out {
+		if e.Command = strings.TrimSpace(e.Command); e.Command != "" {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered, consumed
+}
+
+func unescapeFish(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch s[i+1] {
+			case 'n':
+				b.WriteByte('\n')
+				i++
+				continue
+			case '\\':
+				b.WriteByte('\\')
+				i++
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// parsePowerShellHistory PSReadLine 每行一条命令，多行命令以反引号续行；文件不带时间
+func parsePowerShellHistory(data []byte) ([]historyEntry, int) {
+	lines, consumed := completeLines(data)
+	var out []historyEntry
+	var buf []string
+	pending := 0
+	for _, raw := range lines {
+		line := strings.TrimSuffix(raw, "\r")
+		buf = append(buf, line)
+		pending += len(raw) + 1
+		if strings.HasSuffix(line, "`") {
+			continue
+		}
+		if cmd := strings.TrimSpace(strings.Join(buf, "\n")); cmd != "" {
+			out = append(out, historyEntry{Command: cmd})
+		}
+		buf, pending = buf[:0], 0
+	}
+	return out, consumed - pending
+}
@@ -0,0 +1,184 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseBashHistory(t *testing.T) {
+	data := "ls -la\n#1700000000\ngo test ./...\r\n#1700000060\n"
+	entries, consumed := parseBashHistory([]byte(data))
+	if len(entries) != 2 {
+		t.Fatalf("entries = %+v", entries)
+	}
+	if entries[0].Timestamp != 0 || entries[1].Command != "go test ./..." || entries[1].Timestamp != 1700000000*1000 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	// 末尾的时间行等命令写入后再一起解析
+	if want := len(data) - len("#1700000060\n"); consumed != want {
+		t.Fatalf("consumed = %d, want %d", consumed, want)
+	}
+}
+
+func TestParseZshHistory(t *testing.T) {
+	// "é" 在 zsh 历史中被 metafy：0xC3 0x83 0xA9^0x20
+	meta := string([]byte{0xC3, 0x83, 0xA9 ^ 0x20})
+	data := ": 1700000000:3;make build\n" +
+		": 1700000010:0;echo caf" + meta + "\n" +
+		": 1700000020:1;for f in *; do\\\n  echo $f\\\ndone\n" +
+		": 1700000030:0;git commit \\\n"
+	entries, consumed := parseZshHistory([]byte(data))
+	if len(entries) != 3 {
+		t.Fatalf("entries = %+v", entries)
+	}
+	if entries[0].Command != "make build" || entries[0].Duration != 3 || entries[0].Timestamp != 1700000000*1000 {
+		t.Fatalf("unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].Command != "echo café" {
+		t.Fatalf("unmetafy failed: %q", entries[1].Command)
+	}
+	if entries[2].Command != "for f in *; do\n  echo $f\ndone" {
+		t.Fatalf("multi-line command: %q", entries[2].Command)
+	}
+	if want := len(data) - len(": 1700000030:0;git commit \\\n"); consumed != want {
+		t.Fatalf("consumed = %d, want %d", consumed, want)
+	}
+}
+
+func TestParseFishHistory(t *testing.T) {
+	data := "- cmd: cargo build\n  when: 1700000000\n  paths:\n    - src\n" +
+		"- cmd: echo a\\nb\n  when: 1700000005\n" +
+		"- cmd: half written\n"
+	entries, consumed := parseFishHistory([]byte(data))
+	if len(entries) != 2 || entries[0].Command != "cargo build" || entries[1].Command != "echo a\nb" {
+		t.Fatalf("entries = %+v", entries)
+	}
+	if entries[1].Timestamp != 1700000005*1000 {
+		t.Fatalf("timestamp = %d", entries[1].Timestamp)
+	}
+	if want := len(data) - len("- cmd: half written\n"); consumed != want {
+		t.Fatalf("consumed = %d, want %d", consumed, want)
+	}
+}
+
+func TestParsePowerShellHistory(t *testing.T) {
+	data := "Get-ChildItem\r\nInvoke-RestMethod `\r\n  -Uri http://x\r\nnpm run `\r\n"
+	entries, consumed := parsePowerShellHistory([]byte(data))
+	if len(entries) != 2 || entries[1].Command != "Invoke-RestMethod `\n  -Uri http://x" {
+		t.Fatalf("entries = %+v", entries)
+	}
+	if want := len(data) - len("npm run `\r\n"); consumed != want {
+		t.Fatalf("consumed = %d, want %d", consumed, want)
+	}
+}
+
+func TestTerminalCollector_tailsAppendedCommands(t *testing.T) {
+	dir := t.TempDir()
+	zshPath := filepath.Join(dir, ".zsh_history")
+	psPath := filepath.Join(dir, "ConsoleHost_history.txt")
+	if err := os.WriteFile(zshPath, []byte(": 1700000000:0;old command\n: 1700000100:0;git pull\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(psPath, []byte("Get-Process\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1700000200, 0)
+	c := NewTerminalCollector(&TerminalCollectorConfig{
+		Shells:         []string{ShellZsh, ShellPowerShell},
+		HistoryFiles:   map[string]string{ShellZsh: zshPath, ShellPowerShell: psPath},
+		IgnoreCommands: []string{"ls"},
+		Since:          map[string]int64{ShellZsh: 1700000050 * 1000},
+		Now:            func() time.Time { return now },
+	})
+
+	// 首次读取：只补带时间且晚于游标的命令；PowerShell 的旧历史无法判断时间，跳过
+	c.scanAll()
+	got := drainTerminal(c)
+	if len(got) != 1 || got[0] != "zsh|git pull" {
+		t.Fatalf("first scan = %v", got)
+	}
+
+	appendFile(t, zshPath, ": 1700000150:2;go test ./...\n: 1700000160:0;ls\n")
+	appendFile(t, psPath, "kubectl get pods\nkubectl logs api\n")
+	c.scanAll()
+	got = drainTerminal(c)
+	want := []string{"zsh|go test ./...", "powershell|kubectl get pods", "powershell|kubectl logs api"}
+	if len(got) != len(want) {
+		t.Fatalf("second scan = %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("second scan = %v, want %v", got, want)
+		}
+	}
+
+	// zsh 整体重写（HISTSIZE 截断）：从头重新解析游标之后的命令，已入库的由唯一索引去重
+	if err := os.WriteFile(zshPath, []byte(": 1700000000:0;old command\n: 1700000150:2;go test ./...\n: 1700000170:0;make lint\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c.scanAll()
+	got = drainTerminal(c)
+	if len(got) != 2 || got[0] != "zsh|go test ./..." || got[1] != "zsh|make lint" {
+		t.Fatalf("after rewrite = %v", got)
+	}
+}
+
+func TestTerminalCollector_keepsOlderAppendedBlocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".bash_history")
+	if err := os.WriteFile(path, []byte("#1700000000\nmake build\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := NewTerminalCollector(&TerminalCollectorConfig{
+		Shells:       []string{ShellBash},
+		HistoryFiles: map[string]string{ShellBash: path},
+		Since:        map[string]int64{ShellBash: 1699999000 * 1000},
+		Now:          func() time.Time { return time.Unix(1700001000, 0) },
+	})
+	c.scanAll()
+	if got := drainTerminal(c); len(got) != 1 {
+		t.Fatalf("first scan = %v", got)
+	}
+
+	// 两个 shell 先后退出：较新的一块先追加，之后追加的一块时间更早，仍是新命令
+	appendFile(t, path, "#1700000500\ngit push\n")
+	c.scanAll()
+	appendFile(t, path, "#1700000200\ngo test ./...\n#1700000300\ngo vet ./...\n")
+	c.scanAll()
+	got := drainTerminal(c)
+	want := []string{"bash|git push", "bash|go test ./...", "bash|go vet ./..."}
+	if len(got) != len(want) {
+		t.Fatalf("appended = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("appended = %v, want %v", got, want)
+		}
+	}
+}
+
+func appendFile(t *testing.T, path, s string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(s); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func drainTerminal(c *TerminalCollector) []string {
+	var out []string
+	for {
+		select {
+		case cmd := <-c.eventChan:
+			out = append(out, cmd.Shell+"|"+cmd.Command)
+		default:
+			return out
+		}
+	}
+}
@@ -0,0 +1,30 @@
+//go:build !windows
+
+package collector
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// defaultHistoryFiles Linux/macOS 下各 shell 的默认历史文件
+func defaultHistoryFiles() map[string][]string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return nil
+	}
+	zdot := os.Getenv("ZDOTDIR")
+	if zdot == "" {
+		zdot = home
+	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return map[string][]string{
+		ShellBash:       {filepath.Join(home, ".bash_history")},
+		ShellZsh:        {filepath.Join(zdot, ".zsh_history"), filepath.Join(zdot, ".histfile")},
+		ShellFish:       {filepath.Join(dataHome, "fish", "fish_history")},
+		ShellPowerShell: {filepath.Join(dataHome, "powershell", "PSReadLine", "ConsoleHost_history.txt")},
+	}
+}
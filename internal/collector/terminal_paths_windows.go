@@ -0,0 +1,19 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// defaultHistoryFiles Windows 下各 shell 的默认历史文件（bash/zsh 来自 Git Bash、MSYS2）
+func defaultHistoryFiles() map[string][]string {
+	out := make(map[string][]string)
+	if appData := os.Getenv("APPDATA"); appData != "" {
+		out[ShellPowerShell] = []string{filepath.Join(appData, "Microsoft", "Windows", "PowerShell", "PSReadLine", "ConsoleHost_history.txt")}
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		out[ShellBash] = []string{filepath.Join(home, ".bash_history")}
+		out[ShellZsh] = []string{filepath.Join(home, ".zsh_history")}
+	}
+	return out
+}
@@ -0,0 +1,344 @@
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// unifiedContextLines hunk 上下文行数（与 git diff 默认一致）
+	unifiedContextLines = 3
+	// maxMyersEdits Myers 搜索的最大编辑距离；超过后退化为“整段替换”，避免超大改动占用过多内存
+	maxMyersEdits = 1000
+)
+
+type lineOpKind byte
+
+const (
+	lineEqual  lineOpKind = ' '
+	lineDelete lineOpKind = '-'
+	lineInsert lineOpKind = '+'
+)
+
+type lineOp struct {
+	kind lineOpKind
+	text string
+}
+
+// unifiedDiff 在进程内生成 oldText -> newText 的 unified diff（git diff 风格），返回内容与增删行数。
+// 两者相同时返回空字符串。
+func unifiedDiff(oldName, newName, oldText, newText string) (string, int, int) {
+	if oldText == newText {
+		return "", 0, 0
+	}
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var added, deleted int
+	for _, op := range ops {
+		switch op.kind {
+		case lineInsert:
+			added++
+		case lineDelete:
+			deleted++
+		}
+	}
+	if added == 0 && deleted == 0 {
+		return "", 0, 0
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	writeHunks(&sb, ops, unifiedContextLines)
+	return sb.String(), added, deleted
+}
+
+// splitLines 按行切分；末尾换行不产生空行
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines 先裁掉公共前后缀，再对中间段做 Myers 最短编辑脚本
+func diffLines(a, b []string) []lineOp {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	ops := make([]lineOp, 0, len(a)+len(b))
+	for _, l := range a[:pre] {
+		ops = append(ops, lineOp{lineEqual, l})
+	}
+	ops = append(ops, myersDiff(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for _, l := range a[len(a)-suf:] {
+		ops = append(ops, lineOp{lineEqual, l})
+	}
+	return ops
+}
+
+// myersDiff 经典 O((N+M)D) 算法；trace 只保存每轮 [-d, d] 区间以控制内存
+func myersDiff(a, b []string) []lineOp {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return replaceAll(a, b)
+	}
+
+	maxD := n + m
+	if maxD > maxMyersEdits {
+		maxD = maxMyersEdits
+	}
+	off := maxD + 1
+	v := make([]int, 2*off+1)
+	trace := make([][]int, 0, 16)
+
+	for d := 0; d <= maxD; d++ {
+		snap := make([]int, 2*d+3)
+		copy(snap, v[off-d-1:off+d+2])
+		trace = append(trace, snap)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				return backtrackMyers(a, b, trace)
+			}
+		}
+	}
+	return replaceAll(a, b)
+}
+
+func backtrackMyers(a, b []string, trace [][]int) []lineOp {
+	x, y := len(a), len(b)
+	rev := make([]lineOp, 0, x+y)
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		snap := trace[d]
+		at := func(k int) int { return snap[k+d+1] } // snap 覆盖 [-d-1, d+1]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := 0
+		if d > 0 {
+			prevX = at(prevK)
+		}
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			rev = append(rev, lineOp{lineEqual, a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				rev = append(rev, lineOp{lineInsert, b[prevY]})
+			} else {
+				rev = append(rev, lineOp{lineDelete, a[prevX]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(rev)-1; i < j; i, j = i+1, j-1 {
+		rev[i], rev[j] = rev[j], rev[i]
+	}
+	return rev
+}
+
+func replaceAll(a, b []string) []lineOp {
+	ops := make([]lineOp, 0, len(a)+len(b))
+	for _, l := range a {
+		ops = append(ops, lineOp{lineDelete, l})
+	}
+	for _, l := range b {
+		ops = append(ops, lineOp{lineInsert, l})
+	}
+	return ops
+}
+
+// writeHunks 将编辑脚本按上下文合并为 @@ hunk
+func writeHunks(sb *strings.Builder, ops []lineOp, ctx int) {
+	// oldPos/newPos[i]：ops[i] 之前已消费的旧/新行数
+	oldPos := make([]int, len(ops)+1)
+	newPos := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldPos[i+1], newPos[i+1] = oldPos[i], newPos[i]
+		if op.kind != lineInsert {
+			oldPos[i+1]++
+		}
+		if op.kind != lineDelete {
+			newPos[i+1]++
+		}
+	}
+
+	i := 0
+	for i < len(ops) {
+		for i < len(ops) && ops[i].kind == lineEqual {
+			i++
+		}
+		if i >= len(ops) {
+			return
+		}
+		start := max(0, i-ctx)
+
+		// 向后扩展：两个改动之间的相同行不超过 2*ctx 时合并为同一个 hunk
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != lineEqual {
+				end = j
+				continue
+			}
+			if j-end > 2*ctx {
+				break
+			}
+		}
+		stop := min(len(ops), end+ctx+1)
+
+		oldCount := oldPos[stop] - oldPos[start]
+		newCount := newPos[stop] - newPos[start]
+		fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldPos[start], oldCount), hunkRange(newPos[start], newCount))
+		for _, op := range ops[start:stop] {
+			sb.WriteByte(byte(op.kind))
+			sb.WriteString(op.text)
+			sb.WriteByte('\n')
+		}
+		i = stop
+	}
+}
+
+func hunkRange(pos, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", pos)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", pos+1)
+	}
+	return fmt.Sprintf("%d,%d", pos+1, count)
+}
+
+// applyUnifiedDiff 把单文件 unified diff 按行应用到 base；reverse 时反向应用（由新版本还原旧版本）。
+// 上下文或删除行对不上时返回 false。
+func applyUnifiedDiff(base []string, patch string, reverse bool) ([]string, bool) {
+	del, ins := lineDelete, lineInsert
+	if reverse {
+		del, ins = ins, del
+	}
+	var out []string
+	pos := 0            // base 中已消费的行数
+	left, right := 0, 0 // 当前 hunk 剩余的旧/新行数（按应用方向）
+	for _, line := range strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n") {
+		if left == 0 && right == 0 {
+			if !strings.HasPrefix(line, "@@ ") {
+				continue // 文件头或 hunk 之间的内容
+			}
+			oldStart, oldCount, newStart, newCount, ok := parseHunkHeader(line)
+			if !ok {
+				return nil, false
+			}
+			start := oldStart
+			left, right = oldCount, newCount
+			if reverse {
+				start = newStart
+				left, right = newCount, oldCount
+			}
+			// 区间非空时起始行号从 1 开始；为空时指向插入位置之前的一行
+			if left > 0 {
+				start--
+			}
+			if start < pos || start > len(base) {
+				return nil, false
+			}
+			out = append(out, base[pos:start]...)
+			pos = start
+			continue
+		}
+		kind, text := lineEqual, ""
+		if line != "" {
+			kind, text = lineOpKind(line[0]), line[1:]
+		}
+		switch kind {
+		case lineEqual, del:
+			// untrackedFileDiff 把末尾换行后的空串也记为一行，文件末尾按空行匹配
+			if pos < len(base) {
+				if base[pos] != text {
+					return nil, false
+				}
+				pos++
+			} else if text != "" {
+				return nil, false
+			}
+			left--
+			if kind == lineEqual {
+				out = append(out, text)
+				right--
+			}
+		case ins:
+			out = append(out, text)
+			right--
+		case '\\': // \ No newline at end of file
+		default:
+			return nil, false
+		}
+		if left < 0 || right < 0 {
+			return nil, false
+		}
+	}
+	if left != 0 || right != 0 {
+		return nil, false
+	}
+	return append(out, base[pos:]...), true
+}
+
+// parseHunkHeader 解析 "@@ -l,s +l,s @@"；省略行数时为 1
+func parseHunkHeader(line string) (oldStart, oldCount, newStart, newCount int, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || !strings.HasPrefix(fields[3], "@@") {
+		return 0, 0, 0, 0, false
+	}
+	parse := func(s string, sign byte) (int, int, bool) {
+		if len(s) < 2 || s[0] != sign {
+			return 0, 0, false
+		}
+		startStr, countStr, hasCount := strings.Cut(s[1:], ",")
+		start, err := strconv.Atoi(startStr)
+		if err != nil || start < 0 {
+			return 0, 0, false
+		}
+		count := 1
+		if hasCount {
+			if count, err = strconv.Atoi(countStr); err != nil || count < 0 {
+				return 0, 0, false
+			}
+		}
+		return start, count, true
+	}
+	oldStart, oldCount, ok1 := parse(fields[1], '-')
+	newStart, newCount, ok2 := parse(fields[2], '+')
+	return oldStart, oldCount, newStart, newCount, ok1 && ok2
+}
@@ -0,0 +1,99 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff_singleHunk(t *testing.T) {
+	oldText := "a\nb\nc\nd\ne\n"
+	newText := "a\nb\nC\nd\ne\nf\n"
+
+	got, added, deleted := unifiedDiff("a/x.txt", "b/x.txt", oldText, newText)
+	want := "--- a/x.txt\n+++ b/x.txt\n@@ -1,5 +1,6 @@\n a\n b\n-c\n+C\n d\n e\n+f\n"
+	if got != want {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+	if added != 2 || deleted != 1 {
+		t.Fatalf("expected +2/-1, got +%d/-%d", added, deleted)
+	}
+}
+
+func TestUnifiedDiff_splitsDistantHunks(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, fmt.Sprintf("line%d", i))
+	}
+	oldText := strings.Join(lines, "\n") + "\n"
+	lines[1] = "changed2"
+	lines[27] = "changed28"
+	newText := strings.Join(lines, "\n") + "\n"
+
+	got, added, deleted := unifiedDiff("a/f", "b/f", oldText, newText)
+	if n := strings.Count(got, "\n@@ "); n != 2 {
+		t.Fatalf("expected 2 hunks, got %d:\n%s", n, got)
+	}
+	if !strings.Contains(got, "@@ -1,5 +1,5 @@") || !strings.Contains(got, "@@ -25,6 +25,6 @@") {
+		t.Fatalf("unexpected hunk headers:\n%s", got)
+	}
+	if added != 2 || deleted != 2 {
+		t.Fatalf("expected +2/-2, got +%d/-%d", added, deleted)
+	}
+}
+
+func TestUnifiedDiff_emptySides(t *testing.T) {
+	if got, _, _ := unifiedDiff("a/f", "b/f", "same\n", "same\n"); got != "" {
+		t.Fatalf("expected empty diff for identical input, got %q", got)
+	}
+
+	got, added, deleted := unifiedDiff("a/f", "b/f", "", "x\ny\n")
+	if !strings.Contains(got, "@@ -0,0 +1,2 @@") || added != 2 || deleted != 0 {
+		t.Fatalf("unexpected diff for new content (+%d/-%d):\n%s", added, deleted, got)
+	}
+
+	got, added, deleted = unifiedDiff("a/f", "b/f", "x\n", "")
+	if !strings.Contains(got, "@@ -1 +0,0 @@") || added != 0 || deleted != 1 {
+		t.Fatalf("unexpected diff for removed content (+%d/-%d):\n%s", added, deleted, got)
+	}
+}
+
+func TestApplyUnifiedDiff_roundTrip(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, fmt.Sprintf("line%d", i))
+	}
+	oldText := strings.Join(lines, "\n") + "\n"
+	lines[1] = "changed2"
+	lines = append(lines[:20], lines[22:]...)
+	newText := strings.Join(lines, "\n") + "\nextra\n"
+	patch, _, _ := unifiedDiff("a/f", "b/f", oldText, newText)
+
+	got, ok := applyUnifiedDiff(splitLines(oldText), patch, false)
+	if !ok || strings.Join(got, "\n") != strings.Join(splitLines(newText), "\n") {
+		t.Fatalf("forward apply = %v, %v", ok, got)
+	}
+	got, ok = applyUnifiedDiff(splitLines(newText), patch, true)
+	if !ok || strings.Join(got, "\n") != strings.Join(splitLines(oldText), "\n") {
+		t.Fatalf("reverse apply = %v, %v", ok, got)
+	}
+
+	// 上下文对不上
+	if _, ok := applyUnifiedDiff(splitLines(strings.Replace(oldText, "line3", "other", 1)), patch, false); ok {
+		t.Fatal("expected mismatch")
+	}
+}
+
+func TestApplyUnifiedDiff_untrackedFile(t *testing.T) {
+	// untrackedFileDiff 的格式：末尾换行后的空串也计为一行
+	patch := "--- /dev/null\n+++ b/x.go\n@@ -0,0 +1,2 @@\n+package x\n+\n"
+
+	got, ok := applyUnifiedDiff(nil, patch, false)
+	if !ok || strings.Join(got, "\n") != "package x\n" {
+		t.Fatalf("forward apply = %v, %q", ok, got)
+	}
+	got, ok = applyUnifiedDiff(splitLines("package x\n"), patch, true)
+	if !ok || len(got) != 0 {
+		t.Fatalf("reverse apply = %v, %q", ok, got)
+	}
+}
@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WakaTimeExportHeartbeat WakaTime 数据导出中的一条心跳
+type WakaTimeExportHeartbeat struct {
+	Entity    string  `json:"entity"`
+	Type      string  `json:"type"`
+	Category  string  `json:"category"`
+	Time      float64 `json:"time"` // Unix 秒
+	Project   string  `json:"project"`
+	Branch    string  `json:"branch"`
+	Language  string  `json:"language"`
+	Lines     int     `json:"lines"`
+	LineNo    int     `json:"lineno"`
+	IsWrite   bool    `json:"is_write"`
+	UserAgent string  `json:"user_agent"` // 导出中通常只有 user_agent_id，此时为空
+}
+
+type wakaTimeDayJSON struct {
+	Date       string                    `json:"date"`
+	Heartbeats []WakaTimeExportHeartbeat `json:"heartbeats"`
+}
+
+// ReadWakaTimeExport 按批读取 WakaTime 心跳：支持数据导出（{"days": [{"date", "heartbeats"}]}，逐天回调）、
+// 心跳接口的响应（{"data": [...]}）与心跳数组
+func ReadWakaTimeExport(r io.Reader, fn func([]WakaTimeExportHeartbeat) error) error {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("解析 WakaTime 导出失败: %w", err)
+	}
+	switch tok {
+	case json.Delim('['):
+		return readWakaTimeArray(dec, fn)
+	case json.Delim('{'):
+	default:
+		return fmt.Errorf("解析 WakaTime 导出失败: 不支持的格式")
+	}
+
+	for dec.More() {
+		key, err := readKey(dec)
+		if err != nil {
+			return fmt.Errorf("解析 WakaTime 导出失败: %w", err)
+		}
+		switch key {
+		case "days":
+			if err := expectDelim(dec, '['); err != nil {
+				return fmt.Errorf("解析 WakaTime days 失败: %w", err)
+			}
+			for dec.More() {
+				var day wakaTimeDayJSON
+				if err := dec.Decode(&day); err != nil {
+					return fmt.Errorf("解析 WakaTime 心跳失败: %w", err)
+				}
+				if len(day.Heartbeats) == 0 {
+					continue
+				}
+				if err := fn(day.Heartbeats); err != nil {
+					return err
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				return fmt.Errorf("解析 WakaTime days 失败: %w", err)
+			}
+		case "data":
+			if err := expectDelim(dec, '['); err != nil {
+				return fmt.Errorf("解析 WakaTime data 失败: %w", err)
+			}
+			if err := readWakaTimeArray(dec, fn); err != nil {
+				return err
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return fmt.Errorf("解析 WakaTime 导出失败: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// readWakaTimeArray 读取心跳数组（开头的 '[' 已读取），每 1000 条回调一次
+func readWakaTimeArray(dec *json.Decoder, fn func([]WakaTimeExportHeartbeat) error) error {
+	const batch = 1000
+	beats := make([]WakaTimeExportHeartbeat, 0, batch)
+	for dec.More() {
+		var hb WakaTimeExportHeartbeat
+		if err := dec.Decode(&hb); err != nil {
+			return fmt.Errorf("解析 WakaTime 心跳失败: %w", err)
+		}
+		beats = append(beats, hb)
+		if len(beats) == batch {
+			if err := fn(beats); err != nil {
+				return err
+			}
+			beats = make([]WakaTimeExportHeartbeat, 0, batch)
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("解析 WakaTime 心跳失败: %w", err)
+	}
+	if len(beats) == 0 {
+		return nil
+	}
+	return fn(beats)
+}
@@ -3,10 +3,7 @@
 package collector
 
 import (
-	"errors"
-	"fmt"
 	"log/slog"
-	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
@@ -36,20 +33,34 @@ const (
 	PROCESS_VM_READ           = 0x0010
 )
 
-// WindowInfo 窗口信息
-type WindowInfo struct {
-	HWND      uintptr // 窗口句柄
-	Title     string  // 窗口标题
-	ProcessID uint32  // 进程 ID
-	AppName   string  // 应用程序名称 (如 Chrome.exe)
-}
-
 type lastInputInfo struct {
 	cbSize uint32
 	dwTime uint32
 }
 
-var ErrNoForegroundWindow = errors.New("no foreground window")
+// win32Source 基于 user32 的前台窗口数据源
+type win32Source struct{}
+
+// DefaultForegroundSource 当前平台的前台窗口数据源
+func DefaultForegroundSource() ForegroundSource {
+	return win32Source{}
+}
+
+func (win32Source) ForegroundWindow() (*WindowInfo, error) {
+	w, err := GetForegroundWindowInfo()
+	if err != nil {
+		return nil, err
+	}
+	// 系统窗口（桌面/开始菜单/锁屏等）同样需要切段，避免上一个窗口持续累计
+	if w.IsSystemWindow() {
+		return nil, ErrNoForegroundWindow
+	}
+	return w, nil
+}
+
+func (win32Source) IdleDuration() (time.Duration, error) {
+	return GetIdleDuration()
+}
 
 // GetIdleDuration 返回系统空闲时长（基于最后一次用户输入时间）
 func GetIdleDuration() (time.Duration, error) {
@@ -149,33 +160,6 @@ func getProcessName(pid uint32) string {
 	return syscall.UTF16ToString(buf)
 }
 
-// String 格式化输出窗口信息
-func (w *WindowInfo) String() string {
-	return fmt.Sprintf("[%s] %s (PID: %d)", w.AppName, w.Title, w.ProcessID)
-}
-
-// IsSameWindow 判断是否是同一个窗口
-func (w *WindowInfo) IsSameWindow(other *WindowInfo) bool {
-	if other == nil {
-		return false
-	}
-	return w.HWND == other.HWND
-}
-
-// IsSameApp 判断是否是同一个应用
-func (w *WindowInfo) IsSameApp(other *WindowInfo) bool {
-	if other == nil {
-		return false
-	}
-	return strings.EqualFold(w.AppName, other.AppName)
-}
-
-// GetAppBaseName 获取应用基础名称（去除 .exe 后缀）
-func (w *WindowInfo) GetAppBaseName() string {
-	name := filepath.Base(w.AppName)
-	return strings.TrimSuffix(name, filepath.Ext(name))
-}
-
 // IsSystemWindow 判断是否是系统窗口（应忽略）
 func (w *WindowInfo) IsSystemWindow() bool {
 	ignoreApps := []string{
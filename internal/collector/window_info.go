@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// WindowInfo 窗口信息
+type WindowInfo struct {
+	HWND      uintptr // 窗口句柄（Linux 下为 X11 Window ID）
+	Title     string  // 窗口标题
+	ProcessID uint32  // 进程 ID
+	AppName   string  // 应用程序名称 (如 Chrome.exe / firefox)
+}
+
+var ErrNoForegroundWindow = errors.New("no foreground window")
+
+// String 格式化输出窗口信息
+func (w *WindowInfo) String() string {
+	return fmt.Sprintf("[%s] %s (PID: %d)", w.AppName, w.Title, w.ProcessID)
+}
+
+// IsSameWindow 判断是否是同一个窗口
+func (w *WindowInfo) IsSameWindow(other *WindowInfo) bool {
+	if other == nil {
+		return false
+	}
+	return w.HWND == other.HWND
+}
+
+// IsSameApp 判断是否是同一个应用
+func (w *WindowInfo) IsSameApp(other *WindowInfo) bool {
+	if other == nil {
+		return false
+	}
+	return strings.EqualFold(w.AppName, other.AppName)
+}
+
+// GetAppBaseName 获取应用基础名称（去除 .exe 后缀）
+func (w *WindowInfo) GetAppBaseName() string {
+	name := filepath.Base(w.AppName)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
@@ -0,0 +1,320 @@
+//go:build linux
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/screensaver"
+	"github.com/jezek/xgb/xproto"
+)
+
+// x11RedialInterval 连接 X server 失败后的重试间隔（避免每次轮询都重连刷日志）
+const x11RedialInterval = 5 * time.Second
+
+// x11PropMaxLongs GetProperty 读取上限（32 位单位），足以覆盖常见窗口标题
+const x11PropMaxLongs = 1024
+
+var errX11IdleUnsupported = errors.New("x11: MIT-SCREEN-SAVER extension unavailable")
+
+// x11Session 复用的 X11 连接（EWMH 属性读取 + MIT-SCREEN-SAVER 空闲检测）
+type x11Session struct {
+	conn        *xgb.Conn
+	root        xproto.Window
+	atoms       map[string]xproto.Atom
+	screenSaver bool
+}
+
+var (
+	x11Mu       sync.Mutex
+	x11         *x11Session
+	x11DialedAt time.Time
+)
+
+// withX11 在共享连接上执行 fn；请求失败时丢弃连接，下次轮询重连
+func withX11(fn func(s *x11Session) error) error {
+	x11Mu.Lock()
+	defer x11Mu.Unlock()
+
+	if x11 == nil {
+		if !x11DialedAt.IsZero() && time.Since(x11DialedAt) < x11RedialInterval {
+			return errors.New("x11 未连接")
+		}
+		x11DialedAt = time.Now()
+		s, err := openX11Session()
+		if err != nil {
+			return fmt.Errorf("连接 X server 失败: %w", err)
+		}
+		x11 = s
+	}
+
+	if err := fn(x11); err != nil {
+		if !errors.Is(err, ErrNoForegroundWindow) && !errors.Is(err, errX11IdleUnsupported) {
+			x11.conn.Close()
+			x11 = nil
+		}
+		return err
+	}
+	return nil
+}
+
+func openX11Session() (*x11Session, error) {
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, err
+	}
+	s := &x11Session{
+		conn:  conn,
+		root:  xproto.Setup(conn).DefaultScreen(conn).Root,
+		atoms: make(map[string]xproto.Atom),
+	}
+	if err := screensaver.Init(conn); err != nil {
+		slog.Debug("X11 不支持 MIT-SCREEN-SAVER，空闲检测不可用", "error", err)
+	} else {
+		s.screenSaver = true
+	}
+	return s, nil
+}
+
+func (s *x11Session) atom(name string) (xproto.Atom, error) {
+	if a, ok := s.atoms[name]; ok {
+		return a, nil
+	}
+	reply, err := xproto.InternAtom(s.conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("InternAtom %s 失败: %w", name, err)
+	}
+	s.atoms[name] = reply.Atom
+	return reply.Atom, nil
+}
+
+func (s *x11Session) property(win xproto.Window, name string) (*xproto.GetPropertyReply, error) {
+	a, err := s.atom(name)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := xproto.GetProperty(s.conn, false, win, a, xproto.GetPropertyTypeAny, 0, x11PropMaxLongs).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("读取属性 %s 失败: %w", name, err)
+	}
+	return reply, nil
+}
+
+// atomList 读取 ATOM[] 类型属性（如 _NET_WM_STATE）
+func (s *x11Session) atomList(win xproto.Window, name string) ([]xproto.Atom, error) {
+	reply, err := s.property(win, name)
+	if err != nil {
+		return nil, err
+	}
+	if reply.Format != 32 {
+		return nil, nil
+	}
+	out := make([]xproto.Atom, 0, reply.ValueLen)
+	for i := 0; i+4 <= len(reply.Value); i += 4 {
+		out = append(out, xproto.Atom(xgb.Get32(reply.Value[i:])))
+	}
+	return out, nil
+}
+
+func (s *x11Session) hasAtom(win xproto.Window, prop string, want string) (bool, error) {
+	list, err := s.atomList(win, prop)
+	if err != nil {
+		return false, err
+	}
+	target, err := s.atom(want)
+	if err != nil {
+		return false, err
+	}
+	for _, a := range list {
+		if a == target {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// x11Source 基于 X11 EWMH 的前台窗口数据源
+type x11Source struct{}
+
+// DefaultForegroundSource 当前平台的前台窗口数据源
+func DefaultForegroundSource() ForegroundSource {
+	return x11Source{}
+}
+
+func (x11Source) ForegroundWindow() (*WindowInfo, error) {
+	w, err := GetForegroundWindowInfo()
+	if err != nil {
+		return nil, err
+	}
+	// 桌面 shell/锁屏等系统窗口同样需要切段，避免上一个窗口持续累计
+	if w.IsSystemWindow() {
+		return nil, ErrNoForegroundWindow
+	}
+	return w, nil
+}
+
+func (x11Source) IdleDuration() (time.Duration, error) {
+	return GetIdleDuration()
+}
+
+// GetIdleDuration 返回系统空闲时长（MIT-SCREEN-SAVER ms_since_user_input）
+func GetIdleDuration() (time.Duration, error) {
+	var idle time.Duration
+	err := withX11(func(s *x11Session) error {
+		if !s.screenSaver {
+			return errX11IdleUnsupported
+		}
+		info, err := screensaver.QueryInfo(s.conn, xproto.Drawable(s.root)).Reply()
+		if err != nil {
+			return fmt.Errorf("查询空闲时长失败: %w", err)
+		}
+		idle = time.Duration(info.MsSinceUserInput) * time.Millisecond
+		return nil
+	})
+	return idle, err
+}
+
+// GetForegroundWindowInfo 获取当前前台窗口信息（EWMH _NET_ACTIVE_WINDOW）
+func GetForegroundWindowInfo() (*WindowInfo, error) {
+	var info *WindowInfo
+	err := withX11(func(s *x11Session) error {
+		reply, err := s.property(s.root, "_NET_ACTIVE_WINDOW")
+		if err != nil {
+			return err
+		}
+		if reply.Format != 32 || len(reply.Value) < 4 {
+			return ErrNoForegroundWindow
+		}
+		win := xproto.Window(xgb.Get32(reply.Value))
+		if win == 0 {
+			return ErrNoForegroundWindow
+		}
+
+		// 与 Windows 的 IsIconic 对齐：最小化窗口不计入“正在工作”时间
+		if hidden, err := s.hasAtom(win, "_NET_WM_STATE", "_NET_WM_STATE_HIDDEN"); err != nil {
+			return err
+		} else if hidden {
+			return ErrNoForegroundWindow
+		}
+		// 桌面/面板获得焦点时等同于没有前台应用窗口
+		for _, t := range []string{"_NET_WM_WINDOW_TYPE_DESKTOP", "_NET_WM_WINDOW_TYPE_DOCK"} {
+			if ok, err := s.hasAtom(win, "_NET_WM_WINDOW_TYPE", t); err != nil {
+				return err
+			} else if ok {
+				return ErrNoForegroundWindow
+			}
+		}
+
+		title, err := s.windowTitle(win)
+		if err != nil {
+			return err
+		}
+		pid, err := s.windowPID(win)
+		if err != nil {
+			return err
+		}
+		appName := processNameFromProc(pid)
+		if appName == "" {
+			appName = s.windowClass(win)
+		}
+		if appName == "" {
+			appName = "Unknown"
+		}
+
+		info = &WindowInfo{
+			HWND:      uintptr(win),
+			Title:     title,
+			ProcessID: pid,
+			AppName:   appName,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// windowTitle 优先 _NET_WM_NAME（UTF-8），回退 WM_NAME
+func (s *x11Session) windowTitle(win xproto.Window) (string, error) {
+	for _, name := range []string{"_NET_WM_NAME", "WM_NAME"} {
+		reply, err := s.property(win, name)
+		if err != nil {
+			return "", err
+		}
+		if reply.Format == 8 && len(reply.Value) > 0 {
+			return strings.TrimRight(string(reply.Value[:reply.ValueLen]), "\x00"), nil
+		}
+	}
+	return "", nil
+}
+
+func (s *x11Session) windowPID(win xproto.Window) (uint32, error) {
+	reply, err := s.property(win, "_NET_WM_PID")
+	if err != nil {
+		return 0, err
+	}
+	if reply.Format != 32 || len(reply.Value) < 4 {
+		return 0, nil
+	}
+	return xgb.Get32(reply.Value), nil
+}
+
+// windowClass 读取 WM_CLASS 的 class 部分（instance\0class\0）
+func (s *x11Session) windowClass(win xproto.Window) string {
+	reply, err := s.property(win, "WM_CLASS")
+	if err != nil || reply.Format != 8 {
+		return ""
+	}
+	parts := strings.Split(strings.TrimRight(string(reply.Value[:reply.ValueLen]), "\x00"), "\x00")
+	return strings.TrimSpace(parts[len(parts)-1])
+}
+
+// processNameFromProc 根据 PID 读取 /proc/<pid>/comm
+func processNameFromProc(pid uint32) string {
+	if pid == 0 {
+		return ""
+	}
+	b, err := os.ReadFile("/proc/" + strconv.FormatUint(uint64(pid), 10) + "/comm")
+	if err != nil {
+		slog.Debug("读取进程名失败", "pid", pid, "error", err)
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// IsSystemWindow 判断是否是系统窗口（应忽略）
+func (w *WindowInfo) IsSystemWindow() bool {
+	// /proc/<pid>/comm 最长 15 字节，列表按截断后的名字匹配
+	ignoreApps := []string{
+		"gnome-shell",
+		"plasmashell",
+		"xfdesktop",
+		"xfce4-panel",
+		"xscreensaver",
+		"i3lock",
+		"light-locker",
+		"kscreenlocker_g",
+	}
+
+	for _, app := range ignoreApps {
+		if strings.EqualFold(w.AppName, app) {
+			return true
+		}
+	}
+
+	// 忽略空标题的窗口
+	if strings.TrimSpace(w.Title) == "" {
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,123 @@
+//go:build linux
+
+package collector
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// x11Switcher 脚本化的“窗口管理器”：在 Xvfb 上创建窗口并改写 _NET_ACTIVE_WINDOW
+type x11Switcher struct {
+	t    *testing.T
+	conn *xgb.Conn
+	root xproto.Window
+}
+
+func newX11Switcher(t *testing.T) *x11Switcher {
+	t.Helper()
+	if os.Getenv("DISPLAY") == "" {
+		t.Skip("未设置 DISPLAY（可用 xvfb-run go test ./internal/collector/ 运行）")
+	}
+	conn, err := xgb.NewConn()
+	if err != nil {
+		t.Skipf("无法连接 X server: %v", err)
+	}
+	t.Cleanup(conn.Close)
+	return &x11Switcher{t: t, conn: conn, root: xproto.Setup(conn).DefaultScreen(conn).Root}
+}
+
+func (w *x11Switcher) atom(name string) xproto.Atom {
+	reply, err := xproto.InternAtom(w.conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		w.t.Fatalf("InternAtom %s: %v", name, err)
+	}
+	return reply.Atom
+}
+
+func (w *x11Switcher) setString(win xproto.Window, prop, typ, value string) {
+	xproto.ChangeProperty(w.conn, xproto.PropModeReplace, win, w.atom(prop), w.atom(typ), 8, uint32(len(value)), []byte(value))
+}
+
+func (w *x11Switcher) setCardinal(win xproto.Window, prop, typ string, value uint32) {
+	buf := make([]byte, 4)
+	xgb.Put32(buf, value)
+	xproto.ChangeProperty(w.conn, xproto.PropModeReplace, win, w.atom(prop), w.atom(typ), 32, 1, buf)
+}
+
+func (w *x11Switcher) newWindow(title string) xproto.Window {
+	id, err := xproto.NewWindowId(w.conn)
+	if err != nil {
+		w.t.Fatalf("NewWindowId: %v", err)
+	}
+	win := xproto.Window(id)
+	xproto.CreateWindow(w.conn, 0, win, w.root, 0, 0, 100, 100, 0, xproto.WindowClassInputOutput, 0, 0, nil)
+	w.setString(win, "_NET_WM_NAME", "UTF8_STRING", title)
+	w.setCardinal(win, "_NET_WM_PID", "CARDINAL", uint32(os.Getpid()))
+	return win
+}
+
+func (w *x11Switcher) activate(win xproto.Window) {
+	w.setCardinal(w.root, "_NET_ACTIVE_WINDOW", "WINDOW", uint32(win))
+	// GetInputFocus 作为同步点，确保上面的请求已被服务器处理
+	if _, err := xproto.GetInputFocus(w.conn).Reply(); err != nil {
+		w.t.Fatalf("sync: %v", err)
+	}
+}
+
+func TestGetForegroundWindowInfo_X11(t *testing.T) {
+	sw := newX11Switcher(t)
+	editor := sw.newWindow("main.go - WorkMirror - 编辑器")
+	sw.activate(editor)
+
+	info, err := GetForegroundWindowInfo()
+	if err != nil {
+		t.Fatalf("GetForegroundWindowInfo: %v", err)
+	}
+	if info.HWND != uintptr(editor) {
+		t.Fatalf("HWND=%d, want %d", info.HWND, editor)
+	}
+	if info.Title != "main.go - WorkMirror - 编辑器" {
+		t.Fatalf("Title=%q", info.Title)
+	}
+	if info.ProcessID != uint32(os.Getpid()) {
+		t.Fatalf("ProcessID=%d, want %d", info.ProcessID, os.Getpid())
+	}
+	if want := processNameFromProc(uint32(os.Getpid())); info.AppName != want {
+		t.Fatalf("AppName=%q, want %q", info.AppName, want)
+	}
+
+	sw.activate(0)
+	if _, err := GetForegroundWindowInfo(); err != ErrNoForegroundWindow {
+		t.Fatalf("expected ErrNoForegroundWindow, got %v", err)
+	}
+}
+
+func TestWindowCollector_X11Switch(t *testing.T) {
+	sw := newX11Switcher(t)
+	a := sw.newWindow("A")
+	b := sw.newWindow("B")
+
+	c := NewWindowCollector(&CollectorConfig{PollIntervalMs: 10, MinDurationSec: 0, BufferSize: 8})
+	c.idleThreshold = 0 // Xvfb 的空闲时长不可控，这里只验证切换语义
+
+	sw.activate(a)
+	c.poll()
+	sw.activate(b)
+	c.poll()
+
+	select {
+	case ev := <-c.Events():
+		if ev.Title != "A" || ev.Source != "window" {
+			t.Fatalf("unexpected event: %+v", ev)
+		}
+		if ev.Metadata["hwnd"] != uintptr(a) {
+			t.Fatalf("hwnd=%v, want %d", ev.Metadata["hwnd"], a)
+		}
+	default:
+		t.Fatalf("expected an event for window A after switching to B")
+	}
+}
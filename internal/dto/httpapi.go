@@ -96,6 +96,8 @@ type DailyTrendStatDTO struct {
 	TotalDiffs      int64  `json:"total_diffs"`
 	TotalCodingMins int64  `json:"total_coding_mins"`
 	SessionCount    int64  `json:"session_count"`
+
+	BrowsingCategories []CategoryTrendDTO `json:"browsing_categories,omitempty"`
 }
 
 type TrendReportDTO struct {
@@ -109,6 +111,33 @@ type TrendReportDTO struct {
 	TopSkills       []SkillTrendDTO     `json:"top_skills"`
 	Bottlenecks     []string            `json:"bottlenecks"`
 	DailyStats      []DailyTrendStatDTO `json:"daily_stats,omitempty"`
+	TopDomains      []DomainTrendDTO    `json:"top_domains,omitempty"`
+	BrowsingMins    int64               `json:"browsing_mins"`
+
+	BrowsingCategories []CategoryTrendDTO   `json:"browsing_categories,omitempty"`
+	SessionBrowsing    []SessionBrowsingDTO `json:"session_browsing,omitempty"`
+}
+
+type DomainTrendDTO struct {
+	Domain   string `json:"domain"`
+	Category string `json:"category"`
+	Skill    string `json:"skill,omitempty"`
+	Visits   int64  `json:"visits"`
+	Minutes  int64  `json:"minutes"`
+}
+
+type CategoryTrendDTO struct {
+	Category string `json:"category"` // docs | code | qa | video | social | news | chat | dev | other | 自定义
+	Visits   int64  `json:"visits"`
+	Minutes  int64  `json:"minutes"`
+}
+
+type SessionBrowsingDTO struct {
+	SessionID  int64              `json:"session_id"`
+	Date       string             `json:"date"`
+	StartTime  int64              `json:"start_time"`
+	EndTime    int64              `json:"end_time"`
+	Categories []CategoryTrendDTO `json:"categories"`
 }
 
 type LanguageTrendDTO struct {
@@ -139,11 +168,13 @@ type DiffDetailDTO struct {
 	FileName     string   `json:"file_name"`
 	Language     string   `json:"language"`
 	DiffContent  string   `json:"diff_content"`
+	Cumulative   string   `json:"cumulative_content,omitempty"` // 相对 HEAD 的累计改动
 	Insight      string   `json:"insight"`
 	Skills       []string `json:"skills"`
 	LinesAdded   int      `json:"lines_added"`
 	LinesDeleted int      `json:"lines_deleted"`
 	Timestamp    int64    `json:"timestamp"`
+	Backfilled   bool     `json:"backfilled,omitempty"`
 }
 
 type SettingsDTO struct {
@@ -158,11 +189,31 @@ type SettingsDTO struct {
 	DiffWatchPaths     []string `json:"diff_watch_paths"`
 	BrowserEnabled     bool     `json:"browser_enabled"`
 	BrowserHistoryPath string   `json:"browser_history_path"`
+	BrowserBrowsers    []string `json:"browser_browsers"` // 为空表示全部
+	// 本机发现的浏览器 profile（含禁用状态）
+	BrowserProfiles []BrowserProfileDTO `json:"browser_profiles"`
+	// 提取搜索词作为会话证据（默认关闭）
+	BrowserSearchTerms bool `json:"browser_search_terms"`
+	// 技能相关的文档阅读计入经验（默认关闭）
+	BrowserReadingExp bool `json:"browser_reading_exp"`
+	// 读取 shell 历史作为会话证据（默认关闭）
+	TerminalEnabled bool `json:"terminal_enabled"`
+	// 接收编辑器插件（WakaTime 协议）心跳
+	EditorEnabled bool `json:"editor_enabled"`
 
 	PrivacyEnabled  bool     `json:"privacy_enabled"`
 	PrivacyPatterns []string `json:"privacy_patterns"`
 }
 
+type BrowserProfileDTO struct {
+	Key         string `json:"key"` // <browser>/<profile>
+	Browser     string `json:"browser"`
+	Profile     string `json:"profile"`
+	Name        string `json:"name"`
+	HistoryPath string `json:"history_path"`
+	Enabled     bool   `json:"enabled"`
+}
+
 type AISettingsDTO struct {
 	Provider string `json:"provider"`
 
@@ -203,6 +254,13 @@ type SaveSettingsRequestDTO struct {
 	DiffWatchPaths     *[]string `json:"diff_watch_paths"`
 	BrowserEnabled     *bool     `json:"browser_enabled"`
 	BrowserHistoryPath *string   `json:"browser_history_path"`
+	BrowserBrowsers    *[]string `json:"browser_browsers"`
+	// 不采集的 profile（<browser>/<profile>），整体替换
+	BrowserDisabledProfiles *[]string `json:"browser_disabled_profiles"`
+	BrowserSearchTerms      *bool     `json:"browser_search_terms"`
+	BrowserReadingExp       *bool     `json:"browser_reading_exp"`
+	TerminalEnabled         *bool     `json:"terminal_enabled"`
+	EditorEnabled           *bool     `json:"editor_enabled"`
 
 	PrivacyEnabled  *bool     `json:"privacy_enabled"`
 	PrivacyPatterns *[]string `json:"privacy_patterns"`
@@ -243,18 +301,22 @@ type DateRequestDTO struct {
 }
 
 type SessionDTO struct {
-	ID             int64    `json:"id"`
-	Date           string   `json:"date"`
-	StartTime      int64    `json:"start_time"`
-	EndTime        int64    `json:"end_time"`
-	TimeRange      string   `json:"time_range"`
-	PrimaryApp     string   `json:"primary_app"`
-	SessionVersion int      `json:"session_version"`
-	Category       string   `json:"category"`
-	Summary        string   `json:"summary"`
-	SkillsInvolved []string `json:"skills_involved"`
-	DiffCount      int      `json:"diff_count"`
-	BrowserCount   int      `json:"browser_count"`
+	ID              int64    `json:"id"`
+	Date            string   `json:"date"`
+	StartTime       int64    `json:"start_time"`
+	EndTime         int64    `json:"end_time"`
+	TimeRange       string   `json:"time_range"`
+	PrimaryApp      string   `json:"primary_app"`
+	SessionVersion  int      `json:"session_version"`
+	Category        string   `json:"category"`
+	Summary         string   `json:"summary"`
+	SkillsInvolved  []string `json:"skills_involved"`
+	DiffCount       int      `json:"diff_count"`
+	BrowserCount    int      `json:"browser_count"`
+	CommitCount     int      `json:"commit_count"`
+	CommandCount    int      `json:"command_count"`
+	EditorFileCount int      `json:"editor_file_count"`
+	MeetingCount    int      `json:"meeting_count"`
 
 	SemanticSource  string `json:"semantic_source"`            // ai | rule
 	SemanticVersion string `json:"semantic_version,omitempty"` // e.g. "v1"
@@ -276,6 +338,7 @@ type SessionDiffDTO struct {
 	LinesAdded   int      `json:"lines_added"`
 	LinesDeleted int      `json:"lines_deleted"`
 	Timestamp    int64    `json:"timestamp"`
+	Backfilled   bool     `json:"backfilled,omitempty"` // 启动补扫得到的离线修改
 }
 
 type SessionBrowserEventDTO struct {
@@ -284,7 +347,59 @@ type SessionBrowserEventDTO struct {
 	Domain    string `json:"domain"`
 	Title     string `json:"title"`
 	URL       string `json:"url"`
-	Duration  int    `json:"duration"`
+	Duration  int    `json:"duration"` // 停留秒数
+	Browser   string `json:"browser,omitempty"`
+	Profile   string `json:"profile,omitempty"`
+
+	VisitID     int64  `json:"visit_id,omitempty"`
+	FromVisitID int64  `json:"from_visit_id,omitempty"` // 同一 profile 内的来源访问，串起导航链
+	Transition  string `json:"transition,omitempty"`
+
+	TestingProject string `json:"testing_project,omitempty"` // 本地开发服务的访问：关联到的项目名
+}
+
+type SessionCommitDTO struct {
+	ID           int64    `json:"id"`
+	Hash         string   `json:"hash"`
+	Repo         string   `json:"repo"` // 仓库目录名
+	Branch       string   `json:"branch"`
+	Subject      string   `json:"subject"`
+	Body         string   `json:"body,omitempty"`
+	Timestamp    int64    `json:"timestamp"`
+	FilesChanged int      `json:"files_changed"`
+	LinesAdded   int      `json:"lines_added"`
+	LinesDeleted int      `json:"lines_deleted"`
+	Files        []string `json:"files"`
+}
+
+// SessionTerminalCommandDTO 会话内执行过的终端命令（已脱敏）
+type SessionTerminalCommandDTO struct {
+	ID        int64  `json:"id"`
+	Timestamp int64  `json:"timestamp"`
+	Shell     string `json:"shell"`
+	Command   string `json:"command"`
+	Duration  int    `json:"duration,omitempty"` // 秒（zsh EXTENDED_HISTORY）
+	Timed     bool   `json:"timed"`              // false 表示时间为采集时刻，而非历史文件记录的执行时间
+}
+
+// SessionEditorFileDTO 会话内编辑器插件心跳按文件聚合的编码时长
+type SessionEditorFileDTO struct {
+	Project    string `json:"project"`
+	File       string `json:"file"`
+	Language   string `json:"language,omitempty"`
+	Branch     string `json:"branch,omitempty"`
+	Editor     string `json:"editor,omitempty"`
+	Duration   int    `json:"duration"` // 秒，按心跳间隔推断
+	Heartbeats int    `json:"heartbeats"`
+	Writes     int    `json:"writes"`
+}
+
+// SessionMeetingDTO 会议会话对应的日程（导入的 .ics）
+type SessionMeetingDTO struct {
+	ID        int64  `json:"id"`
+	Title     string `json:"title"`
+	StartTime int64  `json:"start_time"`
+	EndTime   int64  `json:"end_time"`
 }
 
 type SessionWindowEventDTO struct {
@@ -294,11 +409,36 @@ type SessionWindowEventDTO struct {
 	Duration  int    `json:"duration"`
 }
 
+// SessionManualTestingDTO 在本地开发服务上手动测试某个项目
+type SessionManualTestingDTO struct {
+	Project  string   `json:"project"` // 项目名
+	Path     string   `json:"path,omitempty"`
+	Source   string   `json:"source"` // port_hint | diff | window_title
+	Hosts    []string `json:"hosts"`
+	Visits   int      `json:"visits"`
+	Duration int      `json:"duration"` // 秒
+}
+
+type SessionSearchTermDTO struct {
+	ID        int64  `json:"id"`
+	Timestamp int64  `json:"timestamp"`
+	Term      string `json:"term"`
+	Engine    string `json:"engine"`
+	Domain    string `json:"domain,omitempty"`
+}
+
 type SessionDetailDTO struct {
 	SessionDTO
-	AppUsage []SessionAppUsageDTO     `json:"app_usage"`
-	Diffs    []SessionDiffDTO         `json:"diffs"`
-	Browser  []SessionBrowserEventDTO `json:"browser"`
+	AppUsage []SessionAppUsageDTO        `json:"app_usage"`
+	Diffs    []SessionDiffDTO            `json:"diffs"`
+	Browser  []SessionBrowserEventDTO    `json:"browser"`
+	Commits  []SessionCommitDTO          `json:"commits"`
+	Searches []SessionSearchTermDTO      `json:"searches"`
+	Commands []SessionTerminalCommandDTO `json:"commands"`
+
+	EditorFiles   []SessionEditorFileDTO    `json:"editor_files"`
+	ManualTesting []SessionManualTestingDTO `json:"manual_testing"`
+	Meetings      []SessionMeetingDTO       `json:"meetings"`
 }
 
 type SessionBuildResultDTO struct {
@@ -309,3 +449,85 @@ type SessionBuildResultDTO struct {
 type SessionEnrichResultDTO struct {
 	Enriched int `json:"enriched"`
 }
+
+type GitImportRequestDTO struct {
+	StartDate   string   `json:"start_date"`
+	EndDate     string   `json:"end_date"`               // 含当天；为空表示至今
+	AuthorEmail string   `json:"author_email,omitempty"` // 为空时使用各仓库的 user.email
+	Repos       []string `json:"repos,omitempty"`        // 为空时使用 diff.watch_paths 下发现的仓库
+	Analyze     bool     `json:"analyze"`                // 导入后用 AI 分析 Diff（否则按语言离线归因技能）
+}
+
+type GitImportStatusDTO struct {
+	Running         bool   `json:"running"`
+	Phase           string `json:"phase"`
+	Repo            string `json:"repo,omitempty"`
+	ReposDone       int    `json:"repos_done"`
+	ReposTotal      int    `json:"repos_total"`
+	CommitsScanned  int    `json:"commits_scanned"`
+	CommitsImported int    `json:"commits_imported"`
+	CommitsSkipped  int    `json:"commits_skipped"`
+	DiffsCreated    int    `json:"diffs_created"`
+	DiffsAnalyzed   int    `json:"diffs_analyzed"`
+	SessionsCreated int    `json:"sessions_created"`
+	StartedAt       int64  `json:"started_at"`
+	FinishedAt      int64  `json:"finished_at"`
+	Error           string `json:"error,omitempty"`
+}
+
+// HistoryImportResultDTO ActivityWatch / WakaTime 历史导入结果
+type HistoryImportResultDTO struct {
+	Source          string `json:"source"`
+	EventsImported  int    `json:"events_imported"`
+	BrowserImported int    `json:"browser_imported"`
+	Duplicates      int    `json:"duplicates"`
+	Skipped         int    `json:"skipped"`
+	StartTime       int64  `json:"start_time"`
+	EndTime         int64  `json:"end_time"`
+	SessionsCreated int    `json:"sessions_created"`
+}
+
+// CalendarImportRequestDTO .ics 日程导入参数
+type CalendarImportRequestDTO struct {
+	Files     []string `json:"files"`                // 本机 .ics 文件路径
+	StartDate string   `json:"start_date,omitempty"` // 为空时为 90 天前
+	EndDate   string   `json:"end_date,omitempty"`   // 含当天；为空时为 30 天后
+}
+
+// CalendarImportResultDTO .ics 日程导入结果
+type CalendarImportResultDTO struct {
+	Files           int   `json:"files"`
+	Imported        int   `json:"imported"`
+	Updated         int   `json:"updated"`
+	Unchanged       int   `json:"unchanged"`
+	Removed         int   `json:"removed"`
+	Skipped         int   `json:"skipped"`
+	StartTime       int64 `json:"start_time"`
+	EndTime         int64 `json:"end_time"`
+	SessionsCreated int   `json:"sessions_created"`
+}
+
+// IngestEventDTO 外部写入的事件（POST /api/ingest）
+type IngestEventDTO struct {
+	Source    string         `json:"source"`
+	App       string         `json:"app"`
+	Title     string         `json:"title"`
+	Timestamp int64          `json:"timestamp"` // 毫秒；省略为当前时间
+	Duration  int            `json:"duration"`  // 秒
+	Metadata  map[string]any `json:"metadata"`
+}
+
+// IngestBatchDTO {"events": [...]} 形式的请求体
+type IngestBatchDTO struct {
+	Events []IngestEventDTO `json:"events"`
+}
+
+type IngestRejectionDTO struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+type IngestResponseDTO struct {
+	Accepted int                  `json:"accepted"`
+	Rejected []IngestRejectionDTO `json:"rejected"`
+}
@@ -31,24 +31,29 @@ type PrivacyStatusDTO struct {
 }
 
 type CollectorsStatusDTO struct {
-	Window  CollectorStatusDTO `json:"window"`
-	Diff    CollectorStatusDTO `json:"diff"`
-	Browser CollectorStatusDTO `json:"browser"`
+	Window   CollectorStatusDTO `json:"window"`
+	Diff     CollectorStatusDTO `json:"diff"`
+	Browser  CollectorStatusDTO `json:"browser"`
+	Commits  CollectorStatusDTO `json:"commits"`
+	Terminal CollectorStatusDTO `json:"terminal"`
 }
 
 type CollectorStatusDTO struct {
-	Enabled          bool     `json:"enabled"`
-	Running          bool     `json:"running"`
-	LastCollectedAt  int64    `json:"last_collected_at"`
-	LastPersistedAt  int64    `json:"last_persisted_at"`
-	Count24h         int64    `json:"count_24h"`
-	DroppedEvents    int64    `json:"dropped_events,omitempty"`
-	DroppedBatches   int64    `json:"dropped_batches,omitempty"`
-	Skipped          int64    `json:"skipped,omitempty"`
-	WatchPaths       []string `json:"watch_paths,omitempty"`
-	EffectivePaths   int      `json:"effective_paths,omitempty"`
-	HistoryPath      string   `json:"history_path,omitempty"`
-	SanitizedEnabled bool     `json:"sanitized_enabled,omitempty"`
+	Enabled          bool              `json:"enabled"`
+	Running          bool              `json:"running"`
+	LastCollectedAt  int64             `json:"last_collected_at"`
+	LastPersistedAt  int64             `json:"last_persisted_at"`
+	Count24h         int64             `json:"count_24h"`
+	DroppedEvents    int64             `json:"dropped_events,omitempty"`
+	DroppedBatches   int64             `json:"dropped_batches,omitempty"`
+	Skipped          int64             `json:"skipped,omitempty"`
+	WatchPaths       []string          `json:"watch_paths,omitempty"`
+	EffectivePaths   int               `json:"effective_paths,omitempty"`
+	HistoryPath      string            `json:"history_path,omitempty"`
+	HistoryPaths     []string          `json:"history_paths,omitempty"` // 浏览器：正在采集的全部历史文件
+	Backend          string            `json:"backend,omitempty"`
+	WatchModes       map[string]string `json:"watch_modes,omitempty"` // 监控根 -> 实际监控方式
+	SanitizedEnabled bool              `json:"sanitized_enabled,omitempty"`
 }
 
 type PipelineStatusDTO struct {
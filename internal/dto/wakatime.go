@@ -0,0 +1,46 @@
+package dto
+
+// WakaTimeHeartbeatDTO WakaTime 插件上报的心跳（仅声明用到的字段，其余字段忽略）
+type WakaTimeHeartbeatDTO struct {
+	Entity    string  `json:"entity"`
+	Type      string  `json:"type"`
+	Category  string  `json:"category"`
+	Time      float64 `json:"time"`
+	Project   string  `json:"project"`
+	Branch    string  `json:"branch"`
+	Language  string  `json:"language"`
+	Lines     int     `json:"lines"`
+	LineNo    int     `json:"lineno"`
+	CursorPos int     `json:"cursorpos"`
+	IsWrite   bool    `json:"is_write"`
+	UserAgent string  `json:"user_agent"`
+}
+
+// WakaTimeHeartbeatDataDTO 心跳写入后的回显
+type WakaTimeHeartbeatDataDTO struct {
+	ID       string  `json:"id"`
+	Entity   string  `json:"entity"`
+	Type     string  `json:"type"`
+	Category string  `json:"category,omitempty"`
+	Time     float64 `json:"time"`
+	Project  string  `json:"project,omitempty"`
+	Branch   string  `json:"branch,omitempty"`
+	Language string  `json:"language,omitempty"`
+	IsWrite  bool    `json:"is_write"`
+}
+
+// WakaTimeGrandTotalDTO 状态栏今日合计
+type WakaTimeGrandTotalDTO struct {
+	Decimal      string  `json:"decimal"`
+	Digital      string  `json:"digital"`
+	Hours        int     `json:"hours"`
+	Minutes      int     `json:"minutes"`
+	Text         string  `json:"text"`
+	TotalSeconds float64 `json:"total_seconds"`
+}
+
+// WakaTimeStatusBarDTO GET /users/current/statusbar/today 的 data 部分
+type WakaTimeStatusBarDTO struct {
+	GrandTotal WakaTimeGrandTotalDTO `json:"grand_total"`
+	Categories []any                 `json:"categories"`
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/dto"
+	"github.com/yuqie6/WorkMirror/internal/eventbus"
+	"github.com/yuqie6/WorkMirror/internal/service"
+)
+
+// HandleCalendarImport 导入本机 .ics 日程文件；可重复执行，同步文件中的修改与删除
+func (a *API) HandleCalendarImport(w http.ResponseWriter, r *http.Request) {
+	if !a.requireWritableDB(w) {
+		return
+	}
+	var req dto.CalendarImportRequestDTO
+	if err := readJSON(r, &req); err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if a.rt == nil || a.rt.Core == nil || a.rt.Core.Services.CalendarImport == nil {
+		WriteError(w, http.StatusBadRequest, "导入服务未初始化")
+		return
+	}
+
+	in := service.CalendarImportRequest{Files: req.Files}
+	if start := strings.TrimSpace(req.StartDate); start != "" {
+		t, err := time.ParseInLocation("2006-01-02", start, time.Local)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "start_date 格式错误，请使用 YYYY-MM-DD")
+			return
+		}
+		in.Since = t
+	}
+	if end := strings.TrimSpace(req.EndDate); end != "" {
+		t, err := time.ParseInLocation("2006-01-02", end, time.Local)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "end_date 格式错误，请使用 YYYY-MM-DD")
+			return
+		}
+		in.Until = t.Add(24 * time.Hour)
+	}
+
+	res, err := a.rt.Core.Services.CalendarImport.Import(r.Context(), in)
+	if changed := res.Imported + res.Updated + res.Removed; changed > 0 && a.hub != nil {
+		a.hub.Publish(eventbus.Event{
+			Type: "data_changed",
+			Data: map[string]any{"source": "calendar_import", "count": changed},
+		})
+	}
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusOK, &dto.CalendarImportResultDTO{
+		Files:           res.Files,
+		Imported:        res.Imported,
+		Updated:         res.Updated,
+		Unchanged:       res.Unchanged,
+		Removed:         res.Removed,
+		Skipped:         res.Skipped,
+		StartTime:       res.Since,
+		EndTime:         res.Until,
+		SessionsCreated: res.SessionsCreated,
+	})
+}
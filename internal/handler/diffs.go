@@ -1,5 +1,3 @@
-//go:build windows
-
 package handler
 
 import (
@@ -42,10 +40,12 @@ func (a *API) HandleDiffDetail(w http.ResponseWriter, r *http.Request) {
 		FileName:     diff.FileName,
 		Language:     diff.Language,
 		DiffContent:  diff.DiffContent,
+		Cumulative:   diff.CumulativeContent,
 		Insight:      diff.AIInsight,
 		Skills:       skills,
 		LinesAdded:   diff.LinesAdded,
 		LinesDeleted: diff.LinesDeleted,
 		Timestamp:    diff.Timestamp,
+		Backfilled:   diff.Backfilled,
 	})
 }
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/dto"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+	"github.com/yuqie6/WorkMirror/internal/service"
+)
+
+// WakaTime 兼容接口：插件把 api_url 指向 http://<editor.listen_addr>/api/v1 即可上报心跳。
+
+const wakaTimeMaxBody = 4 << 20
+
+// HandleWakaTimeHeartbeat 接收单条心跳（POST /api/v1/users/current/heartbeats）
+func (a *API) HandleWakaTimeHeartbeat(w http.ResponseWriter, r *http.Request) {
+	svc, ok := a.editorService(w, r)
+	if !ok {
+		return
+	}
+	var hb dto.WakaTimeHeartbeatDTO
+	if err := readWakaTimeJSON(r, &hb); err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	results, err := svc.Record(r.Context(), []service.EditorHeartbeat{toEditorHeartbeat(hb, r)})
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if results[0].Err != nil {
+		WriteError(w, http.StatusBadRequest, results[0].Err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusCreated, map[string]any{"data": toWakaTimeHeartbeatData(results[0].Event)})
+}
+
+// HandleWakaTimeHeartbeatsBulk 接收批量心跳（POST /api/v1/users/current/heartbeats.bulk），逐条返回状态
+func (a *API) HandleWakaTimeHeartbeatsBulk(w http.ResponseWriter, r *http.Request) {
+	svc, ok := a.editorService(w, r)
+	if !ok {
+		return
+	}
+	var beats []dto.WakaTimeHeartbeatDTO
+	if err := readWakaTimeJSON(r, &beats); err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	in := make([]service.EditorHeartbeat, 0, len(beats))
+	for _, hb := range beats {
+		in = append(in, toEditorHeartbeat(hb, r))
+	}
+	results, err := svc.Record(r.Context(), in)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responses := make([][2]any, 0, len(results))
+	for _, res := range results {
+		if res.Err != nil {
+			responses = append(responses, [2]any{map[string]any{"error": res.Err.Error()}, http.StatusBadRequest})
+			continue
+		}
+		responses = append(responses, [2]any{map[string]any{"data": toWakaTimeHeartbeatData(res.Event)}, http.StatusCreated})
+	}
+	WriteJSON(w, http.StatusCreated, map[string]any{"responses": responses})
+}
+
+// HandleWakaTimeStatusBar 今日编码时长（GET /api/v1/users/current/statusbar/today），供插件状态栏显示
+func (a *API) HandleWakaTimeStatusBar(w http.ResponseWriter, r *http.Request) {
+	svc, ok := a.editorService(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	sec, err := svc.TodaySeconds(ctx)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"data": dto.WakaTimeStatusBarDTO{
+		GrandTotal: wakaTimeGrandTotal(sec),
+		Categories: []any{},
+	}})
+}
+
+// editorService 校验开关、api_key 与写库状态；写接口还要求 JSON 请求体，
+// 使网页无法用简单表单请求跨站写入（固定端口上的本地接口对任意网页可达）
+func (a *API) editorService(w http.ResponseWriter, r *http.Request) (*service.EditorHeartbeatService, bool) {
+	if a == nil || a.rt == nil || a.rt.Core == nil || a.rt.Core.Services.Editor == nil {
+		WriteError(w, http.StatusNotFound, "编辑器心跳接口未启用")
+		return nil, false
+	}
+	if key := strings.TrimSpace(a.rt.Cfg.Editor.APIKey); key != "" && !wakaTimeKeyMatches(r, key) {
+		WriteError(w, http.StatusUnauthorized, "api_key 无效")
+		return nil, false
+	}
+	if r.Method == http.MethodPost {
+		mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mt != "application/json" {
+			WriteError(w, http.StatusUnsupportedMediaType, "Content-Type 必须为 application/json")
+			return nil, false
+		}
+		if !a.requireWritableDB(w) {
+			return nil, false
+		}
+	}
+	return a.rt.Core.Services.Editor, true
+}
+
+// wakaTimeKeyMatches 插件以 Basic base64(api_key) 发送；也接受 Bearer 与 ?api_key=
+func wakaTimeKeyMatches(r *http.Request, want string) bool {
+	var got string
+	auth := strings.TrimSpace(r.Header.Get("Authorization"))
+	scheme, cred, _ := strings.Cut(auth, " ")
+	cred = strings.TrimSpace(cred)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if b, err := base64.StdEncoding.DecodeString(cred); err == nil {
+			// 部分客户端按 "key:" 形式编码
+			got = strings.TrimSuffix(string(b), ":")
+		}
+	case "bearer":
+		got = cred
+	}
+	if got == "" {
+		got = r.URL.Query().Get("api_key")
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// readWakaTimeJSON 解析插件请求体；插件会带上大量本地不需要的字段，因此不拒绝未知字段
+func readWakaTimeJSON(r *http.Request, out any) error {
+	defer r.Body.Close()
+	if err := json.NewDecoder(io.LimitReader(r.Body, wakaTimeMaxBody)).Decode(out); err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("请求体为空")
+		}
+		return fmt.Errorf("解析心跳失败: %w", err)
+	}
+	return nil
+}
+
+func toEditorHeartbeat(hb dto.WakaTimeHeartbeatDTO, r *http.Request) service.EditorHeartbeat {
+	ua := hb.UserAgent
+	if strings.TrimSpace(ua) == "" {
+		ua = r.Header.Get("User-Agent")
+	}
+	return service.EditorHeartbeat{
+		Entity:    hb.Entity,
+		Type:      hb.Type,
+		Category:  hb.Category,
+		Time:      hb.Time,
+		Project:   hb.Project,
+		Branch:    hb.Branch,
+		Language:  hb.Language,
+		Lines:     hb.Lines,
+		LineNo:    hb.LineNo,
+		IsWrite:   hb.IsWrite,
+		UserAgent: ua,
+	}
+}
+
+func toWakaTimeHeartbeatData(ev *schema.Event) dto.WakaTimeHeartbeatDataDTO {
+	meta := ev.Metadata
+	str := func(key string) string {
+		s, _ := meta[key].(string)
+		return s
+	}
+	isWrite, _ := meta[schema.EditorMetaIsWrite].(bool)
+	return dto.WakaTimeHeartbeatDataDTO{
+		ID:       strconv.FormatInt(ev.ID, 10),
+		Entity:   str(schema.EditorMetaEntity),
+		Type:     str(schema.EditorMetaType),
+		Category: str(schema.EditorMetaCategory),
+		Time:     float64(ev.Timestamp) / 1000,
+		Project:  str(schema.EditorMetaProject),
+		Branch:   str(schema.EditorMetaBranch),
+		Language: str(schema.EditorMetaLanguage),
+		IsWrite:  isWrite,
+	}
+}
+
+func wakaTimeGrandTotal(sec int) dto.WakaTimeGrandTotalDTO {
+	h, m := sec/3600, sec%3600/60
+	var text string
+	switch {
+	case h > 0 && m > 0:
+		text = fmt.Sprintf("%d hr%s %d min%s", h, plural(h), m, plural(m))
+	case h > 0:
+		text = fmt.Sprintf("%d hr%s", h, plural(h))
+	default:
+		text = fmt.Sprintf("%d min%s", m, plural(m))
+	}
+	return dto.WakaTimeGrandTotalDTO{
+		Decimal:      strconv.FormatFloat(float64(sec)/3600, 'f', 2, 64),
+		Digital:      fmt.Sprintf("%d:%02d", h, m),
+		Hours:        h,
+		Minutes:      m,
+		Text:         text,
+		TotalSeconds: float64(sec),
+	}
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
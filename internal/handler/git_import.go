@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/collector"
+	"github.com/yuqie6/WorkMirror/internal/dto"
+	"github.com/yuqie6/WorkMirror/internal/eventbus"
+	"github.com/yuqie6/WorkMirror/internal/service"
+)
+
+// HandleGitImport 导入 Git 历史（默认后台执行，进度通过 git_import_progress 事件与 GET 查询）
+func (a *API) HandleGitImport(w http.ResponseWriter, r *http.Request) {
+	if !a.requireWritableDB(w) {
+		return
+	}
+	var req dto.GitImportRequestDTO
+	if err := readJSON(r, &req); err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if a.rt == nil || a.rt.Core == nil || a.rt.Core.Services.GitImport == nil {
+		WriteError(w, http.StatusBadRequest, "导入服务未初始化")
+		return
+	}
+
+	since, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(req.StartDate), time.Local)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "start_date 格式错误，请使用 YYYY-MM-DD")
+		return
+	}
+	var until time.Time
+	if end := strings.TrimSpace(req.EndDate); end != "" {
+		t, err := time.ParseInLocation("2006-01-02", end, time.Local)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "end_date 格式错误，请使用 YYYY-MM-DD")
+			return
+		}
+		until = t.Add(24 * time.Hour)
+	}
+
+	repos := req.Repos
+	if len(repos) == 0 {
+		repos = collector.DiscoverGitRepos(a.rt.Cfg.Diff.WatchPaths)
+	}
+	if len(repos) == 0 {
+		WriteError(w, http.StatusBadRequest, "监控目录下没有 Git 仓库")
+		return
+	}
+
+	svc := a.rt.Core.Services.GitImport
+	// 导入在后台执行：写锁随任务持有到结束
+	release := takeWriteLock(r)
+	in := service.GitImportRequest{
+		Repos:         repos,
+		Since:         since,
+		Until:         until,
+		AuthorEmail:   strings.TrimSpace(req.AuthorEmail),
+		AnalyzeWithAI: req.Analyze,
+		OnProgress: func(p service.GitImportProgress) {
+			a.publishGitImportProgress(p)
+			if !p.Running {
+				release()
+			}
+		},
+	}
+
+	// 导入可能持续数分钟，不跟随请求上下文取消
+	if err := svc.Start(context.WithoutCancel(r.Context()), in); err != nil {
+		release()
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrGitImportRunning) {
+			status = http.StatusConflict
+		}
+		WriteError(w, status, err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusAccepted, toGitImportStatusDTO(svc.Progress()))
+}
+
+// HandleGitImportStatus 查询当前（或最近一次）导入进度
+func (a *API) HandleGitImportStatus(w http.ResponseWriter, r *http.Request) {
+	if a.rt == nil || a.rt.Core == nil || a.rt.Core.Services.GitImport == nil {
+		WriteError(w, http.StatusBadRequest, "导入服务未初始化")
+		return
+	}
+	WriteJSON(w, http.StatusOK, toGitImportStatusDTO(a.rt.Core.Services.GitImport.Progress()))
+}
+
+func (a *API) publishGitImportProgress(p service.GitImportProgress) {
+	if a.hub == nil {
+		return
+	}
+	// 事件只带概要，完整进度通过 /api/import/git/status 查询
+	a.hub.Publish(eventbus.Event{
+		Type: "git_import_progress",
+		Data: map[string]any{
+			"running":          p.Running,
+			"phase":            p.Phase,
+			"repo":             p.Repo,
+			"repos_done":       p.ReposDone,
+			"repos_total":      p.ReposTotal,
+			"commits_imported": p.CommitsImported,
+			"diffs_created":    p.DiffsCreated,
+			"error":            p.Error,
+		},
+	})
+	if !p.Running && p.DiffsCreated > 0 {
+		a.hub.Publish(eventbus.Event{
+			Type: "data_changed",
+			Data: map[string]any{"source": "git_import", "count": p.DiffsCreated},
+		})
+		a.hub.Publish(eventbus.Event{Type: "pipeline_status_changed"})
+	}
+}
+
+func toGitImportStatusDTO(p service.GitImportProgress) *dto.GitImportStatusDTO {
+	return &dto.GitImportStatusDTO{
+		Running:         p.Running,
+		Phase:           p.Phase,
+		Repo:            p.Repo,
+		ReposDone:       p.ReposDone,
+		ReposTotal:      p.ReposTotal,
+		CommitsScanned:  p.CommitsScanned,
+		CommitsImported: p.CommitsImported,
+		CommitsSkipped:  p.CommitsSkipped,
+		DiffsCreated:    p.DiffsCreated,
+		DiffsAnalyzed:   p.DiffsAnalyzed,
+		SessionsCreated: p.SessionsCreated,
+		StartedAt:       p.StartedAt,
+		FinishedAt:      p.FinishedAt,
+		Error:           p.Error,
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/yuqie6/WorkMirror/internal/dto"
+	"github.com/yuqie6/WorkMirror/internal/eventbus"
+	"github.com/yuqie6/WorkMirror/internal/service"
+)
+
+// 导出文件可能包含数年的数据，解析为流式，上限只防止误传
+const historyImportMaxBody = 1 << 30
+
+// HandleActivityWatchImport 导入 ActivityWatch 导出文件（请求体为导出的 JSON）
+func (a *API) HandleActivityWatchImport(w http.ResponseWriter, r *http.Request) {
+	a.handleHistoryImport(w, r, (*service.HistoryImportService).ImportActivityWatch)
+}
+
+// HandleWakaTimeImport 导入 WakaTime 数据导出（请求体为导出的 JSON）
+func (a *API) HandleWakaTimeImport(w http.ResponseWriter, r *http.Request) {
+	a.handleHistoryImport(w, r, (*service.HistoryImportService).ImportWakaTime)
+}
+
+func (a *API) handleHistoryImport(w http.ResponseWriter, r *http.Request,
+	run func(*service.HistoryImportService, context.Context, io.Reader) (service.HistoryImportResult, error)) {
+	if !a.requireWritableDB(w) {
+		return
+	}
+	if a.rt == nil || a.rt.Core == nil || a.rt.Core.Services.HistoryImport == nil {
+		WriteError(w, http.StatusBadRequest, "导入服务未初始化")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, historyImportMaxBody)
+	defer r.Body.Close()
+
+	res, err := run(a.rt.Core.Services.HistoryImport, r.Context(), r.Body)
+	if res.EventsImported+res.BrowserImported > 0 && a.hub != nil {
+		// 部分失败时已写入的数据同样需要刷新
+		a.hub.Publish(eventbus.Event{
+			Type: "data_changed",
+			Data: map[string]any{"source": res.Source + "_import", "count": res.EventsImported + res.BrowserImported},
+		})
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			WriteError(w, http.StatusRequestEntityTooLarge, "导入文件过大")
+			return
+		}
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusOK, &dto.HistoryImportResultDTO{
+		Source:          res.Source,
+		EventsImported:  res.EventsImported,
+		BrowserImported: res.BrowserImported,
+		Duplicates:      res.Duplicates,
+		Skipped:         res.Skipped,
+		StartTime:       res.StartTime,
+		EndTime:         res.EndTime,
+		SessionsCreated: res.SessionsCreated,
+	})
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yuqie6/WorkMirror/internal/dto"
+	"github.com/yuqie6/WorkMirror/internal/service"
+)
+
+const ingestMaxBody = 4 << 20
+
+// HandleIngest 外部事件写入（POST /api/ingest）：JSON 数组 / {"events": [...]} / NDJSON
+func (a *API) HandleIngest(w http.ResponseWriter, r *http.Request) {
+	if a == nil || a.rt == nil || a.rt.Services.Ingest == nil {
+		WriteError(w, http.StatusNotFound, "外部写入接口未启用（ingest.enabled）")
+		return
+	}
+	token := strings.TrimSpace(a.rt.Cfg.Ingest.Token)
+	if token == "" {
+		WriteAPIError(w, http.StatusUnauthorized, APIError{
+			Error: "未配置写入令牌",
+			Code:  "ingest_token_missing",
+			Hint:  "请在配置文件中设置 ingest.token 并重启 Agent",
+		})
+		return
+	}
+	got, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(got)), []byte(token)) != 1 {
+		WriteError(w, http.StatusUnauthorized, "令牌无效")
+		return
+	}
+	if !a.requireWritableDB(w) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, ingestMaxBody)
+	defer r.Body.Close()
+
+	var (
+		items   []dto.IngestEventDTO
+		indexes []int
+		parsed  []dto.IngestRejectionDTO
+		err     error
+	)
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mt {
+	case "application/json":
+		items, err = readIngestJSON(r.Body)
+		for i := range items {
+			indexes = append(indexes, i)
+		}
+	case "application/x-ndjson", "application/jsonl":
+		items, indexes, parsed, err = readIngestNDJSON(r.Body)
+	default:
+		WriteError(w, http.StatusUnsupportedMediaType, "Content-Type 必须为 application/json 或 application/x-ndjson")
+		return
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			WriteError(w, http.StatusRequestEntityTooLarge, "请求体过大")
+			return
+		}
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(items)+len(parsed) == 0 {
+		WriteError(w, http.StatusBadRequest, "没有事件")
+		return
+	}
+
+	events := make([]service.IngestEvent, 0, len(items))
+	for _, it := range items {
+		events = append(events, service.IngestEvent{
+			Source:    it.Source,
+			App:       it.App,
+			Title:     it.Title,
+			Timestamp: it.Timestamp,
+			Duration:  it.Duration,
+			Metadata:  it.Metadata,
+		})
+	}
+	res, wait, err := a.rt.Services.Ingest.Ingest(events)
+	switch {
+	case errors.Is(err, service.ErrIngestBatchTooBig):
+		WriteError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	case errors.Is(err, service.ErrIngestRateLimited):
+		w.Header().Set("Retry-After", strconv.Itoa(max(int(wait.Seconds()), 1)))
+		WriteError(w, http.StatusTooManyRequests, err.Error())
+		return
+	case err != nil:
+		// 写入队列已满或 Agent 正在退出：整批未接受，调用方可重试
+		WriteError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	rejected := append([]dto.IngestRejectionDTO{}, parsed...)
+	for _, rj := range res.Rejected {
+		rejected = append(rejected, dto.IngestRejectionDTO{Index: indexes[rj.Index], Error: rj.Error})
+	}
+	sort.Slice(rejected, func(i, j int) bool { return rejected[i].Index < rejected[j].Index })
+
+	status := http.StatusAccepted
+	if res.Accepted == 0 {
+		status = http.StatusBadRequest
+	}
+	WriteJSON(w, status, dto.IngestResponseDTO{Accepted: res.Accepted, Rejected: rejected})
+}
+
+// readIngestJSON 解析 JSON 请求体：事件数组、{"events": [...]} 或单个事件
+func readIngestJSON(body io.Reader) ([]dto.IngestEventDTO, error) {
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, errors.New("请求体为空")
+	}
+	if raw[0] == '[' {
+		var items []dto.IngestEventDTO
+		if err := decodeStrict(raw, &items); err != nil {
+			return nil, fmt.Errorf("解析事件失败: %w", err)
+		}
+		return items, nil
+	}
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, fmt.Errorf("解析事件失败: %w", err)
+	}
+	if _, ok := probe["events"]; ok {
+		var batch dto.IngestBatchDTO
+		if err := decodeStrict(raw, &batch); err != nil {
+			return nil, fmt.Errorf("解析事件失败: %w", err)
+		}
+		return batch.Events, nil
+	}
+	var one dto.IngestEventDTO
+	if err := decodeStrict(raw, &one); err != nil {
+		return nil, fmt.Errorf("解析事件失败: %w", err)
+	}
+	return []dto.IngestEventDTO{one}, nil
+}
+
+// readIngestNDJSON 逐行解析；单行格式错误只拒绝该行（Index 为非空行序号）
+func readIngestNDJSON(body io.Reader) ([]dto.IngestEventDTO, []int, []dto.IngestRejectionDTO, error) {
+	var (
+		items    []dto.IngestEventDTO
+		indexes  []int
+		rejected []dto.IngestRejectionDTO
+	)
+	sc := bufio.NewScanner(body)
+	sc.Buffer(make([]byte, 0, 64<<10), ingestMaxBody)
+	n := 0
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var it dto.IngestEventDTO
+		if err := decodeStrict(line, &it); err != nil {
+			rejected = append(rejected, dto.IngestRejectionDTO{Index: n, Error: "解析事件失败: " + err.Error()})
+		} else {
+			items = append(items, it)
+			indexes = append(indexes, n)
+		}
+		n++
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+	return items, indexes, rejected, nil
+}
+
+func decodeStrict(raw []byte, out any) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(out); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("存在多余内容")
+	}
+	return nil
+}
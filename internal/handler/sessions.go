@@ -1,10 +1,9 @@
-//go:build windows
-
 package handler
 
 import (
 	"context"
 	"net/http"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -175,6 +174,8 @@ func (a *API) HandleSessionsByDate(w http.ResponseWriter, r *http.Request) {
 		meta := s.Metadata
 		diffIDs := schema.GetInt64Slice(meta, schema.SessionMetaDiffIDs)
 		browserIDs := schema.GetInt64Slice(meta, schema.SessionMetaBrowserEventIDs)
+		commitIDs := schema.GetInt64Slice(meta, schema.SessionMetaCommitIDs)
+		commandIDs := schema.GetInt64Slice(meta, schema.SessionMetaTerminalCmdIDs)
 		timeRange, semanticSource, semanticVersion, evidenceHint, degradedReason := sessionDerivedForDTO(&s, len(diffIDs), len(browserIDs))
 		result = append(result, dto.SessionDTO{
 			ID:              s.ID,
@@ -189,6 +190,10 @@ func (a *API) HandleSessionsByDate(w http.ResponseWriter, r *http.Request) {
 			SkillsInvolved:  []string(s.SkillsInvolved),
 			DiffCount:       len(diffIDs),
 			BrowserCount:    len(browserIDs),
+			CommitCount:     len(commitIDs),
+			CommandCount:    len(commandIDs),
+			EditorFileCount: len(schema.GetSessionEditorFiles(meta)),
+			MeetingCount:    len(schema.GetInt64Slice(meta, schema.SessionMetaCalendarIDs)),
 			SemanticSource:  semanticSource,
 			SemanticVersion: semanticVersion,
 			EvidenceHint:    evidenceHint,
@@ -222,6 +227,10 @@ func (a *API) HandleSessionDetail(w http.ResponseWriter, r *http.Request) {
 
 	diffIDs := schema.GetInt64Slice(sess.Metadata, schema.SessionMetaDiffIDs)
 	browserIDs := schema.GetInt64Slice(sess.Metadata, schema.SessionMetaBrowserEventIDs)
+	commitIDs := schema.GetInt64Slice(sess.Metadata, schema.SessionMetaCommitIDs)
+	searchIDs := schema.GetInt64Slice(sess.Metadata, schema.SessionMetaSearchTermIDs)
+	commandIDs := schema.GetInt64Slice(sess.Metadata, schema.SessionMetaTerminalCmdIDs)
+	meetingIDs := schema.GetInt64Slice(sess.Metadata, schema.SessionMetaCalendarIDs)
 
 	var diffs []schema.Diff
 	if len(diffIDs) > 0 {
@@ -238,7 +247,29 @@ func (a *API) HandleSessionDetail(w http.ResponseWriter, r *http.Request) {
 			LinesAdded:   d.LinesAdded,
 			LinesDeleted: d.LinesDeleted,
 			Timestamp:    d.Timestamp,
+			Backfilled:   d.Backfilled,
+		})
+	}
+
+	manualTesting := schema.GetSessionManualTesting(sess.Metadata)
+	testingProject := make(map[int64]string)
+	manualTestingDTOs := make([]dto.SessionManualTestingDTO, 0, len(manualTesting))
+	for _, m := range manualTesting {
+		path := ""
+		if m.Source != service.ManualTestingSourceWindowTitle && m.Source != service.ManualTestingSourceEditor {
+			path = m.Project
+		}
+		manualTestingDTOs = append(manualTestingDTOs, dto.SessionManualTestingDTO{
+			Project:  m.Name,
+			Path:     path,
+			Source:   m.Source,
+			Hosts:    m.Hosts,
+			Visits:   m.Visits,
+			Duration: m.Seconds,
 		})
+		for _, id := range m.EventIDs {
+			testingProject[id] = m.Name
+		}
 	}
 
 	var browserEvents []schema.BrowserEvent
@@ -254,6 +285,95 @@ func (a *API) HandleSessionDetail(w http.ResponseWriter, r *http.Request) {
 			Title:     e.Title,
 			URL:       e.URL,
 			Duration:  e.Duration,
+			Browser:   e.Browser,
+			Profile:   e.Profile,
+
+			VisitID:     e.VisitID,
+			FromVisitID: e.FromVisitID,
+			Transition:  e.Transition,
+
+			TestingProject: testingProject[e.ID],
+		})
+	}
+
+	var commits []schema.GitCommit
+	if len(commitIDs) > 0 && a.rt.Repos.Commit != nil {
+		commits, _ = a.rt.Repos.Commit.GetByIDs(r.Context(), commitIDs)
+	}
+	commitDTOs := make([]dto.SessionCommitDTO, 0, len(commits))
+	for _, c := range commits {
+		commitDTOs = append(commitDTOs, dto.SessionCommitDTO{
+			ID:           c.ID,
+			Hash:         c.Hash,
+			Repo:         filepath.Base(c.RepoPath),
+			Branch:       c.Branch,
+			Subject:      c.Subject,
+			Body:         c.Body,
+			Timestamp:    c.Timestamp,
+			FilesChanged: c.FilesChanged,
+			LinesAdded:   c.LinesAdded,
+			LinesDeleted: c.LinesDeleted,
+			Files:        []string(c.Files),
+		})
+	}
+
+	var searches []schema.SearchTerm
+	if len(searchIDs) > 0 && a.rt.Repos.SearchTerm != nil {
+		searches, _ = a.rt.Repos.SearchTerm.GetByIDs(r.Context(), searchIDs)
+	}
+	searchDTOs := make([]dto.SessionSearchTermDTO, 0, len(searches))
+	for _, st := range searches {
+		searchDTOs = append(searchDTOs, dto.SessionSearchTermDTO{
+			ID:        st.ID,
+			Timestamp: st.Timestamp,
+			Term:      st.Term,
+			Engine:    st.Engine,
+			Domain:    st.Domain,
+		})
+	}
+
+	var commands []schema.TerminalCommand
+	if len(commandIDs) > 0 && a.rt.Repos.Terminal != nil {
+		commands, _ = a.rt.Repos.Terminal.GetByIDs(r.Context(), commandIDs)
+	}
+	commandDTOs := make([]dto.SessionTerminalCommandDTO, 0, len(commands))
+	for _, c := range commands {
+		commandDTOs = append(commandDTOs, dto.SessionTerminalCommandDTO{
+			ID:        c.ID,
+			Timestamp: c.Timestamp,
+			Shell:     c.Shell,
+			Command:   c.Command,
+			Duration:  c.Duration,
+			Timed:     c.Timed,
+		})
+	}
+
+	editorFiles := schema.GetSessionEditorFiles(sess.Metadata)
+	editorFileDTOs := make([]dto.SessionEditorFileDTO, 0, len(editorFiles))
+	for _, f := range editorFiles {
+		editorFileDTOs = append(editorFileDTOs, dto.SessionEditorFileDTO{
+			Project:    f.Project,
+			File:       f.File,
+			Language:   f.Language,
+			Branch:     f.Branch,
+			Editor:     f.Editor,
+			Duration:   f.Seconds,
+			Heartbeats: f.Heartbeats,
+			Writes:     f.Writes,
+		})
+	}
+
+	var meetings []schema.CalendarEvent
+	if len(meetingIDs) > 0 && a.rt.Repos.Calendar != nil {
+		meetings, _ = a.rt.Repos.Calendar.GetByIDs(r.Context(), meetingIDs)
+	}
+	meetingDTOs := make([]dto.SessionMeetingDTO, 0, len(meetings))
+	for _, m := range meetings {
+		meetingDTOs = append(meetingDTOs, dto.SessionMeetingDTO{
+			ID:        m.ID,
+			Title:     m.Title,
+			StartTime: m.StartTime,
+			EndTime:   m.EndTime,
 		})
 	}
 
@@ -296,6 +416,10 @@ func (a *API) HandleSessionDetail(w http.ResponseWriter, r *http.Request) {
 			SkillsInvolved:  []string(sess.SkillsInvolved),
 			DiffCount:       len(diffIDs),
 			BrowserCount:    len(browserIDs),
+			CommitCount:     len(commitIDs),
+			CommandCount:    len(commandIDs),
+			EditorFileCount: len(editorFiles),
+			MeetingCount:    len(meetingIDs),
 			SemanticSource:  semanticSource,
 			SemanticVersion: semanticVersion,
 			EvidenceHint:    evidenceHint,
@@ -304,6 +428,13 @@ func (a *API) HandleSessionDetail(w http.ResponseWriter, r *http.Request) {
 		AppUsage: appUsage,
 		Diffs:    diffDTOs,
 		Browser:  browserDTOs,
+		Commits:  commitDTOs,
+		Searches: searchDTOs,
+		Commands: commandDTOs,
+
+		EditorFiles:   editorFileDTOs,
+		ManualTesting: manualTestingDTOs,
+		Meetings:      meetingDTOs,
 	}
 	WriteJSON(w, http.StatusOK, resp)
 }
@@ -352,6 +483,14 @@ func (a *API) HandleSessionEvents(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	// 时间轴只展示窗口切换；编辑器心跳以文件聚合的形式出现在会话详情中
+	windows := events[:0]
+	for _, e := range events {
+		if e.Source != schema.EventSourceEditor && e.Source != schema.EventSourceWakaTime {
+			windows = append(windows, e)
+		}
+	}
+	events = windows
 
 	if offset >= len(events) {
 		WriteJSON(w, http.StatusOK, []dto.SessionWindowEventDTO{})
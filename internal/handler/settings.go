@@ -1,5 +1,3 @@
-//go:build windows
-
 package handler
 
 import (
@@ -8,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/yuqie6/WorkMirror/internal/collector"
 	"github.com/yuqie6/WorkMirror/internal/dto"
 	"github.com/yuqie6/WorkMirror/internal/eventbus"
 	"github.com/yuqie6/WorkMirror/internal/pkg/buildinfo"
@@ -26,7 +25,7 @@ func (a *API) HandleSettings(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) getSettings(w http.ResponseWriter, r *http.Request) {
-	path, err := config.DefaultConfigPath()
+	path, err := a.configPath()
 	if err != nil {
 		WriteError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -86,12 +85,43 @@ func (a *API) getSettings(w http.ResponseWriter, r *http.Request) {
 		DiffWatchPaths:     append([]string{}, cfg.Diff.WatchPaths...),
 		BrowserEnabled:     cfg.Browser.Enabled,
 		BrowserHistoryPath: cfg.Browser.HistoryPath,
+		BrowserBrowsers:    append([]string{}, cfg.Browser.Browsers...),
+		BrowserProfiles:    browserProfilesDTO(cfg),
+		BrowserSearchTerms: cfg.Browser.SearchTermsEnabled,
+		BrowserReadingExp:  cfg.Browser.ReadingExpEnabled,
+		TerminalEnabled:    cfg.Terminal.Enabled,
+		EditorEnabled:      cfg.Editor.Enabled,
 
 		PrivacyEnabled:  cfg.Privacy.Enabled,
 		PrivacyPatterns: append([]string{}, cfg.Privacy.Patterns...),
 	})
 }
 
+// browserProfilesDTO 列出本机已发现的浏览器 profile 及其启用状态
+func browserProfilesDTO(cfg *config.Config) []dto.BrowserProfileDTO {
+	disabled := make(map[string]bool, len(cfg.Browser.DisabledProfiles))
+	for _, k := range cfg.Browser.DisabledProfiles {
+		disabled[k] = true
+	}
+	profiles := collector.DiscoverBrowserProfiles(collector.BrowserDiscoveryConfig{
+		Browsers:           cfg.Browser.Browsers,
+		HistoryPath:        cfg.Browser.HistoryPath,
+		FirefoxProfilesDir: cfg.Browser.FirefoxProfilesDir,
+	})
+	out := make([]dto.BrowserProfileDTO, 0, len(profiles))
+	for _, p := range profiles {
+		out = append(out, dto.BrowserProfileDTO{
+			Key:         p.Key(),
+			Browser:     p.Browser,
+			Profile:     p.Profile,
+			Name:        p.Name,
+			HistoryPath: p.HistoryPath,
+			Enabled:     !disabled[p.Key()],
+		})
+	}
+	return out
+}
+
 func (a *API) saveSettings(w http.ResponseWriter, r *http.Request) {
 	var req dto.SaveSettingsRequestDTO
 	if err := readJSON(r, &req); err != nil {
@@ -99,7 +129,7 @@ func (a *API) saveSettings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	path, err := config.DefaultConfigPath()
+	path, err := a.configPath()
 	if err != nil {
 		WriteError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -256,6 +286,43 @@ func (a *API) saveSettings(w http.ResponseWriter, r *http.Request) {
 		}
 		next.Browser.HistoryPath = p
 	}
+	if req.BrowserBrowsers != nil {
+		browsers := make([]string, 0, len(*req.BrowserBrowsers))
+		for _, b := range *req.BrowserBrowsers {
+			b = strings.ToLower(strings.TrimSpace(b))
+			if !collector.IsKnownBrowser(b) {
+				WriteAPIError(w, http.StatusBadRequest, APIError{
+					Error: "不支持的浏览器: " + b,
+					Code:  "invalid_browser",
+					Hint:  "可选: chromium-family, chrome, chrome-beta, edge, brave, vivaldi, arc, chromium, firefox",
+				})
+				return
+			}
+			browsers = append(browsers, b)
+		}
+		next.Browser.Browsers = browsers
+	}
+	if req.BrowserDisabledProfiles != nil {
+		keys := make([]string, 0, len(*req.BrowserDisabledProfiles))
+		for _, k := range *req.BrowserDisabledProfiles {
+			if k = strings.TrimSpace(k); k != "" {
+				keys = append(keys, k)
+			}
+		}
+		next.Browser.DisabledProfiles = keys
+	}
+	if req.BrowserSearchTerms != nil {
+		next.Browser.SearchTermsEnabled = *req.BrowserSearchTerms
+	}
+	if req.BrowserReadingExp != nil {
+		next.Browser.ReadingExpEnabled = *req.BrowserReadingExp
+	}
+	if req.TerminalEnabled != nil {
+		next.Terminal.Enabled = *req.TerminalEnabled
+	}
+	if req.EditorEnabled != nil {
+		next.Editor.Enabled = *req.EditorEnabled
+	}
 	if req.PrivacyEnabled != nil {
 		next.Privacy.Enabled = *req.PrivacyEnabled
 	}
@@ -296,3 +363,11 @@ func validateFileExists(p string) error {
 	}
 	return nil
 }
+
+// configPath 返回运行时实际使用的配置文件路径
+func (a *API) configPath() (string, error) {
+	if a.rt != nil && a.rt.Core != nil && a.rt.CfgPath != "" {
+		return a.rt.CfgPath, nil
+	}
+	return config.DefaultConfigPath()
+}
@@ -1,5 +1,3 @@
-//go:build windows
-
 package handler
 
 import (
@@ -63,6 +61,30 @@ func (a *API) HandleTrends(w http.ResponseWriter, r *http.Request) {
 			TotalDiffs:      st.TotalDiffs,
 			TotalCodingMins: st.TotalCodingMins,
 			SessionCount:    st.SessionCount,
+
+			BrowsingCategories: categoryTrendsDTO(st.BrowsingCategories),
+		})
+	}
+
+	domains := make([]dto.DomainTrendDTO, 0, len(report.TopDomains))
+	for _, d := range report.TopDomains {
+		domains = append(domains, dto.DomainTrendDTO{
+			Domain:   d.Domain,
+			Category: d.Category,
+			Skill:    d.Skill,
+			Visits:   d.Visits,
+			Minutes:  d.Minutes,
+		})
+	}
+
+	sessionBrowsing := make([]dto.SessionBrowsingDTO, 0, len(report.SessionBrowsing))
+	for _, sb := range report.SessionBrowsing {
+		sessionBrowsing = append(sessionBrowsing, dto.SessionBrowsingDTO{
+			SessionID:  sb.SessionID,
+			Date:       sb.Date,
+			StartTime:  sb.StartTime,
+			EndTime:    sb.EndTime,
+			Categories: categoryTrendsDTO(sb.Categories),
 		})
 	}
 
@@ -77,9 +99,22 @@ func (a *API) HandleTrends(w http.ResponseWriter, r *http.Request) {
 		TopSkills:       skills,
 		Bottlenecks:     report.Bottlenecks,
 		DailyStats:      dailyStats,
+		TopDomains:      domains,
+		BrowsingMins:    report.BrowsingMins,
+
+		BrowsingCategories: categoryTrendsDTO(report.BrowsingCategories),
+		SessionBrowsing:    sessionBrowsing,
 	})
 }
 
+func categoryTrendsDTO(in []service.CategoryTrend) []dto.CategoryTrendDTO {
+	out := make([]dto.CategoryTrendDTO, 0, len(in))
+	for _, c := range in {
+		out = append(out, dto.CategoryTrendDTO{Category: c.Category, Visits: c.Visits, Minutes: c.Minutes})
+	}
+	return out
+}
+
 func (a *API) HandleAppStats(w http.ResponseWriter, r *http.Request) {
 	now := time.Now()
 
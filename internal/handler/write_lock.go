@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+type writeLockKey struct{}
+
+// WriteLockHandoff 中间件为写接口持有的写锁；后台执行的任务可以接管，在任务结束时释放
+type WriteLockHandoff struct {
+	once    sync.Once
+	release func()
+	taken   atomic.Bool
+}
+
+// NewWriteLockHandoff 包装写锁的释放函数
+func NewWriteLockHandoff(release func()) *WriteLockHandoff {
+	return &WriteLockHandoff{release: release}
+}
+
+// WithWriteLockHandoff 把写锁放入请求上下文
+func WithWriteLockHandoff(ctx context.Context, h *WriteLockHandoff) context.Context {
+	return context.WithValue(ctx, writeLockKey{}, h)
+}
+
+// Done 请求处理结束：写锁未被接管时释放
+func (h *WriteLockHandoff) Done() {
+	if !h.taken.Load() {
+		h.unlock()
+	}
+}
+
+func (h *WriteLockHandoff) unlock() {
+	h.once.Do(h.release)
+}
+
+// takeWriteLock 接管请求持有的写锁，返回释放函数（可重复调用）；
+// 没有写锁（如 CLI 进程内调用，写锁由命令自己持有）时返回空函数
+func takeWriteLock(r *http.Request) func() {
+	h, ok := r.Context().Value(writeLockKey{}).(*WriteLockHandoff)
+	if !ok || h == nil {
+		return func() {}
+	}
+	h.taken.Store(true)
+	return h.unlock
+}
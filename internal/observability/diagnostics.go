@@ -1,5 +1,3 @@
-//go:build windows
-
 package observability
 
 import (
@@ -15,7 +13,6 @@ import (
 
 	"github.com/yuqie6/WorkMirror/internal/bootstrap"
 	"github.com/yuqie6/WorkMirror/internal/dto"
-	"github.com/yuqie6/WorkMirror/internal/pkg/config"
 	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
 )
 
@@ -50,7 +47,7 @@ func WriteDiagnosticsZipWithStatus(w io.Writer, rt *bootstrap.AgentRuntime, stat
 	_ = addZipJSON(zw, "status.json", status)
 	_ = addZipText(zw, "README.txt", buildDiagReadme())
 
-	cfgPath, _ := config.DefaultConfigPath()
+	cfgPath := rt.CfgPath
 	if strings.TrimSpace(cfgPath) != "" {
 		if b, err := os.ReadFile(cfgPath); err == nil {
 			_ = addZipText(zw, "config/config.yaml.redacted", redactConfigYAML(string(b)))
@@ -1,5 +1,3 @@
-//go:build windows
-
 package observability
 
 import (
@@ -11,7 +9,6 @@ import (
 	"github.com/yuqie6/WorkMirror/internal/bootstrap"
 	"github.com/yuqie6/WorkMirror/internal/collector"
 	"github.com/yuqie6/WorkMirror/internal/dto"
-	"github.com/yuqie6/WorkMirror/internal/pkg/config"
 	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
 	"github.com/yuqie6/WorkMirror/internal/schema"
 )
@@ -46,6 +43,20 @@ func BuildStatus(ctx context.Context, rt *bootstrap.AgentRuntime, startedAt time
 		}
 	}
 
+	commitCount24h := int64(0)
+	if rt.Repos.Commit != nil {
+		if n, err := rt.Repos.Commit.CountByDateRange(ctx, start24h, end24h); err == nil {
+			commitCount24h = n
+		}
+	}
+
+	terminalCount24h := int64(0)
+	if rt.Repos.Terminal != nil {
+		if n, err := rt.Repos.Terminal.CountByDateRange(ctx, start24h, end24h); err == nil {
+			terminalCount24h = n
+		}
+	}
+
 	windowCollectedAt := int64(0)
 	windowDropped := int64(0)
 	windowRunning := false
@@ -74,6 +85,8 @@ func BuildStatus(ctx context.Context, rt *bootstrap.AgentRuntime, startedAt time
 	diffSkipped := int64(0)
 	diffRunning := false
 	diffWatchPaths := []string(nil)
+	diffBackend := ""
+	diffWatchModes := map[string]string(nil)
 	if rt.Collectors.Diff != nil {
 		st := rt.Collectors.Diff.Stats()
 		diffCollectedAt = st.LastEmitAt
@@ -81,6 +94,8 @@ func BuildStatus(ctx context.Context, rt *bootstrap.AgentRuntime, startedAt time
 		diffSkipped = st.SkippedNonGit
 		diffRunning = st.Running
 		diffWatchPaths = st.WatchPaths
+		diffBackend = st.GitBackend
+		diffWatchModes = st.WatchModes
 	}
 	diffPersistAt := int64(0)
 	if rt.Services.Diff != nil {
@@ -93,8 +108,10 @@ func BuildStatus(ctx context.Context, rt *bootstrap.AgentRuntime, startedAt time
 	browserDropped := int64(0)
 	browserRunning := false
 	browserHistoryPath := strings.TrimSpace(cfg.Browser.HistoryPath)
+	var browserHistoryPaths []string
 	if rt.Collectors.Browser != nil {
 		st := rt.Collectors.Browser.Stats()
+		browserHistoryPaths = st.HistoryPaths
 		browserCollectedAt = st.LastEmitAt
 		browserDropped = st.Dropped
 		browserRunning = st.Running
@@ -109,6 +126,41 @@ func BuildStatus(ctx context.Context, rt *bootstrap.AgentRuntime, startedAt time
 		browserRunning = browserRunning || bs.Running
 	}
 
+	commitStatus := dto.CollectorStatusDTO{
+		Enabled:  cfg.Diff.Enabled && cfg.Diff.CommitsEnabled && len(cfg.Diff.WatchPaths) > 0,
+		Count24h: commitCount24h,
+	}
+	if rt.Collectors.Commit != nil {
+		st := rt.Collectors.Commit.Stats()
+		commitStatus.Running = st.Running
+		commitStatus.LastCollectedAt = st.LastEmitAt
+		commitStatus.DroppedEvents = st.Dropped
+		commitStatus.EffectivePaths = st.Repos
+	}
+	if rt.Services.Commit != nil {
+		cs := rt.Services.Commit.Stats()
+		commitStatus.LastPersistedAt = cs.LastPersistAt
+		commitStatus.Running = commitStatus.Running || cs.Running
+	}
+
+	terminalStatus := dto.CollectorStatusDTO{
+		Enabled:          cfg.Terminal.Enabled,
+		Count24h:         terminalCount24h,
+		SanitizedEnabled: cfg.Terminal.Enabled, // 命令脱敏不受 privacy.enabled 控制
+	}
+	if rt.Collectors.Terminal != nil {
+		st := rt.Collectors.Terminal.Stats()
+		terminalStatus.Running = st.Running
+		terminalStatus.LastCollectedAt = st.LastEmitAt
+		terminalStatus.DroppedEvents = st.Dropped
+		terminalStatus.HistoryPaths = st.HistoryFiles
+	}
+	if rt.Services.Terminal != nil {
+		ts := rt.Services.Terminal.Stats()
+		terminalStatus.LastPersistedAt = ts.LastPersistAt
+		terminalStatus.Running = terminalStatus.Running || ts.Running
+	}
+
 	sessionCount24h := int64(0)
 	pendingSemantic24h := int64(0)
 	if rt.Repos.Session != nil {
@@ -183,7 +235,7 @@ func BuildStatus(ctx context.Context, rt *bootstrap.AgentRuntime, startedAt time
 				if hasDiff && hasBrowser {
 					evidence.WithDiffBrowser++
 				}
-				if !hasDiff && !hasBrowser {
+				if !hasDiff && !hasBrowser && len(schema.GetInt64Slice(s.Metadata, schema.SessionMetaCommitIDs)) == 0 {
 					evidence.WeakEvidence++
 				}
 				for _, id := range diffIDs {
@@ -228,7 +280,7 @@ func BuildStatus(ctx context.Context, rt *bootstrap.AgentRuntime, startedAt time
 	logPath := strings.TrimSpace(cfg.App.LogPath)
 	recentErr := ReadRecentErrors(logPath, privacy.New(cfg.Privacy.Enabled, cfg.Privacy.Patterns), 20)
 
-	cfgPath, _ := config.DefaultConfigPath()
+	cfgPath := rt.CfgPath
 
 	return &dto.StatusDTO{
 		App: dto.AppStatusDTO{
@@ -268,6 +320,8 @@ func BuildStatus(ctx context.Context, rt *bootstrap.AgentRuntime, startedAt time
 				Skipped:         diffSkipped,
 				WatchPaths:      diffWatchPaths,
 				EffectivePaths:  len(cfg.Diff.WatchPaths),
+				Backend:         diffBackend,
+				WatchModes:      diffWatchModes,
 			},
 			Browser: dto.CollectorStatusDTO{
 				Enabled:          cfg.Browser.Enabled,
@@ -277,8 +331,11 @@ func BuildStatus(ctx context.Context, rt *bootstrap.AgentRuntime, startedAt time
 				Count24h:         browserCount24h,
 				DroppedEvents:    browserDropped,
 				HistoryPath:      browserHistoryPath,
+				HistoryPaths:     browserHistoryPaths,
 				SanitizedEnabled: cfg.Privacy.Enabled,
 			},
+			Commits:  commitStatus,
+			Terminal: terminalStatus,
 		},
 		Pipeline: dto.PipelineStatusDTO{
 			Sessions: dto.SessionPipelineStatusDTO{
@@ -18,6 +18,9 @@ type Config struct {
 	Collector CollectorConfig `mapstructure:"collector"`
 	Diff      DiffConfig      `mapstructure:"diff"`
 	Browser   BrowserConfig   `mapstructure:"browser"`
+	Terminal  TerminalConfig  `mapstructure:"terminal"`
+	Editor    EditorConfig    `mapstructure:"editor"`
+	Ingest    IngestConfig    `mapstructure:"ingest"`
 	Storage   StorageConfig   `mapstructure:"storage"`
 	AI        AIConfig        `mapstructure:"ai"`
 	Privacy   PrivacyConfig   `mapstructure:"privacy"`
@@ -54,6 +57,37 @@ type DiffConfig struct {
 	Extensions  []string `mapstructure:"extensions"`
 	BufferSize  int      `mapstructure:"buffer_size"`
 	DebounceSec int      `mapstructure:"debounce_sec"`
+	GitBackend  string   `mapstructure:"git_backend"` // auto | native | exec
+
+	// 在 .gitignore / .git/info/exclude 之外额外忽略的路径（gitignore 语法）
+	Exclude []string       `mapstructure:"exclude"`
+	Repos   []DiffRepoRule `mapstructure:"repos"`
+
+	// 监控方式：auto（fsnotify，注册失败回退轮询）| notify | poll；可在 repos 中按路径覆盖
+	WatchMode       string `mapstructure:"watch_mode"`
+	PollIntervalSec int    `mapstructure:"poll_interval_sec"`
+	PollMaxFiles    int    `mapstructure:"poll_max_files"` // 每次轮询最多检查的文件数
+
+	// 启动时补扫上次记录之后被修改的文件（采集器离线期间的改动）
+	CatchUpEnabled  bool `mapstructure:"catch_up_enabled"`
+	CatchUpMaxFiles int  `mapstructure:"catch_up_max_files"`
+
+	CommitsEnabled bool `mapstructure:"commits_enabled"` // 采集监控目录下仓库的提交
+
+	// 非 Git 目录的影子快照（存放在数据库同级的 snapshots 目录）
+	SnapshotEnabled    bool `mapstructure:"snapshot_enabled"`
+	SnapshotMaxFileKB  int  `mapstructure:"snapshot_max_file_kb"`
+	SnapshotMaxTotalMB int  `mapstructure:"snapshot_max_total_mb"`
+}
+
+// DiffRepoRule 按仓库（目录）的采集范围，模式相对 Path 匹配
+type DiffRepoRule struct {
+	Path    string   `mapstructure:"path"`
+	Include []string `mapstructure:"include"` // 非空时只采集匹配的文件
+	Exclude []string `mapstructure:"exclude"`
+
+	WatchMode string `mapstructure:"watch_mode"` // 为空时使用 diff.watch_mode
+	DevPorts  []int  `mapstructure:"dev_ports"`  // 该项目本地开发服务的端口，localhost 浏览据此归到项目
 }
 
 // BrowserConfig 浏览器采集配置
@@ -61,6 +95,48 @@ type BrowserConfig struct {
 	Enabled         bool   `mapstructure:"enabled"`
 	PollIntervalSec int    `mapstructure:"poll_interval_sec"`
 	HistoryPath     string `mapstructure:"history_path"`
+
+	Browsers           []string `mapstructure:"browsers"`             // chromium-family | chrome | edge | brave | vivaldi | arc | chromium | firefox，为空时全部
+	FirefoxProfilesDir string   `mapstructure:"firefox_profiles_dir"` // 含 profiles.ini 的目录（为空则自动检测）
+	DisabledProfiles   []string `mapstructure:"disabled_profiles"`    // 不采集的 profile：<browser>/<profile 目录名>
+
+	SearchTermsEnabled bool `mapstructure:"search_terms_enabled"` // 提取搜索词作为会话证据（默认关闭）
+
+	DomainRules       []BrowserDomainRule `mapstructure:"domain_rules"`        // 自定义域名分类与技能映射，补充/覆盖内置规则
+	ReadingExpEnabled bool                `mapstructure:"reading_exp_enabled"` // 映射到技能的域名阅读时长计入技能经验
+
+	NativeHostBrowsers []string `mapstructure:"native_host_browsers"` // 安装了扩展（native messaging）的浏览器：停留时长以扩展上报的前台时间为准
+}
+
+// BrowserDomainRule 域名分类规则，Domain 同时匹配子域名
+type BrowserDomainRule struct {
+	Domain   string `mapstructure:"domain"`
+	Category string `mapstructure:"category"` // docs | code | qa | video | social | news | chat | dev | 自定义
+	Skill    string `mapstructure:"skill"`
+}
+
+// TerminalConfig 终端 shell 历史采集配置（默认关闭）
+type TerminalConfig struct {
+	Enabled         bool              `mapstructure:"enabled"`
+	PollIntervalSec int               `mapstructure:"poll_interval_sec"`
+	Shells          []string          `mapstructure:"shells"`          // bash | zsh | fish | powershell，为空时全部
+	HistoryFiles    map[string]string `mapstructure:"history_files"`   // shell -> 历史文件路径，覆盖自动检测
+	IgnoreCommands  []string          `mapstructure:"ignore_commands"` // 按第一个词忽略的命令
+	SecretPatterns  []string          `mapstructure:"secret_patterns"` // 额外的命令脱敏正则（内置规则之外）
+}
+
+// EditorConfig 编辑器插件心跳接入配置（兼容 WakaTime 插件的 api_url）
+type EditorConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	ListenAddr string `mapstructure:"listen_addr"` // 托盘 Agent 的固定监听地址，插件 api_url 为 http://<listen_addr>/api/v1
+	APIKey     string `mapstructure:"api_key"`     // 非空时校验插件配置的 api_key；为空接受任意 key
+}
+
+// IngestConfig 外部事件写入接口（POST /api/ingest）配置（默认关闭）
+type IngestConfig struct {
+	Enabled         bool   `mapstructure:"enabled"`
+	Token           string `mapstructure:"token"`              // 必填：请求需携带 Authorization: Bearer <token>
+	RateLimitPerMin int    `mapstructure:"rate_limit_per_min"` // 每分钟允许写入的事件数
 }
 
 // AIConfig AI 配置
@@ -246,6 +322,44 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("diff.extensions", []string{".go", ".py", ".js", ".ts", ".jsx", ".tsx", ".vue", ".java", ".rs", ".c", ".cpp"})
 	v.SetDefault("diff.buffer_size", 512)
 	v.SetDefault("diff.debounce_sec", 2)
+	v.SetDefault("diff.git_backend", "auto")
+	v.SetDefault("diff.exclude", []string{"node_modules/", "vendor/", "__pycache__/", "dist/", "build/"})
+	v.SetDefault("diff.repos", []map[string]any{})
+	v.SetDefault("diff.watch_mode", "auto")
+	v.SetDefault("diff.poll_interval_sec", 10)
+	v.SetDefault("diff.poll_max_files", 20000)
+	v.SetDefault("diff.catch_up_enabled", true)
+	v.SetDefault("diff.catch_up_max_files", 200)
+	v.SetDefault("diff.commits_enabled", true)
+	v.SetDefault("diff.snapshot_enabled", true)
+	v.SetDefault("diff.snapshot_max_file_kb", 512)
+	v.SetDefault("diff.snapshot_max_total_mb", 64)
+
+	// Browser
+	v.SetDefault("browser.browsers", []string{})
+	v.SetDefault("browser.disabled_profiles", []string{})
+	v.SetDefault("browser.search_terms_enabled", false)
+	v.SetDefault("browser.domain_rules", []map[string]any{})
+	v.SetDefault("browser.reading_exp_enabled", false)
+	v.SetDefault("browser.native_host_browsers", []string{})
+
+	// Terminal
+	v.SetDefault("terminal.enabled", false)
+	v.SetDefault("terminal.poll_interval_sec", 15)
+	v.SetDefault("terminal.shells", []string{})
+	v.SetDefault("terminal.history_files", map[string]string{})
+	v.SetDefault("terminal.ignore_commands", []string{"ls", "ll", "la", "cd", "pwd", "clear", "cls", "exit", "history"})
+	v.SetDefault("terminal.secret_patterns", []string{})
+
+	// Editor
+	v.SetDefault("editor.enabled", true)
+	v.SetDefault("editor.listen_addr", "127.0.0.1:7410")
+	v.SetDefault("editor.api_key", "")
+
+	// Ingest
+	v.SetDefault("ingest.enabled", false)
+	v.SetDefault("ingest.token", "")
+	v.SetDefault("ingest.rate_limit_per_min", 600)
 
 	// AI
 	// 默认使用内置免费服务
@@ -324,6 +438,7 @@ type LoggerOptions struct {
 	Level     string
 	Path      string
 	Component string
+	Console   io.Writer // 控制台输出（nil=stdout）
 }
 
 // SetupLogger 初始化默认日志（stdout + 可选落盘）
@@ -345,7 +460,11 @@ func SetupLogger(opts LoggerOptions) (io.Closer, error) {
 
 	var file *os.File
 	var setupErr error
-	writer := io.Writer(os.Stdout)
+	console := opts.Console
+	if console == nil {
+		console = os.Stdout
+	}
+	writer := console
 	if strings.TrimSpace(opts.Path) != "" {
 		if err := os.MkdirAll(filepath.Dir(opts.Path), 0o755); err != nil {
 			setupErr = fmt.Errorf("创建日志目录失败: %w", err)
@@ -353,7 +472,7 @@ func SetupLogger(opts LoggerOptions) (io.Closer, error) {
 			setupErr = fmt.Errorf("打开日志文件失败: %w", err)
 		} else {
 			file = f
-			writer = io.MultiWriter(os.Stdout, file)
+			writer = io.MultiWriter(console, file)
 		}
 	}
 
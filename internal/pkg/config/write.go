@@ -54,11 +54,52 @@ func WriteFile(path string, cfg *Config) error {
 			"extensions":   append([]string{}, cfg.Diff.Extensions...),
 			"buffer_size":  cfg.Diff.BufferSize,
 			"debounce_sec": cfg.Diff.DebounceSec,
+			"git_backend":  cfg.Diff.GitBackend,
+			"exclude":      append([]string{}, cfg.Diff.Exclude...),
+			"repos":        diffRepoRulesToMaps(cfg.Diff.Repos),
+
+			"watch_mode":        cfg.Diff.WatchMode,
+			"poll_interval_sec": cfg.Diff.PollIntervalSec,
+			"poll_max_files":    cfg.Diff.PollMaxFiles,
+
+			"catch_up_enabled":   cfg.Diff.CatchUpEnabled,
+			"catch_up_max_files": cfg.Diff.CatchUpMaxFiles,
+
+			"commits_enabled":       cfg.Diff.CommitsEnabled,
+			"snapshot_enabled":      cfg.Diff.SnapshotEnabled,
+			"snapshot_max_file_kb":  cfg.Diff.SnapshotMaxFileKB,
+			"snapshot_max_total_mb": cfg.Diff.SnapshotMaxTotalMB,
 		},
 		"browser": map[string]any{
 			"enabled":           cfg.Browser.Enabled,
 			"poll_interval_sec": cfg.Browser.PollIntervalSec,
 			"history_path":      cfg.Browser.HistoryPath,
+
+			"browsers":             cfg.Browser.Browsers,
+			"firefox_profiles_dir": cfg.Browser.FirefoxProfilesDir,
+			"disabled_profiles":    cfg.Browser.DisabledProfiles,
+			"search_terms_enabled": cfg.Browser.SearchTermsEnabled,
+			"domain_rules":         browserDomainRulesToMaps(cfg.Browser.DomainRules),
+			"reading_exp_enabled":  cfg.Browser.ReadingExpEnabled,
+			"native_host_browsers": cfg.Browser.NativeHostBrowsers,
+		},
+		"terminal": map[string]any{
+			"enabled":           cfg.Terminal.Enabled,
+			"poll_interval_sec": cfg.Terminal.PollIntervalSec,
+			"shells":            cfg.Terminal.Shells,
+			"history_files":     cfg.Terminal.HistoryFiles,
+			"ignore_commands":   cfg.Terminal.IgnoreCommands,
+			"secret_patterns":   cfg.Terminal.SecretPatterns,
+		},
+		"editor": map[string]any{
+			"enabled":     cfg.Editor.Enabled,
+			"listen_addr": cfg.Editor.ListenAddr,
+			"api_key":     cfg.Editor.APIKey,
+		},
+		"ingest": map[string]any{
+			"enabled":            cfg.Ingest.Enabled,
+			"token":              cfg.Ingest.Token,
+			"rate_limit_per_min": cfg.Ingest.RateLimitPerMin,
 		},
 		"ai": map[string]any{
 			"provider": cfg.AI.Provider,
@@ -111,3 +152,30 @@ func WriteFile(path string, cfg *Config) error {
 	}
 	return nil
 }
+
+func diffRepoRulesToMaps(rules []DiffRepoRule) []map[string]any {
+	out := make([]map[string]any, 0, len(rules))
+	for _, r := range rules {
+		out = append(out, map[string]any{
+			"path":    r.Path,
+			"include": append([]string{}, r.Include...),
+			"exclude": append([]string{}, r.Exclude...),
+
+			"watch_mode": r.WatchMode,
+			"dev_ports":  append([]int{}, r.DevPorts...),
+		})
+	}
+	return out
+}
+
+func browserDomainRulesToMaps(rules []BrowserDomainRule) []map[string]any {
+	out := make([]map[string]any, 0, len(rules))
+	for _, r := range rules {
+		out = append(out, map[string]any{
+			"domain":   r.Domain,
+			"category": r.Category,
+			"skill":    r.Skill,
+		})
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+// Package lockfile 提供基于文件锁的进程级互斥（单实例 / 写库协调）。
+// 锁随进程退出由操作系统自动释放，不会因崩溃残留。
+package lockfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// ErrLocked 锁已被其他进程持有
+var ErrLocked = errors.New("锁已被其他进程持有")
+
+// Lock 已持有的文件锁
+type Lock struct {
+	f    *os.File
+	path string
+}
+
+// TryLock 非阻塞地获取 path 上的排他锁；已被占用时返回 ErrLocked
+func TryLock(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("创建锁目录失败: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("打开锁文件失败: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	// 写入 PID 便于排查是谁持有锁（内容仅供参考，互斥语义由文件锁保证）
+	_ = f.Truncate(0)
+	_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+
+	return &Lock{f: f, path: path}, nil
+}
+
+// Wait 阻塞等待获取锁，直到成功或 ctx 结束（按 interval 重试）
+func Wait(ctx context.Context, path string, interval time.Duration) (*Lock, error) {
+	if interval <= 0 {
+		interval = 200 * time.Millisecond
+	}
+	for {
+		l, err := TryLock(path)
+		if !errors.Is(err, ErrLocked) {
+			return l, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("等待锁超时: %w", ErrLocked)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Path 锁文件路径
+func (l *Lock) Path() string {
+	if l == nil {
+		return ""
+	}
+	return l.path
+}
+
+// Unlock 释放锁（可重复调用）
+func (l *Lock) Unlock() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	err := unlockFile(l.f)
+	_ = l.f.Close()
+	l.f = nil
+	return err
+}
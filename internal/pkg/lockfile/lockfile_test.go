@@ -0,0 +1,57 @@
+package lockfile
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTryLock_Exclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "agent.lock")
+
+	first, err := TryLock(path)
+	if err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+
+	if _, err := TryLock(path); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked while held, got %v", err)
+	}
+
+	if err := first.Unlock(); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if err := first.Unlock(); err != nil {
+		t.Fatalf("second Unlock should be a no-op: %v", err)
+	}
+
+	second, err := TryLock(path)
+	if err != nil {
+		t.Fatalf("TryLock after unlock: %v", err)
+	}
+	_ = second.Unlock()
+}
+
+func TestWait_AcquiresAfterRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "write.lock")
+
+	held, err := TryLock(path)
+	if err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+
+	short, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := Wait(short, path, 10*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked on timeout, got %v", err)
+	}
+
+	time.AfterFunc(30*time.Millisecond, func() { _ = held.Unlock() })
+	l, err := Wait(context.Background(), path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	_ = l.Unlock()
+}
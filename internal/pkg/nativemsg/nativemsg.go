@@ -0,0 +1,86 @@
+// Package nativemsg 实现浏览器 Native Messaging 协议：
+// 每条消息为 4 字节本机字节序（x86/arm 均为小端）长度前缀 + UTF-8 JSON。
+package nativemsg
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MaxMessageSize 单条消息上限：浏览器限制 host 发出的消息不超过 1MB，
+// 扩展发来的消息也只是标签页事件，超过同样上限视为协议错误
+const MaxMessageSize = 1 << 20
+
+var ErrMessageTooLarge = errors.New("消息超过 1MB 上限")
+
+// Read 读取一条消息；流在消息边界结束时返回 io.EOF
+func Read(r io.Reader) (json.RawMessage, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("读取消息长度失败: %w", err)
+		}
+		return nil, err
+	}
+	n := binary.LittleEndian.Uint32(hdr[:])
+	if n > MaxMessageSize {
+		return nil, fmt.Errorf("%w: %d 字节", ErrMessageTooLarge, n)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("读取消息内容失败: %w", err)
+	}
+	return buf, nil
+}
+
+// Write 将 v 编码为 JSON 并带长度前缀写出（一次 Write 调用，避免与其他写入交错）
+func Write(w io.Writer, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("编码消息失败: %w", err)
+	}
+	if len(body) > MaxMessageSize {
+		return ErrMessageTooLarge
+	}
+	buf := make([]byte, 4+len(body))
+	binary.LittleEndian.PutUint32(buf, uint32(len(body)))
+	copy(buf[4:], body)
+	if _, err := w.Write(buf); err != nil {
+		return fmt.Errorf("写出消息失败: %w", err)
+	}
+	return nil
+}
+
+// Handler 处理一条消息并返回回复（nil 表示不回复）
+type Handler func(ctx context.Context, msg json.RawMessage) any
+
+// Serve 逐条读取消息交给 handler，直到 r 结束（浏览器断开端口）或 ctx 取消。
+// 正常结束返回 nil；ctx 取消只在两条消息之间生效，阻塞中的读取由调用方关闭 r 解除。
+func Serve(ctx context.Context, r io.Reader, w io.Writer, handler Handler) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil
+		}
+		msg, err := Read(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		reply := handler(ctx, msg)
+		if reply == nil {
+			continue
+		}
+		if err := Write(w, reply); err != nil {
+			return err
+		}
+	}
+}
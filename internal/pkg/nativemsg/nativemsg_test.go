@@ -0,0 +1,65 @@
+package nativemsg
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestServe_EchoesFramedMessages(t *testing.T) {
+	var in bytes.Buffer
+	for _, m := range []map[string]any{{"type": "ping", "n": 1}, {"type": "ping", "n": 2}, {"type": "skip"}} {
+		if err := Write(&in, m); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	err := Serve(context.Background(), &in, &out, func(ctx context.Context, raw json.RawMessage) any {
+		var m map[string]any
+		_ = json.Unmarshal(raw, &m)
+		if m["type"] == "skip" {
+			return nil
+		}
+		return map[string]any{"ok": true, "n": m["n"]}
+	})
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+
+	for want := 1.0; want <= 2; want++ {
+		raw, err := Read(&out)
+		if err != nil {
+			t.Fatalf("Read reply: %v", err)
+		}
+		var reply map[string]any
+		if err := json.Unmarshal(raw, &reply); err != nil || reply["n"] != want {
+			t.Fatalf("reply=%s err=%v, want n=%v", raw, err, want)
+		}
+	}
+	if _, err := Read(&out); !errors.Is(err, io.EOF) {
+		t.Fatalf("unexpected extra reply: %v", err)
+	}
+}
+
+func TestRead_RejectsOversizedAndTruncated(t *testing.T) {
+	var hdr [4]byte
+	binary.LittleEndian.PutUint32(hdr[:], MaxMessageSize+1)
+	if _, err := Read(bytes.NewReader(hdr[:])); !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("err=%v, want ErrMessageTooLarge", err)
+	}
+
+	binary.LittleEndian.PutUint32(hdr[:], 10)
+	truncated := append(hdr[:], []byte(`{"a":`)...)
+	if _, err := Read(bytes.NewReader(truncated)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("err=%v, want ErrUnexpectedEOF", err)
+	}
+
+	if _, err := Read(bytes.NewReader([]byte{1, 0})); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("partial header err=%v", err)
+	}
+}
@@ -10,7 +10,21 @@ import (
 // 目标：默认不泄露 URL query/fragment、账号信息等，同时尽量保留可解释性（域名/应用名仍可用于证据链）。
 type Sanitizer struct {
 	enabled  bool
-	patterns []*regexp.Regexp
+	builtin  []*regexp.Regexp // 内置规则：命名分组 keep/tail 原样保留（例如保留参数名、只替换参数值）
+	patterns []*regexp.Regexp // 用户规则：整段匹配替换为 "***"
+}
+
+// commandSecretPatterns 命令行中常见的凭据写法：敏感参数/环境变量的值、认证头、URL 中的密码与常见令牌格式
+var commandSecretPatterns = []string{
+	`(?i)(?P<keep>(?:^|\s)--?(?:[\w-]*[-_])?(?:token|secret|password|passwd|pwd|api[-_]?key|access[-_]?key|private[-_]?key|credentials?)[\w-]*(?:=|\s+))(?:"[^"]*"|'[^']*'|\S+)`,
+	`(?i)(?P<keep>\b[\w]*(?:token|secret|password|passwd|api_?key|access_?key|private_?key)[\w]*\s*=\s*)(?:"[^"]*"|'[^']*'|\S+)`,
+	`(?i)(?P<keep>\bauthorization:\s*(?:bearer|basic|token)?\s*)[^\s"']+`,
+	`(?i)(?P<keep>\b(?:mysql|mysqldump|mysqladmin|mariadb)\b[^|;&\n]*?\s-p)[^\s"']+`,
+	`(?P<keep>\bsshpass\s+-p\s*)\S+`,
+	`(?P<keep>\s(?:-u|--user)\s+[^\s:]+:)\S+`,
+	`(?P<keep>[a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@]+:)[^/\s@]+(?P<tail>@)`,
+	`\b(?:gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,}|glpat-[A-Za-z0-9_-]{20,}|xox[abprs]-[A-Za-z0-9-]{10,}|sk-[A-Za-z0-9_-]{20,}|AKIA[0-9A-Z]{16})\b`,
+	`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`,
 }
 
 func New(enabled bool, patterns []string) *Sanitizer {
@@ -18,8 +32,20 @@ func New(enabled bool, patterns []string) *Sanitizer {
 	if !enabled || len(patterns) == 0 {
 		return s
 	}
+	s.patterns = compilePatterns(patterns)
+	return s
+}
 
-	s.patterns = make([]*regexp.Regexp, 0, len(patterns))
+// NewCommandSanitizer 终端命令脱敏：内置命令行凭据规则 + 额外规则，始终启用（shell 历史里常有明文密钥）
+func NewCommandSanitizer(patterns []string) *Sanitizer {
+	s := New(true, patterns)
+	s.builtin = compilePatterns(commandSecretPatterns)
+	return s
+}
+
+// compilePatterns 编译规则，跳过空串与无效正则
+func compilePatterns(patterns []string) []*regexp.Regexp {
+	out := make([]*regexp.Regexp, 0, len(patterns))
 	for _, raw := range patterns {
 		p := strings.TrimSpace(raw)
 		if p == "" {
@@ -29,9 +55,9 @@ func New(enabled bool, patterns []string) *Sanitizer {
 		if err != nil {
 			continue
 		}
-		s.patterns = append(s.patterns, re)
+		out = append(out, re)
 	}
-	return s
+	return out
 }
 
 func (s *Sanitizer) Enabled() bool {
@@ -53,6 +79,9 @@ func (s *Sanitizer) SanitizeText(input string) string {
 	}
 
 	out := in
+	for _, re := range s.builtin {
+		out = re.ReplaceAllString(out, replacementFor(re))
+	}
 	for _, re := range s.patterns {
 		if re == nil {
 			continue
@@ -62,6 +91,23 @@ func (s *Sanitizer) SanitizeText(input string) string {
 	return strings.TrimSpace(out)
 }
 
+// SanitizeCommand 终端命令脱敏
+func (s *Sanitizer) SanitizeCommand(cmd string) string {
+	return s.SanitizeText(cmd)
+}
+
+// replacementFor 内置规则的替换模板：保留命名分组 keep/tail，其余替换为 ***
+func replacementFor(re *regexp.Regexp) string {
+	repl := "***"
+	if re.SubexpIndex("keep") >= 0 {
+		repl = "${keep}" + repl
+	}
+	if re.SubexpIndex("tail") >= 0 {
+		repl += "${tail}"
+	}
+	return repl
+}
+
 // SanitizeURL 默认只保留 scheme/host，并清空 query/fragment，避免泄露参数与锚点。
 // path 会被替换为 "/..."（保留“这是一个路径”这一事实，且避免 URL 编码噪音）。
 func (s *Sanitizer) SanitizeURL(raw string) string {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/yuqie6/WorkMirror/internal/schema"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BrowserEventRepository 浏览器事件仓储
@@ -27,13 +28,15 @@ func (r *BrowserEventRepository) Create(ctx context.Context, event *schema.Brows
 	return nil
 }
 
-// BatchInsert 批量插入
+// BatchInsert 批量插入；(browser, profile, visit_id) 已存在的访问跳过（重启后重读同一段历史）
 func (r *BrowserEventRepository) BatchInsert(ctx context.Context, events []*schema.BrowserEvent) error {
 	if len(events) == 0 {
 		return nil
 	}
 
-	if err := r.db.WithContext(ctx).CreateInBatches(events, 100).Error; err != nil {
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(events, 100).Error; err != nil {
 		return fmt.Errorf("批量插入浏览器事件失败: %w", err)
 	}
 
@@ -98,17 +101,20 @@ func (r *BrowserEventRepository) GetByIDs(ctx context.Context, ids []int64) ([]s
 	return ordered, nil
 }
 
-// GetDomainStats 获取域名统计
+// GetDomainStats 获取域名统计（limit <= 0 不限制）
 func (r *BrowserEventRepository) GetDomainStats(ctx context.Context, startTime, endTime int64, limit int) ([]DomainStat, error) {
 	var stats []DomainStat
-	if err := r.db.WithContext(ctx).
+	q := r.db.WithContext(ctx).
 		Model(&schema.BrowserEvent{}).
 		Select("domain, COUNT(*) as visit_count, SUM(duration) as total_duration").
 		Where("timestamp >= ? AND timestamp <= ?", startTime, endTime).
+		Where("transition <> ?", schema.BrowserTransitionRedirect). // 重定向途经页不算访问
 		Group("domain").
-		Order("visit_count DESC").
-		Limit(limit).
-		Scan(&stats).Error; err != nil {
+		Order("visit_count DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Scan(&stats).Error; err != nil {
 		return nil, fmt.Errorf("查询域名统计失败: %w", err)
 	}
 
@@ -119,7 +125,7 @@ func (r *BrowserEventRepository) GetDomainStats(ctx context.Context, startTime,
 type DomainStat struct {
 	Domain        string
 	VisitCount    int64
-	TotalDuration int
+	TotalDuration int // 秒
 }
 
 // CountByDateRange 统计日期范围内的事件数量
@@ -143,3 +149,51 @@ func (r *BrowserEventRepository) GetLatestTimestamp(ctx context.Context) (int64,
 	}
 	return ts, nil
 }
+
+// BrowserVisitCursor 某个浏览器 profile 已入库的最新访问
+type BrowserVisitCursor struct {
+	Browser   string
+	Profile   string
+	VisitID   int64
+	Timestamp int64
+}
+
+// GetVisitCursors 按 (browser, profile) 汇总已入库的最大访问 ID 与时间，作为采集器的持久化游标
+func (r *BrowserEventRepository) GetVisitCursors(ctx context.Context) ([]BrowserVisitCursor, error) {
+	var cursors []BrowserVisitCursor
+	if err := r.db.WithContext(ctx).Model(&schema.BrowserEvent{}).
+		Select("browser, profile, MAX(visit_id) AS visit_id, MAX(timestamp) AS timestamp").
+		Where("visit_id > 0").
+		Group("browser, profile").
+		Scan(&cursors).Error; err != nil {
+		return nil, fmt.Errorf("查询浏览器采集游标失败: %w", err)
+	}
+	return cursors, nil
+}
+
+// UpdateVisitDuration 写入浏览器记录的停留时长（覆盖推断值）
+func (r *BrowserEventRepository) UpdateVisitDuration(ctx context.Context, browser, profile string, visitID int64, seconds int) error {
+	if err := r.db.WithContext(ctx).Model(&schema.BrowserEvent{}).
+		Where("browser = ? AND profile = ? AND visit_id = ?", browser, profile, visitID).
+		Update("duration", seconds).Error; err != nil {
+		return fmt.Errorf("更新浏览器访问时长失败: %w", err)
+	}
+	return nil
+}
+
+// FillDurations 为尚无时长的事件写入推断的停留时长（id -> 秒），已有值的不覆盖
+func (r *BrowserEventRepository) FillDurations(ctx context.Context, durations map[int64]int) error {
+	if len(durations) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for id, sec := range durations {
+			if err := tx.Model(&schema.BrowserEvent{}).
+				Where("id = ? AND duration = 0", id).
+				Update("duration", sec).Error; err != nil {
+				return fmt.Errorf("写入推断停留时长失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+	"github.com/yuqie6/WorkMirror/internal/testutil"
+)
+
+func TestBrowserEventRepository_BatchInsertDedupesVisits(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	repo := NewBrowserEventRepository(db)
+	ctx := context.Background()
+
+	ts := time.Now().UnixMilli()
+	first := []*schema.BrowserEvent{
+		{Timestamp: ts, URL: "https://a.dev", Domain: "a.dev", Browser: "chrome", Profile: "Default", VisitID: 10},
+		{Timestamp: ts + 1, URL: "https://b.dev", Domain: "b.dev", Browser: "chrome", Profile: "Default", VisitID: 11},
+		// 旧版本采集的记录没有 visit_id，不参与去重
+		{Timestamp: ts + 2, URL: "https://legacy.dev", Domain: "legacy.dev"},
+		{Timestamp: ts + 3, URL: "https://legacy.dev", Domain: "legacy.dev"},
+	}
+	if err := repo.BatchInsert(ctx, first); err != nil {
+		t.Fatalf("first insert: %v", err)
+	}
+
+	// 重启后重读同一段历史；其他 profile 的相同 visit_id 照常写入
+	second := []*schema.BrowserEvent{
+		{Timestamp: ts + 1, URL: "https://b.dev", Domain: "b.dev", Browser: "chrome", Profile: "Default", VisitID: 11},
+		{Timestamp: ts + 4, URL: "https://c.dev", Domain: "c.dev", Browser: "chrome", Profile: "Profile 1", VisitID: 11},
+		{Timestamp: ts + 5, URL: "https://d.dev", Domain: "d.dev", Browser: "firefox", Profile: "x.default", VisitID: 3},
+	}
+	if err := repo.BatchInsert(ctx, second); err != nil {
+		t.Fatalf("second insert: %v", err)
+	}
+
+	all, err := repo.GetByTimeRange(ctx, ts, ts+10)
+	if err != nil || len(all) != 6 {
+		t.Fatalf("GetByTimeRange len=%d err=%v, want 6", len(all), err)
+	}
+
+	cursors, err := repo.GetVisitCursors(ctx)
+	if err != nil {
+		t.Fatalf("GetVisitCursors: %v", err)
+	}
+	got := make(map[string]BrowserVisitCursor)
+	for _, c := range cursors {
+		got[c.Browser+"/"+c.Profile] = c
+	}
+	if len(got) != 3 || got["chrome/Default"].VisitID != 11 || got["chrome/Default"].Timestamp != ts+1 || got["firefox/x.default"].VisitID != 3 {
+		t.Fatalf("unexpected cursors: %+v", cursors)
+	}
+}
+
+func TestBrowserEventRepository_durations(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	repo := NewBrowserEventRepository(db)
+	ctx := context.Background()
+
+	ts := time.Now().UnixMilli()
+	events := []*schema.BrowserEvent{
+		{Timestamp: ts, URL: "https://t.co/x", Domain: "t.co", Browser: "chrome", Profile: "Default", VisitID: 1, Transition: schema.BrowserTransitionRedirect},
+		{Timestamp: ts, URL: "https://go.dev/doc", Domain: "go.dev", Browser: "chrome", Profile: "Default", VisitID: 2, FromVisitID: 1},
+		{Timestamp: ts + 1, URL: "https://go.dev/blog", Domain: "go.dev", Browser: "chrome", Profile: "Default", VisitID: 3, Duration: 30},
+	}
+	if err := repo.BatchInsert(ctx, events); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	// 推断值只填空缺，浏览器记录的时长覆盖推断值
+	if err := repo.FillDurations(ctx, map[int64]int{events[1].ID: 120, events[2].ID: 5}); err != nil {
+		t.Fatalf("FillDurations: %v", err)
+	}
+	if err := repo.UpdateVisitDuration(ctx, "chrome", "Default", 2, 300); err != nil {
+		t.Fatalf("UpdateVisitDuration: %v", err)
+	}
+
+	stats, err := repo.GetDomainStats(ctx, ts, ts+1, 10)
+	if err != nil {
+		t.Fatalf("GetDomainStats: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Domain != "go.dev" || stats[0].VisitCount != 2 || stats[0].TotalDuration != 330 {
+		t.Fatalf("unexpected domain stats: %+v", stats)
+	}
+}
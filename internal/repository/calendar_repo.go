@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CalendarEventRepository 日程仓储
+type CalendarEventRepository struct {
+	db *gorm.DB
+}
+
+// NewCalendarEventRepository 创建仓储
+func NewCalendarEventRepository(db *gorm.DB) *CalendarEventRepository {
+	return &CalendarEventRepository{db: db}
+}
+
+// Upsert 按 (uid, start_time) 写入日程；已存在的实例更新结束时间、标题与来源（ID 不变，会话中的引用仍有效）
+func (r *CalendarEventRepository) Upsert(ctx context.Context, events []*schema.CalendarEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "uid"}, {Name: "start_time"}},
+			DoUpdates: clause.AssignmentColumns([]string{"end_time", "title", "source", "updated_at"}),
+		}).
+		CreateInBatches(events, 100).Error
+	if err != nil {
+		return fmt.Errorf("写入日程失败: %w", err)
+	}
+	return nil
+}
+
+// GetByTimeRange 查询与时间范围有重叠的日程（按开始时间升序）
+func (r *CalendarEventRepository) GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.CalendarEvent, error) {
+	var events []schema.CalendarEvent
+	if err := r.db.WithContext(ctx).
+		Where("start_time <= ? AND end_time > ?", endTime, startTime).
+		Order("start_time ASC").
+		Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("查询日程失败: %w", err)
+	}
+	return events, nil
+}
+
+// GetByIDs 按 ID 列表批量查询日程（保持输入顺序）
+func (r *CalendarEventRepository) GetByIDs(ctx context.Context, ids []int64) ([]schema.CalendarEvent, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var events []schema.CalendarEvent
+	if err := r.db.WithContext(ctx).
+		Where("id IN ?", ids).
+		Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("查询日程失败: %w", err)
+	}
+
+	byID := make(map[int64]schema.CalendarEvent, len(events))
+	for _, e := range events {
+		byID[e.ID] = e
+	}
+	ordered := make([]schema.CalendarEvent, 0, len(events))
+	for _, id := range ids {
+		if e, ok := byID[id]; ok {
+			ordered = append(ordered, e)
+		}
+	}
+	return ordered, nil
+}
+
+// DeleteByIDs 删除日程（重新导入时文件中已不存在的实例）
+func (r *CalendarEventRepository) DeleteByIDs(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&schema.CalendarEvent{}).Error; err != nil {
+		return fmt.Errorf("删除日程失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+	"github.com/yuqie6/WorkMirror/internal/testutil"
+)
+
+func TestCalendarEventRepository_UpsertKeepsIDs(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	repo := NewCalendarEventRepository(db)
+	ctx := context.Background()
+
+	base := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local).UnixMilli()
+	hour := int64(time.Hour / time.Millisecond)
+	err := repo.Upsert(ctx, []*schema.CalendarEvent{
+		{UID: "standup", StartTime: base, EndTime: base + hour/4, Title: "Standup", Source: "a.ics"},
+		{UID: "standup", StartTime: base + 24*hour, EndTime: base + 24*hour + hour/4, Title: "Standup", Source: "a.ics"},
+		{UID: "review", StartTime: base + 2*hour, EndTime: base + 3*hour, Title: "Review", Source: "a.ics"},
+	})
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	first, err := repo.GetByTimeRange(ctx, base, base+4*hour)
+	if err != nil || len(first) != 2 {
+		t.Fatalf("GetByTimeRange: %v, %d rows", err, len(first))
+	}
+
+	// 重新导入：同一实例更新标题，ID 不变
+	if err := repo.Upsert(ctx, []*schema.CalendarEvent{
+		{UID: "review", StartTime: base + 2*hour, EndTime: base + 3*hour + hour/2, Title: "Review (extended)", Source: "b.ics"},
+	}); err != nil {
+		t.Fatalf("Upsert again: %v", err)
+	}
+	got, err := repo.GetByIDs(ctx, []int64{first[1].ID})
+	if err != nil || len(got) != 1 || got[0].Title != "Review (extended)" || got[0].Source != "b.ics" || got[0].EndTime != base+3*hour+hour/2 {
+		t.Fatalf("GetByIDs after upsert: %v %+v", err, got)
+	}
+
+	// 只与范围末尾相接的日程不算重叠
+	overlap, err := repo.GetByTimeRange(ctx, base+hour/4, base+2*hour-1)
+	if err != nil || len(overlap) != 0 {
+		t.Fatalf("touching ranges: %v %+v", err, overlap)
+	}
+
+	if err := repo.DeleteByIDs(ctx, []int64{first[0].ID}); err != nil {
+		t.Fatalf("DeleteByIDs: %v", err)
+	}
+	rest, err := repo.GetByTimeRange(ctx, base, base+48*hour)
+	if err != nil || len(rest) != 2 {
+		t.Fatalf("after delete: %v, %d rows", err, len(rest))
+	}
+}
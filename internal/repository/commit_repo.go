@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CommitRepository Git 提交仓储
+type CommitRepository struct {
+	db *gorm.DB
+}
+
+// NewCommitRepository 创建仓储
+func NewCommitRepository(db *gorm.DB) *CommitRepository {
+	return &CommitRepository{db: db}
+}
+
+// BatchInsert 批量插入提交；(repo_path, hash) 已存在的跳过，返回实际写入条数
+func (r *CommitRepository) BatchInsert(ctx context.Context, commits []*schema.GitCommit) (int64, error) {
+	if len(commits) == 0 {
+		return 0, nil
+	}
+	res := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(commits, 100)
+	if res.Error != nil {
+		return 0, fmt.Errorf("批量插入提交失败: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// GetByDate 按日期查询提交
+func (r *CommitRepository) GetByDate(ctx context.Context, date string) ([]schema.GitCommit, error) {
+	startTime, endTime, err := DayRange(date)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByTimeRange(ctx, startTime, endTime)
+}
+
+// GetByTimeRange 按时间范围查询提交
+func (r *CommitRepository) GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.GitCommit, error) {
+	var commits []schema.GitCommit
+	if err := r.db.WithContext(ctx).
+		Where("timestamp >= ? AND timestamp <= ?", startTime, endTime).
+		Order("timestamp ASC").
+		Find(&commits).Error; err != nil {
+		return nil, fmt.Errorf("查询提交失败: %w", err)
+	}
+	return commits, nil
+}
+
+// GetByIDs 按 ID 列表批量查询提交（保持输入顺序）
+func (r *CommitRepository) GetByIDs(ctx context.Context, ids []int64) ([]schema.GitCommit, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var commits []schema.GitCommit
+	if err := r.db.WithContext(ctx).
+		Where("id IN ?", ids).
+		Find(&commits).Error; err != nil {
+		return nil, fmt.Errorf("查询提交失败: %w", err)
+	}
+
+	byID := make(map[int64]schema.GitCommit, len(commits))
+	for _, c := range commits {
+		byID[c.ID] = c
+	}
+	ordered := make([]schema.GitCommit, 0, len(commits))
+	for _, id := range ids {
+		if c, ok := byID[id]; ok {
+			ordered = append(ordered, c)
+		}
+	}
+	return ordered, nil
+}
+
+// CountByDateRange 统计时间范围内的提交数量
+func (r *CommitRepository) CountByDateRange(ctx context.Context, startTime, endTime int64) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&schema.GitCommit{}).
+		Where("timestamp >= ? AND timestamp <= ?", startTime, endTime).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("统计提交失败: %w", err)
+	}
+	return count, nil
+}
+
+// GetLatestTimestamp 获取某仓库最新提交时间戳（毫秒，无记录返回 0）
+func (r *CommitRepository) GetLatestTimestamp(ctx context.Context, repoPath string) (int64, error) {
+	var ts int64
+	if err := r.db.WithContext(ctx).Model(&schema.GitCommit{}).
+		Select("COALESCE(MAX(timestamp), 0)").
+		Where("repo_path = ?", repoPath).
+		Scan(&ts).Error; err != nil {
+		return 0, fmt.Errorf("查询最新提交时间失败: %w", err)
+	}
+	return ts, nil
+}
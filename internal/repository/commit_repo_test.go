@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+	"github.com/yuqie6/WorkMirror/internal/testutil"
+)
+
+func TestCommitRepository_BatchInsertDedupes(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	repo := NewCommitRepository(db)
+	ctx := context.Background()
+
+	ts := time.Now().UnixMilli()
+	first := []*schema.GitCommit{
+		{RepoPath: "/src/a", Hash: "h1", Subject: "one", Timestamp: ts},
+		{RepoPath: "/src/a", Hash: "h2", Subject: "two", Timestamp: ts + 1000},
+	}
+	n, err := repo.BatchInsert(ctx, first)
+	if err != nil || n != 2 {
+		t.Fatalf("first insert n=%d err=%v, want 2", n, err)
+	}
+
+	// 同一仓库同一哈希跳过；不同仓库的同名哈希（例如 fork）照常写入
+	second := []*schema.GitCommit{
+		{RepoPath: "/src/a", Hash: "h2", Subject: "two again", Timestamp: ts + 1000},
+		{RepoPath: "/src/b", Hash: "h2", Subject: "fork", Timestamp: ts + 2000},
+	}
+	n, err = repo.BatchInsert(ctx, second)
+	if err != nil || n != 1 {
+		t.Fatalf("second insert n=%d err=%v, want 1", n, err)
+	}
+
+	all, err := repo.GetByTimeRange(ctx, ts, ts+5000)
+	if err != nil || len(all) != 3 {
+		t.Fatalf("GetByTimeRange len=%d err=%v, want 3", len(all), err)
+	}
+	byIDs, err := repo.GetByIDs(ctx, []int64{all[2].ID, all[0].ID})
+	if err != nil || len(byIDs) != 2 || byIDs[0].Subject != "fork" || byIDs[1].Subject != "one" {
+		t.Fatalf("GetByIDs=%v err=%v", byIDs, err)
+	}
+}
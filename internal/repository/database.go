@@ -30,7 +30,7 @@ func NewDatabase(dbPath string) (*Database, error) {
 	}
 
 	// 连接数据库
-	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{
+	db, err := gorm.Open(sqlite.Open(sqliteDSN(dbPath)), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
@@ -55,6 +55,37 @@ func NewDatabase(dbPath string) (*Database, error) {
 	return d, nil
 }
 
+// OpenExistingDatabase 打开 Agent 已初始化的数据库，不做迁移（供 native host 等辅助进程写入）；
+// schema 版本与当前程序不一致时拒绝打开，由 Agent 负责升级
+func OpenExistingDatabase(dbPath string) (*Database, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("数据库不存在，请先启动 Agent: %w", err)
+	}
+	db, err := gorm.Open(sqlite.Open(sqliteDSN(dbPath)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
+	}
+	d := &Database{DB: db}
+	var meta schema.SchemaMeta
+	if err := db.First(&meta, 1).Error; err != nil {
+		_ = d.Close()
+		return nil, fmt.Errorf("读取 schema_meta 失败（请先启动 Agent 完成初始化）: %w", err)
+	}
+	if meta.SchemaVersion != latestSchemaVersion {
+		_ = d.Close()
+		return nil, fmt.Errorf("数据库 schema_version=%d 与当前程序版本=%d 不一致，请先启动 Agent 完成升级", meta.SchemaVersion, latestSchemaVersion)
+	}
+	d.SchemaVersion = meta.SchemaVersion
+	return d, nil
+}
+
+// sqliteDSN 每个连接都设置忙等待：Agent 与辅助进程同时写入时等待锁释放，而不是立即返回 SQLITE_BUSY
+func sqliteDSN(dbPath string) string {
+	return dbPath + "?_pragma=busy_timeout(5000)"
+}
+
 // configureDB 配置 SQLite 性能参数
 func configureDB(db *gorm.DB) error {
 	pragmas := []string{
@@ -87,10 +118,25 @@ func autoMigrate(db *gorm.DB) error {
 		&schema.DailySummary{},
 		&schema.PeriodSummary{},
 		&schema.BrowserEvent{},
+		&schema.GitCommit{},
+		&schema.SearchTerm{},
+		&schema.TerminalCommand{},
+		&schema.CalendarEvent{},
 	)
 }
 
-const latestSchemaVersion = 1
+// latestSchemaVersion 当前 schema 版本
+// v2: diffs.cumulative_content（增量 diff 之外保留累计视图）
+// v3: commits 表
+// v4: diffs.backfilled（启动补扫标记）
+// v5: diffs.commit_hash（Git 历史导入）
+// v6: browser_events.browser/profile（多浏览器、多 profile）
+// v7: browser_events.visit_id + 唯一索引 (browser, profile, visit_id)
+// v8: browser_events.from_visit_id/transition（导航链与停留时长）
+// v9: search_terms 表
+// v10: terminal_commands 表
+// v11: calendar_events 表
+const latestSchemaVersion = 11
 
 func migrateWithVersion(db *gorm.DB, out *Database) error {
 	if db == nil {
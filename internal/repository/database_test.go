@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+func TestOpenExistingDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workmirror.db")
+	if _, err := OpenExistingDatabase(path); err == nil {
+		t.Fatal("expected error for missing database")
+	}
+
+	agent, err := NewDatabase(path)
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	defer agent.Close()
+
+	db, err := OpenExistingDatabase(path)
+	if err != nil {
+		t.Fatalf("OpenExistingDatabase: %v", err)
+	}
+	var timeout int
+	if err := db.DB.Raw("PRAGMA busy_timeout").Scan(&timeout).Error; err != nil || timeout != 5000 {
+		t.Fatalf("busy_timeout = %d, err=%v", timeout, err)
+	}
+	_ = db.Close()
+
+	// 版本不一致（Agent 尚未升级）时拒绝打开
+	if err := agent.DB.Model(&schema.SchemaMeta{}).Where("id = ?", 1).Update("schema_version", latestSchemaVersion-1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenExistingDatabase(path); err == nil {
+		t.Fatal("expected error for outdated schema")
+	}
+}
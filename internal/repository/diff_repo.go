@@ -29,6 +29,47 @@ func (r *DiffRepository) Create(ctx context.Context, diff *schema.Diff) error {
 	return nil
 }
 
+// CreateBatch 在一个事务内写入多条 Diff（同一提交的文件要么全部写入，要么都不写）
+func (r *DiffRepository) CreateBatch(ctx context.Context, diffs []*schema.Diff) error {
+	if len(diffs) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(diffs, 100).Error
+	}); err != nil {
+		return fmt.Errorf("批量创建 Diff 记录失败: %w", err)
+	}
+	return nil
+}
+
+// GetImportedCommitHashes 某项目已导入过的提交哈希
+func (r *DiffRepository) GetImportedCommitHashes(ctx context.Context, projectPath string) (map[string]struct{}, error) {
+	var hashes []string
+	if err := r.db.WithContext(ctx).Model(&schema.Diff{}).
+		Where("project_path = ? AND commit_hash <> ''", projectPath).
+		Distinct().
+		Pluck("commit_hash", &hashes).Error; err != nil {
+		return nil, fmt.Errorf("查询已导入提交失败: %w", err)
+	}
+	out := make(map[string]struct{}, len(hashes))
+	for _, h := range hashes {
+		out[h] = struct{}{}
+	}
+	return out, nil
+}
+
+// GetFirstLiveTimestamp 某项目最早一条实时采集 Diff 的时间（毫秒，无记录返回 0）
+func (r *DiffRepository) GetFirstLiveTimestamp(ctx context.Context, projectPath string) (int64, error) {
+	var ts int64
+	if err := r.db.WithContext(ctx).Model(&schema.Diff{}).
+		Select("COALESCE(MIN(timestamp), 0)").
+		Where("project_path = ? AND (commit_hash = '' OR commit_hash IS NULL)", projectPath).
+		Scan(&ts).Error; err != nil {
+		return 0, fmt.Errorf("查询最早 Diff 时间失败: %w", err)
+	}
+	return ts, nil
+}
+
 // GetByDate 按日期查询 Diff
 func (r *DiffRepository) GetByDate(ctx context.Context, date string) ([]schema.Diff, error) {
 	startTime, endTime, err := DayRange(date)
@@ -102,6 +143,22 @@ func (r *DiffRepository) GetByFilePath(ctx context.Context, filePath string, lim
 	return diffs, nil
 }
 
+// GetLatestLiveByFilePath 获取文件最后一条实时采集的 Diff（不含历史导入，无记录返回 nil）
+func (r *DiffRepository) GetLatestLiveByFilePath(ctx context.Context, filePath string) (*schema.Diff, error) {
+	var diffs []schema.Diff
+	if err := r.db.WithContext(ctx).
+		Where("file_path = ? AND commit_hash = ''", filePath).
+		Order("timestamp DESC").
+		Limit(1).
+		Find(&diffs).Error; err != nil {
+		return nil, fmt.Errorf("查询 Diff 失败: %w", err)
+	}
+	if len(diffs) == 0 {
+		return nil, nil
+	}
+	return &diffs[0], nil
+}
+
 // GetByLanguage 按语言查询
 func (r *DiffRepository) GetByLanguage(ctx context.Context, language string, startTime, endTime int64) ([]schema.Diff, error) {
 	var diffs []schema.Diff
@@ -121,6 +121,32 @@ func TestDiffRepository_GetByFilePath(t *testing.T) {
 	}
 }
 
+func TestDiffRepository_GetLatestLiveByFilePath(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	repo := NewDiffRepository(db)
+	ctx := context.Background()
+
+	path := "/src/main.go"
+	if got, err := repo.GetLatestLiveByFilePath(ctx, path); err != nil || got != nil {
+		t.Fatalf("empty: got %+v err=%v", got, err)
+	}
+	for _, d := range []*schema.Diff{
+		{FilePath: path, Timestamp: 1000, CumulativeContent: "old"},
+		{FilePath: path, Timestamp: 2000, CumulativeContent: "live"},
+		{FilePath: path, Timestamp: 3000, CommitHash: "abc123"}, // 历史导入不计入
+		{FilePath: "/src/other.go", Timestamp: 4000, CumulativeContent: "other"},
+	} {
+		if err := repo.Create(ctx, d); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	got, err := repo.GetLatestLiveByFilePath(ctx, path)
+	if err != nil || got == nil || got.CumulativeContent != "live" {
+		t.Fatalf("got %+v err=%v", got, err)
+	}
+}
+
 func TestDiffRepository_CountByDateRange(t *testing.T) {
 	db := testutil.OpenTestDB(t)
 	repo := NewDiffRepository(db)
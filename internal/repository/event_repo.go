@@ -116,13 +116,14 @@ func (r *EventRepository) GetLatestTimestamp(ctx context.Context) (int64, error)
 	return ts, nil
 }
 
-// GetAppStats 获取应用使用统计
+// GetAppStats 获取应用使用统计（仅窗口事件：编辑器心跳与外部写入事件的时长与前台时间重叠）
 func (r *EventRepository) GetAppStats(ctx context.Context, startTime, endTime int64) ([]AppStat, error) {
 	var stats []AppStat
 	err := r.db.WithContext(ctx).
 		Model(&schema.Event{}).
 		Select("app_name, SUM(duration) as total_duration, COUNT(*) as event_count").
 		Where("timestamp >= ? AND timestamp <= ?", startTime, endTime).
+		Where("COALESCE(source, '') IN ?", schema.WindowEventSources).
 		Group("app_name").
 		Order("total_duration DESC").
 		Scan(&stats).Error
@@ -19,6 +19,8 @@ func TestEventRepositoryBatchInsertAndStats(t *testing.T) {
 		{AppName: "code.exe", Duration: 120, Timestamp: now.UnixMilli()},
 		{AppName: "code.exe", Duration: 240, Timestamp: now.UnixMilli()},
 		{AppName: "chrome.exe", Duration: 600, Timestamp: now.UnixMilli()},
+		// 编辑器心跳不计入应用统计
+		{Source: schema.EventSourceEditor, AppName: "vscode", Timestamp: now.UnixMilli()},
 	}
 	if err := repo.BatchInsert(ctx, events); err != nil {
 		t.Fatalf("BatchInsert error: %v", err)
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SearchTermRepository 搜索词仓储
+type SearchTermRepository struct {
+	db *gorm.DB
+}
+
+// NewSearchTermRepository 创建仓储
+func NewSearchTermRepository(db *gorm.DB) *SearchTermRepository {
+	return &SearchTermRepository{db: db}
+}
+
+// BatchInsert 批量插入；同一次访问（browser, profile, visit_id）已存在的跳过
+func (r *SearchTermRepository) BatchInsert(ctx context.Context, terms []*schema.SearchTerm) error {
+	if len(terms) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(terms, 100).Error; err != nil {
+		return fmt.Errorf("批量插入搜索词失败: %w", err)
+	}
+	return nil
+}
+
+// GetByTimeRange 按时间范围查询搜索词
+func (r *SearchTermRepository) GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.SearchTerm, error) {
+	var terms []schema.SearchTerm
+	if err := r.db.WithContext(ctx).
+		Where("timestamp >= ? AND timestamp <= ?", startTime, endTime).
+		Order("timestamp ASC").
+		Find(&terms).Error; err != nil {
+		return nil, fmt.Errorf("查询搜索词失败: %w", err)
+	}
+	return terms, nil
+}
+
+// GetByIDs 按 ID 列表批量查询搜索词（保持输入顺序）
+func (r *SearchTermRepository) GetByIDs(ctx context.Context, ids []int64) ([]schema.SearchTerm, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var terms []schema.SearchTerm
+	if err := r.db.WithContext(ctx).
+		Where("id IN ?", ids).
+		Find(&terms).Error; err != nil {
+		return nil, fmt.Errorf("查询搜索词失败: %w", err)
+	}
+
+	byID := make(map[int64]schema.SearchTerm, len(terms))
+	for _, t := range terms {
+		byID[t.ID] = t
+	}
+	ordered := make([]schema.SearchTerm, 0, len(terms))
+	for _, id := range ids {
+		if t, ok := byID[id]; ok {
+			ordered = append(ordered, t)
+		}
+	}
+	return ordered, nil
+}
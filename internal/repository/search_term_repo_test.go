@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+	"github.com/yuqie6/WorkMirror/internal/testutil"
+)
+
+func TestSearchTermRepository_BatchInsertDedupesVisits(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	repo := NewSearchTermRepository(db)
+	ctx := context.Background()
+
+	ts := time.Now().UnixMilli()
+	if err := repo.BatchInsert(ctx, []*schema.SearchTerm{
+		{Timestamp: ts, Term: "gorm upsert", Engine: "google", Browser: "chrome", Profile: "Default", VisitID: 1},
+		{Timestamp: ts + 1, Term: "sqlite wal", Engine: "bing", Browser: "chrome", Profile: "Default", VisitID: 2},
+	}); err != nil {
+		t.Fatalf("first insert: %v", err)
+	}
+	// 重读同一段历史；其他 profile 的相同 visit_id 照常写入
+	if err := repo.BatchInsert(ctx, []*schema.SearchTerm{
+		{Timestamp: ts + 1, Term: "sqlite wal", Engine: "bing", Browser: "chrome", Profile: "Default", VisitID: 2},
+		{Timestamp: ts + 2, Term: "go generics", Engine: "google", Browser: "chrome", Profile: "Profile 1", VisitID: 2},
+	}); err != nil {
+		t.Fatalf("second insert: %v", err)
+	}
+
+	all, err := repo.GetByTimeRange(ctx, ts, ts+10)
+	if err != nil || len(all) != 3 {
+		t.Fatalf("GetByTimeRange: %v, %d rows", err, len(all))
+	}
+	if all[0].Term != "gorm upsert" || all[2].Term != "go generics" {
+		t.Fatalf("unexpected order: %+v", all)
+	}
+
+	got, err := repo.GetByIDs(ctx, []int64{all[2].ID, 999, all[0].ID})
+	if err != nil || len(got) != 2 || got[0].ID != all[2].ID || got[1].ID != all[0].ID {
+		t.Fatalf("GetByIDs should keep input order: %v %+v", err, got)
+	}
+}
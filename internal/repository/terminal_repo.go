@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TerminalCommandRepository 终端命令仓储
+type TerminalCommandRepository struct {
+	db *gorm.DB
+}
+
+// NewTerminalCommandRepository 创建仓储
+func NewTerminalCommandRepository(db *gorm.DB) *TerminalCommandRepository {
+	return &TerminalCommandRepository{db: db}
+}
+
+// BatchInsert 批量插入；(shell, timestamp, command) 已存在的跳过（重读同一段历史），返回实际写入条数
+func (r *TerminalCommandRepository) BatchInsert(ctx context.Context, cmds []*schema.TerminalCommand) (int64, error) {
+	if len(cmds) == 0 {
+		return 0, nil
+	}
+	res := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(cmds, 100)
+	if res.Error != nil {
+		return 0, fmt.Errorf("批量插入终端命令失败: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// GetByTimeRange 按时间范围查询终端命令
+func (r *TerminalCommandRepository) GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.TerminalCommand, error) {
+	var cmds []schema.TerminalCommand
+	if err := r.db.WithContext(ctx).
+		Where("timestamp >= ? AND timestamp <= ?", startTime, endTime).
+		Order("timestamp ASC").
+		Find(&cmds).Error; err != nil {
+		return nil, fmt.Errorf("查询终端命令失败: %w", err)
+	}
+	return cmds, nil
+}
+
+// GetByIDs 按 ID 列表批量查询终端命令（保持输入顺序）
+func (r *TerminalCommandRepository) GetByIDs(ctx context.Context, ids []int64) ([]schema.TerminalCommand, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var cmds []schema.TerminalCommand
+	if err := r.db.WithContext(ctx).
+		Where("id IN ?", ids).
+		Find(&cmds).Error; err != nil {
+		return nil, fmt.Errorf("查询终端命令失败: %w", err)
+	}
+
+	byID := make(map[int64]schema.TerminalCommand, len(cmds))
+	for _, c := range cmds {
+		byID[c.ID] = c
+	}
+	ordered := make([]schema.TerminalCommand, 0, len(cmds))
+	for _, id := range ids {
+		if c, ok := byID[id]; ok {
+			ordered = append(ordered, c)
+		}
+	}
+	return ordered, nil
+}
+
+// CountByDateRange 统计时间范围内的终端命令数量
+func (r *TerminalCommandRepository) CountByDateRange(ctx context.Context, startTime, endTime int64) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&schema.TerminalCommand{}).
+		Where("timestamp >= ? AND timestamp <= ?", startTime, endTime).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("统计终端命令失败: %w", err)
+	}
+	return count, nil
+}
+
+// GetLatestTimestamps 各 shell 已入库的最新带时间命令（shell -> 毫秒），作为采集器重启后的起点
+func (r *TerminalCommandRepository) GetLatestTimestamps(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		Shell     string
+		Timestamp int64
+	}
+	if err := r.db.WithContext(ctx).Model(&schema.TerminalCommand{}).
+		Select("shell, MAX(timestamp) AS timestamp").
+		Where("timed = ?", true).
+		Group("shell").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询终端采集游标失败: %w", err)
+	}
+	out := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		out[row.Shell] = row.Timestamp
+	}
+	return out, nil
+}
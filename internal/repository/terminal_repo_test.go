@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+	"github.com/yuqie6/WorkMirror/internal/testutil"
+)
+
+func TestTerminalCommandRepository_BatchInsertDedupes(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	repo := NewTerminalCommandRepository(db)
+	ctx := context.Background()
+
+	ts := time.Now().UnixMilli()
+	n, err := repo.BatchInsert(ctx, []*schema.TerminalCommand{
+		{Shell: "zsh", Timestamp: ts, Command: "go test ./...", Timed: true},
+		{Shell: "zsh", Timestamp: ts + 1000, Command: "git push", Timed: true},
+		{Shell: "powershell", Timestamp: ts + 5000, Command: "kubectl get pods"},
+	})
+	if err != nil || n != 3 {
+		t.Fatalf("first insert: %v, %d rows", err, n)
+	}
+	// 重启后重读 zsh 历史：已入库的命令跳过
+	n, err = repo.BatchInsert(ctx, []*schema.TerminalCommand{
+		{Shell: "zsh", Timestamp: ts + 1000, Command: "git push", Timed: true},
+		{Shell: "zsh", Timestamp: ts + 2000, Command: "git push", Timed: true},
+	})
+	if err != nil || n != 1 {
+		t.Fatalf("second insert: %v, %d rows", err, n)
+	}
+
+	all, err := repo.GetByTimeRange(ctx, ts, ts+10_000)
+	if err != nil || len(all) != 4 {
+		t.Fatalf("GetByTimeRange: %v, %d rows", err, len(all))
+	}
+	got, err := repo.GetByIDs(ctx, []int64{all[3].ID, all[0].ID})
+	if err != nil || len(got) != 2 || got[0].Command != "kubectl get pods" {
+		t.Fatalf("GetByIDs should keep input order: %v %+v", err, got)
+	}
+
+	// 不带时间的历史（PowerShell）不参与游标
+	latest, err := repo.GetLatestTimestamps(ctx)
+	if err != nil || len(latest) != 1 || latest["zsh"] != ts+2000 {
+		t.Fatalf("GetLatestTimestamps: %v %+v", err, latest)
+	}
+}
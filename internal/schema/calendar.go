@@ -0,0 +1,23 @@
+package schema
+
+import "time"
+
+// SessionCategoryMeeting 与日程重叠、由会议时段切出的会话分类
+const SessionCategoryMeeting = "meeting"
+
+// CalendarEvent 从 .ics 文件导入的日程（重复事件按实例展开；标题入库前已脱敏）
+type CalendarEvent struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	UID       string    `gorm:"column:uid;size:255;uniqueIndex:idx_calendar_event,priority:1"`
+	StartTime int64     `gorm:"index;uniqueIndex:idx_calendar_event,priority:2"` // 毫秒；重复事件中各次实例的开始时间
+	EndTime   int64     `gorm:"index"`                                           // 毫秒
+	Title     string    `gorm:"size:500"`
+	Source    string    `gorm:"size:500;index"` // 导入的文件路径；重新导入时据此清理文件中已删除的日程
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (CalendarEvent) TableName() string {
+	return "calendar_events"
+}
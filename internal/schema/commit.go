@@ -0,0 +1,26 @@
+package schema
+
+import "time"
+
+// GitCommit 本地仓库的提交记录（会话证据：提交信息是最直接的“做了什么”）
+type GitCommit struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement"`
+	RepoPath     string    `gorm:"size:500;uniqueIndex:idx_commit_repo_hash"` // 仓库根目录
+	Hash         string    `gorm:"size:64;uniqueIndex:idx_commit_repo_hash"`  // 提交哈希
+	Branch       string    `gorm:"size:255"`                                  // 发现该提交时所在的分支
+	Subject      string    `gorm:"size:1000"`                                 // 提交标题
+	Body         string    `gorm:"type:text"`                                 // 提交正文
+	AuthorName   string    `gorm:"size:255"`
+	AuthorEmail  string    `gorm:"size:255;index"`
+	Timestamp    int64     `gorm:"index"`     // 作者时间（毫秒）
+	Files        JSONArray `gorm:"type:text"` // 变更文件（相对仓库根目录）
+	FilesChanged int       `gorm:"default:0"`
+	LinesAdded   int       `gorm:"default:0"`
+	LinesDeleted int       `gorm:"default:0"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (GitCommit) TableName() string {
+	return "commits"
+}
@@ -7,19 +7,22 @@ import (
 // Diff 代码变更记录
 // 从文件变更中推断学习内容的核心数据源
 type Diff struct {
-	ID             int64     `gorm:"primaryKey;autoIncrement"`
-	Timestamp      int64     `gorm:"index"`           // 变更时间戳
-	FilePath       string    `gorm:"size:500;index"`  // 文件路径
-	FileName       string    `gorm:"size:255"`        // 文件名
-	Language       string    `gorm:"size:50;index"`   // 编程语言
-	DiffContent    string    `gorm:"type:text"`       // Diff 内容
-	LinesAdded     int       `gorm:"default:0"`       // 添加行数
-	LinesDeleted   int       `gorm:"default:0"`       // 删除行数
-	AIInsight      string    `gorm:"type:text"`       // AI 解读
-	SkillsDetected JSONArray `gorm:"type:text"`       // 检测到的技能
-	ProjectPath    string    `gorm:"size:500;index"`  // 项目根目录
-	IsGitRepo      bool      `gorm:"default:false"`   // 是否是 Git 仓库
-	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	ID                int64     `gorm:"primaryKey;autoIncrement"`
+	Timestamp         int64     `gorm:"index"`          // 变更时间戳
+	FilePath          string    `gorm:"size:500;index"` // 文件路径
+	FileName          string    `gorm:"size:255"`       // 文件名
+	Language          string    `gorm:"size:50;index"`  // 编程语言
+	DiffContent       string    `gorm:"type:text"`      // Diff 内容（相对上次采集的增量）
+	CumulativeContent string    `gorm:"type:text"`      // 相对 HEAD/index 的累计 diff（会话证据）
+	LinesAdded        int       `gorm:"default:0"`      // 添加行数
+	LinesDeleted      int       `gorm:"default:0"`      // 删除行数
+	AIInsight         string    `gorm:"type:text"`      // AI 解读
+	SkillsDetected    JSONArray `gorm:"type:text"`      // 检测到的技能
+	ProjectPath       string    `gorm:"size:500;index"` // 项目根目录
+	IsGitRepo         bool      `gorm:"default:false"`  // 是否是 Git 仓库
+	Backfilled        bool      `gorm:"default:false"`  // 启动补扫得到（采集器离线期间的修改，时间戳取文件 mtime）
+	CommitHash        string    `gorm:"size:64;index"`  // 历史导入：来源提交（为空表示实时采集）
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
 }
 
 // TableName 指定表名
@@ -48,15 +51,34 @@ func (DailySummary) TableName() string {
 
 // BrowserEvent 浏览器事件 (Phase 2.2)
 type BrowserEvent struct {
-	ID        int64     `gorm:"primaryKey;autoIncrement"`
-	Timestamp int64     `gorm:"index"`
-	URL       string    `gorm:"size:2000"`
-	Title     string    `gorm:"size:500"`
-	Domain    string    `gorm:"size:255;index"`
-	Duration  int       `gorm:"default:0"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
+	ID          int64     `gorm:"primaryKey;autoIncrement"`
+	Timestamp   int64     `gorm:"index"`
+	URL         string    `gorm:"size:2000"`
+	Title       string    `gorm:"size:500"`
+	Domain      string    `gorm:"size:255;index"`
+	Duration    int       `gorm:"default:0"`                                                                 // 停留时长（秒）：浏览器记录的 visit_duration，缺失时按前台窗口推断
+	Browser     string    `gorm:"size:32;index;uniqueIndex:idx_browser_visit,priority:1,where:visit_id > 0"` // chrome/edge/firefox...（为空表示旧版本采集）
+	Profile     string    `gorm:"size:128;uniqueIndex:idx_browser_visit,priority:2"`                         // profile 目录名
+	VisitID     int64     `gorm:"default:0;uniqueIndex:idx_browser_visit,priority:3"`                        // 浏览器历史库中的访问 ID（0 表示旧版本采集）
+	FromVisitID int64     `gorm:"default:0"`                                                                 // 来源访问 ID（同一 profile 内），串起导航链
+	Transition  string    `gorm:"size:32"`                                                                   // 进入方式：link/typed/redirect...（BrowserTransition*）
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
 }
 
+// 浏览器访问的进入方式（Chrome transition / Firefox visit_type 归一化）
+const (
+	BrowserTransitionLink      = "link"
+	BrowserTransitionTyped     = "typed"
+	BrowserTransitionBookmark  = "bookmark"
+	BrowserTransitionSubframe  = "subframe"
+	BrowserTransitionGenerated = "generated" // 地址栏建议、关键字搜索
+	BrowserTransitionForm      = "form_submit"
+	BrowserTransitionReload    = "reload"
+	BrowserTransitionRedirect  = "redirect" // 重定向途经的中间页，停留时长恒为 0
+	BrowserTransitionOther     = "other"
+	BrowserTransitionFocus     = "focus" // 扩展上报的前台标签页停留（native messaging），时长为实际前台时间
+)
+
 // TableName 指定表名
 func (BrowserEvent) TableName() string {
 	return "browser_events"
@@ -0,0 +1,16 @@
+package schema
+
+// 编辑器心跳事件的 Metadata 键
+const (
+	EditorMetaEntity   = "entity" // 插件上报的完整路径（type=file）或域名/应用名
+	EditorMetaFile     = "file"   // 相对项目的路径
+	EditorMetaProject  = "project"
+	EditorMetaBranch   = "branch"
+	EditorMetaLanguage = "language"
+	EditorMetaCategory = "category" // coding | debugging | building | code reviewing | ...
+	EditorMetaType     = "type"     // file | app | domain
+	EditorMetaIsWrite  = "is_write"
+	EditorMetaLineNo   = "lineno"
+	EditorMetaLines    = "lines"
+	EditorMetaPlugin   = "plugin" // 插件 user agent
+)
@@ -12,7 +12,7 @@ import (
 type Event struct {
 	ID        int64     `gorm:"primaryKey;autoIncrement"`
 	Timestamp int64     `gorm:"index"`         // Unix 时间戳 (毫秒)
-	Source    string    `gorm:"size:50"`      // 来源: window, chrome, vscode
+	Source    string    `gorm:"size:50"`      // 来源: window, editor, 或 /api/ingest 写入的自定义来源
 	AppName   string    `gorm:"size:255;index"` // 应用名: Chrome.exe
 	Title     string    `gorm:"type:text"`    // 窗口标题 (已脱敏)
 	Duration  int       `gorm:"default:0"`    // 持续时长 (秒)
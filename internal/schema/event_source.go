@@ -0,0 +1,15 @@
+package schema
+
+// 事件来源（Event.Source）
+const (
+	EventSourceWindow        = "window"        // 前台窗口采集
+	EventSourceEditor        = "editor"        // 编辑器插件心跳（WakaTime 协议）
+	EventSourceActivityWatch = "activitywatch" // ActivityWatch 导出的窗口事件，按前台窗口统计
+	EventSourceWakaTime      = "wakatime"      // WakaTime 数据导出中的心跳，按编辑器心跳统计
+)
+
+// WindowEventSources 时长代表前台占用的来源（"" 为旧版本数据）
+var WindowEventSources = []string{"", EventSourceWindow, EventSourceActivityWatch}
+
+// BrowserProfileActivityWatch 从 ActivityWatch 导入的浏览记录的 profile 标记
+const BrowserProfileActivityWatch = "activitywatch"
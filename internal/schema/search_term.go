@@ -0,0 +1,21 @@
+package schema
+
+import "time"
+
+// SearchTerm 从浏览记录中提取的搜索词（可选功能，默认关闭；入库前已脱敏）
+type SearchTerm struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	Timestamp int64     `gorm:"index"`         // 搜索时间（毫秒）
+	Term      string    `gorm:"size:500"`      // 搜索词
+	Engine    string    `gorm:"size:32;index"` // google/bing/duckduckgo/baidu/github/stackoverflow；keyword 表示浏览器自定义搜索引擎
+	Domain    string    `gorm:"size:255"`      // 搜索结果页域名
+	Browser   string    `gorm:"size:32;uniqueIndex:idx_search_visit,priority:1"`
+	Profile   string    `gorm:"size:128;uniqueIndex:idx_search_visit,priority:2"`
+	VisitID   int64     `gorm:"uniqueIndex:idx_search_visit,priority:3"` // 对应的浏览器访问 ID
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (SearchTerm) TableName() string {
+	return "search_terms"
+}
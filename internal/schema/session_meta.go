@@ -1,15 +1,110 @@
 package schema
 
+import "encoding/json"
+
 // Session 元数据字段（存储在 Session.Metadata JSONMap 中）。
 //
 // 这些 key 会在 handler/service/observability 等多处使用；集中定义避免字符串漂移。
 const (
 	SessionMetaDiffIDs         = "diff_ids"
 	SessionMetaBrowserEventIDs = "browser_event_ids"
+	SessionMetaCommitIDs       = "commit_ids"
+	SessionMetaSearchTermIDs   = "search_term_ids"
+	SessionMetaTerminalCmdIDs  = "terminal_command_ids"
+	SessionMetaCalendarIDs     = "calendar_event_ids"
 	SessionMetaSkillKeys       = "skill_keys"
+	SessionMetaManualTesting   = "manual_testing" // []SessionManualTesting
+	SessionMetaEditorFiles     = "editor_files"   // []SessionEditorFile
 
 	SessionMetaSemanticSource  = "semantic_source"  // ai | rule
 	SessionMetaSemanticVersion = "semantic_version" // e.g. "v1"
 	SessionMetaEvidenceHint    = "evidence_hint"    // diff+browser | diff | browser | window_only
 	SessionMetaDegradedReason  = "degraded_reason"  // not_configured | provider_error | rate_limited | ...
 )
+
+// SessionManualTesting 会话中在本地开发服务上手动测试某个项目（localhost 浏览关联到项目）
+type SessionManualTesting struct {
+	Project  string   `json:"project"` // 项目根目录；只能从窗口标题/编辑器心跳推断时为项目名
+	Name     string   `json:"name"`
+	Source   string   `json:"source"` // port_hint | diff | editor | window_title
+	Hosts    []string `json:"hosts"`
+	Visits   int      `json:"visits"`
+	Seconds  int      `json:"seconds"`
+	EventIDs []int64  `json:"event_ids"`
+}
+
+// GetSessionManualTesting 读取手动测试证据（兼容从数据库反序列化出的 []interface{}）
+func GetSessionManualTesting(meta JSONMap) []SessionManualTesting {
+	raw, ok := meta[SessionMetaManualTesting]
+	if !ok || raw == nil {
+		return nil
+	}
+	if v, ok := raw.([]SessionManualTesting); ok {
+		return append([]SessionManualTesting(nil), v...)
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var out []SessionManualTesting
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// SetSessionManualTesting 写入手动测试证据；为空时删除
+func SetSessionManualTesting(meta JSONMap, items []SessionManualTesting) {
+	if meta == nil {
+		return
+	}
+	if len(items) == 0 {
+		delete(meta, SessionMetaManualTesting)
+		return
+	}
+	meta[SessionMetaManualTesting] = items
+}
+
+// SessionEditorFile 会话内编辑器插件心跳按文件聚合的编码证据
+type SessionEditorFile struct {
+	Project    string `json:"project"`
+	File       string `json:"file"`
+	Language   string `json:"language,omitempty"`
+	Branch     string `json:"branch,omitempty"`
+	Editor     string `json:"editor,omitempty"`
+	Seconds    int    `json:"seconds"`
+	Heartbeats int    `json:"heartbeats"`
+	Writes     int    `json:"writes"` // 保存次数
+}
+
+// GetSessionEditorFiles 读取编辑器文件证据（兼容从数据库反序列化出的 []interface{}）
+func GetSessionEditorFiles(meta JSONMap) []SessionEditorFile {
+	raw, ok := meta[SessionMetaEditorFiles]
+	if !ok || raw == nil {
+		return nil
+	}
+	if v, ok := raw.([]SessionEditorFile); ok {
+		return append([]SessionEditorFile(nil), v...)
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var out []SessionEditorFile
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// SetSessionEditorFiles 写入编辑器文件证据；为空时删除
+func SetSessionEditorFiles(meta JSONMap, items []SessionEditorFile) {
+	if meta == nil {
+		return
+	}
+	if len(items) == 0 {
+		delete(meta, SessionMetaEditorFiles)
+		return
+	}
+	meta[SessionMetaEditorFiles] = items
+}
@@ -0,0 +1,19 @@
+package schema
+
+import "time"
+
+// TerminalCommand 从 shell 历史文件采集的命令（可选功能，默认关闭；入库前已脱敏）
+type TerminalCommand struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	Shell     string    `gorm:"size:16;uniqueIndex:idx_terminal_command,priority:1"` // bash/zsh/fish/powershell
+	Timestamp int64     `gorm:"index;uniqueIndex:idx_terminal_command,priority:2"`   // 执行时间（毫秒）；历史文件不带时间时为读到该行的时间
+	Command   string    `gorm:"size:2000;uniqueIndex:idx_terminal_command,priority:3"`
+	Duration  int       `gorm:"default:0"`     // 执行耗时（秒），仅 zsh EXTENDED_HISTORY 提供
+	Timed     bool      `gorm:"default:false"` // 时间来自历史文件本身（而非读取时间）
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (TerminalCommand) TableName() string {
+	return "terminal_commands"
+}
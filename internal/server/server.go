@@ -1,5 +1,3 @@
-//go:build windows
-
 package server
 
 import (
@@ -22,6 +20,7 @@ import (
 	"github.com/yuqie6/WorkMirror/internal/bootstrap"
 	"github.com/yuqie6/WorkMirror/internal/eventbus"
 	"github.com/yuqie6/WorkMirror/internal/handler"
+	"github.com/yuqie6/WorkMirror/internal/pkg/lockfile"
 	"github.com/yuqie6/WorkMirror/internal/uiassets"
 )
 
@@ -65,10 +64,8 @@ func Start(ctx context.Context, rt *bootstrap.AgentRuntime, opts Options) (*Loca
 		hub = eventbus.NewHub()
 	}
 
-	api := handler.NewAPI(rt, hub)
-
 	mux := http.NewServeMux()
-	registerRoutes(mux, api)
+	registerRoutes(mux, handler.NewAPI(rt, hub), bootstrap.WriteLockPath(rt.Core.Cfg))
 
 	uiFS, uiSource := pickUIFS()
 	mux.Handle("/", spaHandler(uiFS, "index.html"))
@@ -103,8 +100,16 @@ func Start(ctx context.Context, rt *bootstrap.AgentRuntime, opts Options) (*Loca
 	return ls, nil
 }
 
-// registerRoutes 注册所有 API 路由
-func registerRoutes(mux *http.ServeMux, api *handler.API) {
+// NewAPIHandler 返回仅包含 /api 路由的处理器（不含 UI 静态资源），供 CLI 等进程内调用。
+// 写接口不再加锁：CLI 的写命令已在调用前持有写锁。
+func NewAPIHandler(rt *bootstrap.AgentRuntime, hub *eventbus.Hub) http.Handler {
+	mux := http.NewServeMux()
+	registerRoutes(mux, handler.NewAPI(rt, hub), "")
+	return mux
+}
+
+// registerRoutes 注册所有 API 路由；writeLockPath 非空时会话重建与导入接口需先拿到写锁
+func registerRoutes(mux *http.ServeMux, api *handler.API, writeLockPath string) {
 	mux.HandleFunc("/api/health", requireMethod(http.MethodGet, api.HandleHealth))
 	mux.HandleFunc("/api/events", api.HandleSSE)
 	mux.HandleFunc("/api/status", requireMethod(http.MethodGet, api.HandleStatus))
@@ -127,13 +132,26 @@ func registerRoutes(mux *http.ServeMux, api *handler.API) {
 	mux.HandleFunc("/api/sessions/by-date", requireMethod(http.MethodGet, api.HandleSessionsByDate))
 	mux.HandleFunc("/api/sessions/detail", requireMethod(http.MethodGet, api.HandleSessionDetail))
 	mux.HandleFunc("/api/sessions/events", requireMethod(http.MethodGet, api.HandleSessionEvents))
-	mux.HandleFunc("/api/sessions/build", requireMethod(http.MethodPost, api.HandleBuildSessionsForDate))
-	mux.HandleFunc("/api/sessions/rebuild", requireMethod(http.MethodPost, api.HandleRebuildSessionsForDate))
-	mux.HandleFunc("/api/sessions/enrich", requireMethod(http.MethodPost, api.HandleEnrichSessionsForDate))
+	mux.HandleFunc("/api/sessions/build", requireMethod(http.MethodPost, withWriteLock(writeLockPath, api.HandleBuildSessionsForDate)))
+	mux.HandleFunc("/api/sessions/rebuild", requireMethod(http.MethodPost, withWriteLock(writeLockPath, api.HandleRebuildSessionsForDate)))
+	mux.HandleFunc("/api/sessions/enrich", requireMethod(http.MethodPost, withWriteLock(writeLockPath, api.HandleEnrichSessionsForDate)))
+
+	mux.HandleFunc("/api/import/git", requireMethod(http.MethodPost, withWriteLock(writeLockPath, api.HandleGitImport)))
+	mux.HandleFunc("/api/import/git/status", requireMethod(http.MethodGet, api.HandleGitImportStatus))
+	mux.HandleFunc("/api/import/activitywatch", requireMethod(http.MethodPost, withWriteLock(writeLockPath, api.HandleActivityWatchImport)))
+	mux.HandleFunc("/api/import/wakatime", requireMethod(http.MethodPost, withWriteLock(writeLockPath, api.HandleWakaTimeImport)))
+	mux.HandleFunc("/api/import/calendar", requireMethod(http.MethodPost, withWriteLock(writeLockPath, api.HandleCalendarImport)))
+
+	mux.HandleFunc("/api/ingest", requireMethod(http.MethodPost, api.HandleIngest))
 
 	mux.HandleFunc("/api/diagnostics/export", requireMethod(http.MethodGet, api.HandleDiagnosticsExport))
 
 	mux.HandleFunc("/api/settings", api.HandleSettings)
+
+	// WakaTime 兼容接口（编辑器插件 api_url = <base>/api/v1）
+	mux.HandleFunc("/api/v1/users/current/heartbeats", requireMethod(http.MethodPost, api.HandleWakaTimeHeartbeat))
+	mux.HandleFunc("/api/v1/users/current/heartbeats.bulk", requireMethod(http.MethodPost, api.HandleWakaTimeHeartbeatsBulk))
+	mux.HandleFunc("/api/v1/users/current/statusbar/today", requireMethod(http.MethodGet, api.HandleWakaTimeStatusBar))
 }
 
 // requireMethod 创建要求特定 HTTP 方法的中间件
@@ -147,6 +165,28 @@ func requireMethod(method string, fn http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// withWriteLock 与 Agent 后台任务、CLI 写命令共用写锁；锁被占用时直接返回 409，不在请求中排队
+func withWriteLock(lockPath string, fn http.HandlerFunc) http.HandlerFunc {
+	if lockPath == "" {
+		return fn
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		l, err := lockfile.TryLock(lockPath)
+		if errors.Is(err, lockfile.ErrLocked) {
+			handler.WriteError(w, http.StatusConflict, "后台任务正在写入，请稍后重试")
+			return
+		}
+		if err != nil {
+			handler.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		// 后台执行的导入会接管写锁，在任务结束时释放
+		handoff := handler.NewWriteLockHandoff(func() { _ = l.Unlock() })
+		defer handoff.Done()
+		fn(w, r.WithContext(handler.WithWriteLockHandoff(r.Context(), handoff)))
+	}
+}
+
 // BaseURL 返回服务器的基础 URL
 func (s *LocalServer) BaseURL() string {
 	if s == nil {
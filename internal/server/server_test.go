@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/bootstrap"
+	"github.com/yuqie6/WorkMirror/internal/pkg/config"
+	"github.com/yuqie6/WorkMirror/internal/pkg/lockfile"
+)
+
+// newTestRuntime 使用临时目录中的真实 SQLite 构建 AgentRuntime（关闭 diff/浏览器/AI）
+func newTestRuntime(t *testing.T, ctx context.Context) *bootstrap.AgentRuntime {
+	t.Helper()
+	dir := t.TempDir()
+
+	cfg := config.Default()
+	cfg.Storage.DBPath = filepath.Join(dir, "data", "workmirror.db")
+	cfg.App.LogPath = filepath.Join(dir, "logs", "workmirror.log")
+	cfg.Diff.Enabled = false
+	cfg.Browser.Enabled = false
+	cfg.AI.Default.Enabled = false
+	cfgPath := filepath.Join(dir, "config", "config.yaml")
+	if err := config.WriteFile(cfgPath, cfg); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	rt, err := bootstrap.NewAgentRuntime(ctx, cfgPath)
+	if err != nil {
+		t.Fatalf("NewAgentRuntime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+	return rt
+}
+
+func TestStart_ServesAPIWithSQLite(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rt := newTestRuntime(t, ctx)
+	srv, err := Start(ctx, rt, Options{ListenAddr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	today := time.Now().Format("2006-01-02")
+	for _, path := range []string{
+		"/api/health",
+		"/api/status",
+		"/api/settings",
+		"/api/sessions/by-date?date=" + today,
+		"/api/skills/tree",
+		"/api/trends?days=7",
+	} {
+		resp, err := http.Get(srv.BaseURL() + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		var body any
+		decodeErr := json.NewDecoder(resp.Body).Decode(&body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: status=%d body=%v", path, resp.StatusCode, body)
+		}
+		if decodeErr != nil {
+			t.Fatalf("GET %s: invalid json: %v", path, decodeErr)
+		}
+	}
+
+	resp, err := http.Post(srv.BaseURL()+"/api/status", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST /api/status: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("POST /api/status: status=%d, want 405", resp.StatusCode)
+	}
+}
+
+func TestStart_WriteEndpointsTakeWriteLock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rt := newTestRuntime(t, ctx)
+	srv, err := Start(ctx, rt, Options{ListenAddr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	rebuild := func() int {
+		t.Helper()
+		body := strings.NewReader(`{"date":"` + time.Now().Format("2006-01-02") + `"}`)
+		resp, err := http.Post(srv.BaseURL()+"/api/sessions/rebuild", "application/json", body)
+		if err != nil {
+			t.Fatalf("POST /api/sessions/rebuild: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// 模拟 CLI 写命令或后台任务持有写锁（启动时的后台任务可能短暂占用）
+	var lock *lockfile.Lock
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+		if lock, err = lockfile.TryLock(bootstrap.WriteLockPath(rt.Core.Cfg)); err == nil {
+			break
+		}
+		if !errors.Is(err, lockfile.ErrLocked) || time.Now().After(deadline) {
+			t.Fatalf("TryLock: %v", err)
+		}
+	}
+	if status := rebuild(); status != http.StatusConflict {
+		t.Fatalf("rebuild while locked: status=%d, want 409", status)
+	}
+	for _, path := range []string{"/api/import/git", "/api/import/activitywatch", "/api/import/wakatime", "/api/import/calendar"} {
+		resp, err := http.Post(srv.BaseURL()+path, "application/json", strings.NewReader(`{}`))
+		if err != nil {
+			t.Fatalf("POST %s: %v", path, err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusConflict {
+			t.Fatalf("POST %s while locked: status=%d, want 409", path, resp.StatusCode)
+		}
+	}
+	_ = lock.Unlock()
+	if status := rebuild(); status != http.StatusOK {
+		t.Fatalf("rebuild after unlock: status=%d, want 200", status)
+	}
+}
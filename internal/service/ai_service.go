@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
@@ -22,7 +23,9 @@ type AIService struct {
 	eventRepo    EventRepository
 	summaryRepo  SummaryRepository
 	skillService *SkillService
-	ragService   RAGQuerier // 可选，用于查询历史记忆/索引
+	ragService   RAGQuerier              // 可选，用于查询历史记忆/索引
+	commitRepo   CommitRepository        // 可选，日报引用当日提交
+	calendarRepo CalendarEventRepository // 可选，日报列出当日会议
 
 	lastCallAt     atomic.Int64
 	lastErrorAt    atomic.Int64
@@ -57,6 +60,16 @@ func (s *AIService) SetRAGService(ragService RAGQuerier) {
 	s.ragService = ragService
 }
 
+// SetCommitRepository 设置提交仓储（可选）
+func (s *AIService) SetCommitRepository(repo CommitRepository) {
+	s.commitRepo = repo
+}
+
+// SetCalendarRepository 设置日程仓储（可选）
+func (s *AIService) SetCalendarRepository(repo CalendarEventRepository) {
+	s.calendarRepo = repo
+}
+
 // AnalyzePendingDiffs 分析待处理的 Diff（使用 Worker Pool）
 func (s *AIService) AnalyzePendingDiffs(ctx context.Context, limit int) (int, error) {
 	s.lastCallAt.Store(time.Now().UnixMilli())
@@ -274,6 +287,37 @@ func (s *AIService) GenerateDailySummaryWithOptions(ctx context.Context, date st
 		})
 	}
 
+	// 添加提交信息（可选证据，查询失败不影响日报）
+	if s.commitRepo != nil {
+		commits, err := s.commitRepo.GetByDate(ctx, date)
+		if err != nil {
+			slog.Warn("查询当日提交失败", "date", date, "error", err)
+		}
+		for _, c := range commits {
+			req.Commits = append(req.Commits, ai.CommitInfo{
+				Repo:         filepath.Base(c.RepoPath),
+				Subject:      c.Subject,
+				Body:         c.Body,
+				FilesChanged: c.FilesChanged,
+				LinesChanged: c.LinesAdded + c.LinesDeleted,
+			})
+		}
+	}
+
+	// 添加当日会议（可选证据）
+	if s.calendarRepo != nil {
+		meetings, err := s.calendarRepo.GetByTimeRange(ctx, startTime, endTime)
+		if err != nil {
+			slog.Warn("查询当日日程失败", "date", date, "error", err)
+		}
+		for _, m := range meetings {
+			req.Meetings = append(req.Meetings, ai.MeetingInfo{
+				TimeRange: FormatTimeRangeMs(m.StartTime, m.EndTime),
+				Title:     m.Title,
+			})
+		}
+	}
+
 	// 离线模式：不触发任何 AI 调用，直接走规则总结（保证“无 Key 也可用”且不产生噪音错误日志）。
 	if s.analyzer == nil {
 		s.degraded.Store(true)
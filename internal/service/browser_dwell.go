@@ -0,0 +1,134 @@
+package service
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yuqie6/WorkMirror/internal/collector"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+const (
+	browserDwellMaxSec      = 30 * 60 // 单次访问推断停留时长上限
+	browserDwellNoWindowSec = 5 * 60  // 没有窗口数据时，按到下一次访问的间隔推断的上限
+)
+
+// browserAppHints 浏览器对应的前台进程名（小写、去掉 .exe）
+var browserAppHints = map[string][]string{
+	collector.BrowserChrome:     {"chrome"},
+	collector.BrowserChromeBeta: {"chrome"},
+	collector.BrowserChromium:   {"chromium"},
+	collector.BrowserEdge:       {"msedge", "edge"},
+	collector.BrowserBrave:      {"brave"},
+	collector.BrowserVivaldi:    {"vivaldi"},
+	collector.BrowserArc:        {"arc"},
+	collector.BrowserFirefox:    {"firefox"},
+}
+
+type foregroundSpan struct {
+	app        string
+	start, end int64
+}
+
+// inferBrowserDwell 为缺少停留时长的访问推断时长（事件 ID -> 秒）：
+// 从访问开始到同一 profile 的下一次访问为止，与该浏览器处于前台的时间取交集。
+// 没有后继且尚未超过上限的访问可能仍在浏览，暂不推断，避免写入偏小的值。
+// 该时段内已有扩展上报前台停留的浏览器，其历史访问不再推断，避免重复计时。
+func inferBrowserDwell(visits []schema.BrowserEvent, windows []schema.Event, rangeEnd int64) map[int64]int {
+	var spans []foregroundSpan
+	for _, w := range windows {
+		if !isWindowEvent(w) {
+			continue
+		}
+		if w.Duration <= 0 {
+			continue
+		}
+		app := strings.TrimSuffix(strings.ToLower(filepath.Base(strings.TrimSpace(w.AppName))), ".exe")
+		spans = append(spans, foregroundSpan{app: app, start: w.Timestamp, end: w.Timestamp + int64(w.Duration)*1000})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	focused := make(map[string]bool)
+	for _, v := range visits {
+		if v.Transition == schema.BrowserTransitionFocus {
+			focused[v.Browser] = true
+		}
+	}
+
+	groups := make(map[string][]schema.BrowserEvent)
+	for _, v := range visits {
+		if focused[v.Browser] {
+			continue
+		}
+		key := v.Browser + "/" + v.Profile
+		groups[key] = append(groups[key], v)
+	}
+
+	out := make(map[int64]int)
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool { return group[i].Timestamp < group[j].Timestamp })
+		for i, v := range group {
+			if v.ID <= 0 || v.Duration > 0 || v.Transition == schema.BrowserTransitionRedirect {
+				continue
+			}
+			to := v.Timestamp + browserDwellMaxSec*1000
+			next := i + 1
+			for next < len(group) && group[next].Timestamp <= v.Timestamp {
+				next++
+			}
+			if next < len(group) {
+				to = min(to, group[next].Timestamp)
+			} else if to > rangeEnd {
+				continue
+			}
+
+			var sec int
+			if len(spans) == 0 {
+				sec = min(int((to-v.Timestamp)/1000), browserDwellNoWindowSec)
+			} else {
+				sec = int(foregroundOverlap(spans, v.Browser, v.Timestamp, to) / 1000)
+			}
+			if sec > 0 {
+				out[v.ID] = sec
+			}
+		}
+	}
+	return out
+}
+
+// foregroundOverlap [from, to) 内该浏览器在前台的毫秒数；browser 为空（旧版本采集）时匹配任意浏览器
+func foregroundOverlap(spans []foregroundSpan, browser string, from, to int64) int64 {
+	var total int64
+	for _, sp := range spans {
+		if sp.start >= to {
+			break
+		}
+		if sp.end <= from || !isBrowserApp(sp.app, browser) {
+			continue
+		}
+		total += min(sp.end, to) - max(sp.start, from)
+	}
+	return total
+}
+
+func isBrowserApp(app, browser string) bool {
+	matches := func(hints []string) bool {
+		for _, h := range hints {
+			// 短名称（arc/edge）要求完全一致，避免误匹配 SearchHost 之类的进程
+			if app == h || (len(h) >= 5 && strings.Contains(app, h)) {
+				return true
+			}
+		}
+		return false
+	}
+	if browser != "" {
+		return matches(browserAppHints[browser])
+	}
+	for _, hints := range browserAppHints {
+		if matches(hints) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+func TestInferBrowserDwell(t *testing.T) {
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.Local)
+	ms := func(d time.Duration) int64 { return base.Add(d).UnixMilli() }
+
+	visits := []schema.BrowserEvent{
+		{ID: 1, Timestamp: ms(0), Browser: "chrome", Profile: "Default", Transition: schema.BrowserTransitionRedirect},
+		{ID: 2, Timestamp: ms(0), Browser: "chrome", Profile: "Default"},
+		{ID: 3, Timestamp: ms(20 * time.Minute), Browser: "chrome", Profile: "Default", Duration: 42},
+		{ID: 4, Timestamp: ms(25 * time.Minute), Browser: "chrome", Profile: "Default"},
+		// 另一个 profile 的访问不截断 Default 的停留
+		{ID: 5, Timestamp: ms(5 * time.Minute), Browser: "chrome", Profile: "Profile 1"},
+		{ID: 6, Timestamp: ms(time.Hour), Browser: "firefox", Profile: "p"},
+	}
+	windows := []schema.Event{
+		{Timestamp: ms(0), Source: "window", AppName: "chrome.exe", Duration: 600},
+		{Timestamp: ms(10 * time.Minute), Source: "window", AppName: "Code.exe", Duration: 300},
+		{Timestamp: ms(15 * time.Minute), Source: "window", AppName: "chrome.exe", Duration: 600},
+		{Timestamp: ms(25 * time.Minute), Source: "window", AppName: "SearchHost.exe", Duration: 60},
+		{Timestamp: ms(26 * time.Minute), Source: "window", AppName: "chrome.exe", Duration: 120},
+		{Timestamp: ms(time.Hour), Source: "window", AppName: "firefox.exe", Duration: 900},
+	}
+
+	got := inferBrowserDwell(visits, windows, ms(2*time.Hour))
+	want := map[int64]int{
+		2: 15 * 60, // 到下一次访问（20 分钟）为止，其中 5 分钟在编辑器
+		4: 120,     // 没有后继：上限内的前台时间，SearchHost 不算浏览器
+		5: 17 * 60, // 上限 30 分钟内 chrome 在前台的时间
+		6: 15 * 60,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for id, sec := range want {
+		if got[id] != sec {
+			t.Fatalf("visit %d: got %d, want %d (all %v)", id, got[id], sec, got)
+		}
+	}
+
+	// 最近的访问可能仍在浏览：不推断
+	if got := inferBrowserDwell(visits[5:], windows, ms(time.Hour+10*time.Minute)); len(got) != 0 {
+		t.Fatalf("open visit should be skipped, got %v", got)
+	}
+	// 没有窗口数据时按间隔推断并限制上限
+	if got := inferBrowserDwell(visits[1:3], nil, ms(2*time.Hour)); got[2] != browserDwellNoWindowSec {
+		t.Fatalf("fallback dwell: got %v", got)
+	}
+	// 扩展已上报 chrome 的前台停留：chrome 历史访问不再推断
+	withFocus := append([]schema.BrowserEvent{{ID: 7, Timestamp: ms(time.Minute), Browser: "chrome", Duration: 90, Transition: schema.BrowserTransitionFocus}}, visits...)
+	if got := inferBrowserDwell(withFocus, windows, ms(2*time.Hour)); len(got) != 1 || got[6] != 15*60 {
+		t.Fatalf("focus-tracked browser should be skipped, got %v", got)
+	}
+}
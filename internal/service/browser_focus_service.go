@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+const (
+	browserFocusMaxSec      = 60 * 60     // 单段前台停留上限（休眠等未收到 idle 的情况）
+	browserFocusMaxFuture   = time.Minute // 扩展时钟超前的容忍度
+	browserFocusMinVisitMs  = 1000        // 快速切换标签页时途经的页面不入库
+	browserFocusMaxProfile  = 128         // 与 browser_events.profile 列宽一致
+	browserFocusDefaultName = "chrome"    // 扩展未上报浏览器名时的默认值
+	browserFocusMaxPending  = 64          // 入库失败待重试的前台段上限（保留最近的）
+)
+
+// 扩展发来的消息类型（native messaging）
+const (
+	BrowserFocusTabActivated = "tab_activated" // 切换到某个标签页（含窗口重新获得焦点）
+	BrowserFocusURLChanged   = "url_changed"   // 当前标签页导航到新地址或标题变化
+	BrowserFocusIdle         = "idle"          // chrome.idle 状态变化：idle | locked | active
+	BrowserFocusLost         = "focus_lost"    // 浏览器所有窗口失去焦点
+	BrowserFocusPing         = "ping"
+)
+
+// BrowserFocusMessage 扩展通过 native messaging 发来的一条消息
+type BrowserFocusMessage struct {
+	Type        string `json:"type"`
+	Timestamp   int64  `json:"ts"` // 毫秒；0 表示收到的时间
+	TabID       int    `json:"tab_id"`
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Browser     string `json:"browser"`
+	Profile     string `json:"profile"`
+	State       string `json:"state"`        // idle 消息：idle | locked | active
+	IdleSeconds int    `json:"idle_seconds"` // idle 消息：检测到空闲前已无输入的秒数（chrome.idle 的检测间隔）
+}
+
+// BrowserFocusReply 回复扩展的确认消息
+type BrowserFocusReply struct {
+	OK    bool   `json:"ok"`
+	Type  string `json:"type,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// focusTab 当前前台标签页
+type focusTab struct {
+	tabID   int
+	url     string
+	title   string
+	browser string
+	profile string
+}
+
+// BrowserFocusService 将扩展上报的标签页切换、导航与空闲事件还原为前台停留记录：
+// 同一时刻只有一个前台标签页，下一次切换/导航/空闲/失焦时结束上一段并按实际时长入库。
+// 一个实例对应一个 native messaging 连接，不并发处理消息。
+type BrowserFocusService struct {
+	repo      BrowserEventRepository
+	browsers  map[string]struct{}
+	sanitizer *privacy.Sanitizer
+	now       func() time.Time
+
+	mu        sync.Mutex
+	active    *focusTab
+	since     int64 // 当前前台段开始时间（毫秒）
+	suspended bool  // 空闲或失焦：前台标签页不计时
+	lastTs    int64
+	persisted int
+	pending   []*schema.BrowserEvent // 入库失败的前台段，下一次结束前台段时一并重试
+}
+
+// NewBrowserFocusService 创建前台标签页记录服务；browsers 为允许上报的浏览器（browser.native_host_browsers）
+func NewBrowserFocusService(repo BrowserEventRepository, browsers []string) *BrowserFocusService {
+	allowed := make(map[string]struct{}, len(browsers))
+	for _, b := range browsers {
+		if b = strings.ToLower(strings.TrimSpace(b)); b != "" {
+			allowed[b] = struct{}{}
+		}
+	}
+	return &BrowserFocusService{repo: repo, browsers: allowed, now: time.Now}
+}
+
+// SetSanitizer 设置脱敏器（URL 与标题入库前脱敏）
+func (s *BrowserFocusService) SetSanitizer(z *privacy.Sanitizer) {
+	s.sanitizer = z
+}
+
+// Persisted 已入库的前台停留记录数
+func (s *BrowserFocusService) Persisted() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.persisted
+}
+
+// HandleMessage 处理一条原始消息并返回确认（签名与 nativemsg.Handler 一致）
+func (s *BrowserFocusService) HandleMessage(ctx context.Context, raw json.RawMessage) any {
+	var msg BrowserFocusMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return BrowserFocusReply{Error: fmt.Sprintf("解析消息失败: %v", err)}
+	}
+	if err := s.Handle(ctx, msg); err != nil {
+		return BrowserFocusReply{Type: msg.Type, Error: err.Error()}
+	}
+	return BrowserFocusReply{OK: true, Type: msg.Type}
+}
+
+// Handle 按消息推进前台状态；结束的前台段立即入库
+func (s *BrowserFocusService) Handle(ctx context.Context, msg BrowserFocusMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch msg.Type {
+	case BrowserFocusPing:
+		return nil
+	case BrowserFocusTabActivated, BrowserFocusURLChanged, BrowserFocusIdle, BrowserFocusLost:
+	default:
+		return fmt.Errorf("未知消息类型: %q", msg.Type)
+	}
+
+	ts := s.normalizeTs(msg.Timestamp)
+	switch msg.Type {
+	case BrowserFocusTabActivated:
+		tab, err := s.toTab(msg)
+		if err != nil {
+			return err
+		}
+		err = s.closeLocked(ctx, ts)
+		s.active, s.since, s.suspended = tab, ts, false
+		return err
+
+	case BrowserFocusURLChanged:
+		tab, err := s.toTab(msg)
+		if err != nil {
+			return err
+		}
+		if s.active != nil && tab.tabID != 0 && s.active.tabID != 0 && tab.tabID != s.active.tabID {
+			return nil // 后台标签页的导航不影响前台
+		}
+		if s.active != nil && s.active.url == tab.url {
+			if tab.title != "" {
+				s.active.title = tab.title
+			}
+			return nil
+		}
+		var closeErr error
+		if !s.suspended {
+			closeErr = s.closeLocked(ctx, ts)
+		}
+		s.active, s.since = tab, ts
+		return closeErr
+
+	case BrowserFocusIdle:
+		switch msg.State {
+		case "idle", "locked":
+			if s.suspended {
+				return nil
+			}
+			end := ts
+			if msg.IdleSeconds > 0 && msg.IdleSeconds <= browserFocusMaxSec {
+				end = max(ts-int64(msg.IdleSeconds)*1000, s.since)
+			}
+			err := s.closeLocked(ctx, end)
+			s.suspended = true
+			return err
+		case "active":
+			if s.suspended {
+				s.suspended, s.since = false, ts
+			}
+			return nil
+		default:
+			return fmt.Errorf("未知空闲状态: %q", msg.State)
+		}
+
+	case BrowserFocusLost:
+		err := s.closeLocked(ctx, ts)
+		s.suspended = true
+		return err
+	}
+	return nil
+}
+
+// Close 连接断开（浏览器退出或扩展重载）时结束当前前台段
+func (s *BrowserFocusService) Close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.closeLocked(ctx, s.normalizeTs(0))
+	s.active, s.suspended = nil, true
+	return err
+}
+
+// normalizeTs 缺省取当前时间，超前的时钟截到当前时间，并保证单调不减
+func (s *BrowserFocusService) normalizeTs(ts int64) int64 {
+	now := s.now().UnixMilli()
+	if ts <= 0 || ts > now+browserFocusMaxFuture.Milliseconds() {
+		ts = now
+	}
+	if ts < s.lastTs {
+		ts = s.lastTs
+	}
+	s.lastTs = ts
+	return ts
+}
+
+func (s *BrowserFocusService) toTab(msg BrowserFocusMessage) (*focusTab, error) {
+	browser := strings.ToLower(strings.TrimSpace(msg.Browser))
+	if browser == "" {
+		browser = browserFocusDefaultName
+	}
+	if _, ok := s.browsers[browser]; !ok {
+		return nil, fmt.Errorf("浏览器 %q 未在 browser.native_host_browsers 中启用", browser)
+	}
+	return &focusTab{
+		tabID:   msg.TabID,
+		url:     strings.TrimSpace(msg.URL),
+		title:   strings.TrimSpace(msg.Title),
+		browser: browser,
+		profile: truncateRunes(strings.TrimSpace(msg.Profile), browserFocusMaxProfile),
+	}, nil
+}
+
+// closeLocked 结束当前前台段并入库；内部页面（chrome://、扩展页等）与过短的停留不入库。
+// 入库失败的前台段保留下来，下一次结束前台段时一并重试
+func (s *BrowserFocusService) closeLocked(ctx context.Context, end int64) error {
+	events := s.pending
+	if ev := s.endSegmentLocked(end); ev != nil {
+		events = append(events, ev)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	if err := s.repo.BatchInsert(ctx, events); err != nil {
+		if len(events) > browserFocusMaxPending {
+			events = events[len(events)-browserFocusMaxPending:]
+		}
+		s.pending = events
+		slog.Warn("保存前台标签页记录失败", "pending", len(events), "error", err)
+		return err
+	}
+	s.pending = nil
+	s.persisted += len(events)
+	return nil
+}
+
+// endSegmentLocked 结束当前前台段，返回需要入库的记录（不需要入库时为 nil）
+func (s *BrowserFocusService) endSegmentLocked(end int64) *schema.BrowserEvent {
+	tab, start := s.active, s.since
+	if tab == nil || s.suspended || end-start < browserFocusMinVisitMs {
+		return nil
+	}
+	s.since = end
+
+	u, err := url.Parse(tab.url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil
+	}
+	sec := min(int((end-start+500)/1000), browserFocusMaxSec)
+
+	return &schema.BrowserEvent{
+		Timestamp:  start,
+		URL:        truncateRunes(s.sanitizer.SanitizeURL(tab.url), 2000),
+		Title:      truncateRunes(s.sanitizer.SanitizeBrowserTitle(tab.title), 500),
+		Domain:     strings.ToLower(u.Host),
+		Duration:   sec,
+		Browser:    tab.browser,
+		Profile:    tab.profile,
+		Transition: schema.BrowserTransitionFocus,
+	}
+}
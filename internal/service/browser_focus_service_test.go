@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/pkg/nativemsg"
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+type fakeBrowserRepoForFocus struct {
+	inserted []*schema.BrowserEvent
+	fail     error
+}
+
+func (f *fakeBrowserRepoForFocus) BatchInsert(ctx context.Context, events []*schema.BrowserEvent) error {
+	if f.fail != nil {
+		return f.fail
+	}
+	f.inserted = append(f.inserted, events...)
+	return nil
+}
+func (f *fakeBrowserRepoForFocus) GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.BrowserEvent, error) {
+	return nil, nil
+}
+func (f *fakeBrowserRepoForFocus) GetByIDs(ctx context.Context, ids []int64) ([]schema.BrowserEvent, error) {
+	return nil, nil
+}
+func (f *fakeBrowserRepoForFocus) UpdateVisitDuration(ctx context.Context, browser, profile string, visitID int64, seconds int) error {
+	return nil
+}
+func (f *fakeBrowserRepoForFocus) FillDurations(ctx context.Context, durations map[int64]int) error {
+	return nil
+}
+
+func TestBrowserFocusService_FramedSessionProducesDurations(t *testing.T) {
+	repo := &fakeBrowserRepoForFocus{}
+	svc := NewBrowserFocusService(repo, []string{"chrome"})
+	svc.SetSanitizer(privacy.New(true, nil))
+	base := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	now := base.Add(time.Hour)
+	svc.now = func() time.Time { return now }
+	at := func(sec int) int64 { return base.Add(time.Duration(sec) * time.Second).UnixMilli() }
+
+	msgs := []BrowserFocusMessage{
+		{Type: BrowserFocusTabActivated, Timestamp: at(0), TabID: 1, URL: "https://go.dev/doc/effective_go?utm=x#names", Title: "Effective Go"},
+		{Type: BrowserFocusURLChanged, Timestamp: at(30), TabID: 1, URL: "https://go.dev/doc/effective_go?utm=x#names", Title: "Effective Go - names"},
+		{Type: BrowserFocusURLChanged, Timestamp: at(40), TabID: 2, URL: "https://example.com/background"}, // 后台标签页
+		{Type: BrowserFocusURLChanged, Timestamp: at(90), TabID: 1, URL: "https://pkg.go.dev/net/http", Title: "http package"},
+		{Type: BrowserFocusTabActivated, Timestamp: at(90) + 400, TabID: 3, URL: "chrome://newtab/"},
+		{Type: BrowserFocusTabActivated, Timestamp: at(100), TabID: 4, URL: "https://github.com/yuqie6/WorkMirror/pull/1", Title: "PR"},
+		{Type: BrowserFocusIdle, Timestamp: at(260), State: "idle", IdleSeconds: 60},
+		{Type: BrowserFocusIdle, Timestamp: at(600), State: "active"},
+		{Type: BrowserFocusLost, Timestamp: at(630)},
+		{Type: BrowserFocusTabActivated, Timestamp: at(700), TabID: 5, URL: "https://edge.example/", Browser: "edge"},
+		{Type: "bogus"},
+		{Type: BrowserFocusTabActivated, Timestamp: at(700), TabID: 6, URL: "https://stackoverflow.com/q/1", Title: "Q"},
+	}
+	var in bytes.Buffer
+	for _, m := range msgs {
+		if err := nativemsg.Write(&in, m); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	var out bytes.Buffer
+	if err := nativemsg.Serve(context.Background(), &in, &out, svc.HandleMessage); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	// 连接断开：当前标签页按断开时间结束
+	now = base.Add(720 * time.Second)
+	if err := svc.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	var replies []BrowserFocusReply
+	for {
+		raw, err := nativemsg.Read(&out)
+		if err != nil {
+			break
+		}
+		var r BrowserFocusReply
+		if err := json.Unmarshal(raw, &r); err != nil {
+			t.Fatalf("reply %s: %v", raw, err)
+		}
+		replies = append(replies, r)
+	}
+	if len(replies) != len(msgs) {
+		t.Fatalf("replies=%d, want %d", len(replies), len(msgs))
+	}
+	for i, r := range replies {
+		wantOK := i != 9 && i != 10 // 未启用的浏览器、未知消息类型
+		if r.OK != wantOK {
+			t.Fatalf("reply[%d]=%+v, want ok=%v", i, r, wantOK)
+		}
+	}
+
+	type visit struct {
+		url   string
+		title string
+		start int64
+		sec   int
+	}
+	want := []visit{
+		{"https://go.dev/...", "Effective Go - names", at(0), 90},
+		{"https://github.com/...", "PR", at(100), 100},      // 空闲 60 秒前结束
+		{"https://github.com/...", "PR", at(600), 30},       // 恢复活动后到失焦
+		{"https://stackoverflow.com/...", "Q", at(700), 20}, // 断开连接时结束
+	}
+	if len(repo.inserted) != len(want) {
+		for _, ev := range repo.inserted {
+			t.Logf("%+v", *ev)
+		}
+		t.Fatalf("inserted=%d, want %d", len(repo.inserted), len(want))
+	}
+	for i, w := range want {
+		ev := repo.inserted[i]
+		if ev.URL != w.url || ev.Title != w.title || ev.Timestamp != w.start || ev.Duration != w.sec {
+			t.Fatalf("visit[%d]=%+v, want %+v", i, *ev, w)
+		}
+		if ev.Transition != schema.BrowserTransitionFocus || ev.Browser != "chrome" || ev.VisitID != 0 {
+			t.Fatalf("visit[%d] meta=%+v", i, *ev)
+		}
+	}
+	if repo.inserted[0].Domain != "go.dev" || svc.Persisted() != len(want) {
+		t.Fatalf("domain=%q persisted=%d", repo.inserted[0].Domain, svc.Persisted())
+	}
+}
+
+func TestBrowserFocusService_retriesFailedInsert(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeBrowserRepoForFocus{fail: errors.New("database is locked")}
+	svc := NewBrowserFocusService(repo, []string{"chrome"})
+	base := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	svc.now = func() time.Time { return base.Add(time.Hour) }
+	at := func(sec int) int64 { return base.Add(time.Duration(sec) * time.Second).UnixMilli() }
+
+	if err := svc.Handle(ctx, BrowserFocusMessage{Type: BrowserFocusTabActivated, Timestamp: at(0), TabID: 1, URL: "https://go.dev/"}); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	if err := svc.Handle(ctx, BrowserFocusMessage{Type: BrowserFocusTabActivated, Timestamp: at(30), TabID: 2, URL: "https://pkg.go.dev/"}); err == nil {
+		t.Fatalf("failed insert should be reported")
+	}
+	if svc.Persisted() != 0 {
+		t.Fatalf("persisted=%d, want 0", svc.Persisted())
+	}
+
+	repo.fail = nil
+	if err := svc.Handle(ctx, BrowserFocusMessage{Type: BrowserFocusLost, Timestamp: at(50)}); err != nil {
+		t.Fatalf("focus lost: %v", err)
+	}
+	if len(repo.inserted) != 2 || svc.Persisted() != 2 {
+		t.Fatalf("inserted=%d persisted=%d, want the failed segment retried", len(repo.inserted), svc.Persisted())
+	}
+	if got := repo.inserted[0]; got.Domain != "go.dev" || got.Timestamp != at(0) || got.Duration != 30 {
+		t.Fatalf("retried segment unexpected: %+v", got)
+	}
+	if got := repo.inserted[1]; got.Domain != "pkg.go.dev" || got.Duration != 20 {
+		t.Fatalf("second segment unexpected: %+v", got)
+	}
+}
@@ -1,10 +1,9 @@
-//go:build windows
-
 package service
 
 import (
 	"context"
 	"log/slog"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -26,6 +25,8 @@ type BrowserService struct {
 	running     bool
 	onPersisted func(count int)
 	sanitizer   *privacy.Sanitizer
+	searchRepo  SearchTermRepository // 可选：启用搜索词提取时设置
+	searches    []*schema.SearchTerm
 
 	lastPersistAt atomic.Int64
 	persistErrors atomic.Int64
@@ -56,6 +57,11 @@ func (s *BrowserService) SetSanitizer(z *privacy.Sanitizer) {
 	s.sanitizer = z
 }
 
+// SetSearchTermRepository 设置搜索词仓储（可选），未设置时丢弃采集器提取的搜索词
+func (s *BrowserService) SetSearchTermRepository(repo SearchTermRepository) {
+	s.searchRepo = repo
+}
+
 // Start 启动服务
 func (s *BrowserService) Start(ctx context.Context) error {
 	if s.running {
@@ -100,6 +106,8 @@ func (s *BrowserService) processLoop(ctx context.Context) {
 	defer s.wg.Done()
 
 	events := s.collector.Events()
+	durations := s.collector.Durations()
+	searches := s.collector.SearchQueries()
 
 	for {
 		select {
@@ -112,10 +120,58 @@ func (s *BrowserService) processLoop(ctx context.Context) {
 				return
 			}
 			s.handleEvent(ctx, event)
+		case d := <-durations:
+			s.handleDuration(ctx, d)
+		case q := <-searches:
+			s.handleSearch(q)
 		}
 	}
 }
 
+// handleSearch 搜索词脱敏后随浏览事件一起入库
+func (s *BrowserService) handleSearch(q *collector.SearchQuery) {
+	if s.searchRepo == nil || q == nil {
+		return
+	}
+	term := q.Term
+	if s.sanitizer != nil {
+		term = s.sanitizer.SanitizeText(term)
+	}
+	term = truncateRunes(strings.TrimSpace(term), 200)
+	if term == "" {
+		return
+	}
+
+	s.mu.Lock()
+	s.searches = append(s.searches, &schema.SearchTerm{
+		Timestamp: q.Timestamp,
+		Term:      term,
+		Engine:    q.Engine,
+		Domain:    q.Domain,
+		Browser:   q.Browser,
+		Profile:   q.Profile,
+		VisitID:   q.VisitID,
+	})
+	s.mu.Unlock()
+}
+
+// handleDuration 访问结束后补写停留时长：仍在缓冲区的直接修改，已入库的更新记录
+func (s *BrowserService) handleDuration(ctx context.Context, d collector.VisitDuration) {
+	s.mu.Lock()
+	for _, e := range s.buffer {
+		if e.VisitID == d.VisitID && e.Browser == d.Browser && e.Profile == d.Profile {
+			e.Duration = d.Duration
+			s.mu.Unlock()
+			return
+		}
+	}
+	s.mu.Unlock()
+
+	if err := s.browserRepo.UpdateVisitDuration(ctx, d.Browser, d.Profile, d.VisitID, d.Duration); err != nil {
+		slog.Warn("更新浏览器访问时长失败", "visit_id", d.VisitID, "error", err)
+	}
+}
+
 // handleEvent 处理事件
 func (s *BrowserService) handleEvent(ctx context.Context, event *schema.BrowserEvent) {
 	if s.sanitizer != nil && event != nil {
@@ -136,14 +192,18 @@ func (s *BrowserService) handleEvent(ctx context.Context, event *schema.BrowserE
 // flush 刷新缓冲区
 func (s *BrowserService) flush(ctx context.Context) {
 	s.mu.Lock()
+	searches := s.searches
+	s.searches = nil
 	if len(s.buffer) == 0 {
 		s.mu.Unlock()
+		s.flushSearches(ctx, searches)
 		return
 	}
 
 	events := s.buffer
 	s.buffer = make([]*schema.BrowserEvent, 0, 100)
 	s.mu.Unlock()
+	defer s.flushSearches(ctx, searches)
 
 	if err := s.browserRepo.BatchInsert(ctx, events); err != nil {
 		s.persistErrors.Add(1)
@@ -159,6 +219,15 @@ func (s *BrowserService) flush(ctx context.Context) {
 	}
 }
 
+func (s *BrowserService) flushSearches(ctx context.Context, terms []*schema.SearchTerm) {
+	if len(terms) == 0 || s.searchRepo == nil {
+		return
+	}
+	if err := s.searchRepo.BatchInsert(ctx, terms); err != nil {
+		slog.Warn("保存搜索词失败", "error", err)
+	}
+}
+
 type BrowserServiceStats struct {
 	Running       bool   `json:"running"`
 	LastPersistAt int64  `json:"last_persist_at"`
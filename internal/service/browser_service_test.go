@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yuqie6/WorkMirror/internal/collector"
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+type fakeSearchTermRepo struct {
+	inserted []*schema.SearchTerm
+	terms    []schema.SearchTerm
+}
+
+func (f *fakeSearchTermRepo) BatchInsert(ctx context.Context, terms []*schema.SearchTerm) error {
+	f.inserted = append(f.inserted, terms...)
+	return nil
+}
+func (f *fakeSearchTermRepo) GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.SearchTerm, error) {
+	var out []schema.SearchTerm
+	for _, t := range f.terms {
+		if t.Timestamp >= startTime && t.Timestamp <= endTime {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+func (f *fakeSearchTermRepo) GetByIDs(ctx context.Context, ids []int64) ([]schema.SearchTerm, error) {
+	var out []schema.SearchTerm
+	for _, id := range ids {
+		for _, t := range f.terms {
+			if t.ID == id {
+				out = append(out, t)
+			}
+		}
+	}
+	return out, nil
+}
+
+func TestBrowserService_searchTermsSanitizedBeforePersist(t *testing.T) {
+	repo := &fakeSearchTermRepo{}
+	svc := NewBrowserService(nil, nil)
+	svc.SetSanitizer(privacy.New(true, []string{`(?i)token=\S+`}))
+
+	// 未设置仓储（功能关闭）时直接丢弃
+	svc.handleSearch(&collector.SearchQuery{Term: "ignored"})
+	if len(svc.searches) != 0 {
+		t.Fatal("search terms should be dropped without a repository")
+	}
+
+	svc.SetSearchTermRepository(repo)
+	svc.handleSearch(&collector.SearchQuery{Browser: "chrome", Profile: "Default", VisitID: 9, Timestamp: 1000,
+		Term: "curl token=abc123 401", Engine: "google", Domain: "www.google.com"})
+	svc.handleSearch(&collector.SearchQuery{Term: strings.Repeat("长", 300), Engine: "bing"})
+	svc.handleSearch(&collector.SearchQuery{Term: "   "})
+	svc.flush(context.Background())
+
+	if len(repo.inserted) != 2 {
+		t.Fatalf("expected 2 persisted terms, got %+v", repo.inserted)
+	}
+	if got := repo.inserted[0]; got.Term != "curl *** 401" || got.VisitID != 9 || got.Engine != "google" {
+		t.Fatalf("unexpected term: %+v", got)
+	}
+	if got := repo.inserted[1].Term; got != strings.Repeat("长", 200)+"..." {
+		t.Fatalf("term should be truncated, got %d runes", len([]rune(got)))
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/collector"
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+// 未指定范围时导入过去 90 天到未来 30 天的日程
+const (
+	calendarImportPastDays   = 90
+	calendarImportFutureDays = 30
+)
+
+// CalendarImportRequest 日程导入参数
+type CalendarImportRequest struct {
+	Files []string // .ics 文件路径
+	Since time.Time
+	Until time.Time
+}
+
+// CalendarImportResult 一次日程导入的结果
+type CalendarImportResult struct {
+	Files           int
+	Imported        int // 新增的日程实例
+	Updated         int // 标题或时间有变化
+	Unchanged       int
+	Removed         int // 文件中已不存在（删除或取消）的实例
+	Skipped         int // 全天、已取消、标记为空闲等不导入的日程
+	Since           int64
+	Until           int64
+	SessionsCreated int
+}
+
+type calendarFile struct {
+	path      string
+	instances []collector.ICSInstance
+}
+
+// CalendarImportService 导入 .ics 日程：重复事件展开为实例写入日程表，按 (UID, 开始时间) 对齐，
+// 重新导入同一文件会更新改动、删除文件中已不存在的实例，并重建受影响日期的会话。
+type CalendarImportService struct {
+	repo      CalendarEventRepository
+	sessions  SessionRebuilder
+	sanitizer *privacy.Sanitizer // 可选
+	now       func() time.Time
+}
+
+// NewCalendarImportService 创建日程导入服务
+func NewCalendarImportService(repo CalendarEventRepository, sessions SessionRebuilder) *CalendarImportService {
+	return &CalendarImportService{repo: repo, sessions: sessions, now: time.Now}
+}
+
+// SetSanitizer 设置日程标题脱敏（可选）
+func (s *CalendarImportService) SetSanitizer(z *privacy.Sanitizer) {
+	s.sanitizer = z
+}
+
+// Import 导入一个或多个 .ics 文件；先解析全部文件再写入，任一文件无法解析时不做任何修改
+func (s *CalendarImportService) Import(ctx context.Context, req CalendarImportRequest) (CalendarImportResult, error) {
+	if len(req.Files) == 0 {
+		return CalendarImportResult{}, fmt.Errorf("没有可导入的日程文件")
+	}
+	now := s.now()
+	today0 := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	since, until := req.Since, req.Until
+	if since.IsZero() {
+		since = today0.AddDate(0, 0, -calendarImportPastDays)
+	}
+	if until.IsZero() {
+		until = today0.AddDate(0, 0, calendarImportFutureDays+1)
+	}
+	if !since.Before(until) {
+		return CalendarImportResult{}, fmt.Errorf("导入起始时间必须早于结束时间")
+	}
+	res := CalendarImportResult{Since: since.UnixMilli(), Until: until.UnixMilli()}
+
+	files := make([]calendarFile, 0, len(req.Files))
+	for _, path := range req.Files {
+		f, skipped, err := readCalendarFile(path, since, until)
+		if err != nil {
+			return res, err
+		}
+		files = append(files, f)
+		res.Skipped += skipped
+	}
+
+	days := make(map[string]struct{})
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		if err := s.importFile(ctx, f, since, until, &res, days); err != nil {
+			return res, err
+		}
+		res.Files++
+	}
+
+	// 只重建今天及以前的日期：未来的日程还没有活动，切分时会再关联
+	today := now.Format("2006-01-02")
+	sorted := make([]string, 0, len(days))
+	for d := range days {
+		if d <= today {
+			sorted = append(sorted, d)
+		}
+	}
+	sort.Strings(sorted)
+	for _, d := range sorted {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		created, err := s.sessions.RebuildSessionsForDate(ctx, d)
+		if err != nil {
+			return res, fmt.Errorf("切分会话失败 (%s): %w", d, err)
+		}
+		res.SessionsCreated += created
+	}
+	slog.Info("日程导入完成", "files", res.Files, "imported", res.Imported, "updated", res.Updated,
+		"removed", res.Removed, "skipped", res.Skipped, "sessions", res.SessionsCreated)
+	return res, nil
+}
+
+func readCalendarFile(path string, since, until time.Time) (calendarFile, int, error) {
+	abs, err := filepath.Abs(strings.TrimSpace(path))
+	if err != nil {
+		return calendarFile{}, 0, fmt.Errorf("解析日程文件路径失败 (%s): %w", path, err)
+	}
+	fh, err := os.Open(abs)
+	if err != nil {
+		return calendarFile{}, 0, fmt.Errorf("打开日程文件失败: %w", err)
+	}
+	defer fh.Close()
+	instances, skipped, err := collector.ReadICS(fh, since, until)
+	if err != nil {
+		return calendarFile{}, 0, fmt.Errorf("解析日程文件失败 (%s): %w", abs, err)
+	}
+	return calendarFile{path: abs, instances: instances}, skipped, nil
+}
+
+// importFile 把一个文件的实例与库中同一范围的日程对齐；days 收集新旧时间所在的日期
+func (s *CalendarImportService) importFile(
+	ctx context.Context,
+	f calendarFile,
+	since, until time.Time,
+	res *CalendarImportResult,
+	days map[string]struct{},
+) error {
+	existing, err := s.repo.GetByTimeRange(ctx, since.UnixMilli(), until.UnixMilli())
+	if err != nil {
+		return err
+	}
+	key := func(uid string, start int64) string { return fmt.Sprintf("%s\x00%d", uid, start) }
+	byKey := make(map[string]schema.CalendarEvent, len(existing))
+	for _, e := range existing {
+		byKey[key(e.UID, e.StartTime)] = e
+	}
+	noteDays := func(start, end int64) {
+		days[time.UnixMilli(start).Format("2006-01-02")] = struct{}{}
+		days[time.UnixMilli(end-1).Format("2006-01-02")] = struct{}{}
+	}
+
+	seen := make(map[string]struct{}, len(f.instances))
+	var upserts []*schema.CalendarEvent
+	for _, in := range f.instances {
+		ev := &schema.CalendarEvent{
+			UID:       truncateRunes(in.UID, 255),
+			StartTime: in.Start.UnixMilli(),
+			EndTime:   in.End.UnixMilli(),
+			Title:     truncateRunes(s.sanitizer.SanitizeText(in.Summary), 500),
+			Source:    f.path,
+		}
+		k := key(ev.UID, ev.StartTime)
+		if _, dup := seen[k]; dup {
+			continue
+		}
+		seen[k] = struct{}{}
+
+		old, ok := byKey[k]
+		switch {
+		case !ok:
+			res.Imported++
+		case old.EndTime == ev.EndTime && old.Title == ev.Title && old.Source == ev.Source:
+			res.Unchanged++
+			continue
+		default:
+			res.Updated++
+			noteDays(old.StartTime, old.EndTime)
+		}
+		noteDays(ev.StartTime, ev.EndTime)
+		upserts = append(upserts, ev)
+	}
+	if err := s.repo.Upsert(ctx, upserts); err != nil {
+		return err
+	}
+
+	// 只清理本文件导入、开始时间在本次范围内的实例；范围外的不在这次解析结果中
+	var stale []int64
+	for _, e := range existing {
+		if e.Source != f.path || e.StartTime < since.UnixMilli() || e.StartTime >= until.UnixMilli() {
+			continue
+		}
+		if _, ok := seen[key(e.UID, e.StartTime)]; ok {
+			continue
+		}
+		stale = append(stale, e.ID)
+		noteDays(e.StartTime, e.EndTime)
+	}
+	if err := s.repo.DeleteByIDs(ctx, stale); err != nil {
+		return err
+	}
+	res.Removed += len(stale)
+	return nil
+}
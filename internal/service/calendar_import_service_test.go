@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+)
+
+func writeICS(t *testing.T, path string, events ...string) {
+	t.Helper()
+	body := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.Join(events, "") + "END:VCALENDAR\r\n"
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func icsEvent(uid, summary, start, end string) string {
+	return "BEGIN:VEVENT\r\nUID:" + uid + "\r\nSUMMARY:" + summary + "\r\n" +
+		"DTSTART:" + start + "\r\nDTEND:" + end + "\r\nEND:VEVENT\r\n"
+}
+
+func TestCalendarImportService_ReimportSyncsChanges(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "work.ics")
+	writeICS(t, path,
+		icsEvent("sync", "Sync (zoom 123456789)", "20260302T010000Z", "20260302T013000Z"),
+		icsEvent("review", "Review", "20260303T060000Z", "20260303T070000Z"),
+		icsEvent("planning", "Planning", "20260320T020000Z", "20260320T030000Z"), // 未来
+	)
+
+	repo := &fakeCalendarRepo{}
+	rebuilder := &fakeSessionRebuilder{}
+	svc := NewCalendarImportService(repo, rebuilder)
+	svc.SetSanitizer(privacy.New(true, []string{`\d{6,}`}))
+	svc.now = func() time.Time { return time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC) }
+
+	req := CalendarImportRequest{
+		Files: []string{path},
+		Since: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+	res, err := svc.Import(ctx, req)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if res.Imported != 3 || res.Files != 1 || len(repo.events) != 3 {
+		t.Fatalf("first import = %+v, rows=%d", res, len(repo.events))
+	}
+	for _, e := range repo.events {
+		if strings.Contains(e.Title, "123456789") {
+			t.Fatalf("title not sanitized: %q", e.Title)
+		}
+		if e.Source != path {
+			t.Fatalf("source = %q, want %q", e.Source, path)
+		}
+	}
+	// 只重建今天及以前的日期
+	if len(rebuilder.dates) != 2 {
+		t.Fatalf("rebuilt dates = %v, want the two past days", rebuilder.dates)
+	}
+
+	// 再次导入：review 延长、sync 被删除，planning 不变
+	writeICS(t, path,
+		icsEvent("review", "Review", "20260303T060000Z", "20260303T073000Z"),
+		icsEvent("planning", "Planning", "20260320T020000Z", "20260320T030000Z"),
+	)
+	rebuilder.dates = nil
+	res, err = svc.Import(ctx, req)
+	if err != nil {
+		t.Fatalf("Import again: %v", err)
+	}
+	if res.Imported != 0 || res.Updated != 1 || res.Unchanged != 1 || res.Removed != 1 {
+		t.Fatalf("re-import = %+v", res)
+	}
+	if len(repo.events) != 2 || len(rebuilder.dates) != 2 {
+		t.Fatalf("rows=%d rebuilt=%v", len(repo.events), rebuilder.dates)
+	}
+}
+
+func TestCalendarImportService_BadFileWritesNothing(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.ics")
+	writeICS(t, good, icsEvent("a", "A", "20260302T010000Z", "20260302T013000Z"))
+	bad := filepath.Join(dir, "bad.ics")
+	if err := os.WriteFile(bad, []byte("not a calendar"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &fakeCalendarRepo{}
+	svc := NewCalendarImportService(repo, &fakeSessionRebuilder{})
+	if _, err := svc.Import(context.Background(), CalendarImportRequest{Files: []string{good, bad}}); err == nil {
+		t.Fatal("expected error for invalid file")
+	}
+	if len(repo.events) != 0 {
+		t.Fatalf("rows=%d, want 0", len(repo.events))
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/collector"
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+// CommitService Git 提交持久化服务
+type CommitService struct {
+	collector   *collector.CommitCollector
+	commitRepo  CommitRepository
+	stopChan    chan struct{}
+	wg          sync.WaitGroup
+	running     bool
+	onPersisted func(count int)
+	sanitizer   *privacy.Sanitizer
+
+	lastPersistAt atomic.Int64
+	persistErrors atomic.Int64
+	lastErrorAt   atomic.Int64
+	lastErrorMsg  atomic.Value // string
+}
+
+// NewCommitService 创建提交服务
+func NewCommitService(collector *collector.CommitCollector, commitRepo CommitRepository) *CommitService {
+	return &CommitService{
+		collector:  collector,
+		commitRepo: commitRepo,
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// SetOnPersisted 设置持久化后的回调函数
+func (s *CommitService) SetOnPersisted(fn func(count int)) {
+	s.onPersisted = fn
+}
+
+func (s *CommitService) SetSanitizer(z *privacy.Sanitizer) {
+	s.sanitizer = z
+}
+
+// Start 启动服务
+func (s *CommitService) Start(ctx context.Context) error {
+	if s.running {
+		return nil
+	}
+	s.running = true
+	slog.Info("Git 提交服务启动")
+
+	if err := s.collector.Start(ctx); err != nil {
+		return err
+	}
+	s.wg.Add(1)
+	go s.processLoop(ctx)
+	return nil
+}
+
+// Stop 停止服务
+func (s *CommitService) Stop() error {
+	if !s.running {
+		return nil
+	}
+	_ = s.collector.Stop()
+	close(s.stopChan)
+	s.wg.Wait()
+	s.running = false
+	slog.Info("Git 提交服务已停止")
+	return nil
+}
+
+// processLoop 提交数量少：收到后把通道里已到达的一并写入，不做定时缓冲
+func (s *CommitService) processLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	events := s.collector.Events()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case commit, ok := <-events:
+			if !ok {
+				return
+			}
+			batch := []*schema.GitCommit{commit}
+		drain:
+			for len(batch) < 100 {
+				select {
+				case more, ok := <-events:
+					if !ok {
+						break drain
+					}
+					batch = append(batch, more)
+				default:
+					break drain
+				}
+			}
+			s.persist(ctx, batch)
+		}
+	}
+}
+
+func (s *CommitService) persist(ctx context.Context, commits []*schema.GitCommit) {
+	if s.sanitizer != nil {
+		for _, c := range commits {
+			c.Subject = s.sanitizer.SanitizeText(c.Subject)
+			c.Body = s.sanitizer.SanitizeText(c.Body)
+		}
+	}
+
+	inserted, err := s.commitRepo.BatchInsert(ctx, commits)
+	if err != nil {
+		s.persistErrors.Add(1)
+		s.lastErrorAt.Store(time.Now().UnixMilli())
+		s.lastErrorMsg.Store(err.Error())
+		slog.Error("保存 Git 提交失败", "error", err)
+		return
+	}
+	s.lastPersistAt.Store(time.Now().UnixMilli())
+	if inserted > 0 {
+		slog.Info("Git 提交已保存", "count", inserted)
+		if s.onPersisted != nil {
+			s.onPersisted(int(inserted))
+		}
+	}
+}
+
+type CommitServiceStats struct {
+	Running       bool   `json:"running"`
+	LastPersistAt int64  `json:"last_persist_at"`
+	PersistErrors int64  `json:"persist_errors"`
+	LastErrorAt   int64  `json:"last_error_at"`
+	LastError     string `json:"last_error"`
+}
+
+func (s *CommitService) Stats() CommitServiceStats {
+	if s == nil {
+		return CommitServiceStats{}
+	}
+	raw := s.lastErrorMsg.Load()
+	msg, _ := raw.(string)
+	return CommitServiceStats{
+		Running:       s.running,
+		LastPersistAt: s.lastPersistAt.Load(),
+		PersistErrors: s.persistErrors.Load(),
+		LastErrorAt:   s.lastErrorAt.Load(),
+		LastError:     msg,
+	}
+}
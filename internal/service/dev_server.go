@@ -0,0 +1,225 @@
+package service
+
+import (
+	"net"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+// 手动测试关联来源（按优先级）
+const (
+	ManualTestingSourcePortHint    = "port_hint"    // 配置的项目端口
+	ManualTestingSourceDiff        = "diff"         // 时间上最近的代码变更所在项目
+	ManualTestingSourceWindowTitle = "window_title" // 时间上最近的编辑器窗口标题
+	ManualTestingSourceEditor      = "editor"       // 时间上最近的编辑器插件心跳
+)
+
+// DevPortHint 项目的本地开发服务端口（diff.repos[].dev_ports）
+type DevPortHint struct {
+	Project string
+	Ports   []int
+}
+
+// editorSpan 编辑器窗口标题或插件心跳给出的项目
+type editorSpan struct {
+	project    string
+	source     string
+	start, end int64
+}
+
+// linkLocalBrowsing 把会话内 localhost/内网开发服务的浏览关联到正在开发的项目：
+// 端口配置优先，其次是时间上最近的 diff 所在项目，最后是最近的编辑器心跳或窗口标题。
+// 无法关联的访问保持为普通浏览。
+func linkLocalBrowsing(visits []schema.BrowserEvent, diffs []schema.Diff, windows []schema.Event, hints []DevPortHint, domains *DomainClassifier) []schema.SessionManualTesting {
+	byPort := make(map[int]string)
+	for _, h := range hints {
+		project := strings.TrimSpace(h.Project)
+		if project == "" {
+			continue
+		}
+		for _, p := range h.Ports {
+			if _, ok := byPort[p]; !ok && p > 0 {
+				byPort[p] = project
+			}
+		}
+	}
+
+	projectDiffs := make([]schema.Diff, 0, len(diffs))
+	for _, d := range diffs {
+		if strings.TrimSpace(d.ProjectPath) != "" {
+			projectDiffs = append(projectDiffs, d)
+		}
+	}
+
+	var editors []editorSpan
+	for _, w := range windows {
+		if isEditorHeartbeat(w) {
+			if p := editorMetaString(w.Metadata, schema.EditorMetaProject); p != "" {
+				editors = append(editors, editorSpan{project: p, source: ManualTestingSourceEditor, start: w.Timestamp, end: w.Timestamp})
+			}
+			continue
+		}
+		if !IsCodeEditor(w.AppName) {
+			continue
+		}
+		if p := projectFromEditorTitle(w.Title); p != "" {
+			editors = append(editors, editorSpan{project: p, source: ManualTestingSourceWindowTitle, start: w.Timestamp, end: w.Timestamp + int64(max(w.Duration, 0))*1000})
+		}
+	}
+
+	agg := make(map[string]*schema.SessionManualTesting)
+	var order []string
+	for _, v := range visits {
+		if v.Transition == schema.BrowserTransitionRedirect {
+			continue
+		}
+		host := visitHost(v)
+		if domains.Classify(host).Category != DomainCategoryDev {
+			continue
+		}
+
+		var project, source string
+		if p, ok := byPort[devServerPort(v, host)]; ok {
+			project, source = p, ManualTestingSourcePortHint
+		} else if p := nearestDiffProject(projectDiffs, v.Timestamp); p != "" {
+			project, source = p, ManualTestingSourceDiff
+		} else if p, src := nearestEditorProject(editors, v.Timestamp); p != "" {
+			project, source = p, src
+		} else {
+			continue
+		}
+
+		// 配置路径与 diff 记录的路径分隔符、大小写可能不同
+		key := strings.ToLower(strings.TrimRight(strings.ReplaceAll(project, "\\", "/"), "/"))
+		it, ok := agg[key]
+		if !ok {
+			it = &schema.SessionManualTesting{Project: project, Name: projectDisplayName(project), Source: source}
+			agg[key] = it
+			order = append(order, key)
+		}
+		if !containsString(it.Hosts, host) {
+			it.Hosts = append(it.Hosts, host)
+		}
+		it.Visits++
+		it.Seconds += max(v.Duration, 0)
+		if v.ID > 0 {
+			it.EventIDs = append(it.EventIDs, v.ID)
+		}
+	}
+
+	out := make([]schema.SessionManualTesting, 0, len(order))
+	for _, k := range order {
+		out = append(out, *agg[k])
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Seconds != out[j].Seconds {
+			return out[i].Seconds > out[j].Seconds
+		}
+		return out[i].Visits > out[j].Visits
+	})
+	return out
+}
+
+// visitHost 访问的 host[:port]（旧数据 Domain 为空时从 URL 解析）
+func visitHost(v schema.BrowserEvent) string {
+	if h := strings.ToLower(strings.TrimSpace(v.Domain)); h != "" {
+		return h
+	}
+	if u, err := url.Parse(v.URL); err == nil {
+		return strings.ToLower(u.Host)
+	}
+	return ""
+}
+
+// devServerPort 访问的端口；URL 未写端口时按协议取默认端口
+func devServerPort(v schema.BrowserEvent, host string) int {
+	if _, port, err := net.SplitHostPort(host); err == nil {
+		n, _ := strconv.Atoi(port)
+		return n
+	}
+	if strings.HasPrefix(strings.ToLower(v.URL), "https:") {
+		return 443
+	}
+	return 80
+}
+
+// nearestDiffProject 时间上最近的代码变更所在项目
+func nearestDiffProject(diffs []schema.Diff, ts int64) string {
+	best, bestGap := "", int64(-1)
+	for _, d := range diffs {
+		gap := d.Timestamp - ts
+		if gap < 0 {
+			gap = -gap
+		}
+		if bestGap < 0 || gap < bestGap {
+			best, bestGap = strings.TrimSpace(d.ProjectPath), gap
+		}
+	}
+	return best
+}
+
+// nearestEditorProject 时间上最近（或覆盖该时刻）的编辑器活动对应的项目及其来源
+func nearestEditorProject(spans []editorSpan, ts int64) (string, string) {
+	best, source, bestGap := "", "", int64(-1)
+	for _, sp := range spans {
+		var gap int64
+		switch {
+		case ts < sp.start:
+			gap = sp.start - ts
+		case ts > sp.end:
+			gap = ts - sp.end
+		}
+		if bestGap < 0 || gap < bestGap {
+			best, source, bestGap = sp.project, sp.source, gap
+		}
+	}
+	return best, source
+}
+
+// projectFromEditorTitle 从编辑器窗口标题中解析项目名：
+// VS Code 系为 "file - project - Visual Studio Code"，JetBrains 系为 "project – file [module]"。
+func projectFromEditorTitle(title string) string {
+	title = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(title), "●"))
+	if title == "" {
+		return ""
+	}
+	if parts := strings.Split(title, " – "); len(parts) >= 2 {
+		name := strings.TrimSpace(parts[0])
+		if i := strings.Index(name, " ["); i > 0 {
+			name = strings.TrimSpace(name[:i])
+		}
+		return name
+	}
+	parts := strings.Split(title, " - ")
+	switch {
+	case len(parts) >= 3:
+		return strings.TrimSpace(parts[len(parts)-2])
+	case len(parts) == 2 && !strings.Contains(parts[0], "."):
+		// 没有打开文件时为 "project - Visual Studio Code"
+		return strings.TrimSpace(parts[0])
+	}
+	return ""
+}
+
+// projectDisplayName 项目目录名
+func projectDisplayName(project string) string {
+	p := strings.TrimRight(strings.ReplaceAll(strings.TrimSpace(project), "\\", "/"), "/")
+	if p == "" {
+		return ""
+	}
+	return path.Base(p)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
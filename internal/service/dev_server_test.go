@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+func TestProjectFromEditorTitle(t *testing.T) {
+	cases := map[string]string{
+		"main.go - WorkMirror - Visual Studio Code":           "WorkMirror",
+		"● session.ts - web - Cursor":                         "web",
+		"api - Visual Studio Code":                            "api",
+		"main.go - Visual Studio Code":                        "",
+		"shop-backend – OrderService.java [shop-backend.api]": "shop-backend",
+		"billing [~/code/billing] – pom.xml":                  "billing",
+		"":                                                    "",
+	}
+	for title, want := range cases {
+		if got := projectFromEditorTitle(title); got != want {
+			t.Errorf("projectFromEditorTitle(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestLinkLocalBrowsing(t *testing.T) {
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC).UnixMilli()
+	at := func(min int) int64 { return base + int64(min)*60*1000 }
+
+	visits := []schema.BrowserEvent{
+		{ID: 1, Timestamp: at(1), Domain: "localhost:5173", URL: "http://localhost:5173/", Duration: 60},
+		{ID: 2, Timestamp: at(2), Domain: "127.0.0.1:8080", URL: "http://127.0.0.1:8080/api", Duration: 30},
+		{ID: 3, Timestamp: at(3), Domain: "go.dev", URL: "https://go.dev/doc", Duration: 90},
+		{ID: 4, Timestamp: at(4), Domain: "localhost:5173", URL: "http://localhost:5173/settings", Duration: 45},
+		{ID: 5, Timestamp: at(40), Domain: "localhost:3000", URL: "http://localhost:3000/", Duration: 20},
+		{ID: 6, Timestamp: at(41), Domain: "localhost:5173", Transition: schema.BrowserTransitionRedirect},
+	}
+	diffs := []schema.Diff{
+		{ID: 10, Timestamp: at(0), ProjectPath: "/home/dev/api"},
+		{ID: 11, Timestamp: at(60), ProjectPath: ""},
+	}
+	hints := []DevPortHint{{Project: "/home/dev/web", Ports: []int{5173}}}
+
+	got := linkLocalBrowsing(visits, diffs, nil, hints, nil)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 projects, got %+v", got)
+	}
+	web, api := got[0], got[1]
+	if web.Name != "web" || web.Source != ManualTestingSourcePortHint || web.Visits != 2 || web.Seconds != 105 {
+		t.Fatalf("unexpected port-hint link: %+v", web)
+	}
+	if len(web.EventIDs) != 2 || web.EventIDs[0] != 1 || web.EventIDs[1] != 4 {
+		t.Fatalf("unexpected event ids: %+v", web.EventIDs)
+	}
+	// 没有端口配置时归到时间上最近的 diff 所在项目
+	if api.Name != "api" || api.Source != ManualTestingSourceDiff || api.Visits != 2 {
+		t.Fatalf("unexpected diff link: %+v", api)
+	}
+	if len(api.Hosts) != 2 || api.Hosts[0] != "127.0.0.1:8080" || api.Hosts[1] != "localhost:3000" {
+		t.Fatalf("unexpected hosts: %+v", api.Hosts)
+	}
+}
+
+func TestLinkLocalBrowsing_windowTitleFallback(t *testing.T) {
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC).UnixMilli()
+	visits := []schema.BrowserEvent{
+		{ID: 1, Timestamp: base + 5*60*1000, Domain: "myapp.test", URL: "https://myapp.test/"},
+	}
+	windows := []schema.Event{
+		{Timestamp: base, AppName: "chrome.exe", Title: "Dashboard - Google Chrome", Duration: 600},
+		{Timestamp: base + 60*1000, AppName: "Code.exe", Title: "app.tsx - storefront - Visual Studio Code", Duration: 120},
+	}
+	got := linkLocalBrowsing(visits, nil, windows, nil, nil)
+	if len(got) != 1 || got[0].Project != "storefront" || got[0].Source != ManualTestingSourceWindowTitle {
+		t.Fatalf("unexpected window-title link: %+v", got)
+	}
+
+	// 既没有 diff 也没有编辑器窗口：保持为普通浏览
+	if got := linkLocalBrowsing(visits, nil, windows[:1], nil, nil); len(got) != 0 {
+		t.Fatalf("expected no link without project evidence, got %+v", got)
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"net"
+	"strings"
+)
+
+// 浏览域名分类
+const (
+	DomainCategoryDocs   = "docs"   // 官方文档、参考手册
+	DomainCategoryCode   = "code"   // 代码托管
+	DomainCategoryQA     = "qa"     // 问答社区
+	DomainCategoryVideo  = "video"  // 视频
+	DomainCategorySocial = "social" // 社交、论坛
+	DomainCategoryNews   = "news"   // 资讯
+	DomainCategoryChat   = "chat"   // 聊天、AI 助手
+	DomainCategoryDev    = "dev"    // localhost 与内网开发服务
+	DomainCategoryOther  = "other"
+)
+
+// DomainRule 域名分类规则；Domain 同时匹配其子域名，越具体的规则优先
+type DomainRule struct {
+	Domain        string
+	Category      string // 为空时沿用更宽泛规则的分类
+	Skill         string // 可选：阅读该域名归因到的技能
+	SkillCategory string
+}
+
+// DomainClass 域名的分类结果
+type DomainClass struct {
+	Category      string
+	Skill         string
+	SkillCategory string
+}
+
+// defaultDomainRules 内置规则，用户规则可覆盖或补充
+var defaultDomainRules = []DomainRule{
+	// 文档（带技能映射）
+	{Domain: "go.dev", Category: DomainCategoryDocs, Skill: "Go", SkillCategory: string(CategoryLanguage)},
+	{Domain: "golang.org", Category: DomainCategoryDocs, Skill: "Go", SkillCategory: string(CategoryLanguage)},
+	{Domain: "docs.python.org", Category: DomainCategoryDocs, Skill: "Python", SkillCategory: string(CategoryLanguage)},
+	{Domain: "doc.rust-lang.org", Category: DomainCategoryDocs, Skill: "Rust", SkillCategory: string(CategoryLanguage)},
+	{Domain: "docs.rs", Category: DomainCategoryDocs, Skill: "Rust", SkillCategory: string(CategoryLanguage)},
+	{Domain: "typescriptlang.org", Category: DomainCategoryDocs, Skill: "TypeScript", SkillCategory: string(CategoryLanguage)},
+	{Domain: "kotlinlang.org", Category: DomainCategoryDocs, Skill: "Kotlin", SkillCategory: string(CategoryLanguage)},
+	{Domain: "cppreference.com", Category: DomainCategoryDocs, Skill: "C++", SkillCategory: string(CategoryLanguage)},
+	{Domain: "react.dev", Category: DomainCategoryDocs, Skill: "React", SkillCategory: string(CategoryFramework)},
+	{Domain: "reactjs.org", Category: DomainCategoryDocs, Skill: "React", SkillCategory: string(CategoryFramework)},
+	{Domain: "vuejs.org", Category: DomainCategoryDocs, Skill: "Vue", SkillCategory: string(CategoryFramework)},
+	{Domain: "nextjs.org", Category: DomainCategoryDocs, Skill: "Next.js", SkillCategory: string(CategoryFramework)},
+	{Domain: "tailwindcss.com", Category: DomainCategoryDocs, Skill: "Tailwind CSS", SkillCategory: string(CategoryFramework)},
+	{Domain: "gorm.io", Category: DomainCategoryDocs, Skill: "GORM", SkillCategory: string(CategoryFramework)},
+	{Domain: "nodejs.org", Category: DomainCategoryDocs, Skill: "Node.js", SkillCategory: string(CategoryFramework)},
+	{Domain: "postgresql.org", Category: DomainCategoryDocs, Skill: "PostgreSQL", SkillCategory: string(CategoryDatabase)},
+	{Domain: "sqlite.org", Category: DomainCategoryDocs, Skill: "SQLite", SkillCategory: string(CategoryDatabase)},
+	{Domain: "redis.io", Category: DomainCategoryDocs, Skill: "Redis", SkillCategory: string(CategoryDatabase)},
+	{Domain: "docs.docker.com", Category: DomainCategoryDocs, Skill: "Docker", SkillCategory: string(CategoryDevOps)},
+	{Domain: "kubernetes.io", Category: DomainCategoryDocs, Skill: "Kubernetes", SkillCategory: string(CategoryDevOps)},
+	{Domain: "git-scm.com", Category: DomainCategoryDocs, Skill: "Git", SkillCategory: string(CategoryTool)},
+
+	// 文档（通用）
+	{Domain: "developer.mozilla.org", Category: DomainCategoryDocs},
+	{Domain: "learn.microsoft.com", Category: DomainCategoryDocs},
+	{Domain: "docs.github.com", Category: DomainCategoryDocs},
+	{Domain: "devdocs.io", Category: DomainCategoryDocs},
+	{Domain: "readthedocs.io", Category: DomainCategoryDocs},
+	{Domain: "readthedocs.org", Category: DomainCategoryDocs},
+
+	// 代码托管
+	{Domain: "github.com", Category: DomainCategoryCode},
+	{Domain: "gist.github.com", Category: DomainCategoryCode},
+	{Domain: "gitlab.com", Category: DomainCategoryCode},
+	{Domain: "bitbucket.org", Category: DomainCategoryCode},
+	{Domain: "gitee.com", Category: DomainCategoryCode},
+	{Domain: "codeberg.org", Category: DomainCategoryCode},
+	{Domain: "sourcegraph.com", Category: DomainCategoryCode},
+
+	// 问答
+	{Domain: "stackoverflow.com", Category: DomainCategoryQA},
+	{Domain: "stackexchange.com", Category: DomainCategoryQA},
+	{Domain: "superuser.com", Category: DomainCategoryQA},
+	{Domain: "serverfault.com", Category: DomainCategoryQA},
+	{Domain: "segmentfault.com", Category: DomainCategoryQA},
+	{Domain: "zhihu.com", Category: DomainCategoryQA},
+
+	// 视频
+	{Domain: "youtube.com", Category: DomainCategoryVideo},
+	{Domain: "youtu.be", Category: DomainCategoryVideo},
+	{Domain: "bilibili.com", Category: DomainCategoryVideo},
+	{Domain: "vimeo.com", Category: DomainCategoryVideo},
+	{Domain: "twitch.tv", Category: DomainCategoryVideo},
+
+	// 社交、论坛
+	{Domain: "twitter.com", Category: DomainCategorySocial},
+	{Domain: "x.com", Category: DomainCategorySocial},
+	{Domain: "reddit.com", Category: DomainCategorySocial},
+	{Domain: "weibo.com", Category: DomainCategorySocial},
+	{Domain: "linkedin.com", Category: DomainCategorySocial},
+	{Domain: "facebook.com", Category: DomainCategorySocial},
+	{Domain: "instagram.com", Category: DomainCategorySocial},
+	{Domain: "v2ex.com", Category: DomainCategorySocial},
+
+	// 资讯
+	{Domain: "news.ycombinator.com", Category: DomainCategoryNews},
+	{Domain: "lobste.rs", Category: DomainCategoryNews},
+	{Domain: "infoq.com", Category: DomainCategoryNews},
+	{Domain: "infoq.cn", Category: DomainCategoryNews},
+	{Domain: "theverge.com", Category: DomainCategoryNews},
+	{Domain: "techcrunch.com", Category: DomainCategoryNews},
+	{Domain: "36kr.com", Category: DomainCategoryNews},
+
+	// 聊天、AI 助手
+	{Domain: "chatgpt.com", Category: DomainCategoryChat},
+	{Domain: "chat.openai.com", Category: DomainCategoryChat},
+	{Domain: "claude.ai", Category: DomainCategoryChat},
+	{Domain: "gemini.google.com", Category: DomainCategoryChat},
+	{Domain: "chat.deepseek.com", Category: DomainCategoryChat},
+	{Domain: "slack.com", Category: DomainCategoryChat},
+	{Domain: "discord.com", Category: DomainCategoryChat},
+	{Domain: "web.telegram.org", Category: DomainCategoryChat},
+	{Domain: "web.whatsapp.com", Category: DomainCategoryChat},
+	{Domain: "teams.microsoft.com", Category: DomainCategoryChat},
+}
+
+var defaultDomainClassifier = NewDomainClassifier(nil)
+
+// DomainClassifier 按域名后缀匹配分类与技能
+type DomainClassifier struct {
+	rules map[string]DomainRule
+}
+
+// NewDomainClassifier 在内置规则上叠加用户规则（同一域名以用户规则为准）
+func NewDomainClassifier(custom []DomainRule) *DomainClassifier {
+	c := &DomainClassifier{rules: make(map[string]DomainRule, len(defaultDomainRules)+len(custom))}
+	for _, set := range [][]DomainRule{defaultDomainRules, custom} {
+		for _, r := range set {
+			domain := normalizeDomainHost(r.Domain)
+			if domain == "" {
+				continue
+			}
+			r.Domain = domain
+			r.Category = strings.ToLower(strings.TrimSpace(r.Category))
+			r.Skill = strings.TrimSpace(r.Skill)
+			c.rules[domain] = r
+		}
+	}
+	return c
+}
+
+// Classify 从最具体的域名往上匹配：分类与技能各取最先命中的规则；nil 时使用内置规则
+func (c *DomainClassifier) Classify(domain string) DomainClass {
+	if c == nil {
+		c = defaultDomainClassifier
+	}
+	host := normalizeDomainHost(domain)
+	if host == "" {
+		return DomainClass{Category: DomainCategoryOther}
+	}
+
+	var out DomainClass
+	for h := host; h != ""; {
+		if r, ok := c.rules[h]; ok {
+			if out.Category == "" && r.Category != "" {
+				out.Category = r.Category
+			}
+			if out.Skill == "" && r.Skill != "" {
+				out.Skill, out.SkillCategory = r.Skill, r.SkillCategory
+			}
+			if out.Category != "" && out.Skill != "" {
+				break
+			}
+		}
+		_, rest, ok := strings.Cut(h, ".")
+		if !ok {
+			break
+		}
+		h = rest
+	}
+	if out.Category == "" {
+		out.Category = DomainCategoryOther
+		if isLocalDevHost(host) {
+			out.Category = DomainCategoryDev
+		}
+	}
+	return out
+}
+
+// normalizeDomainHost 小写并去掉端口、末尾的点与 IPv6 方括号
+func normalizeDomainHost(domain string) string {
+	host := strings.ToLower(strings.TrimSpace(domain))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	return strings.TrimSuffix(host, ".")
+}
+
+// isLocalDevHost 本机、内网地址与 .localhost/.local/.test 等开发用域名
+func isLocalDevHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	for _, suffix := range []string{".localhost", ".local", ".test", ".internal"} {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified())
+}
@@ -0,0 +1,43 @@
+package service
+
+import "testing"
+
+func TestDomainClassifier_Classify(t *testing.T) {
+	c := NewDomainClassifier([]DomainRule{
+		{Domain: "GitHub.com", Category: "work"},            // 覆盖内置分类
+		{Domain: "docs.djangoproject.com", Skill: "Django"}, // 只补充技能
+		{Domain: "djangoproject.com", Category: DomainCategoryDocs},
+		{Domain: "  "},
+	})
+	cases := []struct {
+		domain, category, skill string
+	}{
+		{"pkg.go.dev", DomainCategoryDocs, "Go"},
+		{"react.dev", DomainCategoryDocs, "React"},
+		{"docs.github.com", DomainCategoryDocs, ""}, // 更具体的内置规则优先
+		{"github.com", "work", ""},
+		{"docs.djangoproject.com", DomainCategoryDocs, "Django"},
+		{"stackoverflow.com", DomainCategoryQA, ""},
+		{"www.youtube.com:443", DomainCategoryVideo, ""},
+		{"x.com", DomainCategorySocial, ""},
+		{"dropbox.com", DomainCategoryOther, ""},
+		{"localhost:3000", DomainCategoryDev, ""},
+		{"127.0.0.1:8080", DomainCategoryDev, ""},
+		{"[::1]:5173", DomainCategoryDev, ""},
+		{"192.168.1.20", DomainCategoryDev, ""},
+		{"app.localhost", DomainCategoryDev, ""},
+		{"8.8.8.8", DomainCategoryOther, ""},
+		{"", DomainCategoryOther, ""},
+	}
+	for _, tc := range cases {
+		got := c.Classify(tc.domain)
+		if got.Category != tc.category || got.Skill != tc.skill {
+			t.Errorf("Classify(%q) = %+v, want category=%q skill=%q", tc.domain, got, tc.category, tc.skill)
+		}
+	}
+
+	var builtin *DomainClassifier
+	if got := builtin.Classify("github.com"); got.Category != DomainCategoryCode {
+		t.Fatalf("nil classifier should use built-in rules, got %+v", got)
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+// editorHeartbeatTimeoutMs 相邻心跳间隔不超过该值时视为持续编码（与 WakaTime 默认 keystroke timeout 一致）
+const editorHeartbeatTimeoutMs = 15 * 60 * 1000
+
+// isEditorHeartbeat 是否为编辑器插件心跳事件（含从 WakaTime 导入的历史心跳）
+func isEditorHeartbeat(e schema.Event) bool {
+	return e.Source == schema.EventSourceEditor || e.Source == schema.EventSourceWakaTime
+}
+
+// editorHeartbeatDurations 按 WakaTime 的计时方式为心跳推断时长：
+// 每条心跳计到下一条心跳为止，间隔超过超时不计；最后一条心跳不计。
+// 返回与 events 下标一一对应的秒数，非心跳事件为 0。
+func editorHeartbeatDurations(events []schema.Event) []int {
+	out := make([]int, len(events))
+	idx := make([]int, 0, len(events))
+	for i, e := range events {
+		if isEditorHeartbeat(e) && e.Timestamp > 0 {
+			idx = append(idx, i)
+		}
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return events[idx[a]].Timestamp < events[idx[b]].Timestamp })
+	for k := 0; k+1 < len(idx); k++ {
+		gap := events[idx[k+1]].Timestamp - events[idx[k]].Timestamp
+		if gap > 0 && gap <= editorHeartbeatTimeoutMs {
+			out[idx[k]] = int(gap / 1000)
+		}
+	}
+	return out
+}
+
+// editorFilesFromEvents 把 [start, end] 内的编辑器心跳按 (项目, 文件) 聚合，按时长降序
+func editorFilesFromEvents(events []schema.Event, start, end int64) []schema.SessionEditorFile {
+	durations := editorHeartbeatDurations(events)
+	byKey := make(map[string]*schema.SessionEditorFile)
+	var order []string
+	for i, e := range events {
+		if !isEditorHeartbeat(e) || e.Timestamp < start || e.Timestamp > end {
+			continue
+		}
+		file := editorMetaString(e.Metadata, schema.EditorMetaFile)
+		if file == "" {
+			continue
+		}
+		project := editorMetaString(e.Metadata, schema.EditorMetaProject)
+		key := strings.ToLower(project) + "\n" + file
+		it, ok := byKey[key]
+		if !ok {
+			it = &schema.SessionEditorFile{Project: project, File: file, Editor: e.AppName}
+			byKey[key] = it
+			order = append(order, key)
+		}
+		// 会话结束后的间隔不计入本会话
+		sec := durations[i]
+		if rest := int((end - e.Timestamp) / 1000); sec > rest {
+			sec = rest
+		}
+		it.Seconds += sec
+		it.Heartbeats++
+		if w, _ := e.Metadata[schema.EditorMetaIsWrite].(bool); w {
+			it.Writes++
+		}
+		if v := editorMetaString(e.Metadata, schema.EditorMetaLanguage); v != "" {
+			it.Language = v
+		}
+		if v := editorMetaString(e.Metadata, schema.EditorMetaBranch); v != "" {
+			it.Branch = v
+		}
+	}
+
+	out := make([]schema.SessionEditorFile, 0, len(order))
+	for _, k := range order {
+		out = append(out, *byKey[k])
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Seconds != out[j].Seconds {
+			return out[i].Seconds > out[j].Seconds
+		}
+		return out[i].Heartbeats > out[j].Heartbeats
+	})
+	return out
+}
+
+// mergeEditorFiles 合并晚到的心跳聚合：同一文件取较大的统计值，新文件追加
+func mergeEditorFiles(cur, incoming []schema.SessionEditorFile) ([]schema.SessionEditorFile, bool) {
+	if len(incoming) == 0 {
+		return cur, false
+	}
+	out := append([]schema.SessionEditorFile(nil), cur...)
+	pos := make(map[string]int, len(out))
+	for i, f := range out {
+		pos[strings.ToLower(f.Project)+"\n"+f.File] = i
+	}
+	changed := false
+	for _, f := range incoming {
+		i, ok := pos[strings.ToLower(f.Project)+"\n"+f.File]
+		if !ok {
+			pos[strings.ToLower(f.Project)+"\n"+f.File] = len(out)
+			out = append(out, f)
+			changed = true
+			continue
+		}
+		if f.Heartbeats > out[i].Heartbeats || f.Seconds > out[i].Seconds {
+			out[i] = f
+			changed = true
+		}
+	}
+	return out, changed
+}
+
+func editorMetaString(meta schema.JSONMap, key string) string {
+	if meta == nil {
+		return ""
+	}
+	s, _ := meta[key].(string)
+	return strings.TrimSpace(s)
+}
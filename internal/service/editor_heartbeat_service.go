@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+// ErrInvalidHeartbeat 心跳缺少必填字段或时间不合法
+var ErrInvalidHeartbeat = errors.New("心跳无效")
+
+const (
+	editorHeartbeatMaxFuture = 10 * time.Minute // 容忍插件与本机的时钟偏差
+	editorRecentKeysMax      = 4096
+)
+
+// EditorHeartbeat 编辑器插件上报的一条心跳（WakaTime heartbeat 字段子集）
+type EditorHeartbeat struct {
+	Entity    string
+	Type      string // file | app | domain，默认 file
+	Category  string
+	Time      float64 // Unix 秒（可带小数）
+	Project   string
+	Branch    string
+	Language  string
+	Lines     int
+	LineNo    int
+	IsWrite   bool
+	UserAgent string
+}
+
+// EditorHeartbeatResult 单条心跳的处理结果，供批量接口逐条回写状态
+type EditorHeartbeatResult struct {
+	Event *schema.Event // 入库（或去重命中）的事件；Err 非空时为 nil
+	Err   error
+}
+
+// EditorHeartbeatService 接收编辑器插件心跳并写入事件表（source=editor）。
+// 插件离线重试会重发同一心跳，按 (entity, time, is_write) 在进程内去重。
+type EditorHeartbeatService struct {
+	repo        EventRepository
+	sanitizer   *privacy.Sanitizer
+	onPersisted func(count int)
+	now         func() time.Time
+
+	mu     sync.Mutex
+	recent map[string]struct{}
+}
+
+// NewEditorHeartbeatService 创建编辑器心跳服务
+func NewEditorHeartbeatService(repo EventRepository) *EditorHeartbeatService {
+	return &EditorHeartbeatService{
+		repo:   repo,
+		now:    time.Now,
+		recent: make(map[string]struct{}),
+	}
+}
+
+// SetSanitizer 设置标题/路径脱敏（可选）
+func (s *EditorHeartbeatService) SetSanitizer(z *privacy.Sanitizer) {
+	s.sanitizer = z
+}
+
+// SetOnPersisted 设置入库后的回调函数
+func (s *EditorHeartbeatService) SetOnPersisted(fn func(count int)) {
+	s.onPersisted = fn
+}
+
+// Record 校验并写入一批心跳；返回与输入等长的逐条结果。
+// 单条无效不影响其余心跳，只有写库失败才返回 error。
+func (s *EditorHeartbeatService) Record(ctx context.Context, beats []EditorHeartbeat) ([]EditorHeartbeatResult, error) {
+	results := make([]EditorHeartbeatResult, len(beats))
+	events := make([]schema.Event, 0, len(beats))
+	eventIdx := make([]int, 0, len(beats))
+	keys := make([]string, 0, len(beats))
+
+	s.mu.Lock()
+	batch := make(map[string]struct{}, len(beats))
+	for i, hb := range beats {
+		ev, err := s.toEvent(hb)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Event = ev
+		key := editorHeartbeatKey(ev)
+		if _, dup := s.recent[key]; dup {
+			continue
+		}
+		if _, dup := batch[key]; dup {
+			continue
+		}
+		batch[key] = struct{}{}
+		events = append(events, *ev)
+		eventIdx = append(eventIdx, i)
+		keys = append(keys, key)
+	}
+	s.mu.Unlock()
+
+	if len(events) == 0 {
+		return results, nil
+	}
+	if err := s.repo.BatchInsert(ctx, events); err != nil {
+		return nil, fmt.Errorf("写入编辑器心跳失败: %w", err)
+	}
+	for j, i := range eventIdx {
+		results[i].Event = &events[j]
+	}
+
+	s.mu.Lock()
+	if len(s.recent)+len(keys) > editorRecentKeysMax {
+		s.recent = make(map[string]struct{}, len(keys))
+	}
+	for _, k := range keys {
+		s.recent[k] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	if s.onPersisted != nil {
+		s.onPersisted(len(events))
+	}
+	return results, nil
+}
+
+// TodaySeconds 今日编辑器编码时长（秒），按心跳间隔推断
+func (s *EditorHeartbeatService) TodaySeconds(ctx context.Context) (int, error) {
+	now := s.now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	events, err := s.repo.GetByTimeRange(ctx, start.UnixMilli(), now.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, sec := range editorHeartbeatDurations(events) {
+		total += sec
+	}
+	return total, nil
+}
+
+func (s *EditorHeartbeatService) toEvent(hb EditorHeartbeat) (*schema.Event, error) {
+	ev, err := editorHeartbeatEvent(hb, s.sanitizer)
+	if err != nil {
+		return nil, err
+	}
+	if ev.Timestamp > s.now().Add(editorHeartbeatMaxFuture).UnixMilli() {
+		return nil, fmt.Errorf("%w: time 晚于当前时间", ErrInvalidHeartbeat)
+	}
+	return ev, nil
+}
+
+// editorHeartbeatEvent 把心跳转换为 source=editor 的事件：校验必填字段，路径与项目名脱敏
+func editorHeartbeatEvent(hb EditorHeartbeat, z *privacy.Sanitizer) (*schema.Event, error) {
+	entity := strings.TrimSpace(hb.Entity)
+	if entity == "" {
+		return nil, fmt.Errorf("%w: entity 为空", ErrInvalidHeartbeat)
+	}
+	if hb.Time <= 0 || math.IsNaN(hb.Time) || math.IsInf(hb.Time, 0) {
+		return nil, fmt.Errorf("%w: time 无效", ErrInvalidHeartbeat)
+	}
+	ts := int64(hb.Time * 1000)
+
+	typ := strings.ToLower(strings.TrimSpace(hb.Type))
+	if typ == "" {
+		typ = "file"
+	}
+	project := strings.TrimSpace(hb.Project)
+	file := entity
+	if typ == "file" {
+		file = editorRelativeFile(entity, project)
+	}
+	file = z.SanitizeText(file)
+	project = z.SanitizeText(project)
+
+	title := file
+	if project != "" {
+		title = file + " · " + project
+	}
+
+	meta := schema.JSONMap{
+		schema.EditorMetaEntity:  z.SanitizeText(entity),
+		schema.EditorMetaFile:    file,
+		schema.EditorMetaType:    typ,
+		schema.EditorMetaIsWrite: hb.IsWrite,
+	}
+	putMetaString(meta, schema.EditorMetaProject, project)
+	putMetaString(meta, schema.EditorMetaBranch, z.SanitizeText(hb.Branch))
+	putMetaString(meta, schema.EditorMetaLanguage, strings.TrimSpace(hb.Language))
+	putMetaString(meta, schema.EditorMetaCategory, strings.ToLower(strings.TrimSpace(hb.Category)))
+	putMetaString(meta, schema.EditorMetaPlugin, strings.TrimSpace(hb.UserAgent))
+	if hb.LineNo > 0 {
+		meta[schema.EditorMetaLineNo] = hb.LineNo
+	}
+	if hb.Lines > 0 {
+		meta[schema.EditorMetaLines] = hb.Lines
+	}
+
+	return &schema.Event{
+		Timestamp: ts,
+		Source:    schema.EventSourceEditor,
+		AppName:   EditorNameFromUserAgent(hb.UserAgent),
+		Title:     title,
+		Metadata:  meta,
+	}, nil
+}
+
+func putMetaString(meta schema.JSONMap, key, value string) {
+	if value = strings.TrimSpace(value); value != "" {
+		meta[key] = value
+	}
+}
+
+func editorHeartbeatKey(ev *schema.Event) string {
+	entity, _ := ev.Metadata[schema.EditorMetaEntity].(string)
+	isWrite, _ := ev.Metadata[schema.EditorMetaIsWrite].(bool)
+	return fmt.Sprintf("%d\n%t\n%s", ev.Timestamp, isWrite, entity)
+}
+
+// EditorNameFromUserAgent 从插件 user agent 中取编辑器名：
+// "wakatime/v1.90.0 (linux-6.1) go1.22 vscode/1.89.0 vscode-wakatime/24.5.0" -> "vscode"
+func EditorNameFromUserAgent(ua string) string {
+	for _, tok := range strings.Fields(ua) {
+		name, _, ok := strings.Cut(tok, "/")
+		if !ok {
+			continue
+		}
+		if editor, found := strings.CutSuffix(strings.ToLower(name), "-wakatime"); found && editor != "" {
+			return editor
+		}
+	}
+	return "editor"
+}
+
+// editorRelativeFile 把插件上报的绝对路径截为项目内路径（按路径中最后一个与项目同名的目录）；
+// 找不到项目目录时只保留文件名，避免把用户目录结构写进标题。
+func editorRelativeFile(entity, project string) string {
+	p := strings.ReplaceAll(entity, "\\", "/")
+	if project != "" {
+		parts := strings.Split(p, "/")
+		for i := len(parts) - 2; i >= 0; i-- {
+			if strings.EqualFold(parts[i], project) {
+				return strings.Join(parts[i+1:], "/")
+			}
+		}
+	}
+	return path.Base(p)
+}
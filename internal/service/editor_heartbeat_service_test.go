@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/repository"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+type fakeEventRepoForEditor struct {
+	inserted []schema.Event
+	nextID   int64
+}
+
+func (f *fakeEventRepoForEditor) BatchInsert(ctx context.Context, events []schema.Event) error {
+	for i := range events {
+		f.nextID++
+		events[i].ID = f.nextID
+	}
+	f.inserted = append(f.inserted, events...)
+	return nil
+}
+func (f *fakeEventRepoForEditor) GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.Event, error) {
+	out := make([]schema.Event, 0)
+	for _, e := range f.inserted {
+		if e.Timestamp >= startTime && e.Timestamp <= endTime {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+func (f *fakeEventRepoForEditor) GetByDate(ctx context.Context, date string) ([]schema.Event, error) {
+	return nil, nil
+}
+func (f *fakeEventRepoForEditor) GetAppStats(ctx context.Context, startTime, endTime int64) ([]repository.AppStat, error) {
+	return nil, nil
+}
+func (f *fakeEventRepoForEditor) Count(ctx context.Context) (int64, error) { return 0, nil }
+
+func TestEditorHeartbeatService_RecordConvertsAndDedupes(t *testing.T) {
+	repo := &fakeEventRepoForEditor{}
+	svc := NewEditorHeartbeatService(repo)
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	svc.now = func() time.Time { return now }
+	persisted := 0
+	svc.SetOnPersisted(func(count int) { persisted += count })
+
+	ts := float64(now.Add(-time.Minute).UnixMilli()) / 1000
+	beat := EditorHeartbeat{
+		Entity:    `C:\Users\me\src\WorkMirror\internal\service\session_service.go`,
+		Time:      ts,
+		Project:   "WorkMirror",
+		Branch:    "main",
+		Language:  "Go",
+		LineNo:    42,
+		IsWrite:   true,
+		UserAgent: "wakatime/v1.90.0 (windows-10) go1.22 vscode/1.89.0 vscode-wakatime/24.5.0",
+	}
+	results, err := svc.Record(context.Background(), []EditorHeartbeat{
+		beat,
+		beat, // 同一批内重复
+		{Entity: "", Time: ts},
+		{Entity: "a.go", Time: float64(now.Add(time.Hour).Unix())},
+	})
+	if err != nil {
+		t.Fatalf("Record error: %v", err)
+	}
+	if len(repo.inserted) != 1 || persisted != 1 {
+		t.Fatalf("inserted=%d persisted=%d, want 1", len(repo.inserted), persisted)
+	}
+	ev := repo.inserted[0]
+	if ev.Source != schema.EventSourceEditor || ev.AppName != "vscode" {
+		t.Fatalf("source=%q app=%q", ev.Source, ev.AppName)
+	}
+	if ev.Title != "internal/service/session_service.go · WorkMirror" {
+		t.Fatalf("title=%q", ev.Title)
+	}
+	if ev.Duration != 0 || ev.Timestamp != now.Add(-time.Minute).UnixMilli() {
+		t.Fatalf("timestamp=%d duration=%d", ev.Timestamp, ev.Duration)
+	}
+	if ev.Metadata[schema.EditorMetaBranch] != "main" || ev.Metadata[schema.EditorMetaIsWrite] != true || ev.Metadata[schema.EditorMetaLineNo] != 42 {
+		t.Fatalf("metadata=%v", ev.Metadata)
+	}
+	if results[0].Event == nil || results[0].Event.ID != 1 || results[1].Err != nil {
+		t.Fatalf("results[0..1]=%+v %+v", results[0], results[1])
+	}
+	if !errors.Is(results[2].Err, ErrInvalidHeartbeat) || !errors.Is(results[3].Err, ErrInvalidHeartbeat) {
+		t.Fatalf("invalid heartbeats should fail: %v / %v", results[2].Err, results[3].Err)
+	}
+
+	// 插件离线队列重发同一心跳：不重复入库
+	if _, err := svc.Record(context.Background(), []EditorHeartbeat{beat}); err != nil {
+		t.Fatalf("Record error: %v", err)
+	}
+	if len(repo.inserted) != 1 {
+		t.Fatalf("resent heartbeat inserted again: %d", len(repo.inserted))
+	}
+}
+
+func TestEditorNameFromUserAgent(t *testing.T) {
+	cases := map[string]string{
+		"wakatime/v1.90.0 (linux-6.1) go1.22 vscode/1.89.0 vscode-wakatime/24.5.0":   "vscode",
+		"wakatime/v1.90.0 (darwin) go1.22 IntelliJ/2024.1 jetbrains-wakatime/15.0.0": "jetbrains",
+		"curl/8.0": "editor",
+		"":         "editor",
+	}
+	for ua, want := range cases {
+		if got := EditorNameFromUserAgent(ua); got != want {
+			t.Errorf("EditorNameFromUserAgent(%q)=%q, want %q", ua, got, want)
+		}
+	}
+}
+
+func TestTopWindowTitleInfosFromEvents_EditorHeartbeatDurations(t *testing.T) {
+	base := int64(1_700_000_000_000)
+	beat := func(ts int64, title string) schema.Event {
+		return schema.Event{Timestamp: ts, Source: schema.EventSourceEditor, AppName: "vscode", Title: title}
+	}
+	events := []schema.Event{
+		{Timestamp: base, Source: "window", AppName: "Code.exe", Title: "main.go - app - Visual Studio Code", Duration: 60},
+		beat(base, "main.go · app"),
+		beat(base+120*1000, "main.go · app"),
+		beat(base+180*1000, "util.go · app"),
+		beat(base+180*1000+20*60*1000, "main.go · app"), // 超过超时：前一条不计时
+	}
+	infos := TopWindowTitleInfosFromEvents(events, 10)
+	got := make(map[string]int)
+	for _, it := range infos {
+		got[it.Title] = it.DurationSec
+	}
+	if got["main.go · app"] != 180 || got["util.go · app"] != 0 || got["main.go - app - Visual Studio Code"] != 60 {
+		t.Fatalf("durations=%v", got)
+	}
+}
+
+func TestBuildSessionsForRange_EditorHeartbeatsAggregatedByFile(t *testing.T) {
+	ctx := context.Background()
+	baseTs := time.Now().Truncate(time.Hour).UnixMilli()
+
+	beat := func(offsetSec int64, file string, write bool) schema.Event {
+		return schema.Event{
+			Timestamp: baseTs + offsetSec*1000,
+			Source:    schema.EventSourceEditor,
+			AppName:   "vscode",
+			Title:     file + " · app",
+			Metadata: schema.JSONMap{
+				schema.EditorMetaFile:     file,
+				schema.EditorMetaProject:  "app",
+				schema.EditorMetaLanguage: "Go",
+				schema.EditorMetaIsWrite:  write,
+			},
+		}
+	}
+	// 没有窗口事件（如 headless）：心跳本身既是活动点也是证据
+	events := []schema.Event{
+		beat(0, "main.go", false),
+		beat(60, "main.go", true),
+		beat(120, "util.go", false),
+		beat(150, "main.go", false),
+	}
+
+	sessionRepo := &fakeSessionRepoForSession{}
+	svc := NewSessionService(
+		fakeEventRepoForSession{events: events},
+		fakeDiffRepoForSession{},
+		fakeBrowserRepoForSession{},
+		sessionRepo,
+		nil,
+		&SessionServiceConfig{IdleGapMinutes: 6, MinSessionMinutes: 5},
+	)
+	if _, err := svc.BuildSessionsForRange(ctx, baseTs, baseTs+10*60*1000); err != nil {
+		t.Fatalf("BuildSessionsForRange error: %v", err)
+	}
+	if len(sessionRepo.sessions) != 1 {
+		t.Fatalf("persisted=%d, want 1", len(sessionRepo.sessions))
+	}
+	files := schema.GetSessionEditorFiles(sessionRepo.sessions[0].Metadata)
+	if len(files) != 2 {
+		t.Fatalf("editor_files=%+v, want 2 files", files)
+	}
+	if files[0].File != "main.go" || files[0].Seconds != 120 || files[0].Heartbeats != 3 || files[0].Writes != 1 {
+		t.Fatalf("main.go=%+v", files[0])
+	}
+	if files[1].File != "util.go" || files[1].Seconds != 30 {
+		t.Fatalf("util.go=%+v", files[1])
+	}
+}
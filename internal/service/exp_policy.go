@@ -11,9 +11,16 @@ type ExpPolicy interface {
 	CalcDiffExp(diffs []schema.Diff) float64
 }
 
+// ReadingExpPolicy 可选：实现后技能相关的阅读时长也计入经验
+type ReadingExpPolicy interface {
+	CalcReadingExp(durationSec int) float64
+}
+
 // DefaultExpPolicy 默认经验策略：行数主导 + 低成本修正 + clamp
 type DefaultExpPolicy struct{}
 
+const readingExpMinSec = 60 // 停留不足 1 分钟视为扫读，不计经验
+
 // CalcDiffExp 根据 Diff 列表计算经验值
 func (p DefaultExpPolicy) CalcDiffExp(diffs []schema.Diff) float64 {
 	if len(diffs) == 0 {
@@ -42,6 +49,14 @@ func (p DefaultExpPolicy) CalcDiffExp(diffs []schema.Diff) float64 {
 	return clamp(exp, 1, 20)
 }
 
+// CalcReadingExp 每 10 分钟阅读 1 点经验，单次访问最多 3 点（远低于一次代码改动）
+func (p DefaultExpPolicy) CalcReadingExp(durationSec int) float64 {
+	if durationSec < readingExpMinSec {
+		return 0
+	}
+	return clamp(float64(durationSec)/600, 0, 3)
+}
+
 // countHunks 统计 diff 中的 hunk 数量
 func countHunks(diffContent string) int {
 	if diffContent == "" {
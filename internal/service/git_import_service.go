@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/ai"
+	"github.com/yuqie6/WorkMirror/internal/collector"
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+// ErrGitImportRunning 已有导入任务在运行
+var ErrGitImportRunning = errors.New("Git 历史导入正在进行")
+
+// 导入阶段
+const (
+	GitImportPhaseScanning  = "scanning"
+	GitImportPhaseAnalyzing = "analyzing"
+	GitImportPhaseSessions  = "sessions"
+	GitImportPhaseDone      = "done"
+)
+
+// GitImportRequest 历史导入参数
+type GitImportRequest struct {
+	Repos         []string // 仓库根目录
+	Since         time.Time
+	Until         time.Time
+	AuthorEmail   string // 为空时使用各仓库配置的 user.email
+	AnalyzeWithAI bool   // 导入后调用 AI 分析；否则提交说明作为解读，不进入 AI 分析队列
+
+	OnProgress func(GitImportProgress) // 可选：进度回调（节流，阶段变化与结束时必定回调）
+}
+
+// GitImportProgress 导入进度
+type GitImportProgress struct {
+	Running         bool
+	Phase           string
+	Repo            string // 当前处理的仓库
+	ReposDone       int
+	ReposTotal      int
+	CommitsScanned  int
+	CommitsImported int
+	CommitsSkipped  int // 此前已导入
+	DiffsCreated    int
+	DiffsAnalyzed   int
+	SessionsCreated int
+	StartedAt       int64
+	FinishedAt      int64
+	Error           string
+}
+
+// SessionRebuilder 按日期重新切分会话
+type SessionRebuilder interface {
+	RebuildSessionsForDate(ctx context.Context, date string) (int, error)
+}
+
+// PendingDiffAnalyzer 分析尚无解读的 Diff
+type PendingDiffAnalyzer interface {
+	AnalyzePendingDiffs(ctx context.Context, limit int) (int, error)
+}
+
+type gitHistoryReader func(ctx context.Context, repo string, opts collector.GitHistoryOptions, fn func(*collector.HistoricalCommit) error) error
+
+// GitImportService 从 Git 历史生成 Diff、技能与会话（新安装时填充历史数据）。
+// 以 (项目, 提交哈希) 去重，可重复执行；实时采集开始之后的提交不导入，避免与实时 Diff 重复计数。
+type GitImportService struct {
+	diffRepo   GitImportDiffRepository
+	commitRepo CommitRepository
+	skills     *SkillService
+	sessions   SessionRebuilder
+	analyzer   PendingDiffAnalyzer // 可选
+	sanitizer  *privacy.Sanitizer  // 可选
+	extensions []string
+	history    gitHistoryReader
+
+	mu         sync.Mutex
+	progress   GitImportProgress
+	onProgress func(GitImportProgress)
+	lastNotify time.Time
+}
+
+// NewGitImportService 创建导入服务；extensions 与 diff.extensions 一致
+func NewGitImportService(
+	diffRepo GitImportDiffRepository,
+	commitRepo CommitRepository,
+	skills *SkillService,
+	sessions SessionRebuilder,
+	extensions []string,
+) *GitImportService {
+	return &GitImportService{
+		diffRepo:   diffRepo,
+		commitRepo: commitRepo,
+		skills:     skills,
+		sessions:   sessions,
+		extensions: extensions,
+		history:    collector.ReadGitHistory,
+	}
+}
+
+// SetAnalyzer 设置 AI 分析（可选）
+func (s *GitImportService) SetAnalyzer(a PendingDiffAnalyzer) {
+	s.analyzer = a
+}
+
+// SetSanitizer 设置提交说明脱敏（可选）
+func (s *GitImportService) SetSanitizer(z *privacy.Sanitizer) {
+	s.sanitizer = z
+}
+
+// Progress 返回当前（或最近一次）导入的进度
+func (s *GitImportService) Progress() GitImportProgress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.progress
+}
+
+// Start 在后台执行导入；已有任务运行时返回 ErrGitImportRunning
+func (s *GitImportService) Start(ctx context.Context, req GitImportRequest) error {
+	if err := s.begin(req); err != nil {
+		return err
+	}
+	go func() {
+		_ = s.run(ctx, req)
+	}()
+	return nil
+}
+
+// Run 同步执行导入
+func (s *GitImportService) Run(ctx context.Context, req GitImportRequest) (GitImportProgress, error) {
+	if err := s.begin(req); err != nil {
+		return s.Progress(), err
+	}
+	err := s.run(ctx, req)
+	return s.Progress(), err
+}
+
+func (s *GitImportService) begin(req GitImportRequest) error {
+	if len(req.Repos) == 0 {
+		return fmt.Errorf("没有可导入的 Git 仓库")
+	}
+	if !req.Since.IsZero() && !req.Until.IsZero() && !req.Since.Before(req.Until) {
+		return fmt.Errorf("导入起始时间必须早于结束时间")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.progress.Running {
+		return ErrGitImportRunning
+	}
+	s.progress = GitImportProgress{
+		Running:    true,
+		Phase:      GitImportPhaseScanning,
+		ReposTotal: len(req.Repos),
+		StartedAt:  time.Now().UnixMilli(),
+	}
+	s.onProgress = req.OnProgress
+	s.lastNotify = time.Time{}
+	return nil
+}
+
+func (s *GitImportService) run(ctx context.Context, req GitImportRequest) (err error) {
+	defer func() {
+		s.update(true, func(p *GitImportProgress) {
+			p.Running = false
+			p.Phase = GitImportPhaseDone
+			p.Repo = ""
+			p.FinishedAt = time.Now().UnixMilli()
+			if err != nil {
+				p.Error = err.Error()
+			}
+		})
+		if err != nil {
+			slog.Warn("Git 历史导入失败", "error", err)
+		} else {
+			slog.Info("Git 历史导入完成", "progress", s.Progress())
+		}
+	}()
+
+	dates := make(map[string]struct{})
+	for _, repo := range req.Repos {
+		s.update(true, func(p *GitImportProgress) { p.Repo = repo })
+		if err := s.importRepo(ctx, repo, req, dates); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// 单个仓库失败（非 Git 目录、git 不可用等）不影响其它仓库
+			slog.Warn("导入仓库历史失败", "repo", repo, "error", err)
+			s.update(false, func(p *GitImportProgress) { p.Error = err.Error() })
+		}
+		s.update(true, func(p *GitImportProgress) { p.ReposDone++ })
+	}
+
+	if req.AnalyzeWithAI && s.analyzer != nil {
+		s.update(true, func(p *GitImportProgress) { p.Phase = GitImportPhaseAnalyzing })
+		for ctx.Err() == nil {
+			n, err := s.analyzer.AnalyzePendingDiffs(ctx, 50)
+			if err != nil || n == 0 {
+				break
+			}
+			s.update(false, func(p *GitImportProgress) { p.DiffsAnalyzed += n })
+		}
+	}
+
+	// 已有会话的日期需重新切分（提升切分版本），否则导入的证据会产生与旧会话重叠的新会话
+	s.update(true, func(p *GitImportProgress) { p.Phase = GitImportPhaseSessions })
+	sorted := make([]string, 0, len(dates))
+	for d := range dates {
+		sorted = append(sorted, d)
+	}
+	sort.Strings(sorted)
+	for _, d := range sorted {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		created, err := s.sessions.RebuildSessionsForDate(ctx, d)
+		if err != nil {
+			return fmt.Errorf("切分会话失败 (%s): %w", d, err)
+		}
+		s.update(false, func(p *GitImportProgress) { p.SessionsCreated += created })
+	}
+	return nil
+}
+
+// importRepo 导入单个仓库；dates 收集有新 Diff 的日期
+func (s *GitImportService) importRepo(ctx context.Context, repo string, req GitImportRequest, dates map[string]struct{}) error {
+	imported, err := s.diffRepo.GetImportedCommitHashes(ctx, repo)
+	if err != nil {
+		return err
+	}
+	until := req.Until
+	firstLive, err := s.diffRepo.GetFirstLiveTimestamp(ctx, repo)
+	if err != nil {
+		return err
+	}
+	if firstLive > 0 && (until.IsZero() || time.UnixMilli(firstLive).Before(until)) {
+		until = time.UnixMilli(firstLive)
+	}
+
+	opts := collector.GitHistoryOptions{
+		Since:       req.Since,
+		Until:       until,
+		AuthorEmail: req.AuthorEmail,
+		Extensions:  s.extensions,
+	}
+	return s.history(ctx, repo, opts, func(hc *collector.HistoricalCommit) error {
+		s.update(false, func(p *GitImportProgress) { p.CommitsScanned++ })
+		commit := hc.Commit
+		if _, ok := imported[commit.Hash]; ok {
+			s.update(false, func(p *GitImportProgress) { p.CommitsSkipped++ })
+			return nil
+		}
+		if s.sanitizer != nil {
+			commit.Subject = s.sanitizer.SanitizeText(commit.Subject)
+			commit.Body = s.sanitizer.SanitizeText(commit.Body)
+		}
+
+		diffs := s.commitDiffs(repo, hc, req.AnalyzeWithAI)
+		if len(diffs) == 0 {
+			// 没有受监控类型的文件（文档、配置等）
+			return nil
+		}
+		if _, err := s.commitRepo.BatchInsert(ctx, []*schema.GitCommit{commit}); err != nil {
+			return err
+		}
+		if err := s.diffRepo.CreateBatch(ctx, diffs); err != nil {
+			return err
+		}
+		imported[commit.Hash] = struct{}{}
+		s.detectSkillsOffline(ctx, diffs)
+		dates[time.UnixMilli(commit.Timestamp).Format("2006-01-02")] = struct{}{}
+		s.update(false, func(p *GitImportProgress) {
+			p.CommitsImported++
+			p.DiffsCreated += len(diffs)
+		})
+		return nil
+	})
+}
+
+// commitDiffs 把提交中的文件改动转换为 Diff 记录
+func (s *GitImportService) commitDiffs(repo string, hc *collector.HistoricalCommit, analyzeWithAI bool) []*schema.Diff {
+	commit := hc.Commit
+	insight := ""
+	if !analyzeWithAI {
+		insight = commit.Subject
+	}
+	diffs := make([]*schema.Diff, 0, len(hc.Files))
+	for _, f := range hc.Files {
+		if f.Diff == "" && f.LinesAdded == 0 && f.LinesDeleted == 0 {
+			continue
+		}
+		lang := collector.GetLanguageFromExt(strings.ToLower(filepath.Ext(f.Path)))
+		fileInsight := insight
+		if f.Diff == "" {
+			// 超出大小上限：只保留增删行数，没有内容可供 AI 分析
+			fileInsight = commit.Subject
+		}
+		d := &schema.Diff{
+			Timestamp:    commit.Timestamp,
+			FilePath:     filepath.Join(repo, filepath.FromSlash(f.Path)),
+			FileName:     filepath.Base(f.Path),
+			Language:     lang,
+			DiffContent:  f.Diff,
+			LinesAdded:   f.LinesAdded,
+			LinesDeleted: f.LinesDeleted,
+			AIInsight:    fileInsight,
+			ProjectPath:  repo,
+			IsGitRepo:    true,
+			CommitHash:   commit.Hash,
+		}
+		if skill, ok := offlineDiffSkill(lang); ok {
+			d.SkillsDetected = schema.JSONArray{skill.Name}
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs
+}
+
+// detectSkillsOffline 按语言归因技能（不依赖 AI）。逐个 Diff 记贡献（证据为该 Diff），
+// 之后 AI 分析同一 Diff 得到相同技能时按证据去重，不会重复加经验
+func (s *GitImportService) detectSkillsOffline(ctx context.Context, diffs []*schema.Diff) {
+	if s.skills == nil {
+		return
+	}
+	for _, d := range diffs {
+		skill, ok := offlineDiffSkill(d.Language)
+		if !ok {
+			continue
+		}
+		if err := s.skills.UpdateSkillsFromDiffsWithCategory(ctx, []schema.Diff{*d}, []ai.SkillWithCategory{skill}); err != nil {
+			slog.Warn("更新技能失败", "file", d.FileName, "error", err)
+		}
+	}
+}
+
+// offlineDiffSkill 由文件语言推断技能
+func offlineDiffSkill(language string) (ai.SkillWithCategory, bool) {
+	switch language {
+	case "", "Unknown":
+		return ai.SkillWithCategory{}, false
+	case "React", "Vue":
+		return ai.SkillWithCategory{Name: language, Category: "framework"}, true
+	default:
+		return ai.SkillWithCategory{Name: language, Category: "language"}, true
+	}
+}
+
+// update 修改进度并按需回调；force=false 时回调节流到每 500ms 一次
+func (s *GitImportService) update(force bool, fn func(p *GitImportProgress)) {
+	s.mu.Lock()
+	fn(&s.progress)
+	snapshot, cb := s.progress, s.onProgress
+	if cb == nil || (!force && time.Since(s.lastNotify) < 500*time.Millisecond) {
+		s.mu.Unlock()
+		return
+	}
+	s.lastNotify = time.Now()
+	s.mu.Unlock()
+	cb(snapshot)
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/collector"
+	"github.com/yuqie6/WorkMirror/internal/repository"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+type fakeGitImportDiffRepo struct {
+	diffs     []*schema.Diff
+	firstLive int64
+	nextID    int64
+}
+
+func (f *fakeGitImportDiffRepo) CreateBatch(ctx context.Context, diffs []*schema.Diff) error {
+	for _, d := range diffs {
+		f.nextID++
+		d.ID = f.nextID
+		f.diffs = append(f.diffs, d)
+	}
+	return nil
+}
+func (f *fakeGitImportDiffRepo) GetImportedCommitHashes(ctx context.Context, projectPath string) (map[string]struct{}, error) {
+	out := make(map[string]struct{})
+	for _, d := range f.diffs {
+		if d.ProjectPath == projectPath && d.CommitHash != "" {
+			out[d.CommitHash] = struct{}{}
+		}
+	}
+	return out, nil
+}
+func (f *fakeGitImportDiffRepo) GetFirstLiveTimestamp(ctx context.Context, projectPath string) (int64, error) {
+	return f.firstLive, nil
+}
+
+type fakeCommitRepoForImport struct {
+	inserted []*schema.GitCommit
+}
+
+func (f *fakeCommitRepoForImport) BatchInsert(ctx context.Context, commits []*schema.GitCommit) (int64, error) {
+	f.inserted = append(f.inserted, commits...)
+	return int64(len(commits)), nil
+}
+func (f *fakeCommitRepoForImport) GetByDate(ctx context.Context, date string) ([]schema.GitCommit, error) {
+	return nil, nil
+}
+func (f *fakeCommitRepoForImport) GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.GitCommit, error) {
+	return nil, nil
+}
+func (f *fakeCommitRepoForImport) GetByIDs(ctx context.Context, ids []int64) ([]schema.GitCommit, error) {
+	return nil, nil
+}
+
+type fakeSessionRebuilder struct {
+	dates []string
+}
+
+func (f *fakeSessionRebuilder) RebuildSessionsForDate(ctx context.Context, date string) (int, error) {
+	f.dates = append(f.dates, date)
+	return 1, nil
+}
+
+// fakeHistory 按 opts.Until 过滤的固定提交列表（时间倒序）
+func fakeHistory(commits []collector.HistoricalCommit, gotOpts *collector.GitHistoryOptions) gitHistoryReader {
+	return func(ctx context.Context, repo string, opts collector.GitHistoryOptions, fn func(*collector.HistoricalCommit) error) error {
+		*gotOpts = opts
+		for i := range commits {
+			hc := commits[i]
+			c := *hc.Commit
+			hc.Commit = &c
+			if !opts.Until.IsZero() && c.Timestamp >= opts.Until.UnixMilli() {
+				continue
+			}
+			if err := fn(&hc); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestGitImportService_RunIsIdempotent(t *testing.T) {
+	day1 := time.Date(2025, 3, 1, 10, 0, 0, 0, time.Local)
+	day2 := day1.Add(24 * time.Hour)
+	commits := []collector.HistoricalCommit{
+		{
+			Commit: &schema.GitCommit{Hash: "bbb", Subject: "add parser", Timestamp: day2.UnixMilli()},
+			Files: []collector.HistoricalFileDiff{
+				{Path: "pkg/parser.go", Diff: "diff --git a/pkg/parser.go b/pkg/parser.go\n", LinesAdded: 10},
+				{Path: "web/app.tsx", Diff: "diff --git a/web/app.tsx b/web/app.tsx\n", LinesAdded: 3},
+				{Path: "gen/big.go", LinesAdded: 5000}, // 超出大小上限，无 diff 内容
+			},
+		},
+		{
+			Commit: &schema.GitCommit{Hash: "aaa", Subject: "init", Timestamp: day1.UnixMilli()},
+			Files:  []collector.HistoricalFileDiff{{Path: "main.go", Diff: "diff --git a/main.go b/main.go\n", LinesAdded: 1}},
+		},
+	}
+
+	diffRepo := &fakeGitImportDiffRepo{}
+	commitRepo := &fakeCommitRepoForImport{}
+	sessions := &fakeSessionRebuilder{}
+	skillRepo := newFakeSkillRepo()
+	svc := NewGitImportService(diffRepo, commitRepo, NewSkillService(skillRepo, fakeDiffRepo{}, nil, DefaultExpPolicy{}), sessions, nil)
+	var opts collector.GitHistoryOptions
+	svc.history = fakeHistory(commits, &opts)
+
+	var notified []GitImportProgress
+	req := GitImportRequest{
+		Repos:      []string{"/src/demo"},
+		Since:      day1.Add(-time.Hour),
+		OnProgress: func(p GitImportProgress) { notified = append(notified, p) },
+	}
+	p, err := svc.Run(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if p.Running || p.Phase != GitImportPhaseDone || p.CommitsImported != 2 || p.DiffsCreated != 4 || p.SessionsCreated != 2 {
+		t.Fatalf("unexpected progress: %+v", p)
+	}
+	if len(notified) == 0 || notified[len(notified)-1].Running {
+		t.Fatalf("final progress should be notified, got %+v", notified)
+	}
+	if len(commitRepo.inserted) != 2 {
+		t.Fatalf("expected commits stored as evidence, got %d", len(commitRepo.inserted))
+	}
+	if len(sessions.dates) != 2 || sessions.dates[0] != "2025-03-01" || sessions.dates[1] != "2025-03-02" {
+		t.Fatalf("expected sessions rebuilt for touched dates in order, got %v", sessions.dates)
+	}
+
+	first := diffRepo.diffs[0]
+	if first.ProjectPath != "/src/demo" || first.CommitHash != "bbb" || first.Language != "Go" || first.AIInsight != "add parser" {
+		t.Fatalf("unexpected diff: %+v", first)
+	}
+	if len(first.SkillsDetected) != 1 || first.SkillsDetected[0] != "Go" {
+		t.Fatalf("expected offline skill Go, got %v", first.SkillsDetected)
+	}
+	// 超出大小上限的文件保留增删行数
+	big := diffRepo.diffs[2]
+	if big.FileName != "big.go" || big.DiffContent != "" || big.LinesAdded != 5000 {
+		t.Fatalf("expected oversized file kept with stats, got %+v", big)
+	}
+	names := make(map[string]bool)
+	for _, s := range skillRepo.items {
+		names[s.Name] = true
+	}
+	if !names["Go"] || !names["React"] {
+		t.Fatalf("expected Go and React skills, got %v", names)
+	}
+
+	// 再次执行：所有提交都已导入
+	p, err = svc.Run(context.Background(), req)
+	if err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	if p.CommitsImported != 0 || p.CommitsSkipped != 2 || p.DiffsCreated != 0 || len(diffRepo.diffs) != 4 {
+		t.Fatalf("rerun should be a no-op: %+v (diffs=%d)", p, len(diffRepo.diffs))
+	}
+}
+
+func TestGitImportService_stopsAtFirstLiveDiff(t *testing.T) {
+	live := time.Date(2025, 3, 2, 0, 0, 0, 0, time.Local)
+	commits := []collector.HistoricalCommit{
+		{
+			Commit: &schema.GitCommit{Hash: "after", Subject: "covered by live diffs", Timestamp: live.Add(time.Hour).UnixMilli()},
+			Files:  []collector.HistoricalFileDiff{{Path: "a.go", Diff: "diff", LinesAdded: 1}},
+		},
+		{
+			Commit: &schema.GitCommit{Hash: "before", Subject: "historical", Timestamp: live.Add(-time.Hour).UnixMilli()},
+			Files:  []collector.HistoricalFileDiff{{Path: "a.go", Diff: "diff", LinesAdded: 1}},
+		},
+	}
+	diffRepo := &fakeGitImportDiffRepo{firstLive: live.UnixMilli()}
+	svc := NewGitImportService(diffRepo, &fakeCommitRepoForImport{}, nil, &fakeSessionRebuilder{}, nil)
+	var opts collector.GitHistoryOptions
+	svc.history = fakeHistory(commits, &opts)
+
+	p, err := svc.Run(context.Background(), GitImportRequest{
+		Repos:         []string{"/src/demo"},
+		Until:         live.Add(48 * time.Hour),
+		AnalyzeWithAI: true,
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !opts.Until.Equal(time.UnixMilli(live.UnixMilli())) {
+		t.Fatalf("expected until clamped to first live diff, got %v", opts.Until)
+	}
+	if p.CommitsImported != 1 || diffRepo.diffs[0].CommitHash != "before" {
+		t.Fatalf("unexpected import: %+v", p)
+	}
+	if diffRepo.diffs[0].AIInsight != "" {
+		t.Fatalf("diffs should stay pending for AI analysis, got %q", diffRepo.diffs[0].AIInsight)
+	}
+}
+
+// fakeSkillActivityRepo 记录已写入的贡献键，用于验证去重
+type fakeSkillActivityRepo struct {
+	keys map[repository.SkillActivityKey]struct{}
+}
+
+func (f *fakeSkillActivityRepo) BatchInsert(ctx context.Context, activities []schema.SkillActivity) (int64, error) {
+	if f.keys == nil {
+		f.keys = make(map[repository.SkillActivityKey]struct{})
+	}
+	for _, a := range activities {
+		f.keys[repository.SkillActivityKey{Source: a.Source, EvidenceID: a.EvidenceID, SkillKey: a.SkillKey}] = struct{}{}
+	}
+	return int64(len(activities)), nil
+}
+func (f *fakeSkillActivityRepo) ListExistingKeys(ctx context.Context, keys []repository.SkillActivityKey) (map[repository.SkillActivityKey]struct{}, error) {
+	out := make(map[repository.SkillActivityKey]struct{})
+	for _, k := range keys {
+		if _, ok := f.keys[k]; ok {
+			out[k] = struct{}{}
+		}
+	}
+	return out, nil
+}
+func (f *fakeSkillActivityRepo) GetStatsByTimeRange(ctx context.Context, startTime, endTime int64) ([]repository.SkillActivityStat, error) {
+	return nil, nil
+}
+
+func TestGitImportService_aiAnalysisDoesNotRecountOfflineSkills(t *testing.T) {
+	at := time.Date(2025, 3, 1, 10, 0, 0, 0, time.Local)
+	commits := []collector.HistoricalCommit{{
+		Commit: &schema.GitCommit{Hash: "aaa", Subject: "add parser", Timestamp: at.UnixMilli()},
+		Files: []collector.HistoricalFileDiff{
+			{Path: "pkg/parser.go", Diff: "diff --git a/pkg/parser.go b/pkg/parser.go\n", LinesAdded: 40},
+			{Path: "pkg/lexer.go", Diff: "diff --git a/pkg/lexer.go b/pkg/lexer.go\n", LinesAdded: 20},
+		},
+	}}
+	diffRepo := &fakeGitImportDiffRepo{}
+	skillRepo := newFakeSkillRepo()
+	skills := NewSkillService(skillRepo, fakeDiffRepo{}, &fakeSkillActivityRepo{}, DefaultExpPolicy{})
+	svc := NewGitImportService(diffRepo, &fakeCommitRepoForImport{}, skills, &fakeSessionRebuilder{}, nil)
+	var opts collector.GitHistoryOptions
+	svc.history = fakeHistory(commits, &opts)
+
+	if _, err := svc.Run(context.Background(), GitImportRequest{Repos: []string{"/src/demo"}, Since: at.Add(-time.Hour), AnalyzeWithAI: true}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	goSkill := skillRepo.items["go"]
+	if goSkill == nil || goSkill.Exp <= 0 {
+		t.Fatalf("expected offline Go exp, got %+v", goSkill)
+	}
+	before := goSkill.Exp
+
+	// 导入的 Diff 随后经 AI 分析得到相同技能：证据相同，不再加经验
+	pending := make([]schema.Diff, 0, len(diffRepo.diffs))
+	for _, d := range diffRepo.diffs {
+		pending = append(pending, *d)
+	}
+	aiSvc := NewAIService(&fakeAnalyzer{}, &fakeDiffRepoForAI{pending: pending}, fakeEventRepoForAI{}, nil, skills)
+	if n, err := aiSvc.AnalyzePendingDiffs(context.Background(), 10); err != nil || n != len(pending) {
+		t.Fatalf("AnalyzePendingDiffs = %d, %v", n, err)
+	}
+	if after := skillRepo.items["go"].Exp; after != before {
+		t.Fatalf("Go exp changed after AI analysis: %v -> %v", before, after)
+	}
+}
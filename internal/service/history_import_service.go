@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/collector"
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+// HistoryImportResult 一次历史数据导入的结果
+type HistoryImportResult struct {
+	Source          string
+	EventsImported  int
+	BrowserImported int
+	Duplicates      int // 此前已导入（按来源、时间、应用去重）
+	Skipped         int // 无效或不导入的记录：空闲时段、无痕窗口、不支持的 bucket 等
+	StartTime       int64
+	EndTime         int64
+	SessionsCreated int
+}
+
+// HistoryImportService 导入其他工具积累的历史数据（ActivityWatch 导出、WakaTime 数据导出）：
+// 窗口与心跳写入事件表（source 标记来源），浏览记录写入浏览器事件表，导入后按天切分会话。
+// 以 (来源, 时间, 应用) 去重，同一文件可重复导入。
+type HistoryImportService struct {
+	eventRepo   EventRepository
+	browserRepo BrowserEventRepository
+	sessions    SessionRebuilder
+	sanitizer   *privacy.Sanitizer
+	now         func() time.Time
+}
+
+// NewHistoryImportService 创建历史导入服务
+func NewHistoryImportService(eventRepo EventRepository, browserRepo BrowserEventRepository, sessions SessionRebuilder) *HistoryImportService {
+	return &HistoryImportService{
+		eventRepo:   eventRepo,
+		browserRepo: browserRepo,
+		sessions:    sessions,
+		now:         time.Now,
+	}
+}
+
+// SetSanitizer 设置标题/URL 脱敏（可选）
+func (s *HistoryImportService) SetSanitizer(z *privacy.Sanitizer) {
+	s.sanitizer = z
+}
+
+// historyImportRun 一次导入的累计状态
+type historyImportRun struct {
+	res  HistoryImportResult
+	days map[string]struct{} // 有新数据的日期
+}
+
+func newHistoryImportRun(source string) *historyImportRun {
+	return &historyImportRun{res: HistoryImportResult{Source: source}, days: make(map[string]struct{})}
+}
+
+func (r *historyImportRun) note(ts int64) {
+	if r.res.StartTime == 0 || ts < r.res.StartTime {
+		r.res.StartTime = ts
+	}
+	if ts > r.res.EndTime {
+		r.res.EndTime = ts
+	}
+	r.days[time.UnixMilli(ts).Format("2006-01-02")] = struct{}{}
+}
+
+// awInterval 非空闲时段 [start, end)（毫秒）
+type awInterval struct{ start, end int64 }
+
+// ImportActivityWatch 导入 ActivityWatch 导出文件：aw-watcher-window 写为窗口事件，
+// aw-watcher-web 写为前台浏览记录；有 aw-watcher-afk 数据时只保留非空闲时段
+func (s *HistoryImportService) ImportActivityWatch(ctx context.Context, r io.Reader) (HistoryImportResult, error) {
+	run := newHistoryImportRun(schema.EventSourceActivityWatch)
+
+	var windows, webs []*collector.ActivityWatchBucket
+	active := make(map[string][]awInterval)
+	err := collector.ReadActivityWatchExport(r, func(b *collector.ActivityWatchBucket) error {
+		switch b.Type {
+		case collector.ActivityWatchWindow:
+			windows = append(windows, b)
+		case collector.ActivityWatchWeb:
+			webs = append(webs, b)
+		case collector.ActivityWatchAFK:
+			active[b.Hostname] = awActiveIntervals(b.Events)
+		default:
+			run.res.Skipped += len(b.Events)
+		}
+		return nil
+	})
+	if err != nil {
+		return run.res, err
+	}
+
+	// aw-watcher-web 的 hostname 常为 unknown：没有同名主机的空闲数据时合并所有主机
+	var anyActive []awInterval
+	for _, iv := range active {
+		anyActive = append(anyActive, iv...)
+	}
+	anyActive = mergeAWIntervals(anyActive)
+	activeFor := func(host string) []awInterval {
+		if iv, ok := active[host]; ok {
+			return iv
+		}
+		return anyActive
+	}
+	limit := s.now().Add(editorHeartbeatMaxFuture).UnixMilli()
+
+	for _, b := range windows {
+		events := make([]schema.Event, 0, len(b.Events))
+		for _, e := range b.Events {
+			app := strings.TrimSpace(e.App)
+			if app == "" {
+				run.res.Skipped++
+				continue
+			}
+			spans := clipAWEvent(e, activeFor(b.Hostname), len(active) > 0)
+			if len(spans) == 0 || e.Timestamp > limit {
+				run.res.Skipped++
+				continue
+			}
+			title := truncateRunes(s.sanitizer.SanitizeWindowTitle(strings.TrimSpace(e.Title)), 1990)
+			for _, sp := range spans {
+				events = append(events, schema.Event{
+					Timestamp: sp.start,
+					Source:    schema.EventSourceActivityWatch,
+					AppName:   truncateRunes(app, 255),
+					Title:     title,
+					Duration:  int((sp.end - sp.start) / 1000),
+					Metadata:  schema.JSONMap{},
+				})
+			}
+		}
+		if err := s.insertEvents(ctx, run, events); err != nil {
+			return run.res, err
+		}
+	}
+
+	for _, b := range webs {
+		browser := collector.ActivityWatchWebBrowser(b.ID)
+		visits := make([]*schema.BrowserEvent, 0, len(b.Events))
+		for _, e := range b.Events {
+			u, err := url.Parse(strings.TrimSpace(e.URL))
+			if e.Incognito || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || e.Timestamp > limit {
+				run.res.Skipped++
+				continue
+			}
+			spans := clipAWEvent(e, activeFor(b.Hostname), len(active) > 0)
+			if len(spans) == 0 {
+				run.res.Skipped++
+				continue
+			}
+			for _, sp := range spans {
+				visits = append(visits, &schema.BrowserEvent{
+					Timestamp:  sp.start,
+					URL:        truncateRunes(s.sanitizer.SanitizeURL(e.URL), 2000),
+					Title:      truncateRunes(s.sanitizer.SanitizeBrowserTitle(strings.TrimSpace(e.Title)), 500),
+					Domain:     u.Host,
+					Duration:   int((sp.end - sp.start) / 1000),
+					Browser:    browser,
+					Profile:    schema.BrowserProfileActivityWatch,
+					Transition: schema.BrowserTransitionFocus,
+				})
+			}
+		}
+		if err := s.insertVisits(ctx, run, visits); err != nil {
+			return run.res, err
+		}
+	}
+
+	return s.finish(ctx, run)
+}
+
+// ImportWakaTime 导入 WakaTime 数据导出中的心跳，按编辑器心跳参与计时与会话证据
+func (s *HistoryImportService) ImportWakaTime(ctx context.Context, r io.Reader) (HistoryImportResult, error) {
+	run := newHistoryImportRun(schema.EventSourceWakaTime)
+	limit := s.now().Add(editorHeartbeatMaxFuture).UnixMilli()
+
+	err := collector.ReadWakaTimeExport(r, func(beats []collector.WakaTimeExportHeartbeat) error {
+		events := make([]schema.Event, 0, len(beats))
+		for _, hb := range beats {
+			ev, err := editorHeartbeatEvent(EditorHeartbeat{
+				Entity:    hb.Entity,
+				Type:      hb.Type,
+				Category:  hb.Category,
+				Time:      hb.Time,
+				Project:   hb.Project,
+				Branch:    hb.Branch,
+				Language:  hb.Language,
+				Lines:     hb.Lines,
+				LineNo:    hb.LineNo,
+				IsWrite:   hb.IsWrite,
+				UserAgent: hb.UserAgent,
+			}, s.sanitizer)
+			if err != nil || ev.Timestamp > limit {
+				run.res.Skipped++
+				continue
+			}
+			ev.Source = schema.EventSourceWakaTime
+			events = append(events, *ev)
+		}
+		return s.insertEvents(ctx, run, events)
+	})
+	if err != nil {
+		return run.res, err
+	}
+	return s.finish(ctx, run)
+}
+
+// insertEvents 按天与库中同来源事件比对 (时间, 应用) 后写入
+func (s *HistoryImportService) insertEvents(ctx context.Context, run *historyImportRun, events []schema.Event) error {
+	for _, day := range groupByDay(len(events), func(i int) int64 { return events[i].Timestamp }) {
+		existing, err := s.eventRepo.GetByTimeRange(ctx, day.start, day.end)
+		if err != nil {
+			return err
+		}
+		seen := make(map[string]struct{}, len(existing))
+		for _, e := range existing {
+			if e.Source == run.res.Source {
+				seen[historyImportKey(e.Timestamp, e.AppName)] = struct{}{}
+			}
+		}
+		fresh := make([]schema.Event, 0, len(day.idx))
+		for _, i := range day.idx {
+			key := historyImportKey(events[i].Timestamp, events[i].AppName)
+			if _, dup := seen[key]; dup {
+				run.res.Duplicates++
+				continue
+			}
+			seen[key] = struct{}{}
+			fresh = append(fresh, events[i])
+		}
+		if len(fresh) == 0 {
+			continue
+		}
+		if err := s.eventRepo.BatchInsert(ctx, fresh); err != nil {
+			return fmt.Errorf("写入导入事件失败: %w", err)
+		}
+		run.res.EventsImported += len(fresh)
+		for _, e := range fresh {
+			run.note(e.Timestamp)
+		}
+	}
+	return nil
+}
+
+// insertVisits 按天与库中同样来自 ActivityWatch 的浏览记录比对 (时间, 浏览器) 后写入
+func (s *HistoryImportService) insertVisits(ctx context.Context, run *historyImportRun, visits []*schema.BrowserEvent) error {
+	for _, day := range groupByDay(len(visits), func(i int) int64 { return visits[i].Timestamp }) {
+		existing, err := s.browserRepo.GetByTimeRange(ctx, day.start, day.end)
+		if err != nil {
+			return err
+		}
+		seen := make(map[string]struct{}, len(existing))
+		for _, v := range existing {
+			if v.Profile == schema.BrowserProfileActivityWatch {
+				seen[historyImportKey(v.Timestamp, v.Browser)] = struct{}{}
+			}
+		}
+		fresh := make([]*schema.BrowserEvent, 0, len(day.idx))
+		for _, i := range day.idx {
+			key := historyImportKey(visits[i].Timestamp, visits[i].Browser)
+			if _, dup := seen[key]; dup {
+				run.res.Duplicates++
+				continue
+			}
+			seen[key] = struct{}{}
+			fresh = append(fresh, visits[i])
+		}
+		if len(fresh) == 0 {
+			continue
+		}
+		if err := s.browserRepo.BatchInsert(ctx, fresh); err != nil {
+			return fmt.Errorf("写入导入浏览记录失败: %w", err)
+		}
+		run.res.BrowserImported += len(fresh)
+		for _, v := range fresh {
+			run.note(v.Timestamp)
+		}
+	}
+	return nil
+}
+
+// finish 对有新数据的日期逐天重建会话（整段范围一次读入可能是数月的事件）；
+// 当天已有的会话由更高的切分版本覆盖，避免与新切出的会话重叠
+func (s *HistoryImportService) finish(ctx context.Context, run *historyImportRun) (HistoryImportResult, error) {
+	days := make([]string, 0, len(run.days))
+	for d := range run.days {
+		days = append(days, d)
+	}
+	sort.Strings(days)
+	for _, d := range days {
+		if err := ctx.Err(); err != nil {
+			return run.res, err
+		}
+		created, err := s.sessions.RebuildSessionsForDate(ctx, d)
+		if err != nil {
+			return run.res, fmt.Errorf("切分会话失败 (%s): %w", d, err)
+		}
+		run.res.SessionsCreated += created
+	}
+	slog.Info("历史数据导入完成", "source", run.res.Source, "events", run.res.EventsImported,
+		"browser", run.res.BrowserImported, "duplicates", run.res.Duplicates, "sessions", run.res.SessionsCreated)
+	return run.res, nil
+}
+
+type importDay struct {
+	start, end int64
+	idx        []int
+}
+
+// groupByDay 按本地日期分组下标，日期升序
+func groupByDay(n int, ts func(i int) int64) []importDay {
+	byDay := make(map[string]*importDay)
+	for i := 0; i < n; i++ {
+		t := time.UnixMilli(ts(i))
+		key := t.Format("2006-01-02")
+		d, ok := byDay[key]
+		if !ok {
+			start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+			d = &importDay{start: start.UnixMilli(), end: start.AddDate(0, 0, 1).UnixMilli() - 1}
+			byDay[key] = d
+		}
+		d.idx = append(d.idx, i)
+	}
+	out := make([]importDay, 0, len(byDay))
+	for _, d := range byDay {
+		out = append(out, *d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].start < out[j].start })
+	return out
+}
+
+func historyImportKey(ts int64, app string) string {
+	return fmt.Sprintf("%d\n%s", ts, app)
+}
+
+// awActiveIntervals 从 aw-watcher-afk 事件中取非空闲时段（合并相邻与重叠）
+func awActiveIntervals(events []collector.ActivityWatchEvent) []awInterval {
+	out := make([]awInterval, 0, len(events))
+	for _, e := range events {
+		if e.Status != "not-afk" || e.Duration <= 0 {
+			continue
+		}
+		out = append(out, awInterval{start: e.Timestamp, end: e.Timestamp + int64(math.Round(e.Duration*1000))})
+	}
+	return mergeAWIntervals(out)
+}
+
+func mergeAWIntervals(in []awInterval) []awInterval {
+	if len(in) == 0 {
+		return nil
+	}
+	sort.Slice(in, func(i, j int) bool { return in[i].start < in[j].start })
+	out := []awInterval{in[0]}
+	for _, iv := range in[1:] {
+		last := &out[len(out)-1]
+		if iv.start <= last.end {
+			last.end = max(last.end, iv.end)
+			continue
+		}
+		out = append(out, iv)
+	}
+	return out
+}
+
+// clipAWEvent 事件与非空闲时段的交集（不足 1 秒的片段丢弃）；filter=false 时不裁剪
+func clipAWEvent(e collector.ActivityWatchEvent, active []awInterval, filter bool) []awInterval {
+	if e.Duration <= 0 {
+		return nil
+	}
+	ev := awInterval{start: e.Timestamp, end: e.Timestamp + int64(math.Round(e.Duration*1000))}
+	if !filter {
+		if ev.end-ev.start < 1000 {
+			return nil
+		}
+		return []awInterval{ev}
+	}
+	i := sort.Search(len(active), func(i int) bool { return active[i].end > ev.start })
+	var out []awInterval
+	for ; i < len(active) && active[i].start < ev.end; i++ {
+		sp := awInterval{start: max(ev.start, active[i].start), end: min(ev.end, active[i].end)}
+		if sp.end-sp.start >= 1000 {
+			out = append(out, sp)
+		}
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+	"github.com/yuqie6/WorkMirror/internal/repository"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+type fakeEventRepoForImport struct {
+	events []schema.Event
+}
+
+func (f *fakeEventRepoForImport) BatchInsert(ctx context.Context, events []schema.Event) error {
+	f.events = append(f.events, events...)
+	return nil
+}
+func (f *fakeEventRepoForImport) GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.Event, error) {
+	var out []schema.Event
+	for _, e := range f.events {
+		if e.Timestamp >= startTime && e.Timestamp <= endTime {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+func (f *fakeEventRepoForImport) GetByDate(ctx context.Context, date string) ([]schema.Event, error) {
+	return nil, nil
+}
+func (f *fakeEventRepoForImport) GetAppStats(ctx context.Context, startTime, endTime int64) ([]repository.AppStat, error) {
+	return nil, nil
+}
+func (f *fakeEventRepoForImport) Count(ctx context.Context) (int64, error) {
+	return int64(len(f.events)), nil
+}
+
+type fakeBrowserRepoForImport struct {
+	fakeBrowserRepoForFocus
+}
+
+func (f *fakeBrowserRepoForImport) GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.BrowserEvent, error) {
+	var out []schema.BrowserEvent
+	for _, v := range f.inserted {
+		if v.Timestamp >= startTime && v.Timestamp <= endTime {
+			out = append(out, *v)
+		}
+	}
+	return out, nil
+}
+
+const activityWatchExport = `{"buckets": {
+  "aw-watcher-window_host": {"id": "aw-watcher-window_host", "type": "currentwindow", "hostname": "host", "events": [
+    {"timestamp": "2026-03-02T02:00:00Z", "duration": 600, "data": {"app": "Code.exe", "title": "main.go - WorkMirror"}},
+    {"timestamp": "2026-03-02T02:10:00Z", "duration": 0.4, "data": {"app": "explorer.exe", "title": ""}},
+    {"timestamp": "2026-03-02T03:00:00Z", "duration": 120, "data": {"app": "", "title": "?"}}
+  ]},
+  "aw-watcher-afk_host": {"id": "aw-watcher-afk_host", "type": "afkstatus", "hostname": "host", "events": [
+    {"timestamp": "2026-03-02T02:00:00Z", "duration": 180, "data": {"status": "not-afk"}},
+    {"timestamp": "2026-03-02T02:03:00Z", "duration": 240, "data": {"status": "afk"}},
+    {"timestamp": "2026-03-02T02:07:00Z", "duration": 300, "data": {"status": "not-afk"}}
+  ]},
+  "aw-watcher-web-firefox_host": {"id": "aw-watcher-web-firefox_host", "type": "web.tab.current", "hostname": "unknown", "events": [
+    {"timestamp": "2026-03-02T02:01:00Z", "duration": 60, "data": {"url": "https://go.dev/doc/?q=1", "title": "Docs", "incognito": false}},
+    {"timestamp": "2026-03-02T02:02:00Z", "duration": 60, "data": {"url": "https://secret.example/", "title": "S", "incognito": true}},
+    {"timestamp": "2026-03-02T02:02:30Z", "duration": 10, "data": {"url": "about:blank", "title": ""}}
+  ]},
+  "aw-stopwatch": {"id": "aw-stopwatch", "type": "general.stopwatch", "events": [
+    {"timestamp": "2026-03-02T02:00:00Z", "duration": 60, "data": {}}
+  ]}
+}}`
+
+func TestHistoryImportService_ActivityWatch(t *testing.T) {
+	events := &fakeEventRepoForImport{}
+	browser := &fakeBrowserRepoForImport{}
+	sessions := &fakeSessionRebuilder{}
+	svc := NewHistoryImportService(events, browser, sessions)
+	svc.SetSanitizer(privacy.New(true, nil))
+
+	res, err := svc.ImportActivityWatch(context.Background(), strings.NewReader(activityWatchExport))
+	if err != nil {
+		t.Fatalf("ImportActivityWatch: %v", err)
+	}
+	// 窗口事件按非空闲时段裁剪为 02:00-02:03 与 02:07-02:10 两段
+	if res.EventsImported != 2 || res.BrowserImported != 1 || res.Duplicates != 0 {
+		t.Fatalf("res=%+v", res)
+	}
+	if res.Skipped != 5 { // 过短、无应用名、无痕、非 http、stopwatch
+		t.Fatalf("skipped=%d, want 5", res.Skipped)
+	}
+	base := time.Date(2026, 3, 2, 2, 0, 0, 0, time.UTC).UnixMilli()
+	e0, e1 := events.events[0], events.events[1]
+	if e0.Timestamp != base || e0.Duration != 180 || e1.Timestamp != base+7*60*1000 || e1.Duration != 180 {
+		t.Fatalf("events=%+v", events.events)
+	}
+	if e0.Source != schema.EventSourceActivityWatch || e0.AppName != "Code.exe" {
+		t.Fatalf("event=%+v", e0)
+	}
+	v := browser.inserted[0]
+	if v.URL != "https://go.dev/..." || v.Browser != "firefox" || v.Profile != schema.BrowserProfileActivityWatch ||
+		v.Transition != schema.BrowserTransitionFocus || v.Duration != 60 || v.Domain != "go.dev" {
+		t.Fatalf("visit=%+v", *v)
+	}
+	if res.SessionsCreated != len(sessions.dates) || len(sessions.dates) == 0 {
+		t.Fatalf("sessions=%d dates=%v", res.SessionsCreated, sessions.dates)
+	}
+	if d := time.UnixMilli(res.StartTime).Format("2006-01-02"); sessions.dates[0] != d {
+		t.Fatalf("dates=%v, want first %s", sessions.dates, d)
+	}
+
+	// 重复导入：全部去重，不再切分会话
+	sessions.dates = nil
+	res, err = svc.ImportActivityWatch(context.Background(), strings.NewReader(activityWatchExport))
+	if err != nil {
+		t.Fatalf("re-import: %v", err)
+	}
+	if res.EventsImported != 0 || res.BrowserImported != 0 || res.Duplicates != 3 || len(sessions.dates) != 0 {
+		t.Fatalf("re-import res=%+v dates=%v", res, sessions.dates)
+	}
+}
+
+func TestHistoryImportService_WakaTime(t *testing.T) {
+	events := &fakeEventRepoForImport{}
+	sessions := &fakeSessionRebuilder{}
+	svc := NewHistoryImportService(events, &fakeBrowserRepoForImport{}, sessions)
+	svc.now = func() time.Time { return time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC) }
+
+	export := `{"user": {"id": "x"}, "range": {}, "days": [
+	  {"date": "2026-03-02", "heartbeats": [
+	    {"entity": "/home/u/WorkMirror/internal/service/a.go", "type": "file", "time": 1772416800.5, "project": "WorkMirror", "language": "Go", "is_write": true},
+	    {"entity": "/home/u/WorkMirror/internal/service/a.go", "type": "file", "time": 1772416830, "project": "WorkMirror", "language": "Go"},
+	    {"entity": "", "type": "file", "time": 1772416840}
+	  ]},
+	  {"date": "2026-03-03", "heartbeats": []},
+	  {"date": "2026-03-20", "heartbeats": [
+	    {"entity": "/home/u/future.go", "type": "file", "time": 1773964800}
+	  ]}
+	]}`
+	res, err := svc.ImportWakaTime(context.Background(), strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("ImportWakaTime: %v", err)
+	}
+	if res.EventsImported != 2 || res.Skipped != 2 || len(sessions.dates) != 1 {
+		t.Fatalf("res=%+v dates=%v", res, sessions.dates)
+	}
+	ev := events.events[0]
+	if ev.Source != schema.EventSourceWakaTime || ev.Timestamp != 1772416800500 || !isEditorHeartbeat(ev) {
+		t.Fatalf("event=%+v", ev)
+	}
+
+	res, err = svc.ImportWakaTime(context.Background(), strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("re-import: %v", err)
+	}
+	if res.EventsImported != 0 || res.Duplicates != 2 {
+		t.Fatalf("re-import res=%+v", res)
+	}
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+// 外部事件写入的限制
+const (
+	IngestMaxBatch         = 500
+	ingestMaxTitleRunes    = 1990
+	ingestMaxAppRunes      = 255
+	ingestMaxDurationSec   = 24 * 60 * 60
+	ingestMaxMetadataKeys  = 32
+	ingestMaxMetadataBytes = 8 << 10
+	ingestMaxFuture        = 10 * time.Minute
+	ingestMinTimestampMs   = 946684800000 // 2000-01-01，早于此的时间多半是误传了秒级时间戳
+)
+
+var (
+	ErrIngestRateLimited = errors.New("写入过于频繁")
+	ErrIngestBatchTooBig = errors.New("单次写入事件过多")
+
+	ingestSourcePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,49}$`)
+)
+
+// ingestReservedSources 由本地采集链路专用的来源，外部写入会干扰应用时长与编辑器证据
+var ingestReservedSources = map[string]struct{}{
+	schema.EventSourceWindow:        {},
+	schema.EventSourceEditor:        {},
+	schema.EventSourceActivityWatch: {},
+	schema.EventSourceWakaTime:      {},
+}
+
+// IngestEvent 外部工具（测试运行器、部署脚本等）写入的一条事件
+type IngestEvent struct {
+	Source    string
+	App       string
+	Title     string
+	Timestamp int64 // 毫秒；0 表示当前时间
+	Duration  int   // 秒
+	Metadata  map[string]any
+}
+
+// IngestRejection 被拒绝的事件（Index 为请求中的下标）
+type IngestRejection struct {
+	Index int
+	Error string
+}
+
+// IngestResult 一次写入的结果
+type IngestResult struct {
+	Accepted int
+	Rejected []IngestRejection
+}
+
+// IngestStats 外部写入统计
+type IngestStats struct {
+	Accepted     int64
+	Rejected     int64
+	RateLimited  int64
+	LastIngestAt int64
+}
+
+// IngestService 校验外部事件后交给 TrackerService 的批量写入缓冲（落库后由其回调发布 data_changed）。
+// 按事件数做令牌桶限流，整批要么全部进入缓冲、要么整批拒绝，便于调用方重试。
+type IngestService struct {
+	sink      EventIngester
+	sanitizer *privacy.Sanitizer
+	now       func() time.Time
+
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	refilled time.Time
+
+	accepted     atomic.Int64
+	rejected     atomic.Int64
+	rateLimited  atomic.Int64
+	lastIngestAt atomic.Int64
+}
+
+// NewIngestService 创建外部事件写入服务；ratePerMin 为每分钟允许写入的事件数（同时也是突发上限）
+func NewIngestService(sink EventIngester, ratePerMin int) *IngestService {
+	if ratePerMin <= 0 {
+		ratePerMin = 600
+	}
+	return &IngestService{
+		sink:     sink,
+		now:      time.Now,
+		capacity: float64(ratePerMin),
+		tokens:   float64(ratePerMin),
+	}
+}
+
+// SetSanitizer 设置脱敏器（标题由 TrackerService 脱敏，这里处理 metadata 中的字符串值）
+func (s *IngestService) SetSanitizer(z *privacy.Sanitizer) {
+	s.sanitizer = z
+}
+
+// Ingest 校验并写入一批事件。无效事件逐条拒绝，其余事件整批进入写入缓冲；
+// 超出速率时返回 ErrIngestRateLimited 与建议的重试等待时间。
+func (s *IngestService) Ingest(events []IngestEvent) (IngestResult, time.Duration, error) {
+	var res IngestResult
+	if len(events) > IngestMaxBatch {
+		return res, 0, fmt.Errorf("%w: 最多 %d 条", ErrIngestBatchTooBig, IngestMaxBatch)
+	}
+
+	now := s.now()
+	valid := make([]schema.Event, 0, len(events))
+	for i, in := range events {
+		ev, err := s.toEvent(in, now)
+		if err != nil {
+			res.Rejected = append(res.Rejected, IngestRejection{Index: i, Error: err.Error()})
+			continue
+		}
+		valid = append(valid, ev)
+	}
+	s.rejected.Add(int64(len(res.Rejected)))
+	if len(valid) == 0 {
+		return res, 0, nil
+	}
+
+	if wait, ok := s.take(len(valid), now); !ok {
+		s.rateLimited.Add(int64(len(valid)))
+		return res, wait, ErrIngestRateLimited
+	}
+	if err := s.sink.Ingest(valid); err != nil {
+		s.refund(len(valid))
+		return res, 0, err
+	}
+	res.Accepted = len(valid)
+	s.accepted.Add(int64(len(valid)))
+	s.lastIngestAt.Store(now.UnixMilli())
+	return res, 0, nil
+}
+
+// Stats 返回外部写入统计
+func (s *IngestService) Stats() IngestStats {
+	return IngestStats{
+		Accepted:     s.accepted.Load(),
+		Rejected:     s.rejected.Load(),
+		RateLimited:  s.rateLimited.Load(),
+		LastIngestAt: s.lastIngestAt.Load(),
+	}
+}
+
+func (s *IngestService) toEvent(in IngestEvent, now time.Time) (schema.Event, error) {
+	source := strings.ToLower(strings.TrimSpace(in.Source))
+	if !ingestSourcePattern.MatchString(source) {
+		return schema.Event{}, errors.New("source 只能包含小写字母、数字、_ . -，且不超过 50 个字符")
+	}
+	if _, reserved := ingestReservedSources[source]; reserved {
+		return schema.Event{}, fmt.Errorf("source %q 为本地采集保留", source)
+	}
+
+	app := strings.TrimSpace(in.App)
+	if app == "" {
+		app = source
+	}
+	if len([]rune(app)) > ingestMaxAppRunes {
+		return schema.Event{}, fmt.Errorf("app 超过 %d 个字符", ingestMaxAppRunes)
+	}
+
+	if in.Duration < 0 || in.Duration > ingestMaxDurationSec {
+		return schema.Event{}, fmt.Errorf("duration 需在 0~%d 秒之间", ingestMaxDurationSec)
+	}
+
+	ts := in.Timestamp
+	if ts == 0 {
+		ts = now.UnixMilli()
+	}
+	if ts < ingestMinTimestampMs {
+		return schema.Event{}, errors.New("timestamp 需为毫秒级 Unix 时间")
+	}
+	if ts > now.Add(ingestMaxFuture).UnixMilli() {
+		return schema.Event{}, errors.New("timestamp 晚于当前时间")
+	}
+
+	meta, err := s.sanitizeMetadata(in.Metadata)
+	if err != nil {
+		return schema.Event{}, err
+	}
+
+	return schema.Event{
+		Timestamp: ts,
+		Source:    source,
+		AppName:   app,
+		Title:     truncateRunes(strings.TrimSpace(in.Title), ingestMaxTitleRunes),
+		Duration:  in.Duration,
+		Metadata:  meta,
+	}, nil
+}
+
+// sanitizeMetadata 限制 metadata 规模，并对字符串值（含嵌套）做脱敏
+func (s *IngestService) sanitizeMetadata(in map[string]any) (schema.JSONMap, error) {
+	if len(in) == 0 {
+		return schema.JSONMap{}, nil
+	}
+	if len(in) > ingestMaxMetadataKeys {
+		return nil, fmt.Errorf("metadata 最多 %d 个键", ingestMaxMetadataKeys)
+	}
+	out := make(schema.JSONMap, len(in))
+	for k, v := range in {
+		if strings.TrimSpace(k) == "" {
+			return nil, errors.New("metadata 键不能为空")
+		}
+		clean, err := s.sanitizeValue(v)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = clean
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("metadata 无法序列化: %w", err)
+	}
+	if len(b) > ingestMaxMetadataBytes {
+		return nil, fmt.Errorf("metadata 序列化后超过 %d 字节", ingestMaxMetadataBytes)
+	}
+	return out, nil
+}
+
+func (s *IngestService) sanitizeValue(v any) (any, error) {
+	switch x := v.(type) {
+	case string:
+		return s.sanitizer.SanitizeText(x), nil
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return nil, errors.New("metadata 数值无效")
+		}
+		return x, nil
+	case []any:
+		out := make([]any, 0, len(x))
+		for _, it := range x {
+			clean, err := s.sanitizeValue(it)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, clean)
+		}
+		return out, nil
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, it := range x {
+			clean, err := s.sanitizeValue(it)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = clean
+		}
+		return out, nil
+	default:
+		return x, nil
+	}
+}
+
+// take 从令牌桶中取 n 个令牌；不足时返回需要等待的时间
+func (s *IngestService) take(n int, now time.Time) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	perSec := s.capacity / 60
+	if !s.refilled.IsZero() {
+		s.tokens = math.Min(s.capacity, s.tokens+now.Sub(s.refilled).Seconds()*perSec)
+	}
+	s.refilled = now
+	need := float64(n)
+	if need > s.capacity {
+		// 单批超过桶容量时只能等桶满后透支，避免永远无法写入
+		need = s.capacity
+	}
+	if s.tokens < need {
+		return time.Duration(math.Ceil((need-s.tokens)/perSec)) * time.Second, false
+	}
+	s.tokens -= float64(n)
+	return 0, true
+}
+
+func (s *IngestService) refund(n int) {
+	s.mu.Lock()
+	s.tokens = math.Min(s.capacity, s.tokens+float64(n))
+	s.mu.Unlock()
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+type fakeEventIngester struct {
+	events []schema.Event
+	err    error
+}
+
+func (f *fakeEventIngester) Ingest(events []schema.Event) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.events = append(f.events, events...)
+	return nil
+}
+
+func TestIngestService_ValidatesAndSanitizes(t *testing.T) {
+	sink := &fakeEventIngester{}
+	svc := NewIngestService(sink, 600)
+	svc.SetSanitizer(privacy.New(true, []string{`password=\S+`}))
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	svc.now = func() time.Time { return now }
+
+	res, _, err := svc.Ingest([]IngestEvent{
+		{Source: "pytest", Title: "tests/test_api.py: 3 failed", Duration: 42, Metadata: map[string]any{
+			"failed": float64(3),
+			"env":    []any{"CI=1", "password=hunter2"},
+		}},
+		{Source: "Deploy", App: "deploy.sh", Timestamp: now.Add(-time.Hour).UnixMilli()},
+		{Source: "window", Title: "伪装成窗口事件"},
+		{Source: "bad source!"},
+		{Source: "ci", Timestamp: now.Unix()}, // 秒级时间戳
+		{Source: "ci", Duration: -1},
+	})
+	if err != nil {
+		t.Fatalf("Ingest error: %v", err)
+	}
+	if res.Accepted != 2 || len(res.Rejected) != 4 {
+		t.Fatalf("accepted=%d rejected=%+v", res.Accepted, res.Rejected)
+	}
+	for i, rj := range res.Rejected {
+		if rj.Index != i+2 {
+			t.Fatalf("rejected[%d].Index=%d, want %d", i, rj.Index, i+2)
+		}
+	}
+
+	first := sink.events[0]
+	if first.Source != "pytest" || first.AppName != "pytest" || first.Timestamp != now.UnixMilli() || first.Duration != 42 {
+		t.Fatalf("first=%+v", first)
+	}
+	env, _ := first.Metadata["env"].([]any)
+	if len(env) != 2 || env[1] != "***" {
+		t.Fatalf("metadata strings should be sanitized: %v", first.Metadata)
+	}
+	if sink.events[1].Source != "deploy" || sink.events[1].AppName != "deploy.sh" {
+		t.Fatalf("second=%+v", sink.events[1])
+	}
+	if got := svc.Stats(); got.Accepted != 2 || got.Rejected != 4 {
+		t.Fatalf("stats=%+v", got)
+	}
+}
+
+func TestIngestService_RateLimit(t *testing.T) {
+	sink := &fakeEventIngester{}
+	svc := NewIngestService(sink, 60) // 每秒补充 1 个
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	svc.now = func() time.Time { return now }
+
+	batch := func(n int) []IngestEvent {
+		out := make([]IngestEvent, n)
+		for i := range out {
+			out[i] = IngestEvent{Source: "ci"}
+		}
+		return out
+	}
+
+	if _, _, err := svc.Ingest(batch(50)); err != nil {
+		t.Fatalf("first batch: %v", err)
+	}
+	_, wait, err := svc.Ingest(batch(20))
+	if !errors.Is(err, ErrIngestRateLimited) || wait != 10*time.Second {
+		t.Fatalf("err=%v wait=%v, want rate limited for 10s", err, wait)
+	}
+	if len(sink.events) != 50 {
+		t.Fatalf("rate-limited batch must not be partially written: %d", len(sink.events))
+	}
+
+	now = now.Add(10 * time.Second)
+	if _, _, err := svc.Ingest(batch(20)); err != nil {
+		t.Fatalf("after refill: %v", err)
+	}
+
+	// 写入队列拒绝时退回令牌
+	sink.err = ErrTrackerQueueFull
+	now = now.Add(time.Minute)
+	if _, _, err := svc.Ingest(batch(60)); !errors.Is(err, ErrTrackerQueueFull) {
+		t.Fatalf("err=%v, want queue full", err)
+	}
+	sink.err = nil
+	if _, _, err := svc.Ingest(batch(60)); err != nil {
+		t.Fatalf("tokens should be refunded: %v", err)
+	}
+
+	if _, _, err := svc.Ingest(batch(IngestMaxBatch + 1)); !errors.Is(err, ErrIngestBatchTooBig) {
+		t.Fatalf("err=%v, want batch too big", err)
+	}
+}
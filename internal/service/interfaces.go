@@ -23,6 +23,12 @@ type DiffRepository interface {
 	GetByID(ctx context.Context, id int64) (*schema.Diff, error)
 }
 
+type GitImportDiffRepository interface {
+	CreateBatch(ctx context.Context, diffs []*schema.Diff) error
+	GetImportedCommitHashes(ctx context.Context, projectPath string) (map[string]struct{}, error)
+	GetFirstLiveTimestamp(ctx context.Context, projectPath string) (int64, error)
+}
+
 type EventRepository interface {
 	BatchInsert(ctx context.Context, events []schema.Event) error
 	GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.Event, error)
@@ -31,10 +37,43 @@ type EventRepository interface {
 	Count(ctx context.Context) (int64, error)
 }
 
+// EventIngester 接收外部事件进入批量写入链路（TrackerService）
+type EventIngester interface {
+	Ingest(events []schema.Event) error
+}
+
 type BrowserEventRepository interface {
 	BatchInsert(ctx context.Context, events []*schema.BrowserEvent) error
 	GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.BrowserEvent, error)
 	GetByIDs(ctx context.Context, ids []int64) ([]schema.BrowserEvent, error)
+	UpdateVisitDuration(ctx context.Context, browser, profile string, visitID int64, seconds int) error
+	FillDurations(ctx context.Context, durations map[int64]int) error
+}
+
+type SearchTermRepository interface {
+	BatchInsert(ctx context.Context, terms []*schema.SearchTerm) error
+	GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.SearchTerm, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]schema.SearchTerm, error)
+}
+
+type CommitRepository interface {
+	BatchInsert(ctx context.Context, commits []*schema.GitCommit) (int64, error)
+	GetByDate(ctx context.Context, date string) ([]schema.GitCommit, error)
+	GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.GitCommit, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]schema.GitCommit, error)
+}
+
+type TerminalCommandRepository interface {
+	BatchInsert(ctx context.Context, cmds []*schema.TerminalCommand) (int64, error)
+	GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.TerminalCommand, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]schema.TerminalCommand, error)
+}
+
+type CalendarEventRepository interface {
+	Upsert(ctx context.Context, events []*schema.CalendarEvent) error
+	GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.CalendarEvent, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]schema.CalendarEvent, error)
+	DeleteByIDs(ctx context.Context, ids []int64) error
 }
 
 type SessionRepository interface {
@@ -84,3 +123,8 @@ type RAGQuerier interface {
 	IndexDiff(ctx context.Context, diff *schema.Diff) error
 	IndexDailySummary(ctx context.Context, summary *schema.DailySummary) error
 }
+
+// ReadingExpApplier 把技能相关的阅读计入经验（SkillService 实现）
+type ReadingExpApplier interface {
+	ApplyReading(ctx context.Context, visits []schema.BrowserEvent, domains *DomainClassifier) error
+}
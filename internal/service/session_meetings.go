@@ -0,0 +1,97 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+// meetingBlock 合并后的会议时段（重叠或相接的日程合为一段）
+type meetingBlock struct {
+	start, end int64
+	ids        []int64
+}
+
+// carveMeetings 把会议时段从会话中切出，单独成为 Category=meeting 的会话（日程 ID 作为证据）。
+// 只处理前后一个空闲间隔内有活动的会议：整段都没有活动多半是休假或没有参加。
+func (s *SessionService) carveMeetings(sessions []*schema.Session, meetings []schema.CalendarEvent, startTime, endTime int64) []*schema.Session {
+	if len(sessions) == 0 || len(meetings) == 0 {
+		return sessions
+	}
+	idleMs := int64(s.cfg.IdleGapMinutes) * 60 * 1000
+
+	var blocks []meetingBlock
+	for _, b := range mergeMeetingBlocks(meetings, startTime, endTime) {
+		for _, sess := range sessions {
+			if sess != nil && sess.StartTime <= b.end+idleMs && sess.EndTime >= b.start-idleMs {
+				blocks = append(blocks, b)
+				break
+			}
+		}
+	}
+	if len(blocks) == 0 {
+		return sessions
+	}
+
+	out := make([]*schema.Session, 0, len(sessions)+len(blocks))
+	for _, sess := range sessions {
+		if sess != nil {
+			out = append(out, splitAroundMeetings(sess, blocks)...)
+		}
+	}
+	for _, b := range blocks {
+		meta := make(schema.JSONMap)
+		setSessionCalendarEventIDs(meta, b.ids)
+		out = append(out, &schema.Session{
+			StartTime: b.start,
+			EndTime:   b.end,
+			Category:  schema.SessionCategoryMeeting,
+			Metadata:  meta,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartTime < out[j].StartTime })
+	return out
+}
+
+// mergeMeetingBlocks 把日程裁剪到 [startTime, endTime] 后按时间合并
+func mergeMeetingBlocks(meetings []schema.CalendarEvent, startTime, endTime int64) []meetingBlock {
+	sorted := append([]schema.CalendarEvent(nil), meetings...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].StartTime < sorted[j].StartTime })
+
+	var blocks []meetingBlock
+	for _, m := range sorted {
+		start, end := max(m.StartTime, startTime), min(m.EndTime, endTime)
+		if m.ID <= 0 || end <= start {
+			continue
+		}
+		if n := len(blocks); n > 0 && start <= blocks[n-1].end {
+			blocks[n-1].end = max(blocks[n-1].end, end)
+			blocks[n-1].ids = append(blocks[n-1].ids, m.ID)
+			continue
+		}
+		blocks = append(blocks, meetingBlock{start: start, end: end, ids: []int64{m.ID}})
+	}
+	return blocks
+}
+
+// splitAroundMeetings 去掉会话中与会议重叠的部分；证据按闭区间归并，片段与会议之间留 1ms 避免重复归属
+func splitAroundMeetings(sess *schema.Session, blocks []meetingBlock) []*schema.Session {
+	var out []*schema.Session
+	cur := sess.StartTime
+	for _, b := range blocks {
+		if b.end < cur || b.start > sess.EndTime {
+			continue
+		}
+		if b.start-1 > cur {
+			out = append(out, &schema.Session{StartTime: cur, EndTime: b.start - 1, Metadata: make(schema.JSONMap)})
+		}
+		cur = b.end + 1
+	}
+	if cur == sess.StartTime {
+		return []*schema.Session{sess}
+	}
+	if cur < sess.EndTime {
+		out = append(out, &schema.Session{StartTime: cur, EndTime: sess.EndTime, Metadata: make(schema.JSONMap)})
+	}
+	return out
+}
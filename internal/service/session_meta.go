@@ -7,6 +7,26 @@ const (
 	degradedReasonDiffInsightPending = "diff_insight_pending"
 )
 
+// sessionEvidenceKeys 会话证据索引（metadata 中的 ID 列表）；新增证据类型时在此登记
+var sessionEvidenceKeys = []string{
+	schema.SessionMetaDiffIDs,
+	schema.SessionMetaBrowserEventIDs,
+	schema.SessionMetaCommitIDs,
+	schema.SessionMetaSearchTermIDs,
+	schema.SessionMetaTerminalCmdIDs,
+	schema.SessionMetaCalendarIDs,
+}
+
+// hasSessionEvidence 会话是否关联了任意一类证据（含编辑器心跳的文件聚合）
+func hasSessionEvidence(meta schema.JSONMap) bool {
+	for _, key := range sessionEvidenceKeys {
+		if len(schema.GetInt64Slice(meta, key)) > 0 {
+			return true
+		}
+	}
+	return len(schema.GetSessionEditorFiles(meta)) > 0
+}
+
 func getSessionDiffIDs(meta schema.JSONMap) []int64 {
 	return schema.GetInt64Slice(meta, schema.SessionMetaDiffIDs)
 }
@@ -23,6 +43,38 @@ func setSessionBrowserEventIDs(meta schema.JSONMap, ids []int64) {
 	schema.SetInt64Slice(meta, schema.SessionMetaBrowserEventIDs, ids)
 }
 
+func getSessionCommitIDs(meta schema.JSONMap) []int64 {
+	return schema.GetInt64Slice(meta, schema.SessionMetaCommitIDs)
+}
+
+func setSessionCommitIDs(meta schema.JSONMap, ids []int64) {
+	schema.SetInt64Slice(meta, schema.SessionMetaCommitIDs, ids)
+}
+
+func getSessionSearchTermIDs(meta schema.JSONMap) []int64 {
+	return schema.GetInt64Slice(meta, schema.SessionMetaSearchTermIDs)
+}
+
+func setSessionSearchTermIDs(meta schema.JSONMap, ids []int64) {
+	schema.SetInt64Slice(meta, schema.SessionMetaSearchTermIDs, ids)
+}
+
+func getSessionTerminalCommandIDs(meta schema.JSONMap) []int64 {
+	return schema.GetInt64Slice(meta, schema.SessionMetaTerminalCmdIDs)
+}
+
+func setSessionTerminalCommandIDs(meta schema.JSONMap, ids []int64) {
+	schema.SetInt64Slice(meta, schema.SessionMetaTerminalCmdIDs, ids)
+}
+
+func getSessionCalendarEventIDs(meta schema.JSONMap) []int64 {
+	return schema.GetInt64Slice(meta, schema.SessionMetaCalendarIDs)
+}
+
+func setSessionCalendarEventIDs(meta schema.JSONMap, ids []int64) {
+	schema.SetInt64Slice(meta, schema.SessionMetaCalendarIDs, ids)
+}
+
 func getSessionMetaString(meta schema.JSONMap, key string) string {
 	if meta == nil {
 		return ""
@@ -20,7 +20,12 @@ type SessionSemanticService struct {
 	diffRepo    DiffRepository
 	eventRepo   EventRepository
 	browserRepo BrowserEventRepository
-	rag         RAGQuerier // 可选
+	searchRepo  SearchTermRepository      // 可选
+	termRepo    TerminalCommandRepository // 可选
+	calendar    CalendarEventRepository   // 可选
+	rag         RAGQuerier                // 可选
+	domains     *DomainClassifier         // 可选：为空时使用内置域名规则
+	devPorts    []DevPortHint             // 可选：项目的本地开发服务端口
 
 	lastEnrichAt atomic.Int64
 	enrichErrors atomic.Int64
@@ -51,6 +56,27 @@ func NewSessionSemanticService(
 	}
 }
 
+// SetSearchTermRepository 设置搜索词仓储（可选），搜索词作为“搜索过”证据传给 AI
+func (s *SessionSemanticService) SetSearchTermRepository(repo SearchTermRepository) {
+	s.searchRepo = repo
+}
+
+// SetTerminalCommandRepository 设置终端命令仓储（可选），执行过的命令作为操作证据传给 AI
+func (s *SessionSemanticService) SetTerminalCommandRepository(repo TerminalCommandRepository) {
+	s.termRepo = repo
+}
+
+// SetCalendarRepository 设置日程仓储（可选），会议会话的日程标题作为证据传给 AI
+func (s *SessionSemanticService) SetCalendarRepository(repo CalendarEventRepository) {
+	s.calendar = repo
+}
+
+// SetDevServerHints 设置域名分类与项目端口（可选），用于把 localhost 浏览关联到正在开发的项目
+func (s *SessionSemanticService) SetDevServerHints(domains *DomainClassifier, hints []DevPortHint) {
+	s.domains = domains
+	s.devPorts = hints
+}
+
 // SetRAG 设置 RAG 查询服务（可选）
 func (s *SessionSemanticService) SetRAG(rag RAGQuerier) {
 	s.rag = rag
@@ -244,8 +270,10 @@ func (s *SessionSemanticService) enrichOne(ctx context.Context, sess *schema.Ses
 	// 窗口标题证据：弥补“只有 app 分钟数”导致的语义缺失（例如 VSCode 当前文件名/项目名）。
 	// 仅聚合 Top 列表以控制 prompt 规模，避免把整个时间轴塞进 AI。
 	var windowTitleInfos []ai.WindowTitleInfo
+	var windowEvents []schema.Event
 	if s.eventRepo != nil {
 		if rawEvents, err := s.eventRepo.GetByTimeRange(ctx, sess.StartTime, sess.EndTime); err == nil {
+			windowEvents = rawEvents
 			windowTitleInfos = TopWindowTitleInfosFromEvents(rawEvents, 10)
 		} else {
 			slog.Debug("查询窗口事件失败（跳过窗口标题证据）", "session_id", sess.ID, "error", err)
@@ -271,6 +299,27 @@ func (s *SessionSemanticService) enrichOne(ctx context.Context, sess *schema.Ses
 	}
 	setSessionBrowserEventIDs(meta, browserIDs)
 
+	searches := s.sessionSearches(ctx, sess, meta)
+	commands := s.sessionCommands(ctx, sess, meta)
+	meetings := s.sessionMeetings(ctx, sess, meta)
+
+	// localhost 浏览：能关联到项目的视为手动测试，不再当作“查阅资料”
+	manualTesting := linkLocalBrowsing(browserEvents, diffs, windowEvents, s.devPorts, s.domains)
+	schema.SetSessionManualTesting(meta, manualTesting)
+	manualTestingIDs := make(map[int64]struct{})
+	manualTestingInfos := make([]ai.ManualTestingInfo, 0, len(manualTesting))
+	for _, m := range manualTesting {
+		for _, id := range m.EventIDs {
+			manualTestingIDs[id] = struct{}{}
+		}
+		manualTestingInfos = append(manualTestingInfos, ai.ManualTestingInfo{
+			Project:     m.Name,
+			Hosts:       m.Hosts,
+			Visits:      m.Visits,
+			DurationSec: m.Seconds,
+		})
+	}
+
 	// 技能聚合：从已分析 Diff 归因（避免凭空推断）
 	skillNameToKey := make(map[string]string)
 	skillKeyToName := make(map[string]string)
@@ -308,31 +357,49 @@ func (s *SessionSemanticService) enrichOne(ctx context.Context, sess *schema.Ses
 	browserInfos := make([]ai.BrowserInfo, 0, 10)
 	domainCount := make(map[string]int)
 	for _, e := range browserEvents {
+		if e.Transition == schema.BrowserTransitionRedirect {
+			continue
+		}
+		if _, ok := manualTestingIDs[e.ID]; ok {
+			continue
+		}
 		if e.Domain != "" {
 			domainCount[e.Domain]++
 		}
 		if len(browserInfos) < 10 {
 			browserInfos = append(browserInfos, ai.BrowserInfo{
-				Domain: e.Domain,
-				Title:  truncateRunes(strings.TrimSpace(e.Title), 80),
-				URL:    truncateRunes(strings.TrimSpace(e.URL), 200),
+				Domain:      e.Domain,
+				Title:       truncateRunes(strings.TrimSpace(e.Title), 80),
+				URL:         truncateRunes(strings.TrimSpace(e.URL), 200),
+				DurationSec: e.Duration,
 			})
 		}
 	}
 	topDomains := topKeysByCount(domainCount, 6)
 
+	// 同一文件只有最后一条 Diff 的累计内容代表会话结束时的改动，作为证据节选附上
+	latestByFile := make(map[string]int, len(diffs))
+	for i, d := range diffs {
+		if j, ok := latestByFile[d.FilePath]; !ok || d.Timestamp >= diffs[j].Timestamp {
+			latestByFile[d.FilePath] = i
+		}
+	}
 	diffInfos := make([]ai.DiffInfo, 0, len(diffs))
 	diffInsightPending := false
-	for _, d := range diffs {
+	for i, d := range diffs {
 		insight := strings.TrimSpace(d.AIInsight)
 		if s.analyzer != nil && insight == "" {
 			diffInsightPending = true
 		}
+		cumulative := ""
+		if latestByFile[d.FilePath] == i {
+			cumulative = truncateRunes(strings.TrimSpace(d.CumulativeContent), 600)
+		}
 		diffInfos = append(diffInfos, ai.DiffInfo{
 			FileName:     d.FileName,
 			Language:     d.Language,
 			Insight:      truncateRunes(insight, 160),
-			DiffContent:  "",
+			DiffContent:  cumulative,
 			LinesChanged: d.LinesAdded + d.LinesDeleted,
 		})
 	}
@@ -388,16 +455,20 @@ func (s *SessionSemanticService) enrichOne(ctx context.Context, sess *schema.Ses
 	if shouldCallAI {
 		originalSummary := summary
 		req := &ai.SessionSummaryRequest{
-			SessionID:    sess.ID,
-			Date:         sess.Date,
-			TimeRange:    sess.TimeRange,
-			PrimaryApp:   sess.PrimaryApp,
-			AppUsage:     topApps,
-			WindowTitles: windowTitleInfos,
-			Diffs:        diffInfos,
-			Browser:      browserInfos,
-			SkillsHint:   skillNames,
-			Memories:     memories,
+			SessionID:     sess.ID,
+			Date:          sess.Date,
+			TimeRange:     sess.TimeRange,
+			PrimaryApp:    sess.PrimaryApp,
+			AppUsage:      topApps,
+			WindowTitles:  windowTitleInfos,
+			Diffs:         diffInfos,
+			Browser:       browserInfos,
+			Searches:      searches,
+			Commands:      commands,
+			Meetings:      meetings,
+			ManualTesting: manualTestingInfos,
+			SkillsHint:    skillNames,
+			Memories:      memories,
 		}
 		if res, err := s.analyzer.GenerateSessionSummary(ctx, req); err == nil && res != nil {
 			nextSummary := strings.TrimSpace(res.Summary)
@@ -429,12 +500,16 @@ func (s *SessionSemanticService) enrichOne(ctx context.Context, sess *schema.Ses
 	}
 
 	if summary == "" {
-		summary = fallbackSessionSummary(sess, diffs, topDomains, skillNames)
+		summary = fallbackSessionSummary(sess, diffs, topDomains, skillNames, manualTesting, meetings)
 		semanticVersion = sessionSemanticVersionV2
 	}
 	if category == "" {
 		category = fallbackSessionCategory(diffs, browserEvents)
 	}
+	// 会议时段切出的会话始终归为会议，AI 给出的分类只影响摘要
+	if len(meetings) > 0 {
+		category = schema.SessionCategoryMeeting
+	}
 
 	diffCount := len(getSessionDiffIDs(meta))
 	browserCount := len(getSessionBrowserEventIDs(meta))
@@ -515,8 +590,11 @@ func fallbackSessionCategory(diffs []schema.Diff, browser []schema.BrowserEvent)
 }
 
 // fallbackSessionSummary 生成默认的会话摘要
-func fallbackSessionSummary(sess *schema.Session, diffs []schema.Diff, topDomains []string, skills []string) string {
+func fallbackSessionSummary(sess *schema.Session, diffs []schema.Diff, topDomains []string, skills []string, manualTesting []schema.SessionManualTesting, meetings []string) string {
 	parts := []string{}
+	if len(meetings) > 0 {
+		parts = append(parts, "参加会议「"+strings.Join(meetings[:minInt(2, len(meetings))], "」「")+"」")
+	}
 	if len(skills) > 0 {
 		parts = append(parts, "围绕 "+strings.Join(skills[:minInt(3, len(skills))], "、"))
 	}
@@ -541,6 +619,9 @@ func fallbackSessionSummary(sess *schema.Session, diffs []schema.Diff, topDomain
 	if sess != nil && strings.TrimSpace(sess.PrimaryApp) != "" {
 		parts = append(parts, "主要在 "+sess.PrimaryApp)
 	}
+	if len(manualTesting) > 0 && manualTesting[0].Name != "" {
+		parts = append(parts, "在本地手动测试 "+manualTesting[0].Name)
+	}
 	if len(topDomains) > 0 {
 		parts = append(parts, "查阅 "+strings.Join(topDomains[:minInt(2, len(topDomains))], "、"))
 	}
@@ -571,3 +652,99 @@ func uniqueNonEmpty(in []string, limit int) []string {
 	}
 	return out
 }
+
+// sessionSearches 会话内搜索过的关键词（去重，按时间先后）；优先用索引 ID，否则按时间窗补全
+func (s *SessionSemanticService) sessionSearches(ctx context.Context, sess *schema.Session, meta schema.JSONMap) []string {
+	if s.searchRepo == nil {
+		return nil
+	}
+	ids := getSessionSearchTermIDs(meta)
+	var (
+		terms []schema.SearchTerm
+		err   error
+	)
+	if len(ids) > 0 {
+		terms, err = s.searchRepo.GetByIDs(ctx, ids)
+	} else {
+		terms, err = s.searchRepo.GetByTimeRange(ctx, sess.StartTime, sess.EndTime)
+	}
+	if err != nil {
+		slog.Debug("查询搜索词失败（跳过搜索证据）", "session_id", sess.ID, "error", err)
+		return nil
+	}
+	if len(ids) == 0 && len(terms) > 0 {
+		ids = make([]int64, 0, len(terms))
+		for _, t := range terms {
+			ids = append(ids, t.ID)
+		}
+		setSessionSearchTermIDs(meta, ids)
+	}
+
+	out := make([]string, 0, len(terms))
+	seen := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		key := strings.ToLower(strings.TrimSpace(t.Term))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, strings.TrimSpace(t.Term))
+	}
+	return out
+}
+
+// sessionCommands 会话内执行过的终端命令（按时间先后，连续重复的只保留一次）；优先用索引 ID，否则按时间窗补全
+func (s *SessionSemanticService) sessionCommands(ctx context.Context, sess *schema.Session, meta schema.JSONMap) []string {
+	if s.termRepo == nil {
+		return nil
+	}
+	ids := getSessionTerminalCommandIDs(meta)
+	var (
+		cmds []schema.TerminalCommand
+		err  error
+	)
+	if len(ids) > 0 {
+		cmds, err = s.termRepo.GetByIDs(ctx, ids)
+	} else {
+		cmds, err = s.termRepo.GetByTimeRange(ctx, sess.StartTime, sess.EndTime)
+	}
+	if err != nil {
+		slog.Debug("查询终端命令失败（跳过命令证据）", "session_id", sess.ID, "error", err)
+		return nil
+	}
+	if len(ids) == 0 && len(cmds) > 0 {
+		ids = make([]int64, 0, len(cmds))
+		for _, c := range cmds {
+			ids = append(ids, c.ID)
+		}
+		setSessionTerminalCommandIDs(meta, ids)
+	}
+
+	out := make([]string, 0, len(cmds))
+	for _, c := range cmds {
+		cmd := strings.TrimSpace(c.Command)
+		if cmd == "" || (len(out) > 0 && out[len(out)-1] == cmd) {
+			continue
+		}
+		out = append(out, cmd)
+	}
+	return out
+}
+
+// sessionMeetings 会议会话关联的日程标题（去重）；只按索引 ID 查询，普通会话不按时间窗补全
+func (s *SessionSemanticService) sessionMeetings(ctx context.Context, sess *schema.Session, meta schema.JSONMap) []string {
+	ids := getSessionCalendarEventIDs(meta)
+	if s.calendar == nil || len(ids) == 0 {
+		return nil
+	}
+	events, err := s.calendar.GetByIDs(ctx, ids)
+	if err != nil {
+		slog.Debug("查询日程失败（跳过会议证据）", "session_id", sess.ID, "error", err)
+		return nil
+	}
+	titles := make([]string, 0, len(events))
+	for _, e := range events {
+		titles = append(titles, e.Title)
+	}
+	return uniqueNonEmpty(titles, 10)
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -159,10 +160,17 @@ func (f fakeBrowserRepoForSemantic) GetByTimeRange(ctx context.Context, startTim
 func (f fakeBrowserRepoForSemantic) GetByIDs(ctx context.Context, ids []int64) ([]schema.BrowserEvent, error) {
 	return nil, nil
 }
+func (f fakeBrowserRepoForSemantic) UpdateVisitDuration(ctx context.Context, browser, profile string, visitID int64, seconds int) error {
+	return nil
+}
+func (f fakeBrowserRepoForSemantic) FillDurations(ctx context.Context, durations map[int64]int) error {
+	return nil
+}
 
 type fakeAnalyzerForSemantic struct {
 	sessionResult *ai.SessionSummaryResult
 	called        int
+	lastReq       *ai.SessionSummaryRequest
 }
 
 func (f *fakeAnalyzerForSemantic) AnalyzeDiff(ctx context.Context, filePath, language, diffContent string, existingSkills []ai.SkillInfo) (*ai.DiffInsight, error) {
@@ -176,6 +184,7 @@ func (f *fakeAnalyzerForSemantic) GenerateWeeklySummary(ctx context.Context, req
 }
 func (f *fakeAnalyzerForSemantic) GenerateSessionSummary(ctx context.Context, req *ai.SessionSummaryRequest) (*ai.SessionSummaryResult, error) {
 	f.called++
+	f.lastReq = req
 	if f.sessionResult != nil {
 		return f.sessionResult, nil
 	}
@@ -351,6 +360,143 @@ func TestEnrichSessionsForDate_WaitsForDiffInsightThenUpgradesToAI(t *testing.T)
 	}
 }
 
+func TestEnrichSessionsForDate_LinksLocalhostToProject(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	baseTs := now.Truncate(time.Hour).UnixMilli()
+
+	sessionRepo := &fakeSessionRepoForSemantic{sessions: []schema.Session{
+		{ID: 1, StartTime: baseTs, EndTime: baseTs + 600_000},
+	}}
+	analyzer := &fakeAnalyzerForSemantic{}
+	svc := NewSessionSemanticService(
+		analyzer,
+		sessionRepo,
+		fakeDiffRepoForSemantic{diffs: []schema.Diff{
+			{ID: 101, Timestamp: baseTs + 1000, FileName: "App.tsx", Language: "TypeScript", AIInsight: "done", ProjectPath: "/home/dev/storefront"},
+		}},
+		fakeEventRepoForSemantic{},
+		fakeBrowserRepoForSemantic{events: []schema.BrowserEvent{
+			{ID: 201, Timestamp: baseTs + 2000, Domain: "localhost:5173", URL: "http://localhost:5173/cart", Duration: 120},
+			{ID: 202, Timestamp: baseTs + 3000, Domain: "react.dev", URL: "https://react.dev/learn", Duration: 60},
+		}},
+	)
+
+	if _, err := svc.EnrichSessionsForDate(ctx, now.Format("2006-01-02"), 10); err != nil {
+		t.Fatalf("EnrichSessionsForDate error: %v", err)
+	}
+	req := analyzer.lastReq
+	if req == nil || len(req.ManualTesting) != 1 || req.ManualTesting[0].Project != "storefront" {
+		t.Fatalf("expected manual testing of storefront, got %+v", req)
+	}
+	if len(req.Browser) != 1 || req.Browser[0].Domain != "react.dev" {
+		t.Fatalf("linked localhost visits should not be listed as browsing: %+v", req.Browser)
+	}
+	got := schema.GetSessionManualTesting(sessionRepo.updated[1].Metadata)
+	if len(got) != 1 || got[0].Source != ManualTestingSourceDiff || len(got[0].EventIDs) != 1 || got[0].EventIDs[0] != 201 {
+		t.Fatalf("unexpected manual testing meta: %+v", got)
+	}
+}
+
+func TestEnrichSessionsForDate_PassesTerminalCommands(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	baseTs := now.Truncate(time.Hour).UnixMilli()
+
+	sessionRepo := &fakeSessionRepoForSemantic{sessions: []schema.Session{
+		{ID: 1, StartTime: baseTs, EndTime: baseTs + 600_000},
+	}}
+	analyzer := &fakeAnalyzerForSemantic{}
+	svc := NewSessionSemanticService(
+		analyzer,
+		sessionRepo,
+		fakeDiffRepoForSemantic{diffs: []schema.Diff{
+			{ID: 101, Timestamp: baseTs + 1000, FileName: "0042_orders.sql", Language: "SQL", AIInsight: "done"},
+		}},
+		fakeEventRepoForSemantic{},
+		fakeBrowserRepoForSemantic{},
+	)
+	svc.SetTerminalCommandRepository(&fakeTerminalCommandRepo{cmds: []schema.TerminalCommand{
+		{ID: 1, Timestamp: baseTs + 2000, Command: "make migrate"},
+		{ID: 2, Timestamp: baseTs + 3000, Command: "make migrate"},
+		{ID: 3, Timestamp: baseTs + 4000, Command: "psql -c 'select 1'"},
+	}})
+
+	if _, err := svc.EnrichSessionsForDate(ctx, now.Format("2006-01-02"), 10); err != nil {
+		t.Fatalf("EnrichSessionsForDate error: %v", err)
+	}
+	req := analyzer.lastReq
+	if req == nil || len(req.Commands) != 2 || req.Commands[0] != "make migrate" {
+		t.Fatalf("expected deduplicated commands, got %+v", req)
+	}
+	// 旧会话没有命令索引：按时间窗补全后写回
+	if ids := getSessionTerminalCommandIDs(sessionRepo.updated[1].Metadata); len(ids) != 3 {
+		t.Fatalf("terminal_command_ids=%v, want 3 ids", ids)
+	}
+}
+
+func TestEnrichSessionsForDate_PassesLatestCumulativeDiff(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	baseTs := now.Truncate(time.Hour).UnixMilli()
+
+	analyzer := &fakeAnalyzerForSemantic{}
+	svc := NewSessionSemanticService(
+		analyzer,
+		&fakeSessionRepoForSemantic{sessions: []schema.Session{{ID: 1, StartTime: baseTs, EndTime: baseTs + 600_000}}},
+		fakeDiffRepoForSemantic{diffs: []schema.Diff{
+			{ID: 101, Timestamp: baseTs + 1000, FilePath: "/p/main.go", FileName: "main.go", Language: "Go", AIInsight: "done", CumulativeContent: "+a\n"},
+			{ID: 102, Timestamp: baseTs + 2000, FilePath: "/p/main.go", FileName: "main.go", Language: "Go", AIInsight: "done", CumulativeContent: "+a\n+b\n"},
+			{ID: 103, Timestamp: baseTs + 3000, FilePath: "/p/big.go", FileName: "big.go", Language: "Go", AIInsight: "done", CumulativeContent: strings.Repeat("x", 2000)},
+		}},
+		fakeEventRepoForSemantic{},
+		fakeBrowserRepoForSemantic{},
+	)
+
+	if _, err := svc.EnrichSessionsForDate(ctx, now.Format("2006-01-02"), 10); err != nil {
+		t.Fatalf("EnrichSessionsForDate error: %v", err)
+	}
+	req := analyzer.lastReq
+	if req == nil || len(req.Diffs) != 3 {
+		t.Fatalf("expected 3 diffs, got %+v", req)
+	}
+	// 同一文件只附最后一次的累计内容，且有长度上限
+	if req.Diffs[0].DiffContent != "" || req.Diffs[1].DiffContent != "+a\n+b" {
+		t.Fatalf("cumulative content=%q/%q", req.Diffs[0].DiffContent, req.Diffs[1].DiffContent)
+	}
+	if n := len([]rune(req.Diffs[2].DiffContent)); n == 0 || n > 603 {
+		t.Fatalf("cumulative content should be capped, got %d runes", n)
+	}
+}
+
+func TestEnrichSessionsForDate_MeetingKeepsCategory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	baseTs := now.Truncate(time.Hour).UnixMilli()
+
+	meta := schema.JSONMap{}
+	setSessionCalendarEventIDs(meta, []int64{7})
+	sessionRepo := &fakeSessionRepoForSemantic{sessions: []schema.Session{
+		{ID: 1, StartTime: baseTs, EndTime: baseTs + 1_800_000, Category: schema.SessionCategoryMeeting, Metadata: meta},
+	}}
+	analyzer := &fakeAnalyzerForSemantic{}
+	svc := NewSessionSemanticService(analyzer, sessionRepo, fakeDiffRepoForSemantic{}, fakeEventRepoForSemantic{}, fakeBrowserRepoForSemantic{})
+	svc.SetCalendarRepository(&fakeCalendarRepo{events: []schema.CalendarEvent{
+		{ID: 7, UID: "planning", StartTime: baseTs, EndTime: baseTs + 1_800_000, Title: "Sprint planning"},
+	}})
+
+	if _, err := svc.EnrichSessionsForDate(ctx, now.Format("2006-01-02"), 10); err != nil {
+		t.Fatalf("EnrichSessionsForDate error: %v", err)
+	}
+	if req := analyzer.lastReq; req == nil || len(req.Meetings) != 1 || req.Meetings[0] != "Sprint planning" {
+		t.Fatalf("expected meeting title in request, got %+v", req)
+	}
+	// AI 返回的分类不覆盖会议
+	if got := sessionRepo.updated[1].Category; got != schema.SessionCategoryMeeting {
+		t.Fatalf("category=%q, want %q", got, schema.SessionCategoryMeeting)
+	}
+}
+
 func TestFallbackSessionCategory(t *testing.T) {
 	cases := []struct {
 		diffs   []schema.Diff
@@ -378,7 +524,7 @@ func TestFallbackSessionSummary(t *testing.T) {
 	domains := []string{"github.com"}
 	skills := []string{"Go", "React"}
 
-	summary := fallbackSessionSummary(sess, diffs, domains, skills)
+	summary := fallbackSessionSummary(sess, diffs, domains, skills, nil, nil)
 
 	if summary == "" {
 		t.Fatal("fallback summary should not be empty")
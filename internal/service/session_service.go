@@ -19,6 +19,12 @@ type SessionService struct {
 	browserRepo     BrowserEventRepository
 	sessionRepo     SessionRepository
 	sessionDiffRepo SessionDiffRepository
+	commitRepo      CommitRepository          // 可选：未启用提交采集时为 nil
+	searchRepo      SearchTermRepository      // 可选：未启用搜索词提取时为 nil
+	terminalRepo    TerminalCommandRepository // 可选：未启用终端采集时为 nil
+	calendarRepo    CalendarEventRepository   // 可选：未导入日程时为 nil
+	reading         ReadingExpApplier         // 可选：阅读计入技能经验
+	domains         *DomainClassifier
 	cfg             *SessionServiceConfig
 
 	lastSplitAt  atomic.Int64
@@ -64,6 +70,32 @@ func NewSessionService(
 	}
 }
 
+// SetCommitRepository 设置提交仓储（可选），提交作为会话证据参与切分与归并
+func (s *SessionService) SetCommitRepository(repo CommitRepository) {
+	s.commitRepo = repo
+}
+
+// SetSearchTermRepository 设置搜索词仓储（可选），搜索词作为会话证据写入索引
+func (s *SessionService) SetSearchTermRepository(repo SearchTermRepository) {
+	s.searchRepo = repo
+}
+
+// SetTerminalCommandRepository 设置终端命令仓储（可选），命令作为会话证据参与切分与归并
+func (s *SessionService) SetTerminalCommandRepository(repo TerminalCommandRepository) {
+	s.terminalRepo = repo
+}
+
+// SetCalendarRepository 设置日程仓储（可选），会议时段切分为 Category=meeting 的会话
+func (s *SessionService) SetCalendarRepository(repo CalendarEventRepository) {
+	s.calendarRepo = repo
+}
+
+// SetReadingExp 启用阅读经验：切分时把映射到技能的域名停留时长计入经验
+func (s *SessionService) SetReadingExp(reading ReadingExpApplier, domains *DomainClassifier) {
+	s.reading = reading
+	s.domains = domains
+}
+
 // BuildSessionsIncremental 从最近一次会话结束处增量切分
 func (s *SessionService) BuildSessionsIncremental(ctx context.Context) (int, error) {
 	last, err := s.sessionRepo.GetLastSession(ctx)
@@ -182,15 +214,58 @@ func (s *SessionService) buildSessionsForRange(
 	if err != nil {
 		return 0, err
 	}
+	s.fillBrowserDwell(ctx, browserEvents, events, endTime)
+	if s.reading != nil {
+		if err := s.reading.ApplyReading(ctx, browserEvents, s.domains); err != nil {
+			slog.Warn("计入阅读经验失败", "error", err)
+		}
+	}
+	var commits []schema.GitCommit
+	if s.commitRepo != nil {
+		commits, err = s.commitRepo.GetByTimeRange(ctx, startTime, endTime)
+		if err != nil {
+			return 0, err
+		}
+	}
 
-	sessions := s.splitSessions(events, diffs, browserEvents, startTime, endTime)
+	var searches []schema.SearchTerm
+	if s.searchRepo != nil {
+		searches, err = s.searchRepo.GetByTimeRange(ctx, startTime, endTime)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var cmds []schema.TerminalCommand
+	if s.terminalRepo != nil {
+		cmds, err = s.terminalRepo.GetByTimeRange(ctx, startTime, endTime)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var meetings []schema.CalendarEvent
+	if s.calendarRepo != nil {
+		meetings, err = s.calendarRepo.GetByTimeRange(ctx, startTime, endTime)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// 搜索词总伴随一次浏览访问，不单独作为切分的活动点
+	sessions := s.splitSessions(events, evidencePoints(diffs, browserEvents, commits, cmds), startTime, endTime)
 	if len(sessions) == 0 {
 		return 0, nil
 	}
+	sessions = s.carveMeetings(sessions, meetings, startTime, min(endTime, time.Now().UnixMilli()))
 
-	// 证据归并：diff/browser 作为“活动点”参与切分，同时也写入证据索引（用于 drill-down 与报告追溯）。
+	// 证据归并：diff/browser/commit 作为“活动点”参与切分，同时也写入证据索引（用于 drill-down 与报告追溯）。
 	s.attachDiffs(sessions, diffs)
 	s.attachBrowserEvents(sessions, browserEvents)
+	s.attachCommits(sessions, commits)
+	s.attachSearchTerms(sessions, searches)
+	s.attachTerminalCommands(sessions, cmds)
+	s.attachEditorFiles(sessions, events)
 	sessions = s.finalizeSessions(events, sessions, startTime, endTime)
 	if len(sessions) == 0 {
 		return 0, nil
@@ -343,17 +418,34 @@ func (s *SessionService) assignSessionVersions(
 	return nil
 }
 
-// splitSessions 根据空闲间隔切分会话
-func (s *SessionService) splitSessions(events []schema.Event, diffs []schema.Diff, browserEvents []schema.BrowserEvent, startTime, endTime int64) []*schema.Session {
+// evidencePoints 汇总各类证据的时间点（瞬时活动点），按时间升序
+func evidencePoints(diffs []schema.Diff, browserEvents []schema.BrowserEvent, commits []schema.GitCommit, cmds []schema.TerminalCommand) []int64 {
+	points := make([]int64, 0, len(diffs)+len(browserEvents)+len(commits)+len(cmds))
+	for _, d := range diffs {
+		points = append(points, d.Timestamp)
+	}
+	for _, be := range browserEvents {
+		points = append(points, be.Timestamp)
+	}
+	for _, c := range commits {
+		points = append(points, c.Timestamp)
+	}
+	for _, c := range cmds {
+		points = append(points, c.Timestamp)
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i] < points[j] })
+	return points
+}
+
+// splitSessions 根据空闲间隔切分会话；points 为 diff/浏览/提交等证据的时间点（已升序）
+func (s *SessionService) splitSessions(events []schema.Event, points []int64, startTime, endTime int64) []*schema.Session {
 	idleMs := int64(s.cfg.IdleGapMinutes) * 60 * 1000
 
 	// 确保按时间排序
 	sort.Slice(events, func(i, j int) bool { return events[i].Timestamp < events[j].Timestamp })
-	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Timestamp < diffs[j].Timestamp })
-	sort.Slice(browserEvents, func(i, j int) bool { return browserEvents[i].Timestamp < browserEvents[j].Timestamp })
 
 	// 没有任何证据则不产生会话。
-	if len(events) == 0 && len(diffs) == 0 && len(browserEvents) == 0 {
+	if len(events) == 0 && len(points) == 0 {
 		return nil
 	}
 
@@ -424,29 +516,9 @@ func (s *SessionService) splitSessions(events []schema.Event, diffs []schema.Dif
 		}
 	}
 
-	const maxInt64 = int64(^uint64(0) >> 1)
-	iEv, iDiff, iBrowser := 0, 0, 0
-
-	for {
-		nextEv := maxInt64
-		if iEv < len(events) {
-			nextEv = events[iEv].Timestamp
-		}
-		nextDiff := maxInt64
-		if iDiff < len(diffs) {
-			nextDiff = diffs[iDiff].Timestamp
-		}
-		nextBrowser := maxInt64
-		if iBrowser < len(browserEvents) {
-			nextBrowser = browserEvents[iBrowser].Timestamp
-		}
-
-		if nextEv == maxInt64 && nextDiff == maxInt64 && nextBrowser == maxInt64 {
-			break
-		}
-
-		switch {
-		case nextEv <= nextDiff && nextEv <= nextBrowser:
+	iEv, iPoint := 0, 0
+	for iEv < len(events) || iPoint < len(points) {
+		if iEv < len(events) && (iPoint >= len(points) || events[iEv].Timestamp <= points[iPoint]) {
 			ev := events[iEv]
 			iEv++
 			evStart := clamp(ev.Timestamp, startTime, endTime)
@@ -456,19 +528,12 @@ func (s *SessionService) splitSessions(events []schema.Event, diffs []schema.Dif
 			}
 			// duration=0 的 window event 视为瞬时活动点
 			handleActivity(evStart, evEnd)
-
-		case nextDiff <= nextBrowser:
-			d := diffs[iDiff]
-			iDiff++
-			ts := clamp(d.Timestamp, startTime, endTime)
-			handleActivity(ts, ts)
-
-		default:
-			be := browserEvents[iBrowser]
-			iBrowser++
-			ts := clamp(be.Timestamp, startTime, endTime)
-			handleActivity(ts, ts)
+			continue
 		}
+
+		ts := clamp(points[iPoint], startTime, endTime)
+		iPoint++
+		handleActivity(ts, ts)
 	}
 
 	closeSession(lastActivityEnd)
@@ -488,57 +553,50 @@ func (s *SessionService) mergeEvidenceMetadata(ctx context.Context, sess *schema
 		merged = make(schema.JSONMap)
 	}
 
-	curDiff := getSessionDiffIDs(merged)
-	curBrowser := getSessionBrowserEventIDs(merged)
-
-	newDiff := getSessionDiffIDs(sess.Metadata)
-	newBrowser := getSessionBrowserEventIDs(sess.Metadata)
-
 	changed := false
-	if len(newDiff) > 0 {
-		seen := make(map[int64]struct{}, len(curDiff))
-		for _, id := range curDiff {
-			if id > 0 {
-				seen[id] = struct{}{}
-			}
-		}
-		for _, id := range newDiff {
-			if id <= 0 {
-				continue
-			}
-			if _, ok := seen[id]; !ok {
-				changed = true
-				break
-			}
+	for _, key := range sessionEvidenceKeys {
+		cur := schema.GetInt64Slice(merged, key)
+		incoming := schema.GetInt64Slice(sess.Metadata, key)
+		if !hasNewIDs(cur, incoming) {
+			continue
 		}
+		schema.SetInt64Slice(merged, key, append(cur, incoming...))
+		changed = true
 	}
-	if !changed && len(newBrowser) > 0 {
-		seen := make(map[int64]struct{}, len(curBrowser))
-		for _, id := range curBrowser {
-			if id > 0 {
-				seen[id] = struct{}{}
-			}
-		}
-		for _, id := range newBrowser {
-			if id <= 0 {
-				continue
-			}
-			if _, ok := seen[id]; !ok {
-				changed = true
-				break
-			}
-		}
+	if files, ok := mergeEditorFiles(schema.GetSessionEditorFiles(merged), schema.GetSessionEditorFiles(sess.Metadata)); ok {
+		schema.SetSessionEditorFiles(merged, files)
+		changed = true
 	}
 	if !changed {
 		return nil
 	}
 
-	setSessionDiffIDs(merged, append(curDiff, newDiff...))
-	setSessionBrowserEventIDs(merged, append(curBrowser, newBrowser...))
 	setSessionMetaString(merged, schema.SessionMetaEvidenceHint, EvidenceHintFromCounts(len(getSessionDiffIDs(merged)), len(getSessionBrowserEventIDs(merged))))
 	return s.sessionRepo.UpdateSemantic(ctx, sess.ID, schema.SessionSemanticUpdate{Metadata: merged})
 }
 
+// hasNewIDs incoming 中是否存在 cur 未包含的有效 ID
+func hasNewIDs(cur, incoming []int64) bool {
+	if len(incoming) == 0 {
+		return false
+	}
+	seen := make(map[int64]struct{}, len(cur))
+	for _, id := range cur {
+		if id > 0 {
+			seen[id] = struct{}{}
+		}
+	}
+	for _, id := range incoming {
+		if id <= 0 {
+			continue
+		}
+		if _, ok := seen[id]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *SessionService) attachDiffs(sessions []*schema.Session, diffs []schema.Diff) {
 	if len(sessions) == 0 || len(diffs) == 0 {
 		return
@@ -600,9 +658,7 @@ func (s *SessionService) finalizeSessions(events []schema.Event, sessions []*sch
 		if sess.Metadata == nil {
 			sess.Metadata = make(schema.JSONMap)
 		}
-		hasDiff := len(getSessionDiffIDs(sess.Metadata)) > 0
-		hasBrowser := len(getSessionBrowserEventIDs(sess.Metadata)) > 0
-		hasEvidence := hasDiff || hasBrowser
+		hasEvidence := hasSessionEvidence(sess.Metadata)
 
 		duration := sess.EndTime - sess.StartTime
 		if duration < minDurationMs && !hasEvidence {
@@ -622,9 +678,7 @@ func (s *SessionService) finalizeSessions(events []schema.Event, sessions []*sch
 		if sess == nil {
 			continue
 		}
-		hasDiff := len(getSessionDiffIDs(sess.Metadata)) > 0
-		hasBrowser := len(getSessionBrowserEventIDs(sess.Metadata)) > 0
-		hasEvidence := hasDiff || hasBrowser
+		hasEvidence := hasSessionEvidence(sess.Metadata)
 		if strings.TrimSpace(sess.PrimaryApp) == "" && !hasEvidence {
 			continue
 		}
@@ -655,7 +709,7 @@ func (s *SessionService) fillPrimaryApp(sessions []*schema.Session, events []sch
 
 	sessIdx := 0
 	for _, ev := range events {
-		if ev.Timestamp <= 0 || strings.TrimSpace(ev.AppName) == "" || ev.Duration <= 0 {
+		if ev.Timestamp <= 0 || strings.TrimSpace(ev.AppName) == "" || ev.Duration <= 0 || !isWindowEvent(ev) {
 			continue
 		}
 		evStart := ev.Timestamp
@@ -756,6 +810,23 @@ func (s *SessionService) fillPrimaryApp(sessions []*schema.Session, events []sch
 	}
 }
 
+// fillBrowserDwell 为缺少 visit_duration 的浏览记录推断停留时长并回写（失败不影响切分）
+func (s *SessionService) fillBrowserDwell(ctx context.Context, visits []schema.BrowserEvent, windows []schema.Event, rangeEnd int64) {
+	dwell := inferBrowserDwell(visits, windows, rangeEnd)
+	if len(dwell) == 0 {
+		return
+	}
+	if err := s.browserRepo.FillDurations(ctx, dwell); err != nil {
+		slog.Warn("写入浏览停留时长失败", "error", err)
+		return
+	}
+	for i := range visits {
+		if sec, ok := dwell[visits[i].ID]; ok {
+			visits[i].Duration = sec
+		}
+	}
+}
+
 // attachBrowserEvents 将浏览器事件绑定到对应的会话
 func (s *SessionService) attachBrowserEvents(sessions []*schema.Session, events []schema.BrowserEvent) {
 	if len(sessions) == 0 || len(events) == 0 {
@@ -784,6 +855,92 @@ func (s *SessionService) attachBrowserEvents(sessions []*schema.Session, events
 	}
 }
 
+// attachCommits 将提交绑定到时间范围包含它的会话
+func (s *SessionService) attachCommits(sessions []*schema.Session, commits []schema.GitCommit) {
+	sort.Slice(commits, func(i, j int) bool { return commits[i].Timestamp < commits[j].Timestamp })
+	attachByTimestamp(sessions, len(commits),
+		func(i int) int64 { return commits[i].Timestamp },
+		func(i int) int64 { return commits[i].ID },
+		getSessionCommitIDs, setSessionCommitIDs)
+}
+
+// attachSearchTerms 将搜索词绑定到时间范围包含它的会话
+func (s *SessionService) attachSearchTerms(sessions []*schema.Session, terms []schema.SearchTerm) {
+	sort.Slice(terms, func(i, j int) bool { return terms[i].Timestamp < terms[j].Timestamp })
+	attachByTimestamp(sessions, len(terms),
+		func(i int) int64 { return terms[i].Timestamp },
+		func(i int) int64 { return terms[i].ID },
+		getSessionSearchTermIDs, setSessionSearchTermIDs)
+}
+
+// attachTerminalCommands 将终端命令绑定到时间范围包含它的会话
+func (s *SessionService) attachTerminalCommands(sessions []*schema.Session, cmds []schema.TerminalCommand) {
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Timestamp < cmds[j].Timestamp })
+	attachByTimestamp(sessions, len(cmds),
+		func(i int) int64 { return cmds[i].Timestamp },
+		func(i int) int64 { return cmds[i].ID },
+		getSessionTerminalCommandIDs, setSessionTerminalCommandIDs)
+}
+
+// attachByTimestamp 把按时间排序的 n 条记录绑定到时间范围包含它的会话：ts/id 取第 i 条的时间戳与 ID，
+// get/set 读写会话元数据中的 ID 列表
+func attachByTimestamp(
+	sessions []*schema.Session,
+	n int,
+	ts func(i int) int64,
+	id func(i int) int64,
+	get func(schema.JSONMap) []int64,
+	set func(schema.JSONMap, []int64),
+) {
+	sessIdx := 0
+	for i := 0; i < n && sessIdx < len(sessions); i++ {
+		t := ts(i)
+		if id(i) <= 0 {
+			continue
+		}
+		for sessIdx < len(sessions) && t > sessions[sessIdx].EndTime {
+			sessIdx++
+		}
+		if sessIdx >= len(sessions) {
+			break
+		}
+		sess := sessions[sessIdx]
+		if t < sess.StartTime || t > sess.EndTime {
+			continue
+		}
+		if sess.Metadata == nil {
+			sess.Metadata = make(schema.JSONMap)
+		}
+		set(sess.Metadata, append(get(sess.Metadata), id(i)))
+	}
+}
+
+// attachEditorFiles 把编辑器插件心跳按文件聚合到所在会话
+func (s *SessionService) attachEditorFiles(sessions []*schema.Session, events []schema.Event) {
+	beats := make([]schema.Event, 0)
+	for _, e := range events {
+		if isEditorHeartbeat(e) {
+			beats = append(beats, e)
+		}
+	}
+	if len(beats) == 0 {
+		return
+	}
+	for _, sess := range sessions {
+		if sess == nil {
+			continue
+		}
+		files := editorFilesFromEvents(beats, sess.StartTime, sess.EndTime)
+		if len(files) == 0 {
+			continue
+		}
+		if sess.Metadata == nil {
+			sess.Metadata = make(schema.JSONMap)
+		}
+		schema.SetSessionEditorFiles(sess.Metadata, files)
+	}
+}
+
 // formatDate 将时间戳格式化为日期字符串
 func formatDate(ts int64) string {
 	return time.UnixMilli(ts).Format("2006-01-02")
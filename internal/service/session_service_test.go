@@ -85,6 +85,35 @@ func (f fakeBrowserRepoForSession) GetByTimeRange(ctx context.Context, startTime
 func (f fakeBrowserRepoForSession) GetByIDs(ctx context.Context, ids []int64) ([]schema.BrowserEvent, error) {
 	return nil, nil
 }
+func (f fakeBrowserRepoForSession) UpdateVisitDuration(ctx context.Context, browser, profile string, visitID int64, seconds int) error {
+	return nil
+}
+func (f fakeBrowserRepoForSession) FillDurations(ctx context.Context, durations map[int64]int) error {
+	return nil
+}
+
+type fakeCommitRepoForSession struct {
+	commits []schema.GitCommit
+}
+
+func (f fakeCommitRepoForSession) BatchInsert(ctx context.Context, commits []*schema.GitCommit) (int64, error) {
+	return 0, nil
+}
+func (f fakeCommitRepoForSession) GetByDate(ctx context.Context, date string) ([]schema.GitCommit, error) {
+	return f.commits, nil
+}
+func (f fakeCommitRepoForSession) GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.GitCommit, error) {
+	var out []schema.GitCommit
+	for _, c := range f.commits {
+		if c.Timestamp >= startTime && c.Timestamp <= endTime {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+func (f fakeCommitRepoForSession) GetByIDs(ctx context.Context, ids []int64) ([]schema.GitCommit, error) {
+	return nil, nil
+}
 
 type fakeSessionRepoForSession struct {
 	sessions    []*schema.Session
@@ -499,3 +528,200 @@ func TestBuildSessionsForRange_ShortSessionWithDiffKept(t *testing.T) {
 		t.Fatalf("diff_ids=%v, want [101]", diffIDs)
 	}
 }
+
+func TestBuildSessionsForRange_CommitsAttachedAsEvidence(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	baseTs := now.Truncate(time.Hour).UnixMilli()
+
+	events := []schema.Event{
+		{Timestamp: baseTs, AppName: "code.exe", Duration: 90}, // 1.5min
+	}
+	commits := []schema.GitCommit{
+		{ID: 7, Hash: "abc", Timestamp: baseTs + 60*1000},
+		{ID: 8, Hash: "def", Timestamp: baseTs + 3*60*60*1000}, // 区间外
+	}
+
+	sessionRepo := &fakeSessionRepoForSession{}
+	svc := NewSessionService(
+		fakeEventRepoForSession{events: events},
+		fakeDiffRepoForSession{},
+		fakeBrowserRepoForSession{},
+		sessionRepo,
+		nil,
+		&SessionServiceConfig{IdleGapMinutes: 6, MinSessionMinutes: 2},
+	)
+	svc.SetCommitRepository(fakeCommitRepoForSession{commits: commits})
+
+	created, err := svc.BuildSessionsForRange(ctx, baseTs, baseTs+10*60*1000)
+	if err != nil {
+		t.Fatalf("BuildSessionsForRange error: %v", err)
+	}
+	// 提交作为证据，使短会话得以保留
+	if created != 1 || len(sessionRepo.sessions) != 1 {
+		t.Fatalf("created=%d, persisted=%d, want 1", created, len(sessionRepo.sessions))
+	}
+	commitIDs := getSessionCommitIDs(sessionRepo.sessions[0].Metadata)
+	if len(commitIDs) != 1 || commitIDs[0] != 7 {
+		t.Fatalf("commit_ids=%v, want [7]", commitIDs)
+	}
+}
+
+func TestBuildSessionsForRange_SearchTermsAttachedAsEvidence(t *testing.T) {
+	ctx := context.Background()
+	baseTs := time.Now().Truncate(time.Hour).UnixMilli()
+
+	events := []schema.Event{
+		{Timestamp: baseTs, AppName: "code.exe", Duration: 600},
+	}
+	searches := &fakeSearchTermRepo{terms: []schema.SearchTerm{
+		{ID: 3, Timestamp: baseTs + 2*60*1000, Term: "gorm upsert"},
+		{ID: 4, Timestamp: baseTs + 5*60*1000, Term: "sqlite wal"},
+		{ID: 5, Timestamp: baseTs + 3*60*60*1000, Term: "区间外"},
+	}}
+
+	sessionRepo := &fakeSessionRepoForSession{}
+	svc := NewSessionService(
+		fakeEventRepoForSession{events: events},
+		fakeDiffRepoForSession{},
+		fakeBrowserRepoForSession{},
+		sessionRepo,
+		nil,
+		&SessionServiceConfig{IdleGapMinutes: 6, MinSessionMinutes: 2},
+	)
+	svc.SetSearchTermRepository(searches)
+
+	if _, err := svc.BuildSessionsForRange(ctx, baseTs, baseTs+10*60*1000); err != nil {
+		t.Fatalf("BuildSessionsForRange error: %v", err)
+	}
+	if len(sessionRepo.sessions) != 1 {
+		t.Fatalf("persisted=%d, want 1", len(sessionRepo.sessions))
+	}
+	ids := getSessionSearchTermIDs(sessionRepo.sessions[0].Metadata)
+	if len(ids) != 2 || ids[0] != 3 || ids[1] != 4 {
+		t.Fatalf("search_term_ids=%v, want [3 4]", ids)
+	}
+}
+
+type fakeCalendarRepo struct {
+	events []schema.CalendarEvent
+}
+
+func (f *fakeCalendarRepo) Upsert(ctx context.Context, events []*schema.CalendarEvent) error {
+	for _, e := range events {
+		found := false
+		for i := range f.events {
+			if f.events[i].UID == e.UID && f.events[i].StartTime == e.StartTime {
+				e.ID = f.events[i].ID
+				f.events[i] = *e
+				found = true
+				break
+			}
+		}
+		if !found {
+			e.ID = int64(len(f.events) + 100)
+			f.events = append(f.events, *e)
+		}
+	}
+	return nil
+}
+func (f *fakeCalendarRepo) GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.CalendarEvent, error) {
+	var out []schema.CalendarEvent
+	for _, e := range f.events {
+		if e.StartTime <= endTime && e.EndTime > startTime {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+func (f *fakeCalendarRepo) GetByIDs(ctx context.Context, ids []int64) ([]schema.CalendarEvent, error) {
+	var out []schema.CalendarEvent
+	for _, id := range ids {
+		for _, e := range f.events {
+			if e.ID == id {
+				out = append(out, e)
+			}
+		}
+	}
+	return out, nil
+}
+func (f *fakeCalendarRepo) DeleteByIDs(ctx context.Context, ids []int64) error {
+	kept := f.events[:0]
+	for _, e := range f.events {
+		if !containsInt64(ids, e.ID) {
+			kept = append(kept, e)
+		}
+	}
+	f.events = kept
+	return nil
+}
+
+func containsInt64(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBuildSessionsForRange_MeetingsCarvedOut(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+	at := func(h, m int) int64 {
+		return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute).UnixMilli()
+	}
+
+	// 9:00-10:30 持续在编辑器和会议软件里活动
+	var events []schema.Event
+	for ts := at(9, 0); ts < at(10, 30); ts += 60 * 1000 {
+		app := "code.exe"
+		if ts >= at(9, 30) && ts < at(10, 0) {
+			app = "zoom.exe"
+		}
+		events = append(events, schema.Event{Timestamp: ts, AppName: app, Duration: 60})
+	}
+	calendar := &fakeCalendarRepo{events: []schema.CalendarEvent{
+		{ID: 1, UID: "sync", StartTime: at(9, 30), EndTime: at(9, 50), Title: "Sync"},
+		{ID: 2, UID: "review", StartTime: at(9, 45), EndTime: at(10, 0), Title: "Review"},
+		{ID: 3, UID: "offsite", StartTime: at(15, 0), EndTime: at(16, 0), Title: "无人参加"},
+	}}
+
+	sessionRepo := &fakeSessionRepoForSession{}
+	svc := NewSessionService(
+		fakeEventRepoForSession{events: events},
+		fakeDiffRepoForSession{diffs: []schema.Diff{{ID: 9, Timestamp: at(10, 10)}}},
+		fakeBrowserRepoForSession{},
+		sessionRepo,
+		nil,
+		&SessionServiceConfig{IdleGapMinutes: 10, MinSessionMinutes: 2},
+	)
+	svc.SetCalendarRepository(calendar)
+
+	if _, err := svc.BuildSessionsForRange(ctx, day.UnixMilli(), day.AddDate(0, 0, 1).UnixMilli()-1); err != nil {
+		t.Fatalf("BuildSessionsForRange error: %v", err)
+	}
+	got := sessionRepo.sessions
+	if len(got) != 3 {
+		for _, s := range got {
+			t.Logf("%s %s %v", s.TimeRange, s.Category, s.Metadata)
+		}
+		t.Fatalf("persisted=%d, want 3 (before, meeting, after)", len(got))
+	}
+	meeting := got[1]
+	if meeting.Category != schema.SessionCategoryMeeting || meeting.StartTime != at(9, 30) || meeting.EndTime != at(10, 0) {
+		t.Fatalf("meeting session = %+v", meeting)
+	}
+	if ids := getSessionCalendarEventIDs(meeting.Metadata); len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Fatalf("calendar_event_ids=%v, want [1 2]", ids)
+	}
+	if meeting.PrimaryApp != "zoom.exe" {
+		t.Fatalf("meeting primary app = %q, want zoom.exe", meeting.PrimaryApp)
+	}
+	if got[0].EndTime >= at(9, 30) || got[0].Category != "" {
+		t.Fatalf("session before meeting = %+v", got[0])
+	}
+	if got[2].StartTime <= at(10, 0) || len(getSessionDiffIDs(got[2].Metadata)) != 1 {
+		t.Fatalf("session after meeting = %+v", got[2])
+	}
+}
@@ -252,6 +252,44 @@ func (s *SkillService) UpdateSkillsFromDiffsWithCategory(ctx context.Context, di
 	return s.ApplyContributions(ctx, contribs)
 }
 
+// ApplyReading 按域名→技能映射把阅读时长计入经验；经验策略未实现 ReadingExpPolicy 时不计。
+// 贡献以浏览事件 ID 去重，停留时长补全前（为 0）的访问留待下次切分再计
+func (s *SkillService) ApplyReading(ctx context.Context, visits []schema.BrowserEvent, domains *DomainClassifier) error {
+	policy, ok := s.expPolicy.(ReadingExpPolicy)
+	if !ok || len(visits) == 0 {
+		return nil
+	}
+	contribs := make([]SkillContribution, 0)
+	for _, v := range visits {
+		if v.ID <= 0 || v.Transition == schema.BrowserTransitionRedirect {
+			continue
+		}
+		class := domains.Classify(v.Domain)
+		if class.Skill == "" {
+			continue
+		}
+		exp := policy.CalcReadingExp(v.Duration)
+		if exp <= 0 {
+			continue
+		}
+		ctxText := strings.TrimSpace(v.Title)
+		if ctxText == "" {
+			ctxText = v.Domain
+		}
+		contribs = append(contribs, SkillContribution{
+			Source:              "browser",
+			SkillKey:            normalizeKey(class.Skill),
+			SkillName:           class.Skill,
+			Category:            class.SkillCategory,
+			Exp:                 exp,
+			EvidenceID:          v.ID,
+			ContributionContext: truncateRunes(ctxText, 120),
+			Timestamp:           v.Timestamp,
+		})
+	}
+	return s.ApplyContributions(ctx, contribs)
+}
+
 // ApplyDecayToAll 对所有技能应用衰减
 func (s *SkillService) ApplyDecayToAll(ctx context.Context) error {
 	skills, err := s.skillRepo.GetAll(ctx)
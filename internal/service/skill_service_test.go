@@ -183,3 +183,41 @@ func TestApplyDecayToAll(t *testing.T) {
 		t.Fatalf("old skill exp not decayed")
 	}
 }
+
+// diffOnlyPolicy 只实现 ExpPolicy，不计阅读经验
+type diffOnlyPolicy struct{}
+
+func (diffOnlyPolicy) CalcDiffExp(diffs []schema.Diff) float64 { return 1 }
+
+func TestApplyReading(t *testing.T) {
+	ctx := context.Background()
+	visits := []schema.BrowserEvent{
+		{ID: 1, Timestamp: 1000, Domain: "pkg.go.dev", Title: "net/http", Duration: 1200},
+		{ID: 2, Timestamp: 2000, Domain: "go.dev", Duration: 30}, // 扫读
+		{ID: 3, Timestamp: 3000, Domain: "react.dev", Duration: 0},
+		{ID: 4, Timestamp: 4000, Domain: "news.ycombinator.com", Duration: 1800},
+		{ID: 5, Timestamp: 5000, Domain: "docs.djangoproject.com", Duration: 600},
+	}
+	domains := NewDomainClassifier([]DomainRule{{Domain: "djangoproject.com", Skill: "Django", SkillCategory: "framework"}})
+
+	repo := newFakeSkillRepo()
+	svc := NewSkillService(repo, fakeDiffRepo{}, nil, DefaultExpPolicy{})
+	if err := svc.ApplyReading(ctx, visits, domains); err != nil {
+		t.Fatalf("ApplyReading error: %v", err)
+	}
+	if len(repo.items) != 2 {
+		t.Fatalf("skills=%v, want go and django", repo.items)
+	}
+	if g := repo.items["go"]; g == nil || g.Category != "language" || g.Exp != 2 {
+		t.Fatalf("go skill unexpected: %+v", g)
+	}
+	if d := repo.items["django"]; d == nil || d.Exp != 1 {
+		t.Fatalf("django skill unexpected: %+v", d)
+	}
+
+	repo = newFakeSkillRepo()
+	svc = NewSkillService(repo, fakeDiffRepo{}, nil, diffOnlyPolicy{})
+	if err := svc.ApplyReading(ctx, visits, domains); err != nil || len(repo.items) != 0 {
+		t.Fatalf("policy without ReadingExpPolicy should not add exp: %v %v", err, repo.items)
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/collector"
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+const terminalCommandMaxRunes = 1990 // 截断后加省略号仍在 schema 的 2000 以内
+
+// TerminalService 终端命令持久化服务
+type TerminalService struct {
+	collector   *collector.TerminalCollector
+	repo        TerminalCommandRepository
+	stopChan    chan struct{}
+	wg          sync.WaitGroup
+	running     bool
+	onPersisted func(count int)
+	sanitizer   *privacy.Sanitizer
+
+	lastPersistAt atomic.Int64
+	persistErrors atomic.Int64
+	lastErrorAt   atomic.Int64
+	lastErrorMsg  atomic.Value // string
+}
+
+// NewTerminalService 创建终端命令服务
+func NewTerminalService(collector *collector.TerminalCollector, repo TerminalCommandRepository) *TerminalService {
+	return &TerminalService{
+		collector: collector,
+		repo:      repo,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// SetOnPersisted 设置持久化后的回调函数
+func (s *TerminalService) SetOnPersisted(fn func(count int)) {
+	s.onPersisted = fn
+}
+
+// SetSanitizer 设置命令脱敏器（应使用 privacy.NewCommandSanitizer）
+func (s *TerminalService) SetSanitizer(z *privacy.Sanitizer) {
+	s.sanitizer = z
+}
+
+// Start 启动服务
+func (s *TerminalService) Start(ctx context.Context) error {
+	if s.running {
+		return nil
+	}
+	s.running = true
+	slog.Info("终端命令服务启动")
+
+	if err := s.collector.Start(ctx); err != nil {
+		return err
+	}
+	s.wg.Add(1)
+	go s.processLoop(ctx)
+	return nil
+}
+
+// Stop 停止服务
+func (s *TerminalService) Stop() error {
+	if !s.running {
+		return nil
+	}
+	_ = s.collector.Stop()
+	close(s.stopChan)
+	s.wg.Wait()
+	s.running = false
+	slog.Info("终端命令服务已停止")
+	return nil
+}
+
+// processLoop 采集器按轮询批量产出：收到后把通道里已到达的一并写入
+func (s *TerminalService) processLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	events := s.collector.Events()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case cmd, ok := <-events:
+			if !ok {
+				return
+			}
+			batch := []*schema.TerminalCommand{cmd}
+		drain:
+			for len(batch) < 100 {
+				select {
+				case more, ok := <-events:
+					if !ok {
+						break drain
+					}
+					batch = append(batch, more)
+				default:
+					break drain
+				}
+			}
+			s.persist(ctx, batch)
+		}
+	}
+}
+
+func (s *TerminalService) persist(ctx context.Context, cmds []*schema.TerminalCommand) {
+	kept := cmds[:0]
+	for _, c := range cmds {
+		if s.sanitizer != nil {
+			c.Command = s.sanitizer.SanitizeCommand(c.Command)
+		}
+		c.Command = truncateRunes(strings.TrimSpace(c.Command), terminalCommandMaxRunes)
+		if c.Command != "" {
+			kept = append(kept, c)
+		}
+	}
+
+	inserted, err := s.repo.BatchInsert(ctx, kept)
+	if err != nil {
+		s.persistErrors.Add(1)
+		s.lastErrorAt.Store(time.Now().UnixMilli())
+		s.lastErrorMsg.Store(err.Error())
+		slog.Error("保存终端命令失败", "error", err)
+		return
+	}
+	s.lastPersistAt.Store(time.Now().UnixMilli())
+	if inserted > 0 {
+		slog.Debug("终端命令已保存", "count", inserted)
+		if s.onPersisted != nil {
+			s.onPersisted(int(inserted))
+		}
+	}
+}
+
+type TerminalServiceStats struct {
+	Running       bool   `json:"running"`
+	LastPersistAt int64  `json:"last_persist_at"`
+	PersistErrors int64  `json:"persist_errors"`
+	LastErrorAt   int64  `json:"last_error_at"`
+	LastError     string `json:"last_error"`
+}
+
+func (s *TerminalService) Stats() TerminalServiceStats {
+	if s == nil {
+		return TerminalServiceStats{}
+	}
+	raw := s.lastErrorMsg.Load()
+	msg, _ := raw.(string)
+	return TerminalServiceStats{
+		Running:       s.running,
+		LastPersistAt: s.lastPersistAt.Load(),
+		PersistErrors: s.persistErrors.Load(),
+		LastErrorAt:   s.lastErrorAt.Load(),
+		LastError:     msg,
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yuqie6/WorkMirror/internal/pkg/privacy"
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+type fakeTerminalCommandRepo struct {
+	inserted []*schema.TerminalCommand
+	cmds     []schema.TerminalCommand
+}
+
+func (f *fakeTerminalCommandRepo) BatchInsert(ctx context.Context, cmds []*schema.TerminalCommand) (int64, error) {
+	f.inserted = append(f.inserted, cmds...)
+	return int64(len(cmds)), nil
+}
+func (f *fakeTerminalCommandRepo) GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.TerminalCommand, error) {
+	var out []schema.TerminalCommand
+	for _, c := range f.cmds {
+		if c.Timestamp >= startTime && c.Timestamp <= endTime {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+func (f *fakeTerminalCommandRepo) GetByIDs(ctx context.Context, ids []int64) ([]schema.TerminalCommand, error) {
+	var out []schema.TerminalCommand
+	for _, id := range ids {
+		for _, c := range f.cmds {
+			if c.ID == id {
+				out = append(out, c)
+			}
+		}
+	}
+	return out, nil
+}
+
+func TestTerminalService_persistSanitizesCommands(t *testing.T) {
+	repo := &fakeTerminalCommandRepo{}
+	svc := NewTerminalService(nil, repo)
+	svc.SetSanitizer(privacy.NewCommandSanitizer([]string{`(?i)internal\.corp\S*`}))
+	var notified int
+	svc.SetOnPersisted(func(count int) { notified += count })
+
+	svc.persist(context.Background(), []*schema.TerminalCommand{
+		{Shell: "zsh", Timestamp: 1, Command: "kubectl --token=abc123 get pods"},
+		{Shell: "bash", Timestamp: 2, Command: "curl https://api.internal.corp/v1"},
+		{Shell: "bash", Timestamp: 3, Command: "   "},
+		{Shell: "fish", Timestamp: 4, Command: strings.Repeat("x", 3000)},
+	})
+
+	if len(repo.inserted) != 3 || notified != 3 {
+		t.Fatalf("inserted=%d notified=%d, want 3", len(repo.inserted), notified)
+	}
+	if got := repo.inserted[0].Command; strings.Contains(got, "abc123") {
+		t.Fatalf("flag token not masked: %q", got)
+	}
+	if got := repo.inserted[1].Command; strings.Contains(got, "internal.corp") {
+		t.Fatalf("extra pattern not applied: %q", got)
+	}
+	if n := len([]rune(repo.inserted[2].Command)); n > 2000 {
+		t.Fatalf("command not truncated: %d runes", n)
+	}
+}
+
+func TestBuildSessionsForRange_TerminalCommandsAttachedAsEvidence(t *testing.T) {
+	ctx := context.Background()
+	baseTs := time.Now().Truncate(time.Hour).UnixMilli()
+
+	// 只有终端命令（窗口事件很短）：命令作为证据，短会话得以保留
+	events := []schema.Event{
+		{Timestamp: baseTs, AppName: "WindowsTerminal.exe", Duration: 30},
+	}
+	cmds := &fakeTerminalCommandRepo{cmds: []schema.TerminalCommand{
+		{ID: 11, Shell: "powershell", Timestamp: baseTs + 10*1000, Command: "kubectl rollout status deploy/api"},
+		{ID: 12, Shell: "powershell", Timestamp: baseTs + 60*1000, Command: "kubectl logs deploy/api"},
+		{ID: 13, Shell: "powershell", Timestamp: baseTs + 3*60*60*1000, Command: "区间外"},
+	}}
+
+	sessionRepo := &fakeSessionRepoForSession{}
+	svc := NewSessionService(
+		fakeEventRepoForSession{events: events},
+		fakeDiffRepoForSession{},
+		fakeBrowserRepoForSession{},
+		sessionRepo,
+		nil,
+		&SessionServiceConfig{IdleGapMinutes: 6, MinSessionMinutes: 2},
+	)
+	svc.SetTerminalCommandRepository(cmds)
+
+	if _, err := svc.BuildSessionsForRange(ctx, baseTs, baseTs+10*60*1000); err != nil {
+		t.Fatalf("BuildSessionsForRange error: %v", err)
+	}
+	if len(sessionRepo.sessions) != 1 {
+		t.Fatalf("persisted=%d, want 1", len(sessionRepo.sessions))
+	}
+	sess := sessionRepo.sessions[0]
+	ids := getSessionTerminalCommandIDs(sess.Metadata)
+	if len(ids) != 2 || ids[0] != 11 || ids[1] != 12 {
+		t.Fatalf("terminal_command_ids=%v, want [11 12]", ids)
+	}
+	if sess.EndTime < baseTs+60*1000 {
+		t.Fatalf("session should extend to the last command, end=%d", sess.EndTime)
+	}
+}
@@ -1,9 +1,8 @@
-//go:build windows
-
 package service
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"sync"
 	"sync/atomic"
@@ -14,6 +13,11 @@ import (
 	"github.com/yuqie6/WorkMirror/internal/schema"
 )
 
+var (
+	ErrTrackerNotRunning = errors.New("追踪服务未运行")
+	ErrTrackerQueueFull  = errors.New("事件写入队列已满")
+)
+
 // TrackerService 追踪服务 - 负责接收事件并批量写入数据库
 type TrackerService struct {
 	collector      collector.Collector
@@ -32,6 +36,7 @@ type TrackerService struct {
 	writeChan     chan []schema.Event
 	writerDone    chan struct{}
 	writeQueueCap int
+	writeClosed   bool // 受 bufferMu 保护；Stop 开始最终刷新后拒绝外部写入
 
 	lastPersistAt  atomic.Int64
 	lastErrorAt    atomic.Int64
@@ -131,6 +136,11 @@ func (t *TrackerService) Stop() error {
 	// 尽量把 collector 的剩余缓冲也处理掉（Stop 场景优先“尽量不丢”而不是“保活”）
 	t.drainCollectorEvents()
 
+	// 先拒绝新的外部写入，再做最终刷新，保证已接受的事件都能入队
+	t.bufferMu.Lock()
+	t.writeClosed = true
+	t.bufferMu.Unlock()
+
 	// 最终刷新：将缓冲区剩余事件阻塞写入队列（Stop 场景避免丢批次）
 	t.flushToWriterBlocking()
 
@@ -236,6 +246,40 @@ func (t *TrackerService) handleEvent(event *schema.Event) {
 	}
 }
 
+// Ingest 接收外部写入的事件：与窗口事件一样脱敏标题，连同缓冲区里的事件立即分批放入写入队列。
+// 写入队列放不下全部批次时整批拒绝（由调用方重试），而不是像采集链路那样静默丢弃；
+// 返回 nil 即表示事件都已进入写入队列。
+func (t *TrackerService) Ingest(events []schema.Event) error {
+	if !t.running.Load() {
+		return ErrTrackerNotRunning
+	}
+
+	t.bufferMu.Lock()
+	defer t.bufferMu.Unlock()
+	if t.writeClosed {
+		return ErrTrackerNotRunning
+	}
+	// 写入队列只在持有 bufferMu 时入队，检查后空位只会变多
+	size := max(t.flushBatchSize, 1)
+	batches := (len(t.buffer) + len(events) + size - 1) / size
+	if t.writeQueueCap-len(t.writeChan) < batches {
+		return ErrTrackerQueueFull
+	}
+	for _, ev := range events {
+		if t.sanitizer != nil {
+			ev.Title = t.sanitizer.SanitizeWindowTitle(ev.Title)
+		}
+		t.buffer = append(t.buffer, ev)
+	}
+	for i := 0; i < len(t.buffer); i += size {
+		batch := make([]schema.Event, min(size, len(t.buffer)-i))
+		copy(batch, t.buffer[i:])
+		t.writeChan <- batch
+	}
+	t.buffer = t.buffer[:0]
+	return nil
+}
+
 func (t *TrackerService) appendEventNoFlush(event *schema.Event) {
 	if t == nil || event == nil {
 		return
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yuqie6/WorkMirror/internal/schema"
+)
+
+func TestTrackerService_IngestReservesQueueForAllBatches(t *testing.T) {
+	tracker := NewTrackerService(nil, nil, &TrackerConfig{FlushBatchSize: 100, FlushIntervalSec: 5})
+	tracker.running.Store(true)
+	for i := 0; i < tracker.writeQueueCap-2; i++ {
+		tracker.writeChan <- []schema.Event{{}}
+	}
+	events := func(n int) []schema.Event {
+		return make([]schema.Event, n)
+	}
+
+	// 需要 3 个批次但只剩 2 个空位：整批拒绝，不留下部分事件
+	if err := tracker.Ingest(events(300)); !errors.Is(err, ErrTrackerQueueFull) {
+		t.Fatalf("err=%v, want queue full", err)
+	}
+	if st := tracker.Stats(); st.BufferSize != 0 || st.WriteQueueLen != tracker.writeQueueCap-2 {
+		t.Fatalf("rejected batch must not be buffered: %+v", st)
+	}
+
+	tracker.buffer = append(tracker.buffer, schema.Event{})
+	if err := tracker.Ingest(events(150)); err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	st := tracker.Stats()
+	if st.BufferSize != 0 || st.WriteQueueLen != tracker.writeQueueCap || st.DroppedBatches != 0 {
+		t.Fatalf("accepted events should all be queued: %+v", st)
+	}
+	var queued int
+	for len(tracker.writeChan) > 0 {
+		queued += len(<-tracker.writeChan)
+	}
+	if queued != tracker.writeQueueCap-2+151 {
+		t.Fatalf("queued=%d", queued)
+	}
+}
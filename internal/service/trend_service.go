@@ -17,12 +17,19 @@ type TrendService struct {
 	diffRepo     DiffRepository
 	eventRepo    EventRepository
 	sessionRepo  sessionTimeRangeReader
+	browserRepo  browserTrendReader // 可选：未启用浏览器采集时为 nil
+	domains      *DomainClassifier  // 为 nil 时使用内置域名分类
 }
 
 type sessionTimeRangeReader interface {
 	GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.Session, error)
 }
 
+type browserTrendReader interface {
+	GetDomainStats(ctx context.Context, startTime, endTime int64, limit int) ([]repository.DomainStat, error)
+	GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.BrowserEvent, error)
+}
+
 // NewTrendService 创建趋势服务
 func NewTrendService(
 	skillRepo SkillRepository,
@@ -40,6 +47,16 @@ func NewTrendService(
 	}
 }
 
+// SetBrowserRepository 设置浏览记录仓储（可选），用于统计阅读时长
+func (s *TrendService) SetBrowserRepository(repo browserTrendReader) {
+	s.browserRepo = repo
+}
+
+// SetDomainClassifier 设置域名分类规则（含用户自定义）
+func (s *TrendService) SetDomainClassifier(c *DomainClassifier) {
+	s.domains = c
+}
+
 // TrendPeriod 趋势周期
 type TrendPeriod string
 
@@ -70,11 +87,37 @@ type LanguageTrend struct {
 	Percentage   float64
 }
 
+// DomainTrend 域名浏览统计（时长来自访问停留时间）
+type DomainTrend struct {
+	Domain   string
+	Category string
+	Skill    string
+	Visits   int64
+	Minutes  int64
+}
+
+// CategoryTrend 域名分类的浏览统计
+type CategoryTrend struct {
+	Category string
+	Visits   int64
+	Minutes  int64
+}
+
+// SessionBrowsing 单个会话内按分类的浏览时长
+type SessionBrowsing struct {
+	SessionID  int64
+	Date       string
+	StartTime  int64
+	EndTime    int64
+	Categories []CategoryTrend
+}
+
 type DailyStat struct {
-	Date            string
-	TotalDiffs      int64
-	TotalCodingMins int64
-	SessionCount    int64
+	Date               string
+	TotalDiffs         int64
+	TotalCodingMins    int64
+	SessionCount       int64
+	BrowsingCategories []CategoryTrend
 }
 
 // TrendReport 趋势报告
@@ -89,6 +132,11 @@ type TrendReport struct {
 	AvgDiffsPerDay  float64
 	Bottlenecks     []string
 	DailyStats      []DailyStat
+	TopDomains      []DomainTrend
+	BrowsingMins    int64
+
+	BrowsingCategories []CategoryTrend
+	SessionBrowsing    []SessionBrowsing
 }
 
 // GetTrendReport 获取趋势报告
@@ -254,8 +302,17 @@ func (s *TrendService) GetTrendReport(ctx context.Context, period TrendPeriod) (
 
 	totalCodingMins := SumCodingMinutesFromAppStats(appStats)
 
+	topDomains, browsingMins, err := s.domainTrends(ctx, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
 	// Heatmap 用 daily_stats：按自然日统计，返回固定 days 个点（含今天）
 	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	browsing, err := s.browsingBreakdown(ctx, startTime, endTime, dayStart.AddDate(0, 0, -(days-1)).UnixMilli())
+	if err != nil {
+		return nil, err
+	}
 	dailyStats := make([]DailyStat, 0, days)
 	for i := days - 1; i >= 0; i-- {
 		d := dayStart.AddDate(0, 0, -i)
@@ -281,11 +338,13 @@ func (s *TrendService) GetTrendReport(ctx context.Context, period TrendPeriod) (
 			sessionCount = int64(len(sessions))
 		}
 
+		date := d.Format("2006-01-02")
 		dailyStats = append(dailyStats, DailyStat{
-			Date:            d.Format("2006-01-02"),
-			TotalDiffs:      dayDiffs,
-			TotalCodingMins: dayCodingMins,
-			SessionCount:    sessionCount,
+			Date:               date,
+			TotalDiffs:         dayDiffs,
+			TotalCodingMins:    dayCodingMins,
+			SessionCount:       sessionCount,
+			BrowsingCategories: browsing.daily[date].trends(),
 		})
 	}
 
@@ -303,9 +362,154 @@ func (s *TrendService) GetTrendReport(ctx context.Context, period TrendPeriod) (
 		AvgDiffsPerDay:  float64(totalDiffs) / float64(days),
 		Bottlenecks:     bottlenecks,
 		DailyStats:      dailyStats,
+		TopDomains:      topDomains,
+		BrowsingMins:    browsingMins,
+
+		BrowsingCategories: browsing.total.trends(),
+		SessionBrowsing:    browsing.sessions,
 	}, nil
 }
 
+// domainTrends 按停留时长排序的前 10 个域名及总浏览分钟数
+func (s *TrendService) domainTrends(ctx context.Context, startTime, endTime int64) ([]DomainTrend, int64, error) {
+	if s.browserRepo == nil {
+		return nil, 0, nil
+	}
+	stats, err := s.browserRepo.GetDomainStats(ctx, startTime, endTime, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	var totalSec int64
+	out := make([]DomainTrend, 0, len(stats))
+	for _, st := range stats {
+		if st.Domain == "" {
+			continue
+		}
+		totalSec += int64(st.TotalDuration)
+		class := s.domains.Classify(st.Domain)
+		out = append(out, DomainTrend{
+			Domain:   st.Domain,
+			Category: class.Category,
+			Skill:    class.Skill,
+			Visits:   st.VisitCount,
+			Minutes:  int64(st.TotalDuration) / 60,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Minutes != out[j].Minutes {
+			return out[i].Minutes > out[j].Minutes
+		}
+		if out[i].Visits != out[j].Visits {
+			return out[i].Visits > out[j].Visits
+		}
+		return out[i].Domain < out[j].Domain
+	})
+	if len(out) > 10 {
+		out = out[:10]
+	}
+	return out, totalSec / 60, nil
+}
+
+// categoryAcc 按分类累计访问次数与停留秒数
+type categoryAcc map[string]*struct{ visits, seconds int64 }
+
+func (a categoryAcc) add(category string, seconds int) {
+	e, ok := a[category]
+	if !ok {
+		e = &struct{ visits, seconds int64 }{}
+		a[category] = e
+	}
+	e.visits++
+	e.seconds += int64(seconds)
+}
+
+// trends 按时长、访问次数降序
+func (a categoryAcc) trends() []CategoryTrend {
+	out := make([]CategoryTrend, 0, len(a))
+	for cat, e := range a {
+		out = append(out, CategoryTrend{Category: cat, Visits: e.visits, Minutes: e.seconds / 60})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Minutes != out[j].Minutes {
+			return out[i].Minutes > out[j].Minutes
+		}
+		if out[i].Visits != out[j].Visits {
+			return out[i].Visits > out[j].Visits
+		}
+		return out[i].Category < out[j].Category
+	})
+	return out
+}
+
+type browsingStats struct {
+	total    categoryAcc
+	daily    map[string]categoryAcc // date -> 分类
+	sessions []SessionBrowsing
+}
+
+// browsingBreakdown 按域名分类汇总浏览：整个周期、每个自然日（从 dailyFrom 起）与周期内的每个会话
+func (s *TrendService) browsingBreakdown(ctx context.Context, startTime, endTime, dailyFrom int64) (*browsingStats, error) {
+	out := &browsingStats{total: categoryAcc{}, daily: make(map[string]categoryAcc)}
+	if s.browserRepo == nil {
+		return out, nil
+	}
+	visits, err := s.browserRepo.GetByTimeRange(ctx, min(startTime, dailyFrom), endTime)
+	if err != nil {
+		return nil, err
+	}
+	var sessions []schema.Session
+	if s.sessionRepo != nil {
+		if sessions, err = s.sessionRepo.GetByTimeRange(ctx, startTime, endTime); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].StartTime < sessions[j].StartTime })
+	perSession := make([]categoryAcc, len(sessions))
+
+	for _, v := range visits {
+		if v.Transition == schema.BrowserTransitionRedirect {
+			continue
+		}
+		category := s.domains.Classify(v.Domain).Category
+		if v.Timestamp >= dailyFrom {
+			date := time.UnixMilli(v.Timestamp).Format("2006-01-02")
+			if out.daily[date] == nil {
+				out.daily[date] = categoryAcc{}
+			}
+			out.daily[date].add(category, v.Duration)
+		}
+		if v.Timestamp < startTime {
+			continue
+		}
+		out.total.add(category, v.Duration)
+
+		// 最后一个开始时间不晚于访问的会话
+		i := sort.Search(len(sessions), func(i int) bool { return sessions[i].StartTime > v.Timestamp }) - 1
+		if i < 0 || v.Timestamp > sessions[i].EndTime {
+			continue
+		}
+		if perSession[i] == nil {
+			perSession[i] = categoryAcc{}
+		}
+		perSession[i].add(category, v.Duration)
+	}
+
+	for i, acc := range perSession {
+		if acc == nil {
+			continue
+		}
+		sess := sessions[i]
+		out.sessions = append(out.sessions, SessionBrowsing{
+			SessionID:  sess.ID,
+			Date:       sess.Date,
+			StartTime:  sess.StartTime,
+			EndTime:    sess.EndTime,
+			Categories: acc.trends(),
+		})
+	}
+	return out, nil
+}
+
 // detectBottlenecks 检测技能瓶颈
 func (s *TrendService) detectBottlenecks(skills []SkillTrend, totalCodingMins int64) []string {
 	bottlenecks := []string{}
@@ -182,6 +182,24 @@ func (f fakeSessionRepoForTrend) GetByTimeRange(ctx context.Context, startTime,
 	return out, nil
 }
 
+type fakeBrowserRepoForTrend struct {
+	stats  []repository.DomainStat
+	visits []schema.BrowserEvent
+}
+
+func (f fakeBrowserRepoForTrend) GetDomainStats(ctx context.Context, startTime, endTime int64, limit int) ([]repository.DomainStat, error) {
+	return f.stats, nil
+}
+func (f fakeBrowserRepoForTrend) GetByTimeRange(ctx context.Context, startTime, endTime int64) ([]schema.BrowserEvent, error) {
+	var out []schema.BrowserEvent
+	for _, v := range f.visits {
+		if v.Timestamp >= startTime && v.Timestamp <= endTime {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
 func TestGetTrendReport(t *testing.T) {
 	ctx := context.Background()
 	now := time.Now()
@@ -226,6 +244,11 @@ func TestGetTrendReport(t *testing.T) {
 		fakeSessionRepoForTrend{sessions: sessions},
 	)
 
+	svc.SetBrowserRepository(fakeBrowserRepoForTrend{stats: []repository.DomainStat{
+		{Domain: "news.ycombinator.com", VisitCount: 40, TotalDuration: 300},
+		{Domain: "go.dev", VisitCount: 3, TotalDuration: 2400},
+	}})
+
 	report, err := svc.GetTrendReport(ctx, TrendPeriod7Days)
 	if err != nil {
 		t.Fatalf("GetTrendReport error: %v", err)
@@ -258,6 +281,82 @@ func TestGetTrendReport(t *testing.T) {
 	if len(report.DailyStats) != 7 {
 		t.Fatalf("dailyStats len=%d, want 7", len(report.DailyStats))
 	}
+	// 按停留时长而非访问次数排序
+	if report.BrowsingMins != 45 || len(report.TopDomains) != 2 || report.TopDomains[0].Domain != "go.dev" || report.TopDomains[0].Minutes != 40 {
+		t.Fatalf("domain trends unexpected: mins=%d %+v", report.BrowsingMins, report.TopDomains)
+	}
+	if d := report.TopDomains[0]; d.Category != DomainCategoryDocs || d.Skill != "Go" {
+		t.Fatalf("go.dev should be docs mapped to Go: %+v", d)
+	}
+}
+
+func TestGetTrendReport_BrowsingCategories(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	at := func(d time.Duration) int64 { return now.Add(-d).UnixMilli() }
+
+	sessions := []schema.Session{
+		{ID: 1, Date: "s1", StartTime: at(3 * time.Hour), EndTime: at(2 * time.Hour)},
+		{ID: 2, Date: "s2", StartTime: at(time.Hour), EndTime: at(30 * time.Minute)},
+	}
+	visits := []schema.BrowserEvent{
+		{Timestamp: at(170 * time.Minute), Domain: "pkg.go.dev", Duration: 600},
+		{Timestamp: at(160 * time.Minute), Domain: "localhost:5173", Duration: 300},
+		{Timestamp: at(150 * time.Minute), Domain: "t.co", Duration: 0, Transition: schema.BrowserTransitionRedirect},
+		{Timestamp: at(50 * time.Minute), Domain: "www.youtube.com", Duration: 1200},
+		{Timestamp: at(40 * time.Minute), Domain: "wiki.corp", Duration: 240},
+		// 不在任何会话内
+		{Timestamp: at(10 * time.Minute), Domain: "docs.djangoproject.com", Duration: 120},
+	}
+
+	svc := NewTrendService(
+		fakeSkillRepoForTrend{},
+		nil,
+		fakeDiffRepoForTrend{},
+		fakeEventRepoForTrend{},
+		fakeSessionRepoForTrend{sessions: sessions},
+	)
+	svc.SetBrowserRepository(fakeBrowserRepoForTrend{visits: visits})
+	svc.SetDomainClassifier(NewDomainClassifier([]DomainRule{
+		{Domain: "wiki.corp", Category: "work"},
+		{Domain: "djangoproject.com", Category: DomainCategoryDocs, Skill: "Django"},
+	}))
+
+	report, err := svc.GetTrendReport(ctx, TrendPeriod7Days)
+	if err != nil {
+		t.Fatalf("GetTrendReport error: %v", err)
+	}
+
+	want := map[string]int64{DomainCategoryVideo: 20, DomainCategoryDocs: 12, DomainCategoryDev: 5, "work": 4}
+	if len(report.BrowsingCategories) != len(want) || report.BrowsingCategories[0].Category != DomainCategoryVideo {
+		t.Fatalf("unexpected category totals: %+v", report.BrowsingCategories)
+	}
+	for _, c := range report.BrowsingCategories {
+		if want[c.Category] != c.Minutes {
+			t.Fatalf("category %s minutes=%d, want %d", c.Category, c.Minutes, want[c.Category])
+		}
+	}
+
+	var daySum int64
+	for _, d := range report.DailyStats {
+		for _, c := range d.BrowsingCategories {
+			daySum += c.Minutes
+		}
+	}
+	if daySum != 41 {
+		t.Fatalf("daily category minutes=%d, want 41", daySum)
+	}
+
+	if len(report.SessionBrowsing) != 2 {
+		t.Fatalf("session breakdown=%+v, want 2 sessions", report.SessionBrowsing)
+	}
+	s1, s2 := report.SessionBrowsing[0], report.SessionBrowsing[1]
+	if s1.SessionID != 1 || len(s1.Categories) != 2 || s1.Categories[0].Category != DomainCategoryDocs || s1.Categories[1].Category != DomainCategoryDev {
+		t.Fatalf("unexpected session 1 breakdown: %+v", s1)
+	}
+	if s2.SessionID != 2 || len(s2.Categories) != 2 || s2.Categories[0].Minutes != 20 {
+		t.Fatalf("unexpected session 2 breakdown: %+v", s2)
+	}
 }
 
 func TestDetectBottlenecks(t *testing.T) {
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"slices"
 	"sort"
 	"strings"
 
@@ -8,6 +9,12 @@ import (
 	"github.com/yuqie6/WorkMirror/internal/schema"
 )
 
+// isWindowEvent 是否为前台窗口事件（旧数据 source 可能为空；含 ActivityWatch 导入的窗口事件）。
+// 只有窗口事件的时长代表前台占用，编辑器心跳与外部写入的事件不参与应用时长统计。
+func isWindowEvent(e schema.Event) bool {
+	return slices.Contains(schema.WindowEventSources, e.Source)
+}
+
 type windowTitleAgg struct {
 	app   string
 	title string
@@ -18,6 +25,7 @@ type windowTitleAgg struct {
 // TopWindowTitleInfosFromEvents 从窗口事件中聚合“标题证据”。
 // - 仅用于摘要/解释性证据；不是精确时间轴。
 // - 会过滤空 app/title，并做最小化 trim。
+// - 编辑器插件心跳的标题为 "文件 · 项目"，时长按心跳间隔推断，是比窗口标题更精确的文件级证据。
 func TopWindowTitleInfosFromEvents(events []schema.Event, limit int) []ai.WindowTitleInfo {
 	if len(events) == 0 || limit == 0 {
 		return nil
@@ -26,14 +34,18 @@ func TopWindowTitleInfosFromEvents(events []schema.Event, limit int) []ai.Window
 		limit = 0
 	}
 
+	beatSec := editorHeartbeatDurations(events)
 	byKey := make(map[string]*windowTitleAgg, 64)
-	for _, e := range events {
+	for i, e := range events {
 		app := strings.TrimSpace(e.AppName)
 		title := strings.TrimSpace(e.Title)
 		if app == "" || title == "" {
 			continue
 		}
 		sec := e.Duration
+		if isEditorHeartbeat(e) {
+			sec = beatSec[i]
+		}
 		if sec < 0 {
 			sec = 0
 		}
@@ -27,6 +27,10 @@ func OpenTestDB(t *testing.T) *gorm.DB {
 		&schema.Diff{},
 		&schema.DailySummary{},
 		&schema.BrowserEvent{},
+		&schema.GitCommit{},
+		&schema.SearchTerm{},
+		&schema.TerminalCommand{},
+		&schema.CalendarEvent{},
 	); err != nil {
 		t.Fatalf("migrate test db: %v", err)
 	}